@@ -0,0 +1,38 @@
+package graphql
+
+import (
+	"build-service-gin/internal/domains"
+	"build-service-gin/pkg/helpers/resp"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+// encodeCursor returns the opaque cursor of the page following item.
+func encodeCursor(item domains.UserTransactionHistory) string {
+	cursor := domains.HistoryCursor{TransactionID: item.TransactionID}
+	if item.CreatedAt != nil {
+		cursor.CreatedAt = *item.CreatedAt
+	}
+	b, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeCursor returns the position of an encodeCursor cursor, nil for an empty one.
+func decodeCursor(after string) (*domains.HistoryCursor, error) {
+	if after == "" {
+		return nil, nil
+	}
+
+	var cursor domains.HistoryCursor
+	b, err := base64.RawURLEncoding.DecodeString(after)
+	if err == nil {
+		err = json.Unmarshal(b, &cursor)
+	}
+	if err != nil || cursor.CreatedAt.Equal(time.Time{}) && cursor.TransactionID == "" {
+		err := resp.NewError(resp.Invalid, resp.ErrDataInvalid, "after is not a cursor of this connection")
+		err.Fields = []resp.FieldError{{Field: "after", Rule: "cursor", Message: "after is not a valid cursor"}}
+		return nil, err
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,56 @@
+package graphql
+
+import (
+	"build-service-gin/internal/domains"
+	"build-service-gin/pkg/helpers/resp"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDecodeCursor(t *testing.T) {
+	createdAt := time.Date(2026, 3, 1, 10, 30, 0, 123000000, time.UTC)
+
+	tests := []struct {
+		name    string
+		after   string
+		want    *domains.HistoryCursor
+		wantErr bool
+	}{
+		{name: "empty", after: ""},
+		{
+			name:  "encoded item",
+			after: encodeCursor(domains.UserTransactionHistory{TransactionID: "tx-1", CreatedAt: &createdAt}),
+			want:  &domains.HistoryCursor{CreatedAt: createdAt, TransactionID: "tx-1"},
+		},
+		{
+			name:  "item without a creation time",
+			after: encodeCursor(domains.UserTransactionHistory{TransactionID: "tx-2"}),
+			want:  &domains.HistoryCursor{TransactionID: "tx-2"},
+		},
+		{name: "not base64", after: "not a cursor!", wantErr: true},
+		{name: "padded base64", after: base64.URLEncoding.EncodeToString([]byte(`{"transactionID":"tx-10"}`)), wantErr: true},
+		{name: "not json", after: base64.RawURLEncoding.EncodeToString([]byte("tx-1")), wantErr: true},
+		{name: "empty position", after: base64.RawURLEncoding.EncodeToString([]byte(`{}`)), wantErr: true},
+		{name: "wrong types", after: base64.RawURLEncoding.EncodeToString([]byte(`{"createdAt":1}`)), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeCursor(tt.after)
+			if tt.wantErr {
+				var customErr *resp.CustomError
+				if !errors.As(err, &customErr) || !errors.Is(err, resp.Invalid) || len(customErr.Fields) != 1 || customErr.Fields[0].Field != "after" {
+					t.Fatalf("decodeCursor() = %v, want an invalid after field", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeCursor() = %v", err)
+			}
+			if (got == nil) != (tt.want == nil) || got != nil && (!got.CreatedAt.Equal(tt.want.CreatedAt) || got.TransactionID != tt.want.TransactionID) {
+				t.Errorf("decodeCursor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package graphql
+
+import (
+	"build-service-gin/api/http/middlewares"
+	"build-service-gin/common/logger"
+	"build-service-gin/pkg/helpers/resp"
+	"context"
+	"errors"
+	"net/http"
+)
+
+type accessKey struct{}
+
+// queryError is the error of a field, its extensions carry the error code and the invalid fields like the body of
+// the REST errors.
+type queryError struct {
+	message    string
+	extensions map[string]interface{}
+}
+
+func (e *queryError) Error() string {
+	return e.message
+}
+
+func (e *queryError) Extensions() map[string]interface{} {
+	return e.extensions
+}
+
+// newQueryError returns the GraphQL error of err, the GraphQL counterpart of the error middleware of the HTTP
+// server. The message is in the language of the request. Server errors are logged and their details are not sent
+// back.
+func newQueryError(ctx context.Context, err error) error {
+	var queryErr *queryError
+	if errors.As(err, &queryErr) {
+		return queryErr
+	}
+
+	status := resp.HTTPStatus(err)
+	lang := resp.LangFromContext(ctx)
+
+	code, description := int64(resp.ErrSystem), ""
+	var params map[string]interface{}
+	var fields []resp.FieldError
+	var customErr *resp.CustomError
+	if errors.As(err, &customErr) {
+		code, description, params, fields = customErr.ErrorCode, customErr.Description, customErr.Params, customErr.Fields
+	}
+
+	if status >= http.StatusInternalServerError {
+		logger.GetLogger().AddTraceInfoContextRequest(ctx).Error().Err(err).Int("status", status).Msg("query failed")
+		description = ""
+	}
+
+	rs := resp.BuildErrorRespParams(code, description, lang, params)
+	extensions := map[string]interface{}{
+		"errorCode":   rs.ErrorCode,
+		"description": rs.Description,
+		"status":      status,
+	}
+	if fields = resp.LocalizeFields(fields, lang); len(fields) > 0 {
+		extensions["errors"] = fields
+	}
+	return &queryError{message: rs.Message, extensions: extensions}
+}
+
+// renameField renames the invalid field from of err to to, for the arguments validated with a REST request model
+// under another name.
+func renameField(err *resp.CustomError, from, to string) *resp.CustomError {
+	for i := range err.Fields {
+		if err.Fields[i].Field == from {
+			err.Fields[i].Field = to
+		}
+	}
+	return err
+}
+
+func withAccess(ctx context.Context, access middlewares.Access) context.Context {
+	return context.WithValue(ctx, accessKey{}, access)
+}
+
+// accessFromContext returns the request of the query, written to the audit log of the denials.
+func accessFromContext(ctx context.Context) middlewares.Access {
+	access, _ := ctx.Value(accessKey{}).(middlewares.Access)
+	return access
+}
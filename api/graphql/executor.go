@@ -0,0 +1,76 @@
+// Package graphql serves the read API of the transaction histories: the balance, the stats and the transactions of
+// profiles, read in batches across the profiles of a query.
+package graphql
+
+import (
+	"build-service-gin/api/http/middlewares"
+	"build-service-gin/common/logger"
+	"build-service-gin/config"
+	"build-service-gin/internal/services"
+	"context"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/location"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// Request is a GraphQL query with its variables.
+type Request struct {
+	Query         string
+	OperationName string
+	Variables     map[string]interface{}
+}
+
+type Executor struct {
+	schema         graphql.Schema
+	profileService services.IProfileService
+	conf           config.GraphQLConfig
+}
+
+func NewExecutor(profileService services.IProfileService, conf config.GraphQLConfig) *Executor {
+	schema, err := newSchema(&resolver{profileService: profileService, conf: conf})
+	if err != nil {
+		logger.GetLogger().Fatal().Err(err).Msg("build graphql schema failed")
+	}
+
+	return &Executor{
+		schema:         schema,
+		profileService: profileService,
+		conf:           conf,
+	}
+}
+
+// Execute runs req for the caller authenticated in ctx. Queries are validated against the schema and the depth and
+// complexity limits before anything is read. The errors are in the result, with the error code of the REST API in
+// their extensions.
+func (e *Executor) Execute(ctx context.Context, req Request, access middlewares.Access) *graphql.Result {
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(req.Query)})})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	validation := graphql.ValidateDocument(&e.schema, doc, nil)
+	if !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}
+	}
+
+	if err := checkLimits(e.conf, doc, req.OperationName, req.Variables); err != nil {
+		queryErr := newQueryError(ctx, err).(*queryError)
+		return &graphql.Result{Errors: []gqlerrors.FormattedError{{
+			Message:    queryErr.Error(),
+			Locations:  []location.SourceLocation{},
+			Extensions: queryErr.Extensions(),
+		}}}
+	}
+
+	ctx = withLoaders(withAccess(ctx, access), newLoaders(e.profileService))
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        e.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+}
@@ -0,0 +1,181 @@
+package graphql
+
+import (
+	"build-service-gin/api/http/middlewares"
+	"build-service-gin/common/logger"
+	"build-service-gin/common/utils"
+	"build-service-gin/config"
+	"build-service-gin/internal/domains"
+	"build-service-gin/internal/services"
+	"build-service-gin/pkg/auth"
+	"build-service-gin/repositories/user_transaction_history"
+	"context"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testSecret = "graphql-test-secret"
+
+func TestMain(m *testing.M) {
+	logger.InitLog("test")
+	os.Exit(m.Run())
+}
+
+type historyCall struct {
+	profileIDs []string
+	txTypes    []string
+	limit      int64
+}
+
+// fakeRepo records the batched reads, the other methods are not expected to be called.
+type fakeRepo struct {
+	user_transaction_history.IUserTransactionHistoryRepo
+	mu             sync.Mutex
+	historyCalls   []historyCall
+	summarizeCalls [][]string
+}
+
+func (r *fakeRepo) GetUserTransactionHistoryByProfiles(_ context.Context, profileIDs []string, txTypes []string, _ time.Time, _ string, _ *user_transaction_history.HistoryCursor, limit int64) ([]*user_transaction_history.ProfileTransactions, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.historyCalls = append(r.historyCalls, historyCall{profileIDs: slices.Sorted(slices.Values(profileIDs)), txTypes: txTypes, limit: limit})
+
+	histories := make([]*user_transaction_history.ProfileTransactions, 0, len(profileIDs))
+	for _, profileID := range profileIDs {
+		createdAt := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+		histories = append(histories, &user_transaction_history.ProfileTransactions{
+			ProfileID: profileID,
+			Items: []*user_transaction_history.UserTransactionHistory{
+				{TransactionID: profileID + "-tx", ProfileID: profileID, TransactionType: domains.TxTypeEarn, CreatedAt: &createdAt},
+			},
+		})
+	}
+	return histories, nil
+}
+
+func (r *fakeRepo) SummarizeByProfiles(_ context.Context, profileIDs []string, _ time.Time) ([]*user_transaction_history.StatusSummary, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.summarizeCalls = append(r.summarizeCalls, slices.Sorted(slices.Values(profileIDs)))
+	return nil, nil
+}
+
+func newTestExecutor(repo *fakeRepo) *Executor {
+	profileService := services.NewProfileService(&config.SystemConfig{}, nil, repo, nil, nil)
+	return NewExecutor(profileService, config.GraphQLConfig{MaxDepth: 8, MaxComplexity: 1000, MaxProfiles: 50})
+}
+
+// principalContext returns a context authenticated as a service granted scopes.
+func principalContext(t *testing.T, scopes ...string) context.Context {
+	t.Helper()
+	verifier, err := auth.NewVerifier(config.AuthConfig{HS256Secret: testSecret})
+	if err != nil {
+		t.Fatalf("NewVerifier() = %v", err)
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "service-1",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Type:  utils.IASTypeService,
+		Scope: strings.Join(scopes, " "),
+	}).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	principal, err := verifier.Verify(token)
+	if err != nil {
+		t.Fatalf("Verify() = %v", err)
+	}
+	return auth.WithPrincipal(context.Background(), principal)
+}
+
+func TestExecuteBatchesProfiles(t *testing.T) {
+	repo := &fakeRepo{}
+	result := newTestExecutor(repo).Execute(principalContext(t, auth.ScopeHistoryReadAny), Request{
+		Query: `{
+			profiles(ids: ["p1", "p2", "p3"]) {
+				id
+				balance { points }
+				stats { transactionCount }
+				transactions(first: 5, txType: TX_EARN) { edges { node { transactionID } } }
+			}
+		}`,
+	}, middlewares.Access{})
+	if len(result.Errors) > 0 {
+		t.Fatalf("Execute() errors = %v", result.Errors)
+	}
+
+	wantHistory := []historyCall{{profileIDs: []string{"p1", "p2", "p3"}, txTypes: []string{domains.TxTypeEarn}, limit: 6}}
+	if !slices.EqualFunc(repo.historyCalls, wantHistory, func(a, b historyCall) bool {
+		return slices.Equal(a.profileIDs, b.profileIDs) && slices.Equal(a.txTypes, b.txTypes) && a.limit == b.limit
+	}) {
+		t.Errorf("history reads = %+v, want %+v", repo.historyCalls, wantHistory)
+	}
+	// balance and stats of the last 0 months share the summary of a profile
+	wantSummarize := [][]string{{"p1", "p2", "p3"}}
+	if !slices.EqualFunc(repo.summarizeCalls, wantSummarize, slices.Equal[[]string]) {
+		t.Errorf("summary reads = %v, want %v", repo.summarizeCalls, wantSummarize)
+	}
+
+	profiles := result.Data.(map[string]interface{})["profiles"].([]interface{})
+	if len(profiles) != 3 {
+		t.Fatalf("profiles = %v, want 3", profiles)
+	}
+	for i, profileID := range []string{"p1", "p2", "p3"} {
+		profile := profiles[i].(map[string]interface{})
+		edges := profile["transactions"].(map[string]interface{})["edges"].([]interface{})
+		if profile["id"] != profileID || len(edges) != 1 || edges[0].(map[string]interface{})["node"].(map[string]interface{})["transactionID"] != profileID+"-tx" {
+			t.Errorf("profile %d = %v, want the transactions of %s", i, profile, profileID)
+		}
+	}
+}
+
+func TestExecuteBatchesPagesSeparately(t *testing.T) {
+	repo := &fakeRepo{}
+	result := newTestExecutor(repo).Execute(principalContext(t, auth.ScopeHistoryReadAny), Request{
+		Query: `{
+			profiles(ids: ["p1", "p2"]) {
+				recent: stats(recentMonth: 1) { transactionCount }
+				all: stats { transactionCount }
+				short: transactions(first: 1) { edges { cursor } }
+				long: transactions(first: 10) { edges { cursor } }
+			}
+		}`,
+	}, middlewares.Access{})
+	if len(result.Errors) > 0 {
+		t.Fatalf("Execute() errors = %v", result.Errors)
+	}
+
+	if len(repo.historyCalls) != 2 || len(repo.summarizeCalls) != 2 {
+		t.Fatalf("reads = %d history, %d summary, want one per distinct page", len(repo.historyCalls), len(repo.summarizeCalls))
+	}
+	for _, call := range repo.historyCalls {
+		if !slices.Equal(call.profileIDs, []string{"p1", "p2"}) {
+			t.Errorf("history read of %v, want both profiles", call.profileIDs)
+		}
+	}
+}
+
+func TestExecuteRejectsBeforeReading(t *testing.T) {
+	repo := &fakeRepo{}
+	result := newTestExecutor(repo).Execute(principalContext(t, auth.ScopeHistoryReadAny), Request{
+		Query: `{ profiles(ids: ["p1", "p2", "p3"]) { balance { points } transactions(first: 100) { edges { node { transactionID status } } } } }`,
+	}, middlewares.Access{})
+
+	if len(result.Errors) != 1 || result.Errors[0].Extensions["description"] != "query complexity exceeds the limit of 1000" {
+		t.Fatalf("Execute() errors = %v, want the complexity limit", result.Errors)
+	}
+	if result.Data != nil {
+		t.Errorf("Execute() data = %v, want none", result.Data)
+	}
+	if len(repo.historyCalls) != 0 || len(repo.summarizeCalls) != 0 {
+		t.Errorf("reads = %d history, %d summary, want none", len(repo.historyCalls), len(repo.summarizeCalls))
+	}
+}
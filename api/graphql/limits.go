@@ -0,0 +1,148 @@
+package graphql
+
+import (
+	"build-service-gin/common/custom/binding"
+	"build-service-gin/config"
+	"build-service-gin/pkg/helpers/resp"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// cost walks the selections of an operation, counting the fields each one may resolve.
+type cost struct {
+	fragments     map[string]*ast.FragmentDefinition
+	variables     map[string]interface{}
+	defaults      map[string]ast.Value
+	maxComplexity int
+}
+
+// checkLimits rejects the operation of doc nested deeper than MaxDepth fields, or resolving more than MaxComplexity
+// fields: a field costs one plus the cost of its selections times the items it lists, first transactions or the
+// ids of the profiles. Introspection fields are free.
+func checkLimits(conf config.GraphQLConfig, doc *ast.Document, operationName string, variables map[string]interface{}) *resp.CustomError {
+	c := &cost{
+		fragments:     map[string]*ast.FragmentDefinition{},
+		variables:     variables,
+		defaults:      map[string]ast.Value{},
+		maxComplexity: conf.MaxComplexity,
+	}
+
+	var operation *ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			c.fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if operationName == "" || definition.Name != nil && definition.Name.Value == operationName {
+				operation = definition
+			}
+		}
+	}
+	if operation == nil {
+		return nil
+	}
+	for _, definition := range operation.VariableDefinitions {
+		if definition.DefaultValue != nil {
+			c.defaults[definition.Variable.Name.Value] = definition.DefaultValue
+		}
+	}
+
+	complexity, depth := c.selections(operation.SelectionSet)
+	if depth > conf.MaxDepth {
+		return resp.NewError(resp.Invalid, resp.ErrDataInvalid, fmt.Sprintf("query depth %d exceeds the limit of %d", depth, conf.MaxDepth))
+	}
+	if complexity > conf.MaxComplexity {
+		return resp.NewError(resp.Invalid, resp.ErrDataInvalid, fmt.Sprintf("query complexity exceeds the limit of %d", conf.MaxComplexity))
+	}
+	return nil
+}
+
+// selections returns the complexity and the depth of set. The complexity stops growing past maxComplexity, so that
+// nested lists cannot overflow it.
+func (c *cost) selections(set *ast.SelectionSet) (complexity, depth int) {
+	if set == nil {
+		return 0, 0
+	}
+
+	for _, selection := range set.Selections {
+		var selComplexity, selDepth int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name.Value, "__") {
+				continue
+			}
+			childComplexity, childDepth := c.selections(selection.SelectionSet)
+			selComplexity, selDepth = 1+childComplexity*c.items(selection), 1+childDepth
+		case *ast.FragmentSpread:
+			if fragment, ok := c.fragments[selection.Name.Value]; ok {
+				selComplexity, selDepth = c.selections(fragment.SelectionSet)
+			}
+		case *ast.InlineFragment:
+			selComplexity, selDepth = c.selections(selection.SelectionSet)
+		}
+
+		complexity = min(complexity+selComplexity, c.maxComplexity+1)
+		depth = max(depth, selDepth)
+	}
+	return complexity, depth
+}
+
+// items returns how many items field lists at most, one for the fields which are not lists.
+func (c *cost) items(field *ast.Field) int {
+	for _, arg := range field.Arguments {
+		switch arg.Name.Value {
+		case argFirst:
+			first := c.intValue(arg.Value)
+			if first <= 0 {
+				return int(binding.DefaultLimit)
+			}
+			return min(first, int(binding.MaxLimit))
+		case argIDs:
+			return max(c.listLen(arg.Value), 1)
+		}
+	}
+	if field.Name.Value == "transactions" {
+		return int(binding.DefaultLimit)
+	}
+	return 1
+}
+
+func (c *cost) intValue(value ast.Value) int {
+	switch value := value.(type) {
+	case *ast.IntValue:
+		n, _ := strconv.Atoi(value.Value)
+		return n
+	case *ast.Variable:
+		if _, ok := c.variables[value.Name.Value]; !ok && c.defaults[value.Name.Value] != nil {
+			return c.intValue(c.defaults[value.Name.Value])
+		}
+		switch n := c.variables[value.Name.Value].(type) {
+		case int:
+			return n
+		case float64:
+			return int(n)
+		case json.Number:
+			i, _ := n.Int64()
+			return int(i)
+		}
+	}
+	return 0
+}
+
+func (c *cost) listLen(value ast.Value) int {
+	switch value := value.(type) {
+	case *ast.ListValue:
+		return len(value.Values)
+	case *ast.Variable:
+		if _, ok := c.variables[value.Name.Value]; !ok && c.defaults[value.Name.Value] != nil {
+			return c.listLen(c.defaults[value.Name.Value])
+		}
+		list, _ := c.variables[value.Name.Value].([]interface{})
+		return len(list)
+	}
+	return 0
+}
@@ -0,0 +1,103 @@
+package graphql
+
+import (
+	"build-service-gin/config"
+	"build-service-gin/pkg/helpers/resp"
+	"errors"
+	"testing"
+
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+func TestCheckLimits(t *testing.T) {
+	conf := config.GraphQLConfig{MaxDepth: 5, MaxComplexity: 1000, MaxProfiles: 50}
+
+	tests := []struct {
+		name          string
+		query         string
+		operationName string
+		variables     map[string]interface{}
+		wantErr       string
+	}{
+		{name: "shallow query", query: `{ profile { id balance { points } } }`},
+		{
+			name:  "at the depth limit",
+			query: `{ profile { transactions { edges { node { transactionID } } } } }`,
+		},
+		{
+			name:    "over the depth limit",
+			query:   `{ profile { transactions { edges { node { statusHistory { status } } } } } }`,
+			wantErr: "query depth 6 exceeds the limit of 5",
+		},
+		{
+			name:    "depth through a fragment",
+			query:   `{ profile { ...page } } fragment page on Profile { transactions { edges { node { statusHistory { status } } } } }`,
+			wantErr: "query depth 6 exceeds the limit of 5",
+		},
+		{
+			name:  "pages of several profiles under the limit",
+			query: `{ profiles(ids: ["p1", "p2", "p3"]) { transactions(first: 20) { edges { node { transactionID status } } } } }`,
+		},
+		{
+			name:    "pages of several profiles over the limit",
+			query:   `{ profiles(ids: ["p1", "p2", "p3"]) { transactions(first: 100) { edges { node { transactionID status } } } } }`,
+			wantErr: "query complexity exceeds the limit of 1000",
+		},
+		{
+			name:    "first above the maximum counts as the maximum",
+			query:   `{ profiles(ids: ["p1", "p2", "p3"]) { transactions(first: 5000) { edges { node { transactionID status } } } } }`,
+			wantErr: "query complexity exceeds the limit of 1000",
+		},
+		{
+			name:      "ids and first from variables",
+			query:     `query Page($ids: [ID!]!, $first: Int) { profiles(ids: $ids) { transactions(first: $first) { edges { node { transactionID status } } } } }`,
+			variables: map[string]interface{}{"ids": []interface{}{"p1", "p2", "p3"}, "first": float64(100)},
+			wantErr:   "query complexity exceeds the limit of 1000",
+		},
+		{
+			name:    "first from the default of its variable",
+			query:   `query Page($first: Int = 100) { profiles(ids: ["p1", "p2", "p3"]) { transactions(first: $first) { edges { node { transactionID status } } } } }`,
+			wantErr: "query complexity exceeds the limit of 1000",
+		},
+		{
+			name:    "transactions without first count the default page",
+			query:   `{ profiles(ids: ["p1", "p2", "p3", "p4", "p5", "p6", "p7", "p8", "p9", "p10", "p11", "p12", "p13"]) { transactions { edges { node { transactionID status } } } } }`,
+			wantErr: "query complexity exceeds the limit of 1000",
+		},
+		{
+			name:  "introspection is free",
+			query: `{ __schema { types { name fields { name type { name ofType { name ofType { name } } } } } } }`,
+		},
+		{
+			name:          "only the named operation is checked",
+			query:         `query Small { profile { id } } query Deep { profile { transactions { edges { node { statusHistory { status } } } } } }`,
+			operationName: "Small",
+		},
+		{
+			name:          "named operation over the limit",
+			query:         `query Small { profile { id } } query Deep { profile { transactions { edges { node { statusHistory { status } } } } } }`,
+			operationName: "Deep",
+			wantErr:       "query depth 6 exceeds the limit of 5",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(tt.query)})})
+			if err != nil {
+				t.Fatalf("parse query: %v", err)
+			}
+
+			errCustom := checkLimits(conf, doc, tt.operationName, tt.variables)
+			if tt.wantErr == "" {
+				if errCustom != nil {
+					t.Fatalf("checkLimits() = %v", errCustom)
+				}
+				return
+			}
+			if errCustom == nil || !errors.Is(errCustom, resp.Invalid) || errCustom.Description != tt.wantErr {
+				t.Errorf("checkLimits() = %v, want %q", errCustom, tt.wantErr)
+			}
+		})
+	}
+}
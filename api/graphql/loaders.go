@@ -0,0 +1,131 @@
+package graphql
+
+import (
+	"build-service-gin/internal/domains"
+	"build-service-gin/internal/services"
+	"context"
+	"time"
+
+	"github.com/graph-gophers/dataloader/v7"
+)
+
+// batchWait is how long a loader collects the keys of the fields resolved together before reading them in one query.
+const batchWait = 2 * time.Millisecond
+
+type loadersKey struct{}
+
+// summaryKey is the summary of a profile with the stats of the last RecentMonth months.
+type summaryKey struct {
+	ProfileID   string
+	RecentMonth int
+}
+
+// historyKey is a page of the history of a profile, the keys of the same page of several profiles are read together.
+type historyKey struct {
+	ProfileID   string
+	TxType      string
+	Status      string
+	RecentMonth int
+	After       string
+	First       int64
+}
+
+// loaders batch the reads of the fields of the profiles of a query, so N profiles cost one query per field rather
+// than N. They live as long as the query and cache what they read.
+type loaders struct {
+	summary *dataloader.Loader[summaryKey, *domains.ProfileSummary]
+	history *dataloader.Loader[historyKey, []domains.UserTransactionHistory]
+}
+
+func newLoaders(profileService services.IProfileService) *loaders {
+	return &loaders{
+		summary: dataloader.NewBatchedLoader(summaryBatch(profileService), dataloader.WithWait[summaryKey, *domains.ProfileSummary](batchWait)),
+		history: dataloader.NewBatchedLoader(historyBatch(profileService), dataloader.WithWait[historyKey, []domains.UserTransactionHistory](batchWait)),
+	}
+}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFromContext(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+// summaryBatch reads the summaries of the keys with one aggregation per distinct RecentMonth.
+func summaryBatch(profileService services.IProfileService) dataloader.BatchFunc[summaryKey, *domains.ProfileSummary] {
+	return func(ctx context.Context, keys []summaryKey) []*dataloader.Result[*domains.ProfileSummary] {
+		groups := map[int][]string{}
+		for _, key := range keys {
+			groups[key.RecentMonth] = append(groups[key.RecentMonth], key.ProfileID)
+		}
+
+		summaries := map[summaryKey]*domains.ProfileSummary{}
+		errs := map[int]error{}
+		for recentMonth, profileIDs := range groups {
+			byProfile, err := profileService.GetSummaryByProfiles(ctx, profileIDs, recentMonth)
+			if err != nil {
+				errs[recentMonth] = err
+				continue
+			}
+			for profileID, summary := range byProfile {
+				summaries[summaryKey{ProfileID: profileID, RecentMonth: recentMonth}] = summary
+			}
+		}
+
+		results := make([]*dataloader.Result[*domains.ProfileSummary], len(keys))
+		for i, key := range keys {
+			results[i] = &dataloader.Result[*domains.ProfileSummary]{Data: summaries[key], Error: errs[key.RecentMonth]}
+		}
+		return results
+	}
+}
+
+// historyBatch reads the pages of the keys with one query per distinct page, for all the profiles asking for it. One
+// more transaction than asked is read to tell whether a next page exists.
+func historyBatch(profileService services.IProfileService) dataloader.BatchFunc[historyKey, []domains.UserTransactionHistory] {
+	return func(ctx context.Context, keys []historyKey) []*dataloader.Result[[]domains.UserTransactionHistory] {
+		groups := map[historyKey][]string{}
+		for _, key := range keys {
+			page := key
+			page.ProfileID = ""
+			groups[page] = append(groups[page], key.ProfileID)
+		}
+
+		histories := map[historyKey][]domains.UserTransactionHistory{}
+		errs := map[historyKey]error{}
+		for page, profileIDs := range groups {
+			after, err := decodeCursor(page.After)
+			if err != nil {
+				errs[page] = err
+				continue
+			}
+
+			byProfile, errCustom := profileService.GetUserHistoryByProfiles(ctx, domains.GetUserTransactionHistoriesReq{
+				ProfileIDs:  profileIDs,
+				TxType:      page.TxType,
+				Status:      page.Status,
+				RecentMonth: page.RecentMonth,
+				After:       after,
+				First:       page.First + 1,
+			})
+			if errCustom != nil {
+				errs[page] = errCustom
+				continue
+			}
+			for profileID, items := range byProfile {
+				key := page
+				key.ProfileID = profileID
+				histories[key] = items
+			}
+		}
+
+		results := make([]*dataloader.Result[[]domains.UserTransactionHistory], len(keys))
+		for i, key := range keys {
+			page := key
+			page.ProfileID = ""
+			results[i] = &dataloader.Result[[]domains.UserTransactionHistory]{Data: histories[key], Error: errs[page]}
+		}
+		return results
+	}
+}
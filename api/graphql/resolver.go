@@ -0,0 +1,227 @@
+package graphql
+
+import (
+	"build-service-gin/api/http/middlewares"
+	"build-service-gin/api/http/models"
+	"build-service-gin/common/custom/binding"
+	"build-service-gin/common/logger"
+	"build-service-gin/config"
+	"build-service-gin/internal/domains"
+	"build-service-gin/internal/services"
+	"build-service-gin/pkg/auth"
+	"build-service-gin/pkg/helpers/resp"
+	"context"
+	"fmt"
+
+	"github.com/graphql-go/graphql"
+)
+
+const (
+	argFirst = "first"
+	argIDs   = "ids"
+)
+
+type resolver struct {
+	profileService services.IProfileService
+	conf           config.GraphQLConfig
+}
+
+// profileNode is a profile the caller may read, its fields are loaded in batches with the other profiles of the
+// query.
+type profileNode struct {
+	ID string `json:"id"`
+}
+
+type connection struct {
+	Edges    []edge   `json:"edges"`
+	PageInfo pageInfo `json:"pageInfo"`
+}
+
+type edge struct {
+	Cursor string                         `json:"cursor"`
+	Node   domains.UserTransactionHistory `json:"node"`
+}
+
+type pageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor"`
+	EndCursor       *string `json:"endCursor"`
+}
+
+// resolve converts the errors of fn, and of the thunk it may return, to GraphQL errors, see newQueryError. Panics
+// become internal errors rather than sending their message back. Thunks raise their errors as panics: graphql-go
+// keeps the extensions of the errors recovered from a thunk but drops those of the errors it returns.
+func (r *resolver) resolve(fn graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (result interface{}, err error) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				result, err = nil, newQueryError(p.Context, recovered(p.Context, rec))
+			}
+		}()
+
+		result, err = fn(p)
+		if err != nil {
+			return nil, newQueryError(p.Context, err)
+		}
+		if thunk, ok := result.(func() (interface{}, error)); ok {
+			return func() (value interface{}, err error) {
+				defer func() {
+					if rec := recover(); rec != nil {
+						err = recovered(p.Context, rec)
+					}
+					if err != nil {
+						panic(newQueryError(p.Context, err))
+					}
+				}()
+				return thunk()
+			}, nil
+		}
+		return result, nil
+	}
+}
+
+// recovered logs the panic of a resolver and returns the internal error it fails with.
+func recovered(ctx context.Context, rec interface{}) error {
+	logger.GetLogger().AddTraceInfoContextRequest(ctx).Error().Interface("panic", rec).Msg("recovered from panic")
+	return resp.NewError(resp.Internal, resp.ErrSystem, "")
+}
+
+func (r *resolver) profile(p graphql.ResolveParams) (interface{}, error) {
+	id, _ := p.Args["id"].(string)
+	profileID, err := r.resolveProfile(p.Context, id)
+	if err != nil {
+		return nil, err
+	}
+	return &profileNode{ID: profileID}, nil
+}
+
+func (r *resolver) profiles(p graphql.ResolveParams) (interface{}, error) {
+	ids, _ := p.Args[argIDs].([]interface{})
+	if len(ids) > r.conf.MaxProfiles {
+		return nil, resp.NewError(resp.Invalid, resp.ErrDataInvalid, fmt.Sprintf("at most %d profiles may be read at once", r.conf.MaxProfiles))
+	}
+
+	nodes := make([]*profileNode, 0, len(ids))
+	for _, id := range ids {
+		profileID, err := r.resolveProfile(p.Context, id.(string))
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, &profileNode{ID: profileID})
+	}
+	return nodes, nil
+}
+
+// resolveProfile returns the profile id addresses for the caller, like middlewares.ScopeProfile: callers without
+// history:read_any only reach their own profile.
+func (r *resolver) resolveProfile(ctx context.Context, id string) (string, error) {
+	principal := auth.PrincipalFromContext(ctx)
+	if principal == nil {
+		return "", resp.NewError(resp.Unauthorized, resp.ErrAuth, "")
+	}
+
+	profileID, ok := principal.ResolveProfile(id)
+	if !ok {
+		return "", middlewares.DenyAccess(ctx, accessFromContext(ctx), principal, "profile access denied", auth.ScopeHistoryReadAny)
+	}
+
+	req := models.GetUserTransactionHistoryByProfileReq{ProfileID: profileID}
+	if err := binding.Validate(&req); err != nil {
+		return "", resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err)
+	}
+	return profileID, nil
+}
+
+func (r *resolver) balance(p graphql.ResolveParams) (interface{}, error) {
+	node := p.Source.(*profileNode)
+	thunk := loadersFromContext(p.Context).summary.Load(p.Context, summaryKey{ProfileID: node.ID})
+	return func() (interface{}, error) {
+		summary, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		return summary.Balance, nil
+	}, nil
+}
+
+func (r *resolver) stats(p graphql.ResolveParams) (interface{}, error) {
+	node := p.Source.(*profileNode)
+	recentMonth, _ := p.Args["recentMonth"].(int)
+	if err := binding.Validate(&models.GetUserTransactionHistoryReq{RecentMonth: recentMonth}); err != nil {
+		return nil, resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err)
+	}
+
+	thunk := loadersFromContext(p.Context).summary.Load(p.Context, summaryKey{ProfileID: node.ID, RecentMonth: recentMonth})
+	return func() (interface{}, error) {
+		summary, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		return summary.Stats, nil
+	}, nil
+}
+
+func (r *resolver) transactions(p graphql.ResolveParams) (interface{}, error) {
+	node := p.Source.(*profileNode)
+	first, _ := p.Args[argFirst].(int)
+	after, _ := p.Args["after"].(string)
+	req := models.GetUserTransactionHistoryReq{
+		ProfileID:   node.ID,
+		Limit:       int64(first),
+		TxType:      stringArg(p.Args, "txType"),
+		Status:      stringArg(p.Args, "status"),
+		RecentMonth: intArg(p.Args, "recentMonth"),
+	}
+	if err := binding.Validate(&req); err != nil {
+		return nil, renameField(resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err), "limit", argFirst)
+	}
+	if _, err := decodeCursor(after); err != nil {
+		return nil, err
+	}
+
+	key := historyKey{
+		ProfileID:   node.ID,
+		TxType:      req.TxType,
+		Status:      req.Status,
+		RecentMonth: req.RecentMonth,
+		After:       after,
+		First:       req.Limit,
+	}
+	thunk := loadersFromContext(p.Context).history.Load(p.Context, key)
+	return func() (interface{}, error) {
+		items, err := thunk()
+		if err != nil {
+			return nil, err
+		}
+		return newConnection(items, req.Limit, after != ""), nil
+	}, nil
+}
+
+// newConnection pages items, read with one more transaction than first to tell whether a next page exists.
+func newConnection(items []domains.UserTransactionHistory, first int64, hasPrevious bool) connection {
+	conn := connection{Edges: []edge{}, PageInfo: pageInfo{HasPreviousPage: hasPrevious}}
+	if int64(len(items)) > first {
+		items = items[:first]
+		conn.PageInfo.HasNextPage = true
+	}
+
+	for _, item := range items {
+		conn.Edges = append(conn.Edges, edge{Cursor: encodeCursor(item), Node: item})
+	}
+	if len(conn.Edges) > 0 {
+		conn.PageInfo.StartCursor = &conn.Edges[0].Cursor
+		conn.PageInfo.EndCursor = &conn.Edges[len(conn.Edges)-1].Cursor
+	}
+	return conn
+}
+
+func stringArg(args map[string]interface{}, name string) string {
+	value, _ := args[name].(string)
+	return value
+}
+
+func intArg(args map[string]interface{}, name string) int {
+	value, _ := args[name].(int)
+	return value
+}
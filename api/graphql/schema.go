@@ -0,0 +1,157 @@
+package graphql
+
+import (
+	"build-service-gin/internal/domains"
+
+	"github.com/graphql-go/graphql"
+)
+
+// enum returns the GraphQL enum of values, named like the values themselves.
+func enum(name, description string, values []string) *graphql.Enum {
+	config := graphql.EnumValueConfigMap{}
+	for _, value := range values {
+		config[value] = &graphql.EnumValueConfig{Value: value}
+	}
+	return graphql.NewEnum(graphql.EnumConfig{Name: name, Description: description, Values: config})
+}
+
+// newSchema builds the schema of the read API: the profiles with their balance, their stats and their transactions
+// as cursor connections, newest first.
+func newSchema(r *resolver) (graphql.Schema, error) {
+	txType := enum("TransactionType", "Type of a transaction", domains.TxTypes())
+	txStatus := enum("TransactionStatus", "Status of a transaction", domains.TxStatuses())
+
+	statusChange := graphql.NewObject(graphql.ObjectConfig{
+		Name: "StatusChange",
+		Fields: graphql.Fields{
+			"status":    &graphql.Field{Type: graphql.NewNonNull(txStatus)},
+			"changedAt": &graphql.Field{Type: graphql.DateTime},
+		},
+	})
+
+	transaction := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Transaction",
+		Fields: graphql.Fields{
+			"transactionID":         &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"transactionType":       &graphql.Field{Type: txType},
+			"profileID":             &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"status":                &graphql.Field{Type: txStatus},
+			"pointAmount":           &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"pointType":             &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"totalAmount":           &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"currency":              &graphql.Field{Type: graphql.String},
+			"paymentTransactionID":  &graphql.Field{Type: graphql.String},
+			"source":                &graphql.Field{Type: graphql.String},
+			"sourceTime":            &graphql.Field{Type: graphql.DateTime},
+			"sourceType":            &graphql.Field{Type: graphql.String},
+			"statusHistory":         &graphql.Field{Type: graphql.NewList(graphql.NewNonNull(statusChange))},
+			"originalTransactionID": &graphql.Field{Type: graphql.ID},
+			"reversedAmount":        &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"reversedPointAmount":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"createdAt":             &graphql.Field{Type: graphql.DateTime},
+			"updatedAt":             &graphql.Field{Type: graphql.DateTime},
+		},
+	})
+
+	pageInfo := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"hasNextPage":     &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"hasPreviousPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"startCursor":     &graphql.Field{Type: graphql.String},
+			"endCursor":       &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	transactionEdge := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TransactionEdge",
+		Fields: graphql.Fields{
+			"cursor": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"node":   &graphql.Field{Type: graphql.NewNonNull(transaction)},
+		},
+	})
+
+	transactionConnection := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TransactionConnection",
+		Fields: graphql.Fields{
+			"edges":    &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(transactionEdge)))},
+			"pageInfo": &graphql.Field{Type: graphql.NewNonNull(pageInfo)},
+		},
+	})
+
+	balance := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Balance",
+		Description: "Points of the transactions counting toward the balance, and of those still pending",
+		Fields: graphql.Fields{
+			"points":        &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"pendingPoints": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		},
+	})
+
+	stats := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Stats",
+		Description: "Transactions created in the last recentMonth months, all of them when recentMonth is 0",
+		Fields: graphql.Fields{
+			"recentMonth":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"transactionCount":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"successCount":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"failedCount":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"pointsEarned":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"pointsReversed":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"lastTransactionAt": &graphql.Field{Type: graphql.DateTime},
+		},
+	})
+
+	profile := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Profile",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"balance": &graphql.Field{
+				Type:    graphql.NewNonNull(balance),
+				Resolve: r.resolve(r.balance),
+			},
+			"stats": &graphql.Field{
+				Type: graphql.NewNonNull(stats),
+				Args: graphql.FieldConfigArgument{
+					"recentMonth": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+				},
+				Resolve: r.resolve(r.stats),
+			},
+			"transactions": &graphql.Field{
+				Type: graphql.NewNonNull(transactionConnection),
+				Args: graphql.FieldConfigArgument{
+					argFirst:      &graphql.ArgumentConfig{Type: graphql.Int, Description: "Transactions per page, 20 by default and at most 100"},
+					"after":       &graphql.ArgumentConfig{Type: graphql.String, Description: "endCursor of the previous page"},
+					"txType":      &graphql.ArgumentConfig{Type: txType},
+					"status":      &graphql.ArgumentConfig{Type: txStatus},
+					"recentMonth": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+				},
+				Resolve: r.resolve(r.transactions),
+			},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"profile": &graphql.Field{
+				Type:        profile,
+				Description: "A profile, the caller when id is omitted",
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.ID},
+				},
+				Resolve: r.resolve(r.profile),
+			},
+			"profiles": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(profile))),
+				Description: "Several profiles at once, reaching other profiles than the caller needs history:read_any",
+				Args: graphql.FieldConfigArgument{
+					argIDs: &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.ID)))},
+				},
+				Resolve: r.resolve(r.profiles),
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
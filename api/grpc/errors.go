@@ -0,0 +1,97 @@
+package grpc
+
+import (
+	"build-service-gin/common/logger"
+	"build-service-gin/pkg/helpers/resp"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const errorDomain = "build-service-gin"
+
+// categoryCode maps the categories of errors to gRPC codes, like resp.HTTPStatus maps them to HTTP statuses.
+var categoryCode = []struct {
+	category error
+	code     codes.Code
+}{
+	{resp.NotFound, codes.NotFound},
+	{resp.Conflict, codes.FailedPrecondition},
+	{resp.Invalid, codes.InvalidArgument},
+	{resp.Unauthorized, codes.Unauthenticated},
+	{resp.Forbidden, codes.PermissionDenied},
+	{resp.TooManyRequests, codes.ResourceExhausted},
+	{resp.Unavailable, codes.Unavailable},
+	{resp.Internal, codes.Internal},
+}
+
+// grpcCode maps the category of err to a gRPC code, errors without a category are internal errors.
+func grpcCode(err error) codes.Code {
+	for _, cc := range categoryCode {
+		if errors.Is(err, cc.category) {
+			return cc.code
+		}
+	}
+	return codes.Internal
+}
+
+// toStatus returns the gRPC status of err, the gRPC counterpart of the error middleware of the HTTP server. The
+// error code is sent in an ErrorInfo with the message in the language of the request, the invalid fields in a
+// BadRequest. Server errors are logged and their details are not sent back.
+func toStatus(ctx context.Context, err error) *status.Status {
+	if err == nil {
+		return nil
+	}
+	if st, ok := status.FromError(err); ok {
+		return st
+	}
+
+	code := grpcCode(err)
+	lang := resp.LangFromContext(ctx)
+
+	errCode, description := int64(resp.ErrSystem), ""
+	var params map[string]interface{}
+	var fields []resp.FieldError
+	var customErr *resp.CustomError
+	if errors.As(err, &customErr) {
+		errCode, description, params, fields = customErr.ErrorCode, customErr.Description, customErr.Params, customErr.Fields
+	}
+
+	if code == codes.Internal {
+		logger.GetLogger().AddTraceInfoContextRequest(ctx).Error().Err(err).Str("code", code.String()).Msg("request failed")
+		description = ""
+	}
+
+	rs := resp.BuildErrorRespParams(errCode, description, lang, params)
+	st := status.New(code, rs.Description)
+
+	errorInfo := &errdetails.ErrorInfo{
+		Reason:   strconv.FormatInt(rs.ErrorCode, 10),
+		Domain:   errorDomain,
+		Metadata: map[string]string{"message": rs.Message},
+	}
+	localized := &errdetails.LocalizedMessage{Locale: strings.ToLower(lang), Message: rs.Message}
+	withDetails, detailErr := st.WithDetails(errorInfo, localized)
+	if detailErr != nil {
+		return st
+	}
+
+	if fields = resp.LocalizeFields(fields, lang); len(fields) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, field := range fields {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       field.Field,
+				Description: field.Message,
+			})
+		}
+		if withFields, fieldErr := withDetails.WithDetails(badRequest); fieldErr == nil {
+			return withFields
+		}
+	}
+	return withDetails
+}
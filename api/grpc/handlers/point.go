@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"build-service-gin/api/grpc/pb"
+	"build-service-gin/common/custom/binding"
+	"build-service-gin/internal/services"
+	"build-service-gin/pkg/helpers/adapters"
+	"build-service-gin/pkg/helpers/resp"
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// PointServer serves the PointService of the gRPC API with the operations of the point routes.
+type PointServer struct {
+	pb.UnimplementedPointServiceServer
+	pointService services.IPointService
+}
+
+func NewPointServer(pointService services.IPointService) *PointServer {
+	return &PointServer{
+		pointService: pointService,
+	}
+}
+
+func (s *PointServer) CreatePointTransaction(ctx context.Context, in *pb.CreatePointTransactionRequest) (*emptypb.Empty, error) {
+	req := adapters.AdapterGrpc{}.ConvProto2ModelOrderRequest(in)
+	if err := binding.Validate(req); err != nil {
+		return nil, resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err)
+	}
+
+	dataDomain := adapters.AdapterLPPoint{}.ConvertOrderHandler2Domain(req)
+	if err := s.pointService.CreatePointTransaction(ctx, dataDomain); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *PointServer) ReverseTransaction(ctx context.Context, in *pb.ReverseTransactionRequest) (*pb.UserTransactionHistory, error) {
+	req := adapters.AdapterGrpc{}.ConvProto2ModelReverseRequest(in)
+	if err := binding.Validate(req); err != nil {
+		return nil, resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err)
+	}
+
+	dataDomain := adapters.AdapterLPPoint{}.ConvertReverseRequest2Domain(req)
+	data, err := s.pointService.ReverseTransactionPoint(ctx, dataDomain)
+	if err != nil {
+		return nil, err
+	}
+	return adapters.AdapterGrpc{}.ConvDomain2ProtoUserTransactionHistory(data), nil
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"build-service-gin/api/grpc/pb"
+	"build-service-gin/common/custom/binding"
+	"build-service-gin/internal/services"
+	"build-service-gin/pkg/featureflag"
+	"build-service-gin/pkg/helpers/adapters"
+	"build-service-gin/pkg/helpers/resp"
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// ProfileServer serves the ProfileService of the gRPC API with the operations of the profile routes.
+type ProfileServer struct {
+	pb.UnimplementedProfileServiceServer
+	profileService services.IProfileService
+	flags          *featureflag.Service
+}
+
+func NewProfileServer(profileService services.IProfileService, flags *featureflag.Service) *ProfileServer {
+	return &ProfileServer{
+		profileService: profileService,
+		flags:          flags,
+	}
+}
+
+func (s *ProfileServer) ListUserTransactionHistory(ctx context.Context, in *pb.ListUserTransactionHistoryRequest) (*pb.ListUserTransactionHistoryResponse, error) {
+	req := adapters.AdapterGrpc{}.ConvProto2ModelGetUserTransactionHistoryReq(in)
+	if err := binding.Validate(&req); err != nil {
+		return nil, resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err)
+	}
+
+	dataDomain := adapters.AdapterProfile{}.ConvReq2ServUserTransactionHistoryTx(req)
+
+	// the profiles targeted by the rollout read their history from Postgres
+	getHistory := s.profileService.GetUserHistoryByProfile
+	if s.flags.Enabled(featureflag.FlagHistoryPostgresRead, featureflag.TargetFromContext(ctx, req.ProfileID)) {
+		getHistory = s.profileService.GetUserHistoryByProfilePostgresql
+	}
+
+	data, total, err := getHistory(ctx, *dataDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ListUserTransactionHistoryResponse{
+		Items: adapters.AdapterGrpc{}.ConvDomain2ProtoArrayUserTransactionHistory(data),
+		Paging: &pb.Paging{
+			Total:  total,
+			Offset: req.Offset,
+			Limit:  req.Limit,
+		},
+	}, nil
+}
+
+func (s *ProfileServer) CreateUserTransactionHistory(ctx context.Context, in *pb.UserTransactionHistory) (*pb.UserTransactionHistory, error) {
+	req := adapters.AdapterGrpc{}.ConvProto2ModelUserTransactionHistory(in)
+	if err := binding.Validate(&req); err != nil {
+		return nil, resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err)
+	}
+
+	dataDomain := adapters.AdapterProfile{}.ConvModelToDomainUserTransactionHistoryTx(req)
+	data, err := s.profileService.CreateUserTransactionHistory(ctx, dataDomain)
+	if err != nil {
+		return nil, err
+	}
+	return adapters.AdapterGrpc{}.ConvDomain2ProtoUserTransactionHistory(data), nil
+}
+
+func (s *ProfileServer) UpdateUserTransactionHistory(ctx context.Context, in *pb.UserTransactionHistory) (*pb.UserTransactionHistory, error) {
+	req := adapters.AdapterGrpc{}.ConvProto2ModelUserTransactionHistory(in)
+	if err := binding.Validate(&req); err != nil {
+		return nil, resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err)
+	}
+
+	dataDomain := adapters.AdapterProfile{}.ConvModelToDomainUserTransactionHistoryTx(req)
+	data, err := s.profileService.UpdateUserTransactionHistoryByProfile(ctx, dataDomain, dataDomain.ProfileID)
+	if err != nil {
+		return nil, err
+	}
+	return adapters.AdapterGrpc{}.ConvDomain2ProtoUserTransactionHistory(data), nil
+}
+
+func (s *ProfileServer) DeleteUserTransactionHistory(ctx context.Context, in *pb.DeleteUserTransactionHistoryRequest) (*emptypb.Empty, error) {
+	req := adapters.AdapterGrpc{}.ConvProto2ModelDeleteUserTransactionHistoryReq(in)
+	if err := binding.Validate(&req); err != nil {
+		return nil, resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err)
+	}
+
+	if err := s.profileService.DeleteUserTransactionHistoryByProfile(ctx, req.ProfileID); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
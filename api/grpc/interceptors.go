@@ -0,0 +1,206 @@
+package grpc
+
+import (
+	"build-service-gin/api/grpc/pb"
+	"build-service-gin/api/http/middlewares"
+	"build-service-gin/common/logger"
+	"build-service-gin/common/utils"
+	"build-service-gin/pkg/auth"
+	"build-service-gin/pkg/helpers/resp"
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	headerRequestID      = "x-request-id"
+	headerAcceptLanguage = "accept-language"
+	headerAuthorization  = "authorization"
+	headerUserAgent      = "user-agent"
+
+	// accessMethod is the method of gRPC calls in the access and audit logs, their path is the full method name.
+	accessMethod   = "GRPC"
+	fieldProfileID = "profile_id"
+)
+
+// authenticatedTokenTypes are the token types accepted on methods where the scopes decide what a caller may do.
+var authenticatedTokenTypes = []string{utils.IASTypeClient, utils.IASTypeService, utils.IASTypeInternal}
+
+// methodRule authorizes the calls of a method like the middlewares of its HTTP route: the caller must hold one of
+// tokenTypes and every scope, scopeProfile restricts it to its own profile like middlewares.ScopeProfile.
+type methodRule struct {
+	tokenTypes   []string
+	scopes       []string
+	scopeProfile bool
+}
+
+// methodRules lists every method of the API, Start refuses to serve methods without a rule.
+var methodRules = map[string]methodRule{
+	pb.ProfileService_ListUserTransactionHistory_FullMethodName:   {authenticatedTokenTypes, []string{auth.ScopeHistoryRead}, true},
+	pb.ProfileService_CreateUserTransactionHistory_FullMethodName: {authenticatedTokenTypes, []string{auth.ScopeHistoryWrite}, true},
+	pb.ProfileService_UpdateUserTransactionHistory_FullMethodName: {authenticatedTokenTypes, []string{auth.ScopeHistoryAdmin}, false},
+	pb.ProfileService_DeleteUserTransactionHistory_FullMethodName: {authenticatedTokenTypes, []string{auth.ScopeHistoryAdmin}, false},
+	pb.PointService_CreatePointTransaction_FullMethodName:         {[]string{utils.IASTypeService, utils.IASTypeInternal}, []string{auth.ScopePointWrite}, false},
+	pb.PointService_ReverseTransaction_FullMethodName:             {[]string{utils.IASTypeService, utils.IASTypeInternal}, []string{auth.ScopePointReverse}, false},
+}
+
+// publicServices are served without authentication, like the health route of the HTTP server.
+var publicServices = []string{grpc_health_v1.Health_ServiceDesc.ServiceName, "grpc.reflection."}
+
+type interceptors struct {
+	auth *middlewares.AuthMiddleware
+}
+
+// trace binds the request ID, the client region and the language of the call metadata into the context, like
+// AddExtraDataForRequestContext and Language do for HTTP requests, and sends the request ID back in the header.
+func (i *interceptors) trace(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	reqID := middlewares.RequestID(firstValue(md, headerRequestID))
+	ctx = middlewares.WithTraceInfo(ctx, reqID, firstValue(md, utils.KeyRegion))
+	ctx = resp.WithLang(ctx, middlewares.NegotiateLanguage(firstValue(md, headerAcceptLanguage)))
+	_ = grpc.SetHeader(ctx, metadata.Pairs(headerRequestID, reqID))
+
+	return handler(ctx, req)
+}
+
+// observe converts the error of the call to its gRPC status, recovers from panics, counts the call and writes it to
+// the access log like middlewares.Logging.
+func (i *interceptors) observe(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res interface{}, err error) {
+	start := time.Now()
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.GetLogger().AddTraceInfoContextRequest(ctx).Error().Interface("panic", r).Str("method", info.FullMethod).Msg("recovered from panic")
+			res, err = nil, resp.NewError(resp.Internal, resp.ErrSystem, "")
+		}
+
+		statusCode := http.StatusOK
+		if err != nil {
+			statusCode = resp.HTTPStatus(err)
+		}
+		st := toStatus(ctx, err)
+		middlewares.ObserveGrpcRequest(info.FullMethod, st.Code().String(), start)
+
+		if !isPublic(info.FullMethod) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			middlewares.LogRequest(ctx, middlewares.RequestLog{
+				Method:       accessMethod,
+				Path:         info.FullMethod,
+				IP:           peerIP(ctx),
+				UserAgent:    firstValue(md, headerUserAgent),
+				RequestID:    utils.GetRequestIdByContext(ctx).RequestID,
+				StatusCode:   statusCode,
+				Latency:      time.Since(start),
+				RequestBody:  marshalMessage(req),
+				ResponseBody: marshalMessage(res),
+			})
+		}
+		err = st.Err()
+	}()
+
+	return handler(ctx, req)
+}
+
+// authorize verifies the bearer token of the authorization metadata and applies the rule of the method, see
+// methodRules. The caller is bound into the context.
+func (i *interceptors) authorize(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if isPublic(info.FullMethod) {
+		return handler(ctx, req)
+	}
+
+	access := middlewares.Access{Method: accessMethod, Path: info.FullMethod, IP: peerIP(ctx)}
+	rule, ok := methodRules[info.FullMethod]
+	if !ok {
+		return nil, middlewares.DenyAccess(ctx, access, nil, "method has no authorization rule")
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	principal, err := i.auth.Verify(ctx, access, firstValue(md, headerAuthorization), rule.tokenTypes...)
+	if err != nil {
+		return nil, err
+	}
+	ctx = auth.WithPrincipal(ctx, principal)
+
+	if err := middlewares.CheckScopes(ctx, access, rule.scopes...); err != nil {
+		return nil, err
+	}
+	if rule.scopeProfile {
+		if err := scopeProfile(ctx, access, principal, req); err != nil {
+			return nil, err
+		}
+	}
+
+	return handler(ctx, req)
+}
+
+// scopeProfile restricts the profile_id of req to the profile of the caller and fills it when missing, see
+// auth.Principal.ResolveProfile.
+func scopeProfile(ctx context.Context, access middlewares.Access, principal *auth.Principal, req interface{}) *resp.CustomError {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return nil
+	}
+
+	message := msg.ProtoReflect()
+	field := message.Descriptor().Fields().ByName(fieldProfileID)
+	if field == nil {
+		return nil
+	}
+
+	profileID, ok := principal.ResolveProfile(message.Get(field).String())
+	if !ok {
+		return middlewares.DenyAccess(ctx, access, principal, "profile access denied", auth.ScopeHistoryReadAny)
+	}
+	message.Set(field, protoreflect.ValueOfString(profileID))
+	return nil
+}
+
+func isPublic(fullMethod string) bool {
+	for _, service := range publicServices {
+		if strings.HasPrefix(strings.TrimPrefix(fullMethod, "/"), service) {
+			return true
+		}
+	}
+	return false
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+		return host
+	}
+	return p.Addr.String()
+}
+
+func marshalMessage(v interface{}) string {
+	msg, ok := v.(proto.Message)
+	if !ok || msg == nil {
+		return ""
+	}
+	b, err := protojson.Marshal(msg)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
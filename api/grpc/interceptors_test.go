@@ -0,0 +1,294 @@
+package grpc
+
+import (
+	"build-service-gin/api/grpc/pb"
+	"build-service-gin/common/logger"
+	"build-service-gin/common/utils"
+	"build-service-gin/config"
+	"build-service-gin/pkg/auth"
+	"build-service-gin/pkg/helpers/resp"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testSecret = "grpc-test-secret"
+
+func TestMain(m *testing.M) {
+	logger.InitLog("test")
+	os.Exit(m.Run())
+}
+
+// fakeProfileServer answers ListUserTransactionHistory with list, the other methods are unimplemented.
+type fakeProfileServer struct {
+	pb.UnimplementedProfileServiceServer
+	list func(ctx context.Context, in *pb.ListUserTransactionHistoryRequest) (*pb.ListUserTransactionHistoryResponse, error)
+}
+
+func (s *fakeProfileServer) ListUserTransactionHistory(ctx context.Context, in *pb.ListUserTransactionHistoryRequest) (*pb.ListUserTransactionHistoryResponse, error) {
+	return s.list(ctx, in)
+}
+
+type fakePointServer struct {
+	pb.UnimplementedPointServiceServer
+}
+
+// startServer serves the services of profileServer behind the interceptors of the service on an in-memory listener
+// and returns a connection to it.
+func startServer(t *testing.T, profileServer pb.ProfileServiceServer) *grpc.ClientConn {
+	t.Helper()
+	conf := &config.SystemConfig{}
+	conf.AuthConfig.HS256Secret = testSecret
+
+	app := NewGrpcServe(conf, profileServer, &fakePointServer{})
+	app.InitServices()
+
+	listener := bufconn.Listen(1 << 20)
+	go func() { _ = app.grpcServer.Serve(listener) }()
+	t.Cleanup(app.grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func signToken(t *testing.T, subject, tokenType string, scopes ...string) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Type:  tokenType,
+		Scope: strings.Join(scopes, " "),
+	})
+	signed, err := token.SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+// errorCode returns the error code of the ErrorInfo of st, 0 without one.
+func errorCode(st *status.Status) int64 {
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			code, _ := strconv.ParseInt(info.Reason, 10, 64)
+			return code
+		}
+	}
+	return 0
+}
+
+func TestInterceptors(t *testing.T) {
+	profileServer := &fakeProfileServer{}
+	client := pb.NewProfileServiceClient(startServer(t, profileServer))
+
+	invalid := resp.NewError(resp.Invalid, resp.ErrDataInvalid, "limit - max=100")
+	invalid.Fields = []resp.FieldError{{Field: "limit", Rule: "max", Param: "100", Message: "limit - max=100"}}
+
+	tests := []struct {
+		name          string
+		token         string
+		lang          string
+		profileID     string
+		list          func(ctx context.Context, in *pb.ListUserTransactionHistoryRequest) (*pb.ListUserTransactionHistoryResponse, error)
+		wantCode      codes.Code
+		wantErrorCode int64
+		// wantField is the field of the BadRequest detail, wantLocale the locale of the LocalizedMessage.
+		wantField  string
+		wantLocale string
+	}{
+		{name: "no token", wantCode: codes.Unauthenticated, wantErrorCode: resp.ErrAuth},
+		{name: "malformed token", token: "Bearer not-a-jwt", wantCode: codes.Unauthenticated, wantErrorCode: resp.ErrAuth},
+		{name: "token without the scope", token: signToken(t, "alice", utils.IASTypeClient), wantCode: codes.PermissionDenied, wantErrorCode: resp.ErrForbidden},
+		{
+			name:          "token of a type the method refuses",
+			token:         signToken(t, "alice", utils.IASTypePublic, auth.ScopeHistoryRead),
+			wantCode:      codes.PermissionDenied,
+			wantErrorCode: resp.ErrForbidden,
+		},
+		{
+			name:          "profile of another caller",
+			token:         signToken(t, "alice", utils.IASTypeClient, auth.ScopeHistoryRead),
+			profileID:     "bob",
+			wantCode:      codes.PermissionDenied,
+			wantErrorCode: resp.ErrForbidden,
+		},
+		{
+			name:  "profile of the caller filled in",
+			token: signToken(t, "alice", utils.IASTypeClient, auth.ScopeHistoryRead),
+			list: func(ctx context.Context, in *pb.ListUserTransactionHistoryRequest) (*pb.ListUserTransactionHistoryResponse, error) {
+				if in.ProfileId != "alice" {
+					return nil, resp.NewError(resp.Forbidden, resp.ErrForbidden, "profile "+in.ProfileId)
+				}
+				return &pb.ListUserTransactionHistoryResponse{}, nil
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name:  "not found error",
+			token: signToken(t, "alice", utils.IASTypeClient, auth.ScopeHistoryRead),
+			list: func(ctx context.Context, in *pb.ListUserTransactionHistoryRequest) (*pb.ListUserTransactionHistoryResponse, error) {
+				return nil, resp.NewError(resp.NotFound, resp.ErrHandleProfileIdNotFound, "profile not found")
+			},
+			wantCode:      codes.NotFound,
+			wantErrorCode: resp.ErrHandleProfileIdNotFound,
+			wantLocale:    "en",
+		},
+		{
+			name:  "invalid fields in vietnamese",
+			token: signToken(t, "alice", utils.IASTypeClient, auth.ScopeHistoryRead),
+			lang:  "vi-VN",
+			list: func(ctx context.Context, in *pb.ListUserTransactionHistoryRequest) (*pb.ListUserTransactionHistoryResponse, error) {
+				return nil, invalid
+			},
+			wantCode:      codes.InvalidArgument,
+			wantErrorCode: resp.ErrDataInvalid,
+			wantField:     "limit",
+			wantLocale:    "vi",
+		},
+		{
+			name:  "conflict error",
+			token: signToken(t, "alice", utils.IASTypeClient, auth.ScopeHistoryRead),
+			list: func(ctx context.Context, in *pb.ListUserTransactionHistoryRequest) (*pb.ListUserTransactionHistoryResponse, error) {
+				return nil, &resp.CustomError{ErrorCode: resp.ErrHandleTxAlreadyReversed}
+			},
+			wantCode:      codes.FailedPrecondition,
+			wantErrorCode: resp.ErrHandleTxAlreadyReversed,
+		},
+		{
+			name:  "plain error",
+			token: signToken(t, "alice", utils.IASTypeClient, auth.ScopeHistoryRead),
+			list: func(ctx context.Context, in *pb.ListUserTransactionHistoryRequest) (*pb.ListUserTransactionHistoryResponse, error) {
+				return nil, errors.New("mongo: connection refused")
+			},
+			wantCode:      codes.Internal,
+			wantErrorCode: resp.ErrSystem,
+		},
+		{
+			name:  "status error kept",
+			token: signToken(t, "alice", utils.IASTypeClient, auth.ScopeHistoryRead),
+			list: func(ctx context.Context, in *pb.ListUserTransactionHistoryRequest) (*pb.ListUserTransactionHistoryResponse, error) {
+				return nil, status.Error(codes.Aborted, "aborted")
+			},
+			wantCode: codes.Aborted,
+		},
+		{
+			name:  "panic recovered",
+			token: signToken(t, "alice", utils.IASTypeClient, auth.ScopeHistoryRead),
+			list: func(ctx context.Context, in *pb.ListUserTransactionHistoryRequest) (*pb.ListUserTransactionHistoryResponse, error) {
+				panic("mongo: connection refused")
+			},
+			wantCode:      codes.Internal,
+			wantErrorCode: resp.ErrSystem,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profileServer.list = tt.list
+			if profileServer.list == nil {
+				profileServer.list = func(ctx context.Context, in *pb.ListUserTransactionHistoryRequest) (*pb.ListUserTransactionHistoryResponse, error) {
+					t.Error("handler called")
+					return &pb.ListUserTransactionHistoryResponse{}, nil
+				}
+			}
+
+			ctx := metadata.AppendToOutgoingContext(context.Background(), headerRequestID, "request-1")
+			if tt.token != "" {
+				token := tt.token
+				if !strings.HasPrefix(token, "Bearer ") {
+					token = "Bearer " + token
+				}
+				ctx = metadata.AppendToOutgoingContext(ctx, headerAuthorization, token)
+			}
+			if tt.lang != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, headerAcceptLanguage, tt.lang)
+			}
+
+			var header metadata.MD
+			_, err := client.ListUserTransactionHistory(ctx, &pb.ListUserTransactionHistoryRequest{ProfileId: tt.profileID}, grpc.Header(&header))
+
+			st := status.Convert(err)
+			if st.Code() != tt.wantCode {
+				t.Fatalf("code = %s, want %s: %v", st.Code(), tt.wantCode, err)
+			}
+			if got := firstValue(header, headerRequestID); got != "request-1" {
+				t.Errorf("%s header = %q, want request-1", headerRequestID, got)
+			}
+			if tt.wantErrorCode != 0 {
+				if got := errorCode(st); got != tt.wantErrorCode {
+					t.Errorf("error code = %d, want %d", got, tt.wantErrorCode)
+				}
+			}
+			if strings.Contains(st.Message(), "mongo") {
+				t.Errorf("message %q leaks the cause of a server error", st.Message())
+			}
+
+			var field, locale string
+			for _, detail := range st.Details() {
+				switch detail := detail.(type) {
+				case *errdetails.BadRequest:
+					field = detail.FieldViolations[0].Field
+				case *errdetails.LocalizedMessage:
+					locale = detail.Locale
+				}
+			}
+			if field != tt.wantField {
+				t.Errorf("field violation = %q, want %q", field, tt.wantField)
+			}
+			if tt.wantLocale != "" && locale != tt.wantLocale {
+				t.Errorf("locale = %q, want %q", locale, tt.wantLocale)
+			}
+		})
+	}
+}
+
+func TestHealthIsPublic(t *testing.T) {
+	conn := startServer(t, &fakeProfileServer{})
+
+	res, err := grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{
+		Service: pb.ProfileService_ServiceDesc.ServiceName,
+	})
+	if err != nil {
+		t.Fatalf("Check() = %v", err)
+	}
+	if res.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Errorf("status = %s, want SERVING", res.Status)
+	}
+}
+
+func TestUnauthorizedMethods(t *testing.T) {
+	services := map[string]grpc.ServiceInfo{
+		"other.v1.ProfileService":                     {Methods: []grpc.MethodInfo{{Name: "ListUserTransactionHistory"}}},
+		pb.PointService_ServiceDesc.ServiceName:       {Methods: []grpc.MethodInfo{{Name: "CreatePointTransaction"}, {Name: "Unruled"}}},
+		grpc_health_v1.Health_ServiceDesc.ServiceName: {Methods: []grpc.MethodInfo{{Name: "Check"}}},
+	}
+
+	got := unauthorizedMethods(services)
+	want := []string{"/" + pb.PointService_ServiceDesc.ServiceName + "/Unruled", "/other.v1.ProfileService/ListUserTransactionHistory"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("unauthorizedMethods() = %v, want %v", got, want)
+	}
+}
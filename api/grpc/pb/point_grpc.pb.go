@@ -0,0 +1,386 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: point.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ProfileService_ListUserTransactionHistory_FullMethodName   = "/buildservicegin.v1.ProfileService/ListUserTransactionHistory"
+	ProfileService_CreateUserTransactionHistory_FullMethodName = "/buildservicegin.v1.ProfileService/CreateUserTransactionHistory"
+	ProfileService_UpdateUserTransactionHistory_FullMethodName = "/buildservicegin.v1.ProfileService/UpdateUserTransactionHistory"
+	ProfileService_DeleteUserTransactionHistory_FullMethodName = "/buildservicegin.v1.ProfileService/DeleteUserTransactionHistory"
+)
+
+// ProfileServiceClient is the client API for ProfileService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ProfileService reads and changes the transaction history of profiles, like the profile routes of the HTTP API.
+// Callers without the history:read_any scope only reach their own profile.
+type ProfileServiceClient interface {
+	ListUserTransactionHistory(ctx context.Context, in *ListUserTransactionHistoryRequest, opts ...grpc.CallOption) (*ListUserTransactionHistoryResponse, error)
+	CreateUserTransactionHistory(ctx context.Context, in *UserTransactionHistory, opts ...grpc.CallOption) (*UserTransactionHistory, error)
+	UpdateUserTransactionHistory(ctx context.Context, in *UserTransactionHistory, opts ...grpc.CallOption) (*UserTransactionHistory, error)
+	DeleteUserTransactionHistory(ctx context.Context, in *DeleteUserTransactionHistoryRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type profileServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewProfileServiceClient(cc grpc.ClientConnInterface) ProfileServiceClient {
+	return &profileServiceClient{cc}
+}
+
+func (c *profileServiceClient) ListUserTransactionHistory(ctx context.Context, in *ListUserTransactionHistoryRequest, opts ...grpc.CallOption) (*ListUserTransactionHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListUserTransactionHistoryResponse)
+	err := c.cc.Invoke(ctx, ProfileService_ListUserTransactionHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *profileServiceClient) CreateUserTransactionHistory(ctx context.Context, in *UserTransactionHistory, opts ...grpc.CallOption) (*UserTransactionHistory, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UserTransactionHistory)
+	err := c.cc.Invoke(ctx, ProfileService_CreateUserTransactionHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *profileServiceClient) UpdateUserTransactionHistory(ctx context.Context, in *UserTransactionHistory, opts ...grpc.CallOption) (*UserTransactionHistory, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UserTransactionHistory)
+	err := c.cc.Invoke(ctx, ProfileService_UpdateUserTransactionHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *profileServiceClient) DeleteUserTransactionHistory(ctx context.Context, in *DeleteUserTransactionHistoryRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, ProfileService_DeleteUserTransactionHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProfileServiceServer is the server API for ProfileService service.
+// All implementations must embed UnimplementedProfileServiceServer
+// for forward compatibility.
+//
+// ProfileService reads and changes the transaction history of profiles, like the profile routes of the HTTP API.
+// Callers without the history:read_any scope only reach their own profile.
+type ProfileServiceServer interface {
+	ListUserTransactionHistory(context.Context, *ListUserTransactionHistoryRequest) (*ListUserTransactionHistoryResponse, error)
+	CreateUserTransactionHistory(context.Context, *UserTransactionHistory) (*UserTransactionHistory, error)
+	UpdateUserTransactionHistory(context.Context, *UserTransactionHistory) (*UserTransactionHistory, error)
+	DeleteUserTransactionHistory(context.Context, *DeleteUserTransactionHistoryRequest) (*emptypb.Empty, error)
+	mustEmbedUnimplementedProfileServiceServer()
+}
+
+// UnimplementedProfileServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedProfileServiceServer struct{}
+
+func (UnimplementedProfileServiceServer) ListUserTransactionHistory(context.Context, *ListUserTransactionHistoryRequest) (*ListUserTransactionHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUserTransactionHistory not implemented")
+}
+func (UnimplementedProfileServiceServer) CreateUserTransactionHistory(context.Context, *UserTransactionHistory) (*UserTransactionHistory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateUserTransactionHistory not implemented")
+}
+func (UnimplementedProfileServiceServer) UpdateUserTransactionHistory(context.Context, *UserTransactionHistory) (*UserTransactionHistory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateUserTransactionHistory not implemented")
+}
+func (UnimplementedProfileServiceServer) DeleteUserTransactionHistory(context.Context, *DeleteUserTransactionHistoryRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteUserTransactionHistory not implemented")
+}
+func (UnimplementedProfileServiceServer) mustEmbedUnimplementedProfileServiceServer() {}
+func (UnimplementedProfileServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeProfileServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ProfileServiceServer will
+// result in compilation errors.
+type UnsafeProfileServiceServer interface {
+	mustEmbedUnimplementedProfileServiceServer()
+}
+
+func RegisterProfileServiceServer(s grpc.ServiceRegistrar, srv ProfileServiceServer) {
+	// If the following call pancis, it indicates UnimplementedProfileServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ProfileService_ServiceDesc, srv)
+}
+
+func _ProfileService_ListUserTransactionHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUserTransactionHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProfileServiceServer).ListUserTransactionHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProfileService_ListUserTransactionHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProfileServiceServer).ListUserTransactionHistory(ctx, req.(*ListUserTransactionHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProfileService_CreateUserTransactionHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserTransactionHistory)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProfileServiceServer).CreateUserTransactionHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProfileService_CreateUserTransactionHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProfileServiceServer).CreateUserTransactionHistory(ctx, req.(*UserTransactionHistory))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProfileService_UpdateUserTransactionHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserTransactionHistory)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProfileServiceServer).UpdateUserTransactionHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProfileService_UpdateUserTransactionHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProfileServiceServer).UpdateUserTransactionHistory(ctx, req.(*UserTransactionHistory))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProfileService_DeleteUserTransactionHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteUserTransactionHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProfileServiceServer).DeleteUserTransactionHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProfileService_DeleteUserTransactionHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProfileServiceServer).DeleteUserTransactionHistory(ctx, req.(*DeleteUserTransactionHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ProfileService_ServiceDesc is the grpc.ServiceDesc for ProfileService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ProfileService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "buildservicegin.v1.ProfileService",
+	HandlerType: (*ProfileServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListUserTransactionHistory",
+			Handler:    _ProfileService_ListUserTransactionHistory_Handler,
+		},
+		{
+			MethodName: "CreateUserTransactionHistory",
+			Handler:    _ProfileService_CreateUserTransactionHistory_Handler,
+		},
+		{
+			MethodName: "UpdateUserTransactionHistory",
+			Handler:    _ProfileService_UpdateUserTransactionHistory_Handler,
+		},
+		{
+			MethodName: "DeleteUserTransactionHistory",
+			Handler:    _ProfileService_DeleteUserTransactionHistory_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "point.proto",
+}
+
+const (
+	PointService_CreatePointTransaction_FullMethodName = "/buildservicegin.v1.PointService/CreatePointTransaction"
+	PointService_ReverseTransaction_FullMethodName     = "/buildservicegin.v1.PointService/ReverseTransaction"
+)
+
+// PointServiceClient is the client API for PointService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PointService creates and reverses point transactions, like the point routes of the HTTP API.
+type PointServiceClient interface {
+	CreatePointTransaction(ctx context.Context, in *CreatePointTransactionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ReverseTransaction(ctx context.Context, in *ReverseTransactionRequest, opts ...grpc.CallOption) (*UserTransactionHistory, error)
+}
+
+type pointServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPointServiceClient(cc grpc.ClientConnInterface) PointServiceClient {
+	return &pointServiceClient{cc}
+}
+
+func (c *pointServiceClient) CreatePointTransaction(ctx context.Context, in *CreatePointTransactionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, PointService_CreatePointTransaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pointServiceClient) ReverseTransaction(ctx context.Context, in *ReverseTransactionRequest, opts ...grpc.CallOption) (*UserTransactionHistory, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UserTransactionHistory)
+	err := c.cc.Invoke(ctx, PointService_ReverseTransaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PointServiceServer is the server API for PointService service.
+// All implementations must embed UnimplementedPointServiceServer
+// for forward compatibility.
+//
+// PointService creates and reverses point transactions, like the point routes of the HTTP API.
+type PointServiceServer interface {
+	CreatePointTransaction(context.Context, *CreatePointTransactionRequest) (*emptypb.Empty, error)
+	ReverseTransaction(context.Context, *ReverseTransactionRequest) (*UserTransactionHistory, error)
+	mustEmbedUnimplementedPointServiceServer()
+}
+
+// UnimplementedPointServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPointServiceServer struct{}
+
+func (UnimplementedPointServiceServer) CreatePointTransaction(context.Context, *CreatePointTransactionRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePointTransaction not implemented")
+}
+func (UnimplementedPointServiceServer) ReverseTransaction(context.Context, *ReverseTransactionRequest) (*UserTransactionHistory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReverseTransaction not implemented")
+}
+func (UnimplementedPointServiceServer) mustEmbedUnimplementedPointServiceServer() {}
+func (UnimplementedPointServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafePointServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PointServiceServer will
+// result in compilation errors.
+type UnsafePointServiceServer interface {
+	mustEmbedUnimplementedPointServiceServer()
+}
+
+func RegisterPointServiceServer(s grpc.ServiceRegistrar, srv PointServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPointServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PointService_ServiceDesc, srv)
+}
+
+func _PointService_CreatePointTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePointTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PointServiceServer).CreatePointTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PointService_CreatePointTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PointServiceServer).CreatePointTransaction(ctx, req.(*CreatePointTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PointService_ReverseTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReverseTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PointServiceServer).ReverseTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PointService_ReverseTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PointServiceServer).ReverseTransaction(ctx, req.(*ReverseTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PointService_ServiceDesc is the grpc.ServiceDesc for PointService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PointService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "buildservicegin.v1.PointService",
+	HandlerType: (*PointServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreatePointTransaction",
+			Handler:    _PointService_CreatePointTransaction_Handler,
+		},
+		{
+			MethodName: "ReverseTransaction",
+			Handler:    _PointService_ReverseTransaction_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "point.proto",
+}
@@ -0,0 +1,131 @@
+package grpc
+
+import (
+	"build-service-gin/api/grpc/pb"
+	"build-service-gin/api/http/middlewares"
+	"build-service-gin/common/logger"
+	"build-service-gin/config"
+	"build-service-gin/pkg/auth"
+	"context"
+	"fmt"
+	"net"
+	"sort"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+type GrpcServInterface interface {
+	Start()
+	Shutdown(ctx context.Context) error
+}
+
+type grpcServ struct {
+	conf          *config.SystemConfig
+	profileServer pb.ProfileServiceServer
+	pointServer   pb.PointServiceServer
+	grpcServer    *grpc.Server
+	healthServer  *health.Server
+}
+
+func NewGrpcServe(
+	conf *config.SystemConfig,
+	profileServer pb.ProfileServiceServer,
+	pointServer pb.PointServiceServer,
+) *grpcServ {
+	return &grpcServ{
+		conf:          conf,
+		profileServer: profileServer,
+		pointServer:   pointServer,
+		healthServer:  health.NewServer(),
+	}
+}
+
+// InitServices registers the services of the API, the health service and, when configured, the reflection service
+// on a server authorizing calls like the HTTP routes.
+func (app *grpcServ) InitServices() {
+	verifier, err := auth.NewVerifier(app.conf.AuthConfig)
+	if err != nil {
+		logger.GetLogger().Fatal().Err(err).Msg("init auth verifier failed")
+	}
+	interceptor := &interceptors{auth: middlewares.NewAuthMiddleware(verifier)}
+
+	app.grpcServer = grpc.NewServer(grpc.ChainUnaryInterceptor(
+		interceptor.trace,
+		interceptor.observe,
+		interceptor.authorize,
+	))
+
+	pb.RegisterProfileServiceServer(app.grpcServer, app.profileServer)
+	pb.RegisterPointServiceServer(app.grpcServer, app.pointServer)
+	grpc_health_v1.RegisterHealthServer(app.grpcServer, app.healthServer)
+	if app.conf.GrpcConfig.Reflection {
+		reflection.Register(app.grpcServer)
+	}
+
+	// every method must be authorized like its HTTP route
+	if missing := unauthorizedMethods(app.grpcServer.GetServiceInfo()); len(missing) > 0 {
+		logger.GetLogger().Fatal().Strs("methods", missing).Msg("gRPC methods without authorization rule")
+	}
+
+	for service := range app.grpcServer.GetServiceInfo() {
+		app.healthServer.SetServingStatus(service, grpc_health_v1.HealthCheckResponse_SERVING)
+	}
+}
+
+func (app *grpcServ) Start() {
+	log := logger.GetLogger()
+	app.InitServices()
+	grpcPort := app.conf.GrpcConfig.Port
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", grpcPort))
+	if err != nil {
+		log.Fatal().Msgf("can't listen on gRPC port: %v", err)
+	}
+	go func() {
+		if err := app.grpcServer.Serve(listener); err != nil && err != grpc.ErrServerStopped {
+			log.Fatal().Msgf("can't start gRPC: %v", err)
+		}
+	}()
+	log.Info().Msg("gRPC server started on port: " + fmt.Sprintf("%d", grpcPort))
+}
+
+// Shutdown reports the services as not serving and waits for the running calls, calls still running when ctx is done
+// are cancelled.
+func (app *grpcServ) Shutdown(ctx context.Context) error {
+	log := logger.GetLogger()
+	app.healthServer.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		app.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		log.Info().Msg("gRPC server shutdown gracefully")
+		return nil
+	case <-ctx.Done():
+		app.grpcServer.Stop()
+		log.Error().Msgf("gRPC server shutdown failed: %v", ctx.Err())
+		return ctx.Err()
+	}
+}
+
+// unauthorizedMethods lists the methods of services without a rule in methodRules, public services excepted.
+func unauthorizedMethods(services map[string]grpc.ServiceInfo) []string {
+	var missing []string
+	for service, info := range services {
+		for _, method := range info.Methods {
+			fullMethod := "/" + service + "/" + method.Name
+			if _, ok := methodRules[fullMethod]; !ok && !isPublic(fullMethod) {
+				missing = append(missing, fullMethod)
+			}
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"build-service-gin/config"
+	"build-service-gin/pkg/helpers/resp"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DebugHandler struct {
+	conf *config.SystemConfig
+}
+
+func NewDebugHandler(conf *config.SystemConfig) *DebugHandler {
+	return &DebugHandler{
+		conf: conf,
+	}
+}
+
+// GetConfig shows the configuration in effect with its secrets redacted: the startup values with the reloaded ones
+// applied on top.
+func (h *DebugHandler) GetConfig(c *gin.Context) {
+	cf := *h.conf
+	config.CurrentReloadable().Apply(&cf)
+
+	resp.JSON(c, http.StatusOK, resp.BuildSuccessResp(resp.LangFromContext(c.Request.Context()), gin.H{
+		"file":   config.LoadedFile(),
+		"config": config.Redacted(&cf),
+	}))
+}
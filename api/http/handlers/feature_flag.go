@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"build-service-gin/api/http/models"
+	"build-service-gin/common/custom/binding"
+	"build-service-gin/internal/services"
+	"build-service-gin/pkg/helpers/adapters"
+	"build-service-gin/pkg/helpers/resp"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FeatureFlagHandler struct {
+	flagService services.IFeatureFlagService
+}
+
+func NewFeatureFlagHandler(flagService services.IFeatureFlagService) *FeatureFlagHandler {
+	return &FeatureFlagHandler{
+		flagService: flagService,
+	}
+}
+
+func (h *FeatureFlagHandler) GetFlags(c *gin.Context) {
+	data, err := h.flagService.ListFlags(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp.JSON(c, http.StatusOK, resp.BuildSuccessResp(resp.LangFromContext(c.Request.Context()), data))
+}
+
+func (h *FeatureFlagHandler) PutFlag(c *gin.Context) {
+	var req models.FeatureFlagRequest
+	if err := binding.GetBinding().Bind(c, &req); err != nil {
+		c.Error(resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err))
+		return
+	}
+	req.Key = c.Param("key")
+
+	data, err := h.flagService.UpsertFlag(c.Request.Context(), adapters.AdapterFeatureFlag{}.ConvReq2Flag(&req))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp.JSON(c, http.StatusOK, resp.BuildSuccessResp(resp.LangFromContext(c.Request.Context()), data))
+}
+
+func (h *FeatureFlagHandler) DeleteFlag(c *gin.Context) {
+	var req models.FeatureFlagKeyRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.Error(resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err))
+		return
+	}
+
+	if err := h.flagService.DeleteFlag(c.Request.Context(), req.Key); err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp.JSON(c, http.StatusOK, resp.BuildSuccessResp(resp.LangFromContext(c.Request.Context()), nil))
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"build-service-gin/api/graphql"
+	"build-service-gin/api/http/middlewares"
+	"build-service-gin/api/http/models"
+	"build-service-gin/common/custom/binding"
+	"build-service-gin/config"
+	"build-service-gin/internal/services"
+	"build-service-gin/pkg/helpers/resp"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GraphQLHandler struct {
+	executor *graphql.Executor
+}
+
+func NewGraphQLHandler(profileService services.IProfileService, conf config.GraphQLConfig) *GraphQLHandler {
+	return &GraphQLHandler{
+		executor: graphql.NewExecutor(profileService, conf),
+	}
+}
+
+// Query runs a GraphQL query. The result is sent with a 200 status, the errors of the query in its errors field
+// rather than the error body of the REST routes.
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	var req models.GraphQLRequest
+	if err := binding.GetBinding().Bind(c, &req); err != nil {
+		c.Error(resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err))
+		return
+	}
+
+	result := h.executor.Execute(c.Request.Context(), graphql.Request{
+		Query:         req.Query,
+		OperationName: req.OperationName,
+		Variables:     req.Variables,
+	}, middlewares.AccessOf(c))
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"build-service-gin/pkg/health"
+	"build-service-gin/pkg/helpers/resp"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	registry *health.Registry
+}
+
+func NewHealthHandler(registry *health.Registry) *HealthHandler {
+	return &HealthHandler{
+		registry: registry,
+	}
+}
+
+// Live tells the service is running, whatever the state of its dependencies, it is restarted only when it stops
+// answering.
+func (h *HealthHandler) Live(c *gin.Context) {
+	resp.JSON(c, http.StatusOK, resp.BuildSuccessResp(resp.LangFromContext(c.Request.Context()), nil))
+}
+
+// Ready reports the last state of the dependencies, with 503 while a critical one is down, before they are first
+// checked and once the service drains.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	lang := resp.LangFromContext(c.Request.Context())
+	report := h.registry.Report()
+	if report.Ready() {
+		resp.JSON(c, http.StatusOK, resp.BuildSuccessResp(lang, report))
+		return
+	}
+
+	r := resp.BuildErrorResp(resp.ErrServiceNotReady, "", lang)
+	r.Data = report
+	resp.JSON(c, http.StatusServiceUnavailable, r)
+}
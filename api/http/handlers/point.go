@@ -24,7 +24,7 @@ func (h *PointHandler) CreatePointTransaction(c *gin.Context) {
 	var req *models.OrderRequest
 
 	if err := binding.GetBinding().Bind(c, &req); err != nil {
-		c.JSON(http.StatusBadRequest, resp.BuildErrorResp(resp.ErrDataInvalid, err.Error(), resp.LangEN))
+		c.Error(resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err))
 		return
 	}
 
@@ -32,9 +32,33 @@ func (h *PointHandler) CreatePointTransaction(c *gin.Context) {
 
 	err := h.pointService.CreatePointTransaction(c.Request.Context(), dataDomain)
 	if err != nil {
-		c.JSON(http.StatusNotFound, resp.BuildErrorResp(err.ErrorCode, err.Description, resp.LangEN))
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, resp.BuildSuccessResp(resp.LangEN, nil))
+	resp.JSON(c, http.StatusOK, resp.BuildSuccessResp(resp.LangFromContext(c.Request.Context()), nil))
+}
+
+func (h *PointHandler) ReverseTransaction(c *gin.Context) {
+	var req *models.ReverseTransactionRequest
+
+	if err := binding.GetBinding().Bind(c, &req); err != nil {
+		c.Error(resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err))
+		return
+	}
+	// the HTTP callers must name the profile owning the transaction, only the reversals of that profile go through
+	if req.ProfileID == "" {
+		c.Error(resp.NewError(resp.Invalid, resp.ErrDataInvalid, "profileID is required"))
+		return
+	}
+
+	dataDomain := adapters.AdapterLPPoint{}.ConvertReverseRequest2Domain(req)
+
+	data, err := h.pointService.ReverseTransactionPoint(c.Request.Context(), dataDomain)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp.JSON(c, http.StatusOK, resp.BuildSuccessResp(resp.LangFromContext(c.Request.Context()), data))
 }
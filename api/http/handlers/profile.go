@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"build-service-gin/api/http/middlewares"
 	"build-service-gin/api/http/models"
 	"build-service-gin/common/custom/binding"
 	"build-service-gin/internal/services"
+	"build-service-gin/pkg/featureflag"
 	"build-service-gin/pkg/helpers/adapters"
 	"build-service-gin/pkg/helpers/resp"
 	"net/http"
@@ -14,11 +16,13 @@ import (
 
 type ProfileHandler struct {
 	profileService services.IProfileService
+	flags          *featureflag.Service
 }
 
-func NewProfileHandler(profileService services.IProfileService) *ProfileHandler {
+func NewProfileHandler(profileService services.IProfileService, flags *featureflag.Service) *ProfileHandler {
 	return &ProfileHandler{
 		profileService: profileService,
+		flags:          flags,
 	}
 }
 
@@ -26,31 +30,46 @@ func (h *ProfileHandler) GetUserTransactionHistory(c *gin.Context) {
 	var req models.GetUserTransactionHistoryReq
 
 	if err := binding.GetBinding().Bind(c, &req); err != nil {
-		c.JSON(http.StatusBadRequest, resp.BuildErrorResp(resp.ErrDataInvalid, err.Error(), resp.LangEN))
+		c.Error(resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err))
+		return
+	}
+	if err := middlewares.CheckProfile(c, req.ProfileID); err != nil {
+		c.Error(err)
 		return
 	}
 
 	ctx := c.Request.Context()
 	dataDomain := adapters.AdapterProfile{}.ConvReq2ServUserTransactionHistoryTx(req)
-	data, total, err := h.profileService.GetUserHistoryByProfile(ctx, *dataDomain)
+
+	// the profiles targeted by the rollout read their history from Postgres
+	getHistory := h.profileService.GetUserHistoryByProfile
+	if h.flags.Enabled(featureflag.FlagHistoryPostgresRead, featureflag.TargetFromContext(ctx, req.ProfileID)) {
+		getHistory = h.profileService.GetUserHistoryByProfilePostgresql
+	}
+
+	data, total, err := getHistory(ctx, *dataDomain)
 	if err != nil {
-		c.JSON(http.StatusNotFound, resp.BuildErrorResp(err.ErrorCode, err.Description, resp.LangEN))
+		c.Error(err)
 		return
 	}
 
-	rs := resp.BuildSuccessResp(resp.LangEN, data)
+	rs := resp.BuildSuccessResp(resp.LangFromContext(c.Request.Context()), data)
 	rs.Paging = &resp.Paging{
 		Total:  total,
 		Offset: req.Offset,
 		Limit:  req.Limit,
 	}
-	c.JSON(http.StatusOK, rs)
+	resp.JSON(c, http.StatusOK, rs)
 }
 
 func (h *ProfileHandler) CreateUserTransactionHistory(c *gin.Context) {
 	var req models.UserTransactionHistory
 	if err := binding.GetBinding().Bind(c, &req); err != nil {
-		c.JSON(http.StatusBadRequest, resp.BuildErrorResp(resp.ErrDataInvalid, err.Error(), resp.LangEN))
+		c.Error(resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err))
+		return
+	}
+	if err := middlewares.CheckProfile(c, req.ProfileID); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -60,18 +79,18 @@ func (h *ProfileHandler) CreateUserTransactionHistory(c *gin.Context) {
 
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to create user transaction history")
-		c.JSON(http.StatusNotFound, resp.BuildErrorResp(err.ErrorCode, err.Description, resp.LangEN))
+		c.Error(err)
 		return
 	}
 
-	successResp := resp.BuildSuccessResp(resp.LangEN, *data)
-	c.JSON(http.StatusOK, successResp)
+	successResp := resp.BuildSuccessResp(resp.LangFromContext(c.Request.Context()), *data)
+	resp.JSON(c, http.StatusOK, successResp)
 }
 
 func (h *ProfileHandler) UpdateUserTransactionHistory(c *gin.Context) {
 	var req models.UserTransactionHistory
 	if err := binding.GetBinding().Bind(c, &req); err != nil {
-		c.JSON(http.StatusBadRequest, resp.BuildErrorResp(resp.ErrDataInvalid, err.Error(), resp.LangEN))
+		c.Error(resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err))
 		return
 	}
 
@@ -79,33 +98,37 @@ func (h *ProfileHandler) UpdateUserTransactionHistory(c *gin.Context) {
 	dataDomain := adapters.AdapterProfile{}.ConvModelToDomainUserTransactionHistoryTx(req)
 	data, err := h.profileService.UpdateUserTransactionHistoryByProfile(ctx, dataDomain, dataDomain.ProfileID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, resp.BuildErrorResp(err.ErrorCode, err.Description, resp.LangEN))
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, resp.BuildSuccessResp(resp.LangEN, data))
+	resp.JSON(c, http.StatusOK, resp.BuildSuccessResp(resp.LangFromContext(c.Request.Context()), data))
 }
 
 func (h *ProfileHandler) DeleteUserTransactionHistory(c *gin.Context) {
 	var req models.GetUserTransactionHistoryByProfileReq
 	if err := binding.GetBinding().Bind(c, &req); err != nil {
-		c.JSON(http.StatusBadRequest, resp.BuildErrorResp(resp.ErrDataInvalid, err.Error(), resp.LangEN))
+		c.Error(resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err))
 		return
 	}
 
 	ctx := c.Request.Context()
 	err := h.profileService.DeleteUserTransactionHistoryByProfile(ctx, req.ProfileID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, resp.BuildErrorResp(err.ErrorCode, err.Description, resp.LangEN))
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, resp.BuildSuccessResp(resp.LangEN, nil))
+	resp.JSON(c, http.StatusOK, resp.BuildSuccessResp(resp.LangFromContext(c.Request.Context()), nil))
 }
 
 func (h *ProfileHandler) GetUserTransactionHistoryPostgres(c *gin.Context) {
 	var req models.GetUserTransactionHistoryReq
 
 	if err := binding.GetBinding().Bind(c, &req); err != nil {
-		c.JSON(http.StatusBadRequest, resp.BuildErrorResp(resp.ErrDataInvalid, err.Error(), resp.LangEN))
+		c.Error(resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err))
+		return
+	}
+	if err := middlewares.CheckProfile(c, req.ProfileID); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -113,23 +136,27 @@ func (h *ProfileHandler) GetUserTransactionHistoryPostgres(c *gin.Context) {
 	dataDomain := adapters.AdapterProfile{}.ConvReq2ServUserTransactionHistoryTx(req)
 	data, total, err := h.profileService.GetUserHistoryByProfilePostgresql(ctx, *dataDomain)
 	if err != nil {
-		c.JSON(http.StatusNotFound, resp.BuildErrorResp(err.ErrorCode, err.Description, resp.LangEN))
+		c.Error(err)
 		return
 	}
 
-	rs := resp.BuildSuccessResp(resp.LangEN, data)
+	rs := resp.BuildSuccessResp(resp.LangFromContext(c.Request.Context()), data)
 	rs.Paging = &resp.Paging{
 		Total:  total,
 		Offset: req.Offset,
 		Limit:  req.Limit,
 	}
-	c.JSON(http.StatusOK, rs)
+	resp.JSON(c, http.StatusOK, rs)
 }
 
 func (h *ProfileHandler) CreateUserTransactionHistoryPostgres(c *gin.Context) {
 	var req models.UserTransactionHistory
 	if err := binding.GetBinding().Bind(c, &req); err != nil {
-		c.JSON(http.StatusBadRequest, resp.BuildErrorResp(resp.ErrDataInvalid, err.Error(), resp.LangEN))
+		c.Error(resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err))
+		return
+	}
+	if err := middlewares.CheckProfile(c, req.ProfileID); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -139,10 +166,10 @@ func (h *ProfileHandler) CreateUserTransactionHistoryPostgres(c *gin.Context) {
 
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to create user transaction history")
-		c.JSON(http.StatusNotFound, resp.BuildErrorResp(err.ErrorCode, err.Description, resp.LangEN))
+		c.Error(err)
 		return
 	}
 
-	successResp := resp.BuildSuccessResp(resp.LangEN, *data)
-	c.JSON(http.StatusOK, successResp)
+	successResp := resp.BuildSuccessResp(resp.LangFromContext(c.Request.Context()), *data)
+	resp.JSON(c, http.StatusOK, successResp)
 }
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"build-service-gin/api/http/middlewares"
+	"build-service-gin/api/http/models"
+	"build-service-gin/common/custom/binding"
+	"build-service-gin/common/utils"
+	"build-service-gin/config"
+	"build-service-gin/internal/services"
+	"build-service-gin/pkg/helpers/resp"
+	"build-service-gin/pkg/stream"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// streamWriteTimeout bounds each write of a stream, which outlives the write timeout of the server.
+const streamWriteTimeout = 10 * time.Second
+
+type StreamHandler struct {
+	streamService services.IStreamService
+	conf          config.StreamConfig
+	done          chan struct{}
+	closeOnce     sync.Once
+}
+
+func NewStreamHandler(streamService services.IStreamService, conf config.StreamConfig) *StreamHandler {
+	return &StreamHandler{
+		streamService: streamService,
+		conf:          conf,
+		done:          make(chan struct{}),
+	}
+}
+
+// Close ends the running streams, which would otherwise hold the shutdown of the server until its timeout.
+func (h *StreamHandler) Close() {
+	h.closeOnce.Do(func() { close(h.done) })
+}
+
+// StreamProfile streams the transaction and balance events of the profile as server-sent events, with a comment
+// sent as heartbeat when nothing happened for HeartbeatInterval. The stream ends with the request, when the client
+// falls too far behind or when the server shuts down; the client then reconnects with the id of the last event it
+// got in Last-Event-ID.
+func (h *StreamHandler) StreamProfile(c *gin.Context) {
+	var req models.ProfileStreamReq
+	if err := binding.GetBinding().Bind(c, &req); err != nil {
+		c.Error(resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err))
+		return
+	}
+	if err := middlewares.CheckProfile(c, req.ProfileID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	lastEventID := c.GetHeader(utils.HeaderLastEventID)
+	if lastEventID == "" {
+		lastEventID = req.LastEventID
+	}
+
+	ctx := c.Request.Context()
+	sub, err := h.streamService.Subscribe(ctx, req.ProfileID, lastEventID)
+	if err != nil {
+		if errors.Is(err, resp.Unavailable) {
+			c.Header("Retry-After", strconv.Itoa(int(h.conf.RetryInterval.Seconds())+1))
+		}
+		c.Error(err)
+		return
+	}
+	defer sub.Close()
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", utils.MIMEEventStream)
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	controller := http.NewResponseController(c.Writer)
+	_ = controller.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	if _, err := fmt.Fprintf(c.Writer, "retry: %d\n\n", h.conf.RetryInterval.Milliseconds()); err != nil {
+		return
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.conf.HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.done:
+			return
+		case <-heartbeat.C:
+			_ = controller.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			if _, err := io.WriteString(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case <-sub.Ready():
+			_ = controller.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			events, errNext := sub.Next()
+			for _, event := range events {
+				if err := writeEvent(c.Writer, event); err != nil {
+					return
+				}
+			}
+			if errNext != nil {
+				return
+			}
+			heartbeat.Reset(h.conf.HeartbeatInterval)
+		}
+		c.Writer.Flush()
+	}
+}
+
+// writeEvent writes event in the text/event-stream format, every line of its data in a data field.
+func writeEvent(w io.Writer, event stream.Event) error {
+	var b strings.Builder
+	if event.ID != "" {
+		fmt.Fprintf(&b, "id: %s\n", event.ID)
+	}
+	fmt.Fprintf(&b, "event: %s\n", event.Name)
+	data := string(event.Data)
+	if data == "" {
+		data = "{}"
+	}
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
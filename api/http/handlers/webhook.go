@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"build-service-gin/api/http/models"
+	"build-service-gin/common/custom/binding"
+	"build-service-gin/internal/services"
+	"build-service-gin/pkg/helpers/adapters"
+	"build-service-gin/pkg/helpers/resp"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	webhookService services.IWebhookService
+}
+
+func NewWebhookHandler(webhookService services.IWebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+func (h *WebhookHandler) CreateSubscription(c *gin.Context) {
+	var req models.WebhookSubscriptionRequest
+	if err := binding.GetBinding().Bind(c, &req); err != nil {
+		c.Error(resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err))
+		return
+	}
+
+	dataDomain := adapters.AdapterWebhook{}.ConvReq2DomainSubscription(&req)
+	data, err := h.webhookService.CreateSubscription(c.Request.Context(), dataDomain)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp.JSON(c, http.StatusOK, resp.BuildSuccessResp(resp.LangFromContext(c.Request.Context()), data))
+}
+
+func (h *WebhookHandler) GetSubscriptions(c *gin.Context) {
+	var req models.GetWebhookSubscriptionsReq
+	if err := binding.GetBinding().Bind(c, &req); err != nil {
+		c.Error(resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err))
+		return
+	}
+
+	data, total, err := h.webhookService.ListSubscriptions(c.Request.Context(), req.Offset, req.Limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	rs := resp.BuildSuccessResp(resp.LangFromContext(c.Request.Context()), data)
+	rs.Paging = &resp.Paging{
+		Total:  total,
+		Offset: req.Offset,
+		Limit:  req.Limit,
+	}
+	resp.JSON(c, http.StatusOK, rs)
+}
+
+func (h *WebhookHandler) GetSubscription(c *gin.Context) {
+	var req models.WebhookSubscriptionIDRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.Error(resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err))
+		return
+	}
+
+	data, err := h.webhookService.GetSubscription(c.Request.Context(), req.SubscriptionID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp.JSON(c, http.StatusOK, resp.BuildSuccessResp(resp.LangFromContext(c.Request.Context()), data))
+}
+
+func (h *WebhookHandler) UpdateSubscription(c *gin.Context) {
+	var req models.WebhookSubscriptionRequest
+	if err := binding.GetBinding().Bind(c, &req); err != nil {
+		c.Error(resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err))
+		return
+	}
+	req.SubscriptionID = c.Param("subscriptionID")
+
+	dataDomain := adapters.AdapterWebhook{}.ConvReq2DomainSubscription(&req)
+	data, err := h.webhookService.UpdateSubscription(c.Request.Context(), dataDomain)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp.JSON(c, http.StatusOK, resp.BuildSuccessResp(resp.LangFromContext(c.Request.Context()), data))
+}
+
+func (h *WebhookHandler) DeleteSubscription(c *gin.Context) {
+	var req models.WebhookSubscriptionIDRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.Error(resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err))
+		return
+	}
+
+	if err := h.webhookService.DeleteSubscription(c.Request.Context(), req.SubscriptionID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp.JSON(c, http.StatusOK, resp.BuildSuccessResp(resp.LangFromContext(c.Request.Context()), nil))
+}
+
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	var req models.GetWebhookDeliveriesReq
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.Error(resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err))
+		return
+	}
+	if err := binding.GetBinding().Bind(c, &req); err != nil {
+		c.Error(resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err))
+		return
+	}
+
+	dataDomain := adapters.AdapterWebhook{}.ConvReq2DomainDeliveries(&req)
+	data, total, err := h.webhookService.ListDeliveries(c.Request.Context(), dataDomain)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	rs := resp.BuildSuccessResp(resp.LangFromContext(c.Request.Context()), data)
+	rs.Paging = &resp.Paging{
+		Total:  total,
+		Offset: req.Offset,
+		Limit:  req.Limit,
+	}
+	resp.JSON(c, http.StatusOK, rs)
+}
+
+func (h *WebhookHandler) GetDelivery(c *gin.Context) {
+	var req models.WebhookDeliveryIDRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.Error(resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err))
+		return
+	}
+
+	data, err := h.webhookService.GetDelivery(c.Request.Context(), req.DeliveryID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp.JSON(c, http.StatusOK, resp.BuildSuccessResp(resp.LangFromContext(c.Request.Context()), data))
+}
+
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	var req models.WebhookDeliveryIDRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.Error(resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err))
+		return
+	}
+
+	data, err := h.webhookService.Redeliver(c.Request.Context(), req.DeliveryID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp.JSON(c, http.StatusOK, resp.BuildSuccessResp(resp.LangFromContext(c.Request.Context()), data))
+}
@@ -0,0 +1,113 @@
+package http
+
+import (
+	"build-service-gin/config"
+	"build-service-gin/pkg/health"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestHealthProbes checks the liveness probe answers whatever the state of the dependencies, and the readiness
+// probes follow it.
+func TestHealthProbes(t *testing.T) {
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	hung := health.CheckerFunc(func(context.Context) error {
+		<-release
+		return nil
+	})
+	up := health.CheckerFunc(func(context.Context) error { return nil })
+	down := health.CheckerFunc(func(context.Context) error { return errors.New("connection refused") })
+
+	tests := []struct {
+		name       string
+		critical   health.Checker
+		optional   health.Checker
+		notStarted bool
+		draining   bool
+		wantStatus string
+		wantReady  int
+	}{
+		{name: "not checked yet", critical: up, optional: up, notStarted: true, wantStatus: health.StatusUnknown, wantReady: http.StatusServiceUnavailable},
+		{name: "every dependency up", critical: up, optional: up, wantStatus: health.StatusUp, wantReady: http.StatusOK},
+		{name: "optional dependency down", critical: up, optional: down, wantStatus: health.StatusDegraded, wantReady: http.StatusOK},
+		{name: "critical dependency down", critical: down, optional: up, wantStatus: health.StatusDown, wantReady: http.StatusServiceUnavailable},
+		{name: "critical dependency hung", critical: hung, optional: up, wantStatus: health.StatusDown, wantReady: http.StatusServiceUnavailable},
+		{name: "draining", critical: up, optional: up, draining: true, wantStatus: health.StatusDraining, wantReady: http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := health.NewRegistry(config.HealthConfig{Interval: time.Hour, Timeout: 20 * time.Millisecond, CacheTTL: time.Hour}, nil)
+			registry.Register("mongo", true, tt.critical)
+			registry.Register("receiver", false, tt.optional)
+			if !tt.notStarted {
+				startRegistry(t, registry)
+			}
+			if tt.draining {
+				registry.SetDraining()
+			}
+
+			conf := &config.SystemConfig{}
+			conf.AuthConfig.HS256Secret = testSecret
+			g := gin.New()
+			NewHttpServe(conf, nil, nil, nil, nil, nil, nil, registry).InitRouters(g)
+
+			if code, _ := probe(t, g, "/build-service-gin/v1/health/live"); code != http.StatusOK {
+				t.Errorf("live = %d, want %d", code, http.StatusOK)
+			}
+			for _, path := range []string{"/build-service-gin/v1/health/ready", "/build-service-gin/v1/health"} {
+				code, report := probe(t, g, path)
+				if code != tt.wantReady || report.Status != tt.wantStatus {
+					t.Errorf("%s = %d %s, want %d %s", path, code, report.Status, tt.wantReady, tt.wantStatus)
+				}
+				if len(report.Checks) != 2 || !report.Checks["mongo"].Critical || report.Checks["receiver"].Critical {
+					t.Errorf("%s checks = %+v, want mongo critical and receiver optional", path, report.Checks)
+				}
+			}
+		})
+	}
+}
+
+// startRegistry runs the checks of registry until the end of the test, once they were all checked.
+func startRegistry(t *testing.T, registry *health.Registry) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		registry.Start(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for registry.Report().Status == health.StatusUnknown {
+		if time.Now().After(deadline) {
+			t.Fatal("dependencies were not checked")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func probe(t *testing.T, handler http.Handler, path string) (int, health.Report) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var body struct {
+		Data health.Report `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode %s: %v, body %s", path, err, rec.Body.String())
+	}
+	return rec.Code, body.Data
+}
@@ -0,0 +1,163 @@
+package middlewares
+
+import (
+	"build-service-gin/common/logger"
+	"build-service-gin/common/utils"
+	"build-service-gin/config"
+	"build-service-gin/pkg/auth"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/tidwall/gjson"
+)
+
+const testSecret = "middlewares-test-secret"
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	logger.InitLog("test")
+	os.Exit(m.Run())
+}
+
+func signToken(t *testing.T, subject string, scopes ...string) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Type:  utils.IASTypeClient,
+		Scope: strings.Join(scopes, " "),
+	})
+	signed, err := token.SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+// profileRouter serves a scoped route echoing the profile it sees, then the one checked after binding.
+func profileRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	verifier, err := auth.NewVerifier(config.AuthConfig{HS256Secret: testSecret})
+	if err != nil {
+		t.Fatalf("new verifier: %v", err)
+	}
+
+	router := gin.New()
+	router.Use(ErrorHandler)
+	router.Any("/profile", NewAuthMiddleware(verifier).Authenticate(utils.IASTypeClient), ScopeProfile, func(c *gin.Context) {
+		profileID := c.Query(keyProfileID)
+		if c.Request.Body != nil {
+			body, _ := io.ReadAll(c.Request.Body)
+			if len(body) > 0 {
+				profileID = gjson.GetBytes(body, keyProfileID).String()
+			}
+		}
+		if errCustom := CheckProfile(c, profileID); errCustom != nil {
+			c.Error(errCustom)
+			return
+		}
+		c.String(http.StatusOK, profileID)
+	})
+	return router
+}
+
+func TestScopeProfile(t *testing.T) {
+	router := profileRouter(t)
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		scopes      []string
+		noToken     bool
+		wantStatus  int
+		wantProfile string
+	}{
+		{name: "query pinned to the subject", method: http.MethodGet, target: "/profile", wantStatus: http.StatusOK, wantProfile: "alice"},
+		{name: "query for the subject", method: http.MethodGet, target: "/profile?profileID=alice", wantStatus: http.StatusOK, wantProfile: "alice"},
+		{name: "query for another profile", method: http.MethodGet, target: "/profile?profileID=bob", wantStatus: http.StatusForbidden},
+		{name: "json body pinned to the subject", method: http.MethodPost, target: "/profile", contentType: "application/json", body: `{"amount":1}`, wantStatus: http.StatusOK, wantProfile: "alice"},
+		{name: "json body with charset", method: http.MethodPost, target: "/profile", contentType: "application/json; charset=utf-8", body: `{"profileID":"alice"}`, wantStatus: http.StatusOK, wantProfile: "alice"},
+		{name: "json body for another profile", method: http.MethodPost, target: "/profile", contentType: "application/json", body: `{"profileID":"bob"}`, wantStatus: http.StatusForbidden},
+		{name: "form body", method: http.MethodPost, target: "/profile", contentType: "application/x-www-form-urlencoded", body: "profileID=bob", wantStatus: http.StatusBadRequest},
+		{name: "multipart body", method: http.MethodPost, target: "/profile", contentType: "multipart/form-data; boundary=x", body: "--x\r\n\r\n--x--\r\n", wantStatus: http.StatusBadRequest},
+		{name: "body without content type", method: http.MethodPost, target: "/profile", body: "profileID=bob", wantStatus: http.StatusBadRequest},
+		{name: "read_any addresses any profile", method: http.MethodGet, target: "/profile?profileID=bob", scopes: []string{auth.ScopeHistoryReadAny}, wantStatus: http.StatusOK, wantProfile: "bob"},
+		{name: "no token", method: http.MethodGet, target: "/profile", noToken: true, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			req := httptest.NewRequest(tt.method, tt.target, body)
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			if !tt.noToken {
+				req.Header.Set("Authorization", "Bearer "+signToken(t, "alice", tt.scopes...))
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK && rec.Body.String() != tt.wantProfile {
+				t.Errorf("profile = %q, want %q", rec.Body.String(), tt.wantProfile)
+			}
+		})
+	}
+}
+
+func TestCheckProfile(t *testing.T) {
+	verifier, err := auth.NewVerifier(config.AuthConfig{HS256Secret: testSecret})
+	if err != nil {
+		t.Fatalf("new verifier: %v", err)
+	}
+	principal, err := verifier.Verify(signToken(t, "alice"))
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		profileID string
+		wantErr   bool
+	}{
+		{name: "own profile", principal: principal, profileID: "alice"},
+		{name: "empty profile", principal: principal, profileID: ""},
+		{name: "another profile", principal: principal, profileID: "bob", wantErr: true},
+		{name: "no principal", profileID: "alice", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/profile", nil)
+			if tt.principal != nil {
+				req = req.WithContext(auth.WithPrincipal(req.Context(), tt.principal))
+			}
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = req
+
+			if errCustom := CheckProfile(c, tt.profileID); (errCustom != nil) != tt.wantErr {
+				t.Fatalf("CheckProfile() = %v, wantErr %v", errCustom, tt.wantErr)
+			}
+		})
+	}
+}
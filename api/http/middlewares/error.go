@@ -0,0 +1,48 @@
+package middlewares
+
+import (
+	"build-service-gin/common/logger"
+	"build-service-gin/pkg/helpers/resp"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorHandler writes the response of the last error added with c.Error when nothing was written yet, in the format
+// negotiated by ResponseFormat. The category of the error picks the status, see resp.HTTPStatus. Server errors are
+// logged and their details are not sent back.
+func ErrorHandler(c *gin.Context) {
+	c.Next()
+
+	if len(c.Errors) == 0 || c.Writer.Written() {
+		return
+	}
+
+	ctx := c.Request.Context()
+	err := c.Errors.Last().Err
+	status := resp.HTTPStatus(err)
+
+	code, description := int64(resp.ErrSystem), ""
+	var params map[string]interface{}
+	var fields []resp.FieldError
+	var customErr *resp.CustomError
+	if errors.As(err, &customErr) {
+		code, description, params, fields = customErr.ErrorCode, customErr.Description, customErr.Params, customErr.Fields
+	}
+
+	if status >= http.StatusInternalServerError {
+		logger.GetLogger().AddTraceInfoContextRequest(ctx).Error().Err(err).Int("status", status).Msg("request failed")
+		description = ""
+	}
+
+	rs := resp.BuildErrorRespParams(code, description, resp.LangFromContext(ctx), params)
+	rs.Errors = resp.LocalizeFields(fields, resp.LangFromContext(ctx))
+	resp.JSON(c, status, rs)
+}
+
+// abortWithError stops the chain, ErrorHandler writes the response of err.
+func abortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
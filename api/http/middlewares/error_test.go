@@ -0,0 +1,135 @@
+package middlewares
+
+import (
+	"build-service-gin/pkg/helpers/resp"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+)
+
+// errorRouter serves /fail, failing with err, behind the middlewares negotiating the response.
+func errorRouter(err error) *gin.Engine {
+	router := gin.New()
+	router.Use(Language, ResponseFormat, ErrorHandler)
+	router.GET("/fail", func(c *gin.Context) {
+		_ = c.Error(err)
+	})
+	return router
+}
+
+func TestErrorHandler(t *testing.T) {
+	invalid := resp.NewError(resp.Invalid, resp.ErrHandleLimitInvalid, "limit - max=100")
+	invalid.Fields = []resp.FieldError{{Field: "limit", Rule: "max", Param: "100", Message: "limit - max=100"}}
+
+	tests := []struct {
+		name            string
+		err             error
+		accept          string
+		acceptLanguage  string
+		wantStatus      int
+		wantContentType string
+		wantLanguage    string
+		// wantBody maps gjson paths of the body to their values.
+		wantBody map[string]string
+	}{
+		{
+			name:            "envelope",
+			err:             invalid,
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "application/json; charset=utf-8",
+			wantLanguage:    "en",
+			wantBody: map[string]string{
+				"errorCode":        "2012",
+				"description":      "limit - max=100",
+				"message":          "Limit invalid",
+				"errors.0.field":   "limit",
+				"errors.0.message": "limit must be at most 100",
+			},
+		},
+		{
+			name:            "envelope in vietnamese",
+			err:             invalid,
+			accept:          "application/json",
+			acceptLanguage:  "vi-VN,vi;q=0.9",
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "application/json; charset=utf-8",
+			wantLanguage:    "vi",
+			wantBody:        map[string]string{"errors.0.message": "limit phải nhỏ hơn hoặc bằng 100"},
+		},
+		{
+			name:            "problem",
+			err:             invalid,
+			accept:          "application/problem+json",
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "application/problem+json; charset=utf-8",
+			wantLanguage:    "en",
+			wantBody: map[string]string{
+				"type":           "urn:build-service-gin:error:2012",
+				"title":          "Limit invalid",
+				"status":         "400",
+				"detail":         "limit - max=100",
+				"code":           "2012",
+				"errors.0.field": "limit",
+			},
+		},
+		{
+			name:            "json preferred over problem",
+			err:             invalid,
+			accept:          "application/json, application/problem+json;q=0.5",
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "application/json; charset=utf-8",
+			wantLanguage:    "en",
+			wantBody:        map[string]string{"errorCode": "2012"},
+		},
+		{
+			name:            "server error hides its cause",
+			err:             errors.New("mongo: connection refused"),
+			accept:          "application/problem+json",
+			acceptLanguage:  "vi",
+			wantStatus:      http.StatusInternalServerError,
+			wantContentType: "application/problem+json; charset=utf-8",
+			wantLanguage:    "vi",
+			wantBody:        map[string]string{"code": "1000", "title": "Lỗi hệ thống", "detail": ""},
+		},
+		{
+			name:            "category picks the status",
+			err:             resp.NewError(resp.Unavailable, resp.ErrCallExternalService, "reward down"),
+			wantStatus:      http.StatusServiceUnavailable,
+			wantContentType: "application/json; charset=utf-8",
+			wantLanguage:    "en",
+			wantBody:        map[string]string{"errorCode": "9000", "description": "There was an error during the call external services"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			rec := httptest.NewRecorder()
+			errorRouter(tt.err).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantContentType)
+			}
+			if got := rec.Header().Get(headerContentLanguage); got != tt.wantLanguage {
+				t.Errorf("Content-Language = %q, want %q", got, tt.wantLanguage)
+			}
+			for path, want := range tt.wantBody {
+				if got := gjson.Get(rec.Body.String(), path).String(); got != want {
+					t.Errorf("%s = %q, want %q in %s", path, got, want, rec.Body.String())
+				}
+			}
+		})
+	}
+}
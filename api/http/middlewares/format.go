@@ -0,0 +1,22 @@
+package middlewares
+
+import (
+	"build-service-gin/pkg/helpers/resp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// ResponseFormat negotiates the format of the response body from Accept and stores it in the request context, read
+// back with resp.FormatFromContext. Clients accepting application/problem+json before application/json get
+// resp.FormatProblem, everyone else keeps the legacy envelope.
+func ResponseFormat(c *gin.Context) {
+	format := resp.FormatEnvelope
+	if c.NegotiateFormat(binding.MIMEJSON, resp.MIMEProblemJSON) == resp.MIMEProblemJSON {
+		format = resp.FormatProblem
+	}
+	c.Request = c.Request.WithContext(resp.WithFormat(c.Request.Context(), format))
+	c.Writer.Header().Add("Vary", "Accept")
+
+	c.Next()
+}
@@ -0,0 +1,34 @@
+package middlewares
+
+import (
+	"build-service-gin/pkg/helpers/resp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+const headerContentLanguage = "Content-Language"
+
+// supportedLanguages is ordered like resp.Languages, the first one is the default.
+var (
+	supportedLanguages = []language.Tag{language.English, language.Vietnamese}
+	languageMatcher    = language.NewMatcher(supportedLanguages)
+)
+
+// Language negotiates the response language from Accept-Language and stores it in the request context, read back
+// with resp.LangFromContext. Unsupported or missing languages get English.
+func Language(c *gin.Context) {
+	lang := NegotiateLanguage(c.GetHeader("Accept-Language"))
+	c.Request = c.Request.WithContext(resp.WithLang(c.Request.Context(), lang))
+	c.Header(headerContentLanguage, strings.ToLower(lang))
+
+	c.Next()
+}
+
+// NegotiateLanguage returns the language of resp.Languages best matching an Accept-Language value.
+func NegotiateLanguage(acceptLanguage string) string {
+	tags, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+	_, index, _ := languageMatcher.Match(tags...)
+	return resp.Languages[index]
+}
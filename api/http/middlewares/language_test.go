@@ -0,0 +1,30 @@
+package middlewares
+
+import (
+	"build-service-gin/pkg/helpers/resp"
+	"testing"
+)
+
+func TestNegotiateLanguage(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		want           string
+	}{
+		{acceptLanguage: "", want: resp.LangEN},
+		{acceptLanguage: "vi", want: resp.LangVI},
+		{acceptLanguage: "vi-VN,vi;q=0.9,en;q=0.8", want: resp.LangVI},
+		{acceptLanguage: "en-US,en;q=0.9,vi;q=0.8", want: resp.LangEN},
+		{acceptLanguage: "fr-FR,vi;q=0.5", want: resp.LangVI},
+		{acceptLanguage: "en;q=0.2,vi;q=0.7", want: resp.LangVI},
+		{acceptLanguage: "fr, de", want: resp.LangEN},
+		{acceptLanguage: "*", want: resp.LangEN},
+		{acceptLanguage: "not a language;;", want: resp.LangEN},
+	}
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			if got := NegotiateLanguage(tt.acceptLanguage); got != tt.want {
+				t.Errorf("NegotiateLanguage(%q) = %q, want %q", tt.acceptLanguage, got, tt.want)
+			}
+		})
+	}
+}
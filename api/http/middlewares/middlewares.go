@@ -20,10 +20,7 @@ import (
 
 // AddExtraDataForRequestContext middleware to add extra data to the request context
 func AddExtraDataForRequestContext(c *gin.Context) {
-	reqID := c.Request.Header.Get("X-Request-ID")
-	if reqID == "" {
-		reqID = generateNewRequestID()
-	}
+	reqID := RequestID(c.Request.Header.Get("X-Request-ID"))
 
 	// Set request_id to request
 	c.Request.Header.Set("X-Request-ID", reqID)
@@ -31,15 +28,27 @@ func AddExtraDataForRequestContext(c *gin.Context) {
 	// Set request_id to response
 	c.Writer.Header().Set("X-Request-ID", reqID)
 
-	// Set trace_info to context
-	traceInfo := utils.TraceInfo{RequestID: reqID}
-	ctx := c.Request.Context()
-	ctxTraceInfo := context.WithValue(ctx, utils.KeyTraceInfo, traceInfo)
-	c.Request = c.Request.WithContext(ctxTraceInfo)
+	// Set trace_info and the client region to context
+	c.Request = c.Request.WithContext(WithTraceInfo(c.Request.Context(), reqID, c.Request.Header.Get(utils.KeyRegion)))
 
 	c.Next()
 }
 
+// WithTraceInfo stores the trace info of the request reqID in ctx, read by the logger, and the client region, read by
+// the region targeting of feature flags.
+func WithTraceInfo(ctx context.Context, reqID, region string) context.Context {
+	ctx = context.WithValue(ctx, utils.KeyTraceInfo, utils.TraceInfo{RequestID: reqID})
+	return context.WithValue(ctx, utils.KeyRegion, region)
+}
+
+// RequestID returns reqID, or a new request ID when the caller sent none.
+func RequestID(reqID string) string {
+	if reqID == "" {
+		return generateNewRequestID()
+	}
+	return reqID
+}
+
 func generateNewRequestID() string {
 	return uuid.New().String()
 }
@@ -69,7 +78,6 @@ func Logging(c *gin.Context) {
 	// After handler processing
 	res := c.Writer
 	latency := time.Since(start)
-	latencyInMs := float64(latency.Nanoseconds()) / 1000000.0
 	statusCode := res.Status()
 	method := req.Method
 	path := req.URL.Path
@@ -99,28 +107,58 @@ func Logging(c *gin.Context) {
 		}
 	}
 
-	log := logger.GetLogger().AddTraceInfoContextRequest(req.Context())
+	LogRequest(req.Context(), RequestLog{
+		Method:       method,
+		Path:         path,
+		IP:           c.ClientIP(), // Use ClientIP() for Gin
+		UserAgent:    req.UserAgent(),
+		RequestID:    req.Header.Get("X-Request-ID"),
+		StatusCode:   statusCode,
+		Latency:      latency,
+		Params:       c.Request.URL.Query(),
+		RequestBody:  requestBody,
+		ResponseBody: responseBody,
+	})
+}
+
+// RequestLog is the access log entry of a request, StatusCode is the HTTP status or the one of the gRPC code.
+type RequestLog struct {
+	Method       string
+	Path         string
+	IP           string
+	UserAgent    string
+	RequestID    string
+	StatusCode   int
+	Latency      time.Duration
+	Params       interface{}
+	RequestBody  string
+	ResponseBody string
+}
+
+// LogRequest writes the access log entry of a request, at error level for server errors.
+func LogRequest(ctx context.Context, entry RequestLog) {
+	log := logger.GetLogger().AddTraceInfoContextRequest(ctx)
 
 	var newLog logger.Logger
 	newLog = *log
 
 	var eventLog *logger.Event
-	if statusCode >= 500 {
+	if entry.StatusCode >= 500 {
 		eventLog = newLog.Error()
 	} else {
 		eventLog = newLog.Info()
 	}
 
-	eventLog.Str("method", method).
-		Str("path", path).
-		Str("ip", c.ClientIP()). // Use ClientIP() for Gin
-		Str("user_agent", req.UserAgent()).
-		Str("request_id", req.Header.Get("X-Request-ID")).
-		Int("statusCode", statusCode).
-		Float64("latency", latencyInMs).
-		Interface("params", c.Request.URL.Query()).
-		Str("request_body", requestBody).
-		Str("response_body", responseBody).Msg("request income")
+	eventLog.Str("method", entry.Method).
+		Str("path", entry.Path).
+		Str("ip", entry.IP).
+		Str("user_agent", entry.UserAgent).
+		Str("request_id", entry.RequestID).
+		Int("statusCode", entry.StatusCode).
+		Float64("latency", float64(entry.Latency.Nanoseconds())/1000000.0).
+		Interface("params", entry.Params).
+		Str("request_body", entry.RequestBody).
+		Str("response_body", entry.ResponseBody).Msg("request income")
 }
 
 type bodyDumpResponseWriter struct {
@@ -132,7 +170,11 @@ func (w *bodyDumpResponseWriter) WriteHeader(code int) {
 	w.ResponseWriter.WriteHeader(code)
 }
 
+// Write dumps b unless the response is an event stream, which would grow the dump as long as the stream lasts.
 func (w *bodyDumpResponseWriter) Write(b []byte) (int, error) {
+	if strings.HasPrefix(w.Header().Get("Content-Type"), utils.MIMEEventStream) {
+		return w.ResponseWriter.Write(b)
+	}
 	if n, err := w.Writer.Write(b); err != nil {
 		return n, err
 	}
@@ -143,6 +185,11 @@ func (w *bodyDumpResponseWriter) Flush() {
 	w.ResponseWriter.(http.Flusher).Flush()
 }
 
+// Unwrap lets http.ResponseController reach the connection, to move the write deadline of a stream.
+func (w *bodyDumpResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 func (w *bodyDumpResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	return w.ResponseWriter.(http.Hijacker).Hijack()
 }
@@ -0,0 +1,72 @@
+package middlewares
+
+import (
+	"build-service-gin/common/logger"
+	"build-service-gin/pkg/auth"
+	"build-service-gin/pkg/helpers/resp"
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+const auditAccessDenied = "access_denied"
+
+// Access is the request an authorization decision is taken on, written to the audit log of denials.
+type Access struct {
+	Method string
+	Path   string
+	IP     string
+}
+
+// AccessOf returns the request of c an authorization decision is taken on.
+func AccessOf(c *gin.Context) Access {
+	return Access{Method: c.Request.Method, Path: c.FullPath(), IP: c.ClientIP()}
+}
+
+// RequireScope only lets callers granted every scope through, either by the token scopes or by its roles.
+func RequireScope(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := CheckScopes(c.Request.Context(), AccessOf(c), scopes...); err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// CheckScopes returns an error unless the caller bound in ctx is granted every scope.
+func CheckScopes(ctx context.Context, access Access, scopes ...string) *resp.CustomError {
+	principal := auth.PrincipalFromContext(ctx)
+	if principal == nil {
+		return resp.NewError(resp.Unauthorized, resp.ErrAuth, "")
+	}
+
+	if !principal.HasScopes(scopes...) {
+		return DenyAccess(ctx, access, principal, "missing scope", scopes...)
+	}
+	return nil
+}
+
+// DenyAccess writes the denial to the audit log and returns the 403 error of the request.
+func DenyAccess(ctx context.Context, access Access, principal *auth.Principal, reason string, required ...string) *resp.CustomError {
+	log := logger.GetLogger().AddTraceInfoContextRequest(ctx)
+
+	event := log.Warn().
+		Str("audit", auditAccessDenied).
+		Str("reason", reason).
+		Str("method", access.Method).
+		Str("path", access.Path).
+		Str("ip", access.IP).
+		Strs("required", required)
+	if principal != nil {
+		event = event.
+			Str("sub", principal.Subject).
+			Str("type", principal.TokenType).
+			Strs("roles", principal.Claims.Roles).
+			Strs("scopes", principal.Scopes())
+	}
+	event.Msg("authorization denied")
+
+	return resp.NewError(resp.Forbidden, resp.ErrForbidden, reason)
+}
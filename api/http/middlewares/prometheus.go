@@ -22,11 +22,39 @@ var (
 			Buckets: prometheus.DefBuckets,
 		},
 	)
+
+	grpcRequestCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "grpc_requests_total",
+			Help: "Total number of gRPC requests",
+		},
+		[]string{"method", "code"},
+	)
+
+	grpcResponseDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "grpc_response_duration_seconds",
+			Help:    "Duration of gRPC requests in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"method"},
+	)
+
+	dependencyUp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "health_dependency_up",
+			Help: "Whether the last health check of a dependency succeeded",
+		},
+		[]string{"dependency"},
+	)
 )
 
 func InitMetrics() {
 	prometheus.MustRegister(requestCount)
 	prometheus.MustRegister(responseDuration)
+	prometheus.MustRegister(grpcRequestCount)
+	prometheus.MustRegister(grpcResponseDuration)
+	prometheus.MustRegister(dependencyUp)
 }
 
 func TraceNumberRequestAndTimeResponse(c *gin.Context) {
@@ -40,3 +68,18 @@ func TraceNumberRequestAndTimeResponse(c *gin.Context) {
 	duration := time.Since(start).Seconds()
 	responseDuration.Observe(duration)
 }
+
+// ObserveGrpcRequest counts a gRPC call of method answered with code, started at start.
+func ObserveGrpcRequest(method, code string, start time.Time) {
+	grpcRequestCount.WithLabelValues(method, code).Inc()
+	grpcResponseDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+// ObserveDependency records the result of the last health check of dependency.
+func ObserveDependency(dependency string, up bool) {
+	value := 0.0
+	if up {
+		value = 1
+	}
+	dependencyUp.WithLabelValues(dependency).Set(value)
+}
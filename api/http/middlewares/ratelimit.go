@@ -0,0 +1,134 @@
+package middlewares
+
+import (
+	"build-service-gin/common/logger"
+	"build-service-gin/common/utils"
+	"build-service-gin/config"
+	"build-service-gin/pkg/auth"
+	"build-service-gin/pkg/helpers/resp"
+	"build-service-gin/pkg/ratelimit"
+	"fmt"
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	headerRateLimitLimit     = "RateLimit-Limit"
+	headerRateLimitRemaining = "RateLimit-Remaining"
+	headerRateLimitReset     = "RateLimit-Reset"
+	headerRateLimitPolicy    = "RateLimit-Policy"
+	headerRetryAfter         = "Retry-After"
+)
+
+type RateLimitMiddleware struct {
+	limiter ratelimit.Limiter
+	state   atomic.Pointer[rateLimitState]
+}
+
+// rateLimitState is swapped as a whole on Reload so a request never sees half of a change.
+type rateLimitState struct {
+	enabled bool
+	rules   map[string]ratelimit.Rule
+}
+
+// NewRateLimitMiddleware parses the configured rule overrides, a malformed override fails at startup rather than
+// silently leaving a route unlimited.
+func NewRateLimitMiddleware(limiter ratelimit.Limiter, conf config.RateLimitConfig) (*RateLimitMiddleware, error) {
+	m := &RateLimitMiddleware{limiter: limiter}
+	if err := m.Reload(conf); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Reload applies new rule overrides to the routes already set up, on error the current rules are kept.
+func (m *RateLimitMiddleware) Reload(conf config.RateLimitConfig) error {
+	rules := make(map[string]ratelimit.Rule, len(conf.Rules))
+	for name, raw := range conf.Rules {
+		rule, err := ratelimit.ParseRule(raw, ratelimit.KeyByIP)
+		if err != nil {
+			return fmt.Errorf("rate limit %s: %w", name, err)
+		}
+		rules[name] = rule
+	}
+
+	m.state.Store(&rateLimitState{enabled: conf.Enabled, rules: rules})
+	return nil
+}
+
+// Limit applies the limit called name, def is used unless the configuration overrides it. Limits keyed by subject
+// or profile have to run after Authenticate and fall back to the client IP for anonymous callers.
+func (m *RateLimitMiddleware) Limit(name string, def ratelimit.Rule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		state := m.state.Load()
+		if !state.enabled {
+			c.Next()
+			return
+		}
+
+		rule, ok := state.rules[name]
+		if !ok {
+			rule = def
+		}
+
+		ctx := c.Request.Context()
+		key := fmt.Sprintf("%s:%s:%s:%s", utils.KeyRateLimit, name, rule.KeyBy, rateLimitKey(c, rule.KeyBy))
+
+		rs, err := m.limiter.Allow(ctx, key, rule)
+		if err != nil {
+			logger.GetLogger().AddTraceInfoContextRequest(ctx).Error().Err(err).Str("limit", name).Msg("rate limit failed, request let through")
+			c.Next()
+			return
+		}
+
+		reset := strconv.Itoa(int(math.Ceil(rs.Reset.Seconds())))
+		c.Header(headerRateLimitLimit, strconv.Itoa(rs.Limit))
+		c.Header(headerRateLimitRemaining, strconv.Itoa(max(rs.Remaining, 0)))
+		c.Header(headerRateLimitReset, reset)
+		c.Header(headerRateLimitPolicy, rule.Policy())
+
+		if !rs.Allowed {
+			logger.GetLogger().AddTraceInfoContextRequest(ctx).Warn().
+				Str("limit", name).
+				Str("key", key).
+				Dur("reset", rs.Reset.Round(time.Millisecond)).
+				Msg("rate limit exceeded")
+			c.Header(headerRetryAfter, reset)
+			abortWithError(c, &resp.CustomError{
+				ErrorCode: resp.ErrTooManyRequest,
+				Kind:      resp.TooManyRequests,
+				Params:    map[string]interface{}{"retryAfter": reset},
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// rateLimitKey resolves the caller identity the rule counts against.
+func rateLimitKey(c *gin.Context, keyBy string) string {
+	principal := auth.PrincipalFromContext(c.Request.Context())
+
+	switch keyBy {
+	case ratelimit.KeyByProfile:
+		if profileID := c.GetHeader(utils.HeaderXMeProfile); profileID != "" {
+			return profileID
+		}
+		if profileID := c.Query(keyProfileID); profileID != "" {
+			return profileID
+		}
+		if principal != nil {
+			return principal.Subject
+		}
+	case ratelimit.KeyBySubject:
+		if principal != nil {
+			return principal.Subject
+		}
+	}
+	return c.ClientIP()
+}
@@ -0,0 +1,175 @@
+package middlewares
+
+import (
+	"build-service-gin/common/utils"
+	"build-service-gin/config"
+	"build-service-gin/pkg/auth"
+	"build-service-gin/pkg/signature"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testSigningSecret = "partner-secret"
+
+// signedRouter serves a route checking the scope of the caller before its signature, like the point routes.
+func signedRouter(t *testing.T, enabled bool) *gin.Engine {
+	t.Helper()
+	verifier, err := auth.NewVerifier(config.AuthConfig{HS256Secret: testSecret})
+	if err != nil {
+		t.Fatalf("new verifier: %v", err)
+	}
+	signatureMiddleware, err := NewSignatureMiddleware(signature.NewMemoryNonceStore(), config.SignatureConfig{
+		Enabled:  enabled,
+		HMACKeys: map[string]string{"partner": testSigningSecret},
+		MaxSkew:  time.Minute,
+		NonceTTL: 2 * time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("new signature middleware: %v", err)
+	}
+
+	router := gin.New()
+	router.Use(ErrorHandler)
+	router.POST("/signed",
+		NewAuthMiddleware(verifier).Authenticate(utils.IASTypeClient),
+		RequireScope(auth.ScopePointWrite),
+		signatureMiddleware.Verify,
+		func(c *gin.Context) { c.String(http.StatusOK, c.GetString(utils.KeySignature)) },
+	)
+	return router
+}
+
+type signedRequest struct {
+	keyID     string
+	secret    string
+	timestamp time.Time
+	nonce     string
+	body      string
+	sentBody  string
+	scopes    []string
+}
+
+func (r signedRequest) build(t *testing.T) *http.Request {
+	t.Helper()
+	timestamp := strconv.FormatInt(r.timestamp.Unix(), 10)
+	sig, _ := signature.NewHMACKey([]byte(r.secret)).Sign(signature.CanonicalRequest(http.MethodPost, "/signed", []byte(r.body), timestamp, r.nonce))
+
+	sentBody := r.body
+	if r.sentBody != "" {
+		sentBody = r.sentBody
+	}
+	req := httptest.NewRequest(http.MethodPost, "/signed", strings.NewReader(sentBody))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+signToken(t, "partner-service", r.scopes...))
+	req.Header.Set(utils.HeaderXSignatureKeyID, r.keyID)
+	req.Header.Set(utils.HeaderXSignatureTimestamp, timestamp)
+	req.Header.Set(utils.HeaderXSignatureNonce, r.nonce)
+	req.Header.Set(utils.HeaderXSignature, sig)
+	return req
+}
+
+func validSignedRequest(nonce string) signedRequest {
+	return signedRequest{
+		keyID:     "partner",
+		secret:    testSigningSecret,
+		timestamp: time.Now(),
+		nonce:     nonce,
+		body:      `{"orderNumber":"o-1"}`,
+		scopes:    []string{auth.ScopePointWrite},
+	}
+}
+
+func TestSignatureVerify(t *testing.T) {
+	tests := []struct {
+		name       string
+		mutate     func(r *signedRequest)
+		wantStatus int
+	}{
+		{name: "valid signature", mutate: func(r *signedRequest) {}, wantStatus: http.StatusOK},
+		{name: "unknown key", mutate: func(r *signedRequest) { r.keyID = "other" }, wantStatus: http.StatusUnauthorized},
+		{name: "wrong secret", mutate: func(r *signedRequest) { r.secret = "other" }, wantStatus: http.StatusUnauthorized},
+		{name: "tampered body", mutate: func(r *signedRequest) { r.sentBody = `{"orderNumber":"o-2"}` }, wantStatus: http.StatusUnauthorized},
+		{name: "stale timestamp", mutate: func(r *signedRequest) { r.timestamp = time.Now().Add(-2 * time.Minute) }, wantStatus: http.StatusUnauthorized},
+		{name: "missing nonce", mutate: func(r *signedRequest) { r.nonce = "" }, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := validSignedRequest("n-1")
+			tt.mutate(&r)
+
+			rec := httptest.NewRecorder()
+			signedRouter(t, true).ServeHTTP(rec, r.build(t))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK && rec.Body.String() != "partner" {
+				t.Errorf("key id = %q, want partner", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestSignatureVerifyReplay(t *testing.T) {
+	router := signedRouter(t, true)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, validSignedRequest("n-1").build(t))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("first request status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, validSignedRequest("n-1").build(t))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("replayed request status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestSignatureVerifyAfterScope(t *testing.T) {
+	router := signedRouter(t, true)
+
+	// a caller missing the scope is turned away before its nonce is claimed
+	unscoped := validSignedRequest("n-1")
+	unscoped.scopes = nil
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, unscoped.build(t))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("unscoped request status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, validSignedRequest("n-1").build(t))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("scoped request status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+}
+
+func TestSignatureDisabled(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/signed", strings.NewReader("{}"))
+	req.Header.Set("Authorization", "Bearer "+signToken(t, "partner-service", auth.ScopePointWrite))
+
+	rec := httptest.NewRecorder()
+	signedRouter(t, false).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+}
+
+func TestNewSignatureMiddlewareWithoutKeys(t *testing.T) {
+	conf := config.SignatureConfig{Enabled: true, MaxSkew: time.Minute, NonceTTL: 2 * time.Minute}
+	if _, err := NewSignatureMiddleware(signature.NewMemoryNonceStore(), conf); err == nil {
+		t.Error("NewSignatureMiddleware() accepted verification without keys")
+	}
+
+	conf.Enabled = false
+	if _, err := NewSignatureMiddleware(signature.NewMemoryNonceStore(), conf); err != nil {
+		t.Errorf("NewSignatureMiddleware() = %v with verification disabled", err)
+	}
+}
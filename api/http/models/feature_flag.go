@@ -0,0 +1,14 @@
+package models
+
+type FeatureFlagRequest struct {
+	Key         string   `json:"-"`
+	Description string   `json:"description"`
+	Enabled     bool     `json:"enabled"`
+	Percentage  *int     `json:"percentage" validate:"omitempty,min=0,max=100"`
+	Regions     []string `json:"regions"`
+	Profiles    []string `json:"profiles"`
+}
+
+type FeatureFlagKeyRequest struct {
+	Key string `uri:"key" validate:"required"`
+}
@@ -0,0 +1,7 @@
+package models
+
+type GraphQLRequest struct {
+	Query         string                 `json:"query" validate:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
@@ -3,8 +3,18 @@ package models
 type OrderRequest struct {
 	OrderNumber string  `json:"orderNumber"`
 	CreateTime  int64   `json:"createTime"`
-	Amount      float64 `json:"amount"`
-	Currency    string  `json:"currency"`
-	VGAUserID   string  `json:"vgaUserId"`
-	SourceType  string  `json:"sourceType"`
+	Amount      float64 `json:"amount" validate:"money=2"`
+	Currency    string  `json:"currency" validate:"required,currency"`
+	VGAUserID   string  `json:"vgaUserId" validate:"required,profile_id"`
+	SourceType  string  `json:"sourceType" validate:"required,source_type"`
+}
+
+type ReverseTransactionRequest struct {
+	ReversalID           string  `json:"reversalID" validate:"required,max=64"`
+	ProfileID            string  `json:"profileID" validate:"omitempty,profile_id"`
+	TransactionID        string  `json:"transactionID" validate:"required_without=PaymentTransactionID"`
+	PaymentTransactionID string  `json:"paymentTransactionID" validate:"required_without=TransactionID"`
+	Amount               float64 `json:"amount" validate:"omitempty,money=2"`
+	Currency             string  `json:"currency" validate:"omitempty,currency"`
+	Reason               string  `json:"reason" validate:"max=255"`
 }
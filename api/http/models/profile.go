@@ -3,31 +3,42 @@ package models
 import "time"
 
 type GetUserTransactionHistoryReq struct {
-	ProfileID   string `form:"profileID" query:"profileID"`
-	Offset      int64  `form:"offset" query:"offset"`
+	ProfileID   string `form:"profileID" query:"profileID" validate:"omitempty,profile_id"`
+	Offset      int64  `form:"offset" query:"offset" validate:"min=0"`
 	Limit       int64  `form:"limit" query:"limit"`
-	TxType      string `form:"txType" query:"txType"`
-	Status      string `form:"status" query:"status"`
-	RecentMonth int    `form:"recentMonth" query:"recentMonth"`
+	TxType      string `form:"txType" query:"txType" validate:"omitempty,tx_type"`
+	Status      string `form:"status" query:"status" validate:"omitempty,tx_status"`
+	RecentMonth int    `form:"recentMonth" query:"recentMonth" validate:"min=0"`
+}
+
+func (r *GetUserTransactionHistoryReq) PageLimit() *int64 {
+	return &r.Limit
 }
 
 type GetUserTransactionHistoryByProfileReq struct {
-	ProfileID string `form:"profileID" query:"profileID"`
+	ProfileID string `form:"profileID" query:"profileID" validate:"required,profile_id"`
+}
+
+// ProfileStreamReq opens the stream of a profile, resuming after LastEventID when the Last-Event-ID header is not
+// sent, e.g. by a first EventSource connection.
+type ProfileStreamReq struct {
+	ProfileID   string `form:"profileID" query:"profileID" validate:"required,profile_id"`
+	LastEventID string `form:"lastEventID" query:"lastEventID"`
 }
 
 type UserTransactionHistory struct {
 	TransactionID        string     `json:"transactionID" form:"transactionID"`
-	TransactionType      string     `json:"transactionType" form:"transactionType"`
-	ProfileID            string     `json:"profileID" form:"profileID"`
-	Status               string     `json:"status" form:"status"`
-	PointAmount          int64      `json:"pointAmount" form:"pointAmount"`
-	PointType            int64      `json:"pointType" form:"pointType"`
-	TotalAmount          float64    `json:"totalAmount" form:"totalAmount"`
-	Currency             string     `json:"currency" form:"currency"`
+	TransactionType      string     `json:"transactionType" form:"transactionType" validate:"omitempty,tx_type"`
+	ProfileID            string     `json:"profileID" form:"profileID" validate:"required,profile_id"`
+	Status               string     `json:"status" form:"status" validate:"omitempty,tx_status"`
+	PointAmount          int64      `json:"pointAmount" form:"pointAmount" validate:"required_if_fold=Status SUCCESS"`
+	PointType            int64      `json:"pointType" form:"pointType" validate:"min=0"`
+	TotalAmount          float64    `json:"totalAmount" form:"totalAmount" validate:"omitempty,money=2"`
+	Currency             string     `json:"currency" form:"currency" validate:"required_with=TotalAmount,omitempty,currency"`
 	PaymentTransactionID string     `json:"paymentTransactionID" form:"paymentTransactionID"`
 	Source               string     `json:"source" form:"source"`
 	SourceTime           *time.Time `json:"sourceTime" form:"sourceTime"`
-	SourceType           string     `json:"sourceType" form:"sourceType"`
+	SourceType           string     `json:"sourceType" form:"sourceType" validate:"omitempty,source_type"`
 	CreatedAt            *time.Time `json:"createdAt" form:"createdAt"`
 	UpdatedAt            *time.Time `json:"updatedAt" form:"updatedAt"`
 }
@@ -0,0 +1,38 @@
+package models
+
+type WebhookSubscriptionRequest struct {
+	SubscriptionID string   `json:"-"`
+	Name           string   `json:"name" validate:"required"`
+	URL            string   `json:"url" validate:"required,url"`
+	Secret         string   `json:"secret"`
+	EventTypes     []string `json:"eventTypes"`
+	Enabled        *bool    `json:"enabled"`
+}
+
+type WebhookSubscriptionIDRequest struct {
+	SubscriptionID string `uri:"subscriptionID" validate:"required"`
+}
+
+type GetWebhookSubscriptionsReq struct {
+	Offset int64 `form:"offset" query:"offset" validate:"min=0"`
+	Limit  int64 `form:"limit" query:"limit"`
+}
+
+func (r *GetWebhookSubscriptionsReq) PageLimit() *int64 {
+	return &r.Limit
+}
+
+type GetWebhookDeliveriesReq struct {
+	SubscriptionID string `uri:"subscriptionID" validate:"required"`
+	Status         string `form:"status" query:"status"`
+	Offset         int64  `form:"offset" query:"offset" validate:"min=0"`
+	Limit          int64  `form:"limit" query:"limit"`
+}
+
+func (r *GetWebhookDeliveriesReq) PageLimit() *int64 {
+	return &r.Limit
+}
+
+type WebhookDeliveryIDRequest struct {
+	DeliveryID string `uri:"deliveryID" validate:"required"`
+}
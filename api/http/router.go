@@ -1,36 +1,84 @@
 package http
 
 import (
+	"build-service-gin/api/http/handlers"
 	"build-service-gin/api/http/middlewares"
 	"build-service-gin/api/http/routers"
-	"build-service-gin/pkg/helpers/resp"
+	"build-service-gin/common/logger"
+	"build-service-gin/common/redis"
+	"build-service-gin/config"
+	"build-service-gin/pkg/auth"
+	"build-service-gin/pkg/ratelimit"
+	"build-service-gin/pkg/signature"
 	"github.com/gin-gonic/gin"
-	"net/http"
 )
 
-const prefixPath = "build-service-gin"
-const healthPath = prefixPath + "/v1/health"
-
 func (app *httpServ) InitRouters(g *gin.Engine) {
 	g.Use(gin.Logger())
 	g.Use(gin.Recovery())
 
 	g.Use(middlewares.AddExtraDataForRequestContext)
+	g.Use(middlewares.Language)
+	g.Use(middlewares.ResponseFormat)
+	g.Use(middlewares.ErrorHandler)
 	g.Use(middlewares.Logging)
 
-	g.GET(healthPath, func(c *gin.Context) {
-		if healthCheck {
-			c.JSON(http.StatusOK, resp.BuildSuccessResp(resp.LangEN, nil))
-		} else {
-			c.JSON(http.StatusInternalServerError, resp.BuildErrorResp(resp.ErrSystem, "", resp.LangEN))
+	//health router
+	healthController := routers.NewHealthController(g, handlers.NewHealthHandler(app.health))
+	healthController.SetupHealthRoutes()
+
+	verifier, err := auth.NewVerifier(app.conf.AuthConfig)
+	if err != nil {
+		logger.GetLogger().Fatal().Err(err).Msg("init auth verifier failed")
+	}
+	authMiddleware := middlewares.NewAuthMiddleware(verifier)
+
+	rateLimitMiddleware, err := middlewares.NewRateLimitMiddleware(ratelimit.NewLimiter(redis.GetInstance(), redis.RedisConfig(app.conf.RedisConfig)), app.conf.RateLimitConfig)
+	if err != nil {
+		logger.GetLogger().Fatal().Err(err).Msg("init rate limit failed")
+	}
+	config.Subscribe(func(r config.Reloadable) {
+		if err := rateLimitMiddleware.Reload(r.RateLimitConfig); err != nil {
+			logger.GetLogger().Error().Err(err).Msg("reload rate limit failed, keeping the current rules")
 		}
 	})
 
+	signatureMiddleware, err := middlewares.NewSignatureMiddleware(signature.NewNonceStore(redis.GetInstance()), app.conf.SignatureConfig)
+	if err != nil {
+		logger.GetLogger().Fatal().Err(err).Msg("init request signature failed")
+	}
+
 	//profile router
-	controller := routers.NewProfileController(g, app.profileHandler)
+	controller := routers.NewProfileController(g, app.profileHandler, authMiddleware, rateLimitMiddleware)
 	controller.SetupProfileRoutes()
 
+	//stream router
+	streamController := routers.NewStreamController(g, app.streamHandler, authMiddleware, rateLimitMiddleware)
+	streamController.SetupStreamRoutes()
+
 	//point router
-	pointController := routers.NewPointController(g, app.pointHandler)
+	pointController := routers.NewPointController(g, app.pointHandler, authMiddleware, rateLimitMiddleware, signatureMiddleware)
 	pointController.SetupPointRoutes()
+
+	//webhook router
+	webhookController := routers.NewWebhookController(g, app.webhookHandler, authMiddleware)
+	webhookController.SetupWebhookRoutes()
+
+	//feature flag router
+	flagController := routers.NewFeatureFlagController(g, app.flagHandler, authMiddleware)
+	flagController.SetupFeatureFlagRoutes()
+
+	//graphql router
+	if app.conf.GraphQLConfig.Enabled {
+		graphQLController := routers.NewGraphQLController(g, app.graphQLHandler, authMiddleware, rateLimitMiddleware)
+		graphQLController.SetupGraphQLRoutes()
+	}
+
+	//debug router
+	debugController := routers.NewDebugController(g, handlers.NewDebugHandler(app.conf), authMiddleware)
+	debugController.SetupDebugRoutes()
+
+	//openapi router
+	openAPIController := routers.NewOpenAPIController(g, app.conf.OpenAPIConfig)
+	openAPIController.SetupOpenAPIRoutes()
 }
@@ -0,0 +1,32 @@
+package http
+
+import (
+	"build-service-gin/api/http/routers"
+	"build-service-gin/common/logger"
+	"build-service-gin/config"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	logger.InitLog("test")
+	os.Exit(m.Run())
+}
+
+// TestOpenAPIDocumentsEveryRoute fails when a route is registered without its operation, see openapi.Handle.
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	conf := &config.SystemConfig{}
+	conf.AuthConfig.HS256Secret = "router-test-secret"
+	conf.OpenAPIConfig.Enabled = true
+	conf.GraphQLConfig.Enabled = true
+
+	g := gin.New()
+	NewHttpServe(conf, nil, nil, nil, nil, nil, nil, nil).InitRouters(g)
+
+	if err := routers.CheckOpenAPI(g); err != nil {
+		t.Fatal(err)
+	}
+}
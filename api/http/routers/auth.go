@@ -0,0 +1,6 @@
+package routers
+
+import "build-service-gin/common/utils"
+
+// authenticatedTokenTypes are the token types accepted on routes where the scopes decide what a caller may do.
+var authenticatedTokenTypes = []string{utils.IASTypeClient, utils.IASTypeService, utils.IASTypeInternal}
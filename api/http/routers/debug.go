@@ -0,0 +1,38 @@
+package routers
+
+import (
+	"build-service-gin/api/http/handlers"
+	"build-service-gin/api/http/middlewares"
+	"build-service-gin/pkg/auth"
+	"build-service-gin/pkg/openapi"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DebugController struct {
+	router   *gin.Engine
+	adminSys *gin.RouterGroup
+	handlers *handlers.DebugHandler
+	auth     *middlewares.AuthMiddleware
+}
+
+func NewDebugController(router *gin.Engine, handlers *handlers.DebugHandler, auth *middlewares.AuthMiddleware) *DebugController {
+	return &DebugController{
+		router:   router,
+		adminSys: router.Group(prefixAdminPath),
+		handlers: handlers,
+		auth:     auth,
+	}
+}
+
+func (app *DebugController) SetupDebugRoutes() {
+	debug := app.adminSys.Group(prefixDebug,
+		app.auth.Authenticate(authenticatedTokenTypes...),
+		middlewares.RequireScope(auth.ScopeConfigRead),
+	)
+	openapi.Handle(debug, http.MethodGet, prefixDebugConfigPath, openapi.Operation{
+		Summary: "Configuration in effect, secrets redacted", Tags: []string{"debug"}, Scopes: []string{auth.ScopeConfigRead},
+		Response: map[string]interface{}{},
+	}, app.handlers.GetConfig)
+}
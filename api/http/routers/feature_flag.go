@@ -0,0 +1,48 @@
+package routers
+
+import (
+	"build-service-gin/api/http/handlers"
+	"build-service-gin/api/http/middlewares"
+	"build-service-gin/api/http/models"
+	"build-service-gin/pkg/auth"
+	"build-service-gin/pkg/featureflag"
+	"build-service-gin/pkg/openapi"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FeatureFlagController struct {
+	router   *gin.Engine
+	adminSys *gin.RouterGroup
+	handlers *handlers.FeatureFlagHandler
+	auth     *middlewares.AuthMiddleware
+}
+
+func NewFeatureFlagController(router *gin.Engine, handlers *handlers.FeatureFlagHandler, auth *middlewares.AuthMiddleware) *FeatureFlagController {
+	return &FeatureFlagController{
+		router:   router,
+		adminSys: router.Group(prefixAdminPath),
+		handlers: handlers,
+		auth:     auth,
+	}
+}
+
+func (app *FeatureFlagController) SetupFeatureFlagRoutes() {
+	flags := app.adminSys.Group(prefixFeatureFlag,
+		app.auth.Authenticate(authenticatedTokenTypes...),
+		middlewares.RequireScope(auth.ScopeFlagAdmin),
+	)
+	openapi.Handle(flags, http.MethodGet, "", openapi.Operation{
+		Summary: "List the feature flags", Tags: []string{"feature flag"}, Scopes: []string{auth.ScopeFlagAdmin},
+		Response: []featureflag.Flag{},
+	}, app.handlers.GetFlags)
+	openapi.Handle(flags, http.MethodPut, prefixFeatureFlagKeyPath, openapi.Operation{
+		Summary: "Create or update a feature flag", Tags: []string{"feature flag"}, Scopes: []string{auth.ScopeFlagAdmin},
+		Request: models.FeatureFlagRequest{}, Response: featureflag.Flag{},
+	}, app.handlers.PutFlag)
+	openapi.Handle(flags, http.MethodDelete, prefixFeatureFlagKeyPath, openapi.Operation{
+		Summary: "Delete a feature flag", Tags: []string{"feature flag"}, Scopes: []string{auth.ScopeFlagAdmin},
+		Request: models.FeatureFlagKeyRequest{},
+	}, app.handlers.DeleteFlag)
+}
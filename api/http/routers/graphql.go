@@ -0,0 +1,37 @@
+package routers
+
+import (
+	"build-service-gin/api/http/handlers"
+	"build-service-gin/api/http/middlewares"
+	"build-service-gin/pkg/auth"
+	"github.com/gin-gonic/gin"
+)
+
+type GraphQLController struct {
+	router    *gin.Engine
+	service   *gin.RouterGroup
+	handlers  *handlers.GraphQLHandler
+	auth      *middlewares.AuthMiddleware
+	rateLimit *middlewares.RateLimitMiddleware
+}
+
+func NewGraphQLController(router *gin.Engine, handlers *handlers.GraphQLHandler, auth *middlewares.AuthMiddleware, rateLimit *middlewares.RateLimitMiddleware) *GraphQLController {
+	return &GraphQLController{
+		router:    router,
+		service:   router.Group(prefixServicePath),
+		handlers:  handlers,
+		auth:      auth,
+		rateLimit: rateLimit,
+	}
+}
+
+// SetupGraphQLRoutes sets up the GraphQL read API, authenticated like the self-service routes: callers only reach
+// their own profile unless granted history:read_any, which the resolvers check per profile.
+func (app *GraphQLController) SetupGraphQLRoutes() {
+	app.service.POST(prefixGraphQLPath,
+		app.auth.Authenticate(authenticatedTokenTypes...),
+		app.rateLimit.Limit(limitHistoryRead, ruleHistoryRead),
+		middlewares.RequireScope(auth.ScopeHistoryRead),
+		app.handlers.Query,
+	)
+}
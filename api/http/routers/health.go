@@ -0,0 +1,38 @@
+package routers
+
+import (
+	"build-service-gin/api/http/handlers"
+	"build-service-gin/pkg/health"
+	"build-service-gin/pkg/openapi"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+	router   *gin.Engine
+	service  *gin.RouterGroup
+	handlers *handlers.HealthHandler
+}
+
+func NewHealthController(router *gin.Engine, handlers *handlers.HealthHandler) *HealthController {
+	return &HealthController{
+		router:   router,
+		service:  router.Group(prefixServicePath),
+		handlers: handlers,
+	}
+}
+
+// SetupHealthRoutes registers the probes, the health route of the former probes answers like the readiness one.
+func (app *HealthController) SetupHealthRoutes() {
+	healthGroup := app.service.Group(prefixHealthPath)
+	openapi.Handle(healthGroup, http.MethodGet, "", openapi.Operation{
+		Summary: "Readiness of the service, kept for the former probes", Tags: []string{"health"}, Response: health.Report{},
+	}, app.handlers.Ready)
+	openapi.Handle(healthGroup, http.MethodGet, prefixHealthLivePath, openapi.Operation{
+		Summary: "Liveness of the service", Tags: []string{"health"},
+	}, app.handlers.Live)
+	openapi.Handle(healthGroup, http.MethodGet, prefixHealthReadyPath, openapi.Operation{
+		Summary: "Readiness of the service with the state of its dependencies", Tags: []string{"health"}, Response: health.Report{},
+	}, app.handlers.Ready)
+}
@@ -0,0 +1,98 @@
+package routers
+
+import (
+	"build-service-gin/common/custom/validate"
+	"build-service-gin/common/logger"
+	"build-service-gin/config"
+	"build-service-gin/internal/domains"
+	"build-service-gin/pkg/helpers/constants"
+	"build-service-gin/pkg/openapi"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+const swaggerInitializer = "/swagger-initializer.js"
+
+// undocumentedPaths are the routes left out of the document, the document itself and the GraphQL API, described by
+// its introspection.
+var undocumentedPaths = []string{prefixServicePath + prefixOpenAPIPath, prefixServicePath + prefixDocsPath, prefixServicePath + prefixGraphQLPath}
+
+func init() {
+	openapi.RegisterRule("currency", openapi.Pattern("^[A-Z]{3}$"))
+	openapi.RegisterRule("money", func(s *openapi.Schema, param string) {
+		zero := float64(0)
+		s.ExclusiveMinimum = &zero
+		openapi.Describe("At most {param} decimals")(s, param)
+	})
+	openapi.RegisterRule("source_type", openapi.Enum(constants.SourceTypes...))
+	openapi.RegisterRule("tx_type", openapi.Enum(domains.TxTypes()...))
+	openapi.RegisterRule("tx_status", openapi.Enum(domains.TxStatuses()...))
+	openapi.RegisterRule("profile_id", openapi.Pattern(validate.ProfileIDPattern.String()))
+	openapi.RegisterRule("required_if_fold", openapi.Describe("Required when {param}"))
+	openapi.RegisterRule("required_with", openapi.Describe("Required with {param}"))
+}
+
+type OpenAPIController struct {
+	router *gin.Engine
+	conf   config.OpenAPIConfig
+}
+
+func NewOpenAPIController(router *gin.Engine, conf config.OpenAPIConfig) *OpenAPIController {
+	return &OpenAPIController{
+		router: router,
+		conf:   conf,
+	}
+}
+
+// CheckOpenAPI reports the routes of router registered without their operation, see openapi.Handle, the tests run it
+// on the router of the service.
+func CheckOpenAPI(router *gin.Engine) error {
+	return openapi.Check(router.Routes(), undocumentedPaths...)
+}
+
+// SetupOpenAPIRoutes serves the document of the routes of the router with the Swagger UI, set it up once every other
+// route is registered.
+func (app *OpenAPIController) SetupOpenAPIRoutes() {
+	if !app.conf.Enabled {
+		return
+	}
+
+	doc, err := json.Marshal(openapi.Build(openapi.Info{
+		Title:       "build-service-gin",
+		Version:     "1.0.0",
+		Description: "Point and transaction history API. Error messages follow Accept-Language (en, vi).",
+	}, openapi.Operations(app.router.Routes(), undocumentedPaths...)))
+	if err != nil {
+		logger.GetLogger().Fatal().Err(err).Msg("build openapi document failed")
+	}
+
+	service := app.router.Group(prefixServicePath)
+	service.GET(prefixOpenAPIPath, func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", doc)
+	})
+	service.GET(prefixDocsPath+"/*filepath", app.swaggerUI)
+}
+
+// swaggerUI serves the embedded Swagger UI, its initializer is replaced to load the document of this service.
+func (app *OpenAPIController) swaggerUI(c *gin.Context) {
+	file := c.Param("filepath")
+	if file == swaggerInitializer {
+		c.Data(http.StatusOK, "application/javascript; charset=utf-8", []byte(strings.ReplaceAll(`window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: "{url}",
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    plugins: [SwaggerUIBundle.plugins.DownloadUrl],
+    layout: "StandaloneLayout"
+  });
+};
+`, "{url}", prefixServicePath+prefixOpenAPIPath)))
+		return
+	}
+	c.FileFromFS(file, http.FS(swaggerFiles.FS))
+}
@@ -2,20 +2,47 @@ package routers
 
 import (
 	"build-service-gin/api/http/handlers"
+	"build-service-gin/api/http/middlewares"
+	"build-service-gin/api/http/models"
+	"build-service-gin/common/utils"
+	"build-service-gin/internal/domains"
+	"build-service-gin/pkg/auth"
+	"build-service-gin/pkg/openapi"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 )
 
+var signatureHeaders = []openapi.Parameter{
+	{Name: utils.HeaderXSignature, In: "header", Description: "Signature of the request, required when signing is enabled", Schema: &openapi.Schema{Type: "string"}},
+	{Name: utils.HeaderXSignatureKeyID, In: "header", Description: "Key the request is signed with", Schema: &openapi.Schema{Type: "string"}},
+	{Name: utils.HeaderXSignatureTimestamp, In: "header", Description: "Unix time the request was signed at", Schema: &openapi.Schema{Type: "string"}},
+	{Name: utils.HeaderXSignatureNonce, In: "header", Description: "Nonce of the request, used once", Schema: &openapi.Schema{Type: "string"}},
+}
+
 type PointController struct {
 	router    *gin.Engine
 	clientSys *gin.RouterGroup
 	handlers  *handlers.PointHandler
+	auth      *middlewares.AuthMiddleware
+	rateLimit *middlewares.RateLimitMiddleware
+	signature *middlewares.SignatureMiddleware
 }
 
-func NewPointController(router *gin.Engine, handlers *handlers.PointHandler) *PointController {
+func NewPointController(
+	router *gin.Engine,
+	handlers *handlers.PointHandler,
+	auth *middlewares.AuthMiddleware,
+	rateLimit *middlewares.RateLimitMiddleware,
+	signature *middlewares.SignatureMiddleware,
+) *PointController {
 	return &PointController{
 		router:    router,
 		clientSys: router.Group(prefixSystemPath),
 		handlers:  handlers,
+		auth:      auth,
+		rateLimit: rateLimit,
+		signature: signature,
 	}
 }
 
@@ -24,6 +51,22 @@ func (app *PointController) SetupPointRoutes() {
 }
 
 func (app *PointController) SetupRouterPoint() {
-	profile := app.clientSys.Group(prefixPoint)
-	profile.POST(prefixPointTransactionPath, app.handlers.CreatePointTransaction)
+	profile := app.clientSys.Group(prefixPoint, app.auth.Authenticate(utils.IASTypeService, utils.IASTypeInternal))
+	openapi.Handle(profile, http.MethodPost, prefixPointTransactionPath, openapi.Operation{
+		Summary: "Create a point transaction", Tags: []string{"point"}, Scopes: []string{auth.ScopePointWrite},
+		Request: models.OrderRequest{}, Headers: signatureHeaders,
+	},
+		app.rateLimit.Limit(limitPointCreate, rulePointCreate),
+		middlewares.RequireScope(auth.ScopePointWrite),
+		app.signature.Verify,
+		app.handlers.CreatePointTransaction,
+	)
+	openapi.Handle(profile, http.MethodPost, prefixPointReversePath, openapi.Operation{
+		Summary: "Reverse a transaction", Tags: []string{"point"}, Scopes: []string{auth.ScopePointReverse},
+		Request: models.ReverseTransactionRequest{}, Response: domains.UserTransactionHistory{},
+	},
+		app.rateLimit.Limit(limitPointReverse, rulePointReverse),
+		middlewares.RequireScope(auth.ScopePointReverse),
+		app.handlers.ReverseTransaction,
+	)
 }
@@ -1,17 +1,54 @@
 package routers
 
 const (
-	prefixSystemPath = "/build-service-gin/api-main"
-	prefixHealthPath = "/v1/health"
+	prefixServicePath = "/build-service-gin"
+	prefixSystemPath  = "/build-service-gin/api-main"
+	prefixAdminPath   = "/build-service-gin/api-admin"
+	prefixHealthPath  = "/v1/health"
+)
+
+const (
+	prefixHealthLivePath  = "/live"
+	prefixHealthReadyPath = "/ready"
 )
 
 const (
 	prefixProfile                            = "/v1/profile"
 	prefixUserTransactionHistoryPath         = "/user-transaction-history"
 	prefixUserTransactionHistoryPostgresPath = "/user-transaction-history-postgresql"
+	prefixProfileStreamPath                  = "/stream"
 )
 
 const (
 	prefixPoint                = "/v1/point"
 	prefixPointTransactionPath = "/create-point-transaction"
+	prefixPointReversePath     = "/reverse"
+)
+
+const (
+	prefixWebhookSubscription       = "/v1/webhook-subscriptions"
+	prefixWebhookSubscriptionIDPath = "/:subscriptionID"
+	prefixWebhookDeliveriesPath     = "/deliveries"
+	prefixWebhookDelivery           = "/v1/webhook-deliveries"
+	prefixWebhookDeliveryIDPath     = "/:deliveryID"
+	prefixWebhookRedeliverPath      = "/redeliver"
+)
+
+const (
+	prefixFeatureFlag        = "/v1/feature-flags"
+	prefixFeatureFlagKeyPath = "/:key"
+)
+
+const (
+	prefixDebug           = "/debug"
+	prefixDebugConfigPath = "/config"
+)
+
+const (
+	prefixGraphQLPath = "/graphql"
+)
+
+const (
+	prefixOpenAPIPath = "/openapi.json"
+	prefixDocsPath    = "/docs"
 )
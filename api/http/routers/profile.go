@@ -2,35 +2,89 @@ package routers
 
 import (
 	"build-service-gin/api/http/handlers"
+	"build-service-gin/api/http/middlewares"
+	"build-service-gin/api/http/models"
+	"build-service-gin/internal/domains"
+	"build-service-gin/pkg/auth"
+	"build-service-gin/pkg/openapi"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 )
 
 type ProfileController struct {
 	router    *gin.Engine
 	clientSys *gin.RouterGroup
+	adminSys  *gin.RouterGroup
 	handlers  *handlers.ProfileHandler
+	auth      *middlewares.AuthMiddleware
+	rateLimit *middlewares.RateLimitMiddleware
 }
 
-func NewProfileController(router *gin.Engine, handlers *handlers.ProfileHandler) *ProfileController {
+func NewProfileController(router *gin.Engine, handlers *handlers.ProfileHandler, auth *middlewares.AuthMiddleware, rateLimit *middlewares.RateLimitMiddleware) *ProfileController {
 	return &ProfileController{
 		router:    router,
 		clientSys: router.Group(prefixSystemPath),
+		adminSys:  router.Group(prefixAdminPath),
 		handlers:  handlers,
+		auth:      auth,
+		rateLimit: rateLimit,
 	}
 }
 
 func (app *ProfileController) SetupProfileRoutes() {
 	// Set up router for profile
 	app.SetupRouterProfile()
+	app.SetupRouterAdminProfile()
 }
 
+// SetupRouterProfile sets up the self-service routes, callers only reach their own profile unless granted
+// history:read_any.
 func (app *ProfileController) SetupRouterProfile() {
-	profile := app.clientSys.Group(prefixProfile)
-	profile.GET(prefixUserTransactionHistoryPath, app.handlers.GetUserTransactionHistory)
-	profile.POST(prefixUserTransactionHistoryPath, app.handlers.CreateUserTransactionHistory)
-	profile.PUT(prefixUserTransactionHistoryPath, app.handlers.UpdateUserTransactionHistory)
-	profile.DELETE(prefixUserTransactionHistoryPath, app.handlers.DeleteUserTransactionHistory)
-
-	profile.GET(prefixUserTransactionHistoryPostgresPath, app.handlers.GetUserTransactionHistoryPostgres)
-	profile.POST(prefixUserTransactionHistoryPostgresPath, app.handlers.CreateUserTransactionHistoryPostgres)
+	profile := app.clientSys.Group(prefixProfile,
+		app.auth.Authenticate(authenticatedTokenTypes...),
+		middlewares.ScopeProfile,
+	)
+	read := app.rateLimit.Limit(limitHistoryRead, ruleHistoryRead)
+	write := app.rateLimit.Limit(limitHistoryWrite, ruleHistoryWrite)
+
+	openapi.Handle(profile, http.MethodGet, prefixUserTransactionHistoryPath, openapi.Operation{
+		Summary: "Transaction history of the caller", Tags: []string{"profile"}, Scopes: []string{auth.ScopeHistoryRead},
+		Request: models.GetUserTransactionHistoryReq{}, Response: []domains.UserTransactionHistory{},
+	}, read, middlewares.RequireScope(auth.ScopeHistoryRead), app.handlers.GetUserTransactionHistory)
+	openapi.Handle(profile, http.MethodPost, prefixUserTransactionHistoryPath, openapi.Operation{
+		Summary: "Record a transaction of the caller", Tags: []string{"profile"}, Scopes: []string{auth.ScopeHistoryWrite},
+		Request: models.UserTransactionHistory{}, Response: domains.UserTransactionHistory{},
+	}, write, middlewares.RequireScope(auth.ScopeHistoryWrite), app.handlers.CreateUserTransactionHistory)
+
+	openapi.Handle(profile, http.MethodGet, prefixUserTransactionHistoryPostgresPath, openapi.Operation{
+		Summary: "Transaction history of the caller from postgres", Tags: []string{"profile"}, Scopes: []string{auth.ScopeHistoryRead},
+		Request: models.GetUserTransactionHistoryReq{}, Response: []domains.UserTransactionHistory{},
+	}, read, middlewares.RequireScope(auth.ScopeHistoryRead), app.handlers.GetUserTransactionHistoryPostgres)
+	openapi.Handle(profile, http.MethodPost, prefixUserTransactionHistoryPostgresPath, openapi.Operation{
+		Summary: "Record a transaction of the caller in postgres", Tags: []string{"profile"}, Scopes: []string{auth.ScopeHistoryWrite},
+		Request: models.UserTransactionHistory{}, Response: domains.UserTransactionHistory{},
+	}, write, middlewares.RequireScope(auth.ScopeHistoryWrite), app.handlers.CreateUserTransactionHistoryPostgres)
+}
+
+// SetupRouterAdminProfile sets up the routes reading or changing the history of any profile.
+func (app *ProfileController) SetupRouterAdminProfile() {
+	profile := app.adminSys.Group(prefixProfile, app.auth.Authenticate(authenticatedTokenTypes...))
+	openapi.Handle(profile, http.MethodGet, prefixUserTransactionHistoryPath, openapi.Operation{
+		Summary: "Transaction history of any profile", Tags: []string{"profile"}, Scopes: []string{auth.ScopeHistoryReadAny},
+		Request: models.GetUserTransactionHistoryReq{}, Response: []domains.UserTransactionHistory{},
+	}, middlewares.RequireScope(auth.ScopeHistoryReadAny), app.handlers.GetUserTransactionHistory)
+	openapi.Handle(profile, http.MethodPut, prefixUserTransactionHistoryPath, openapi.Operation{
+		Summary: "Update a transaction", Tags: []string{"profile"}, Scopes: []string{auth.ScopeHistoryAdmin},
+		Request: models.UserTransactionHistory{}, Response: domains.UserTransactionHistory{},
+	}, middlewares.RequireScope(auth.ScopeHistoryAdmin), app.handlers.UpdateUserTransactionHistory)
+	openapi.Handle(profile, http.MethodDelete, prefixUserTransactionHistoryPath, openapi.Operation{
+		Summary: "Delete the history of a profile", Tags: []string{"profile"}, Scopes: []string{auth.ScopeHistoryAdmin},
+		Request: models.GetUserTransactionHistoryByProfileReq{},
+	}, middlewares.RequireScope(auth.ScopeHistoryAdmin), app.handlers.DeleteUserTransactionHistory)
+
+	openapi.Handle(profile, http.MethodGet, prefixUserTransactionHistoryPostgresPath, openapi.Operation{
+		Summary: "Transaction history of any profile from postgres", Tags: []string{"profile"}, Scopes: []string{auth.ScopeHistoryReadAny},
+		Request: models.GetUserTransactionHistoryReq{}, Response: []domains.UserTransactionHistory{},
+	}, middlewares.RequireScope(auth.ScopeHistoryReadAny), app.handlers.GetUserTransactionHistoryPostgres)
 }
@@ -0,0 +1,22 @@
+package routers
+
+import (
+	"build-service-gin/pkg/ratelimit"
+	"time"
+)
+
+// Names of the route limits, RATE_LIMIT_RULES overrides a limit by its name.
+const (
+	limitHistoryRead  = "history.read"
+	limitHistoryWrite = "history.write"
+	limitPointCreate  = "point.create"
+	limitPointReverse = "point.reverse"
+)
+
+// Default limits used when the configuration does not override them.
+var (
+	ruleHistoryRead  = ratelimit.Rule{Limit: 120, Window: time.Minute, KeyBy: ratelimit.KeyByProfile}
+	ruleHistoryWrite = ratelimit.Rule{Limit: 30, Window: time.Minute, KeyBy: ratelimit.KeyByProfile}
+	rulePointCreate  = ratelimit.Rule{Limit: 10, Window: time.Minute, KeyBy: ratelimit.KeyBySubject}
+	rulePointReverse = ratelimit.Rule{Limit: 10, Window: time.Minute, KeyBy: ratelimit.KeyBySubject}
+)
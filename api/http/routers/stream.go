@@ -0,0 +1,52 @@
+package routers
+
+import (
+	"build-service-gin/api/http/handlers"
+	"build-service-gin/api/http/middlewares"
+	"build-service-gin/api/http/models"
+	"build-service-gin/common/utils"
+	"build-service-gin/pkg/auth"
+	"build-service-gin/pkg/openapi"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var streamHeaders = []openapi.Parameter{
+	{Name: utils.HeaderLastEventID, In: "header", Description: "Id of the last event received, the stream resumes after it", Schema: &openapi.Schema{Type: "string"}},
+}
+
+type StreamController struct {
+	router    *gin.Engine
+	clientSys *gin.RouterGroup
+	handlers  *handlers.StreamHandler
+	auth      *middlewares.AuthMiddleware
+	rateLimit *middlewares.RateLimitMiddleware
+}
+
+func NewStreamController(router *gin.Engine, handlers *handlers.StreamHandler, auth *middlewares.AuthMiddleware, rateLimit *middlewares.RateLimitMiddleware) *StreamController {
+	return &StreamController{
+		router:    router,
+		clientSys: router.Group(prefixSystemPath),
+		handlers:  handlers,
+		auth:      auth,
+		rateLimit: rateLimit,
+	}
+}
+
+// SetupStreamRoutes sets up the stream of the point updates of a profile, callers only reach their own profile
+// unless granted history:read_any.
+func (app *StreamController) SetupStreamRoutes() {
+	profile := app.clientSys.Group(prefixProfile,
+		app.auth.Authenticate(authenticatedTokenTypes...),
+		middlewares.ScopeProfile,
+	)
+	openapi.Handle(profile, http.MethodGet, prefixProfileStreamPath, openapi.Operation{
+		Summary: "Transaction and balance events of the caller, as server-sent events", Tags: []string{"profile"},
+		Scopes: []string{auth.ScopeHistoryRead}, Request: models.ProfileStreamReq{}, Headers: streamHeaders, Stream: utils.MIMEEventStream,
+	},
+		app.rateLimit.Limit(limitHistoryRead, ruleHistoryRead),
+		middlewares.RequireScope(auth.ScopeHistoryRead),
+		app.handlers.StreamProfile,
+	)
+}
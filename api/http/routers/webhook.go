@@ -0,0 +1,77 @@
+package routers
+
+import (
+	"build-service-gin/api/http/handlers"
+	"build-service-gin/api/http/middlewares"
+	"build-service-gin/api/http/models"
+	"build-service-gin/internal/domains"
+	"build-service-gin/pkg/auth"
+	"build-service-gin/pkg/openapi"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookController struct {
+	router   *gin.Engine
+	adminSys *gin.RouterGroup
+	handlers *handlers.WebhookHandler
+	auth     *middlewares.AuthMiddleware
+}
+
+func NewWebhookController(router *gin.Engine, handlers *handlers.WebhookHandler, auth *middlewares.AuthMiddleware) *WebhookController {
+	return &WebhookController{
+		router:   router,
+		adminSys: router.Group(prefixAdminPath),
+		handlers: handlers,
+		auth:     auth,
+	}
+}
+
+func (app *WebhookController) SetupWebhookRoutes() {
+	app.SetupRouterWebhook()
+}
+
+func (app *WebhookController) SetupRouterWebhook() {
+	subscription := app.adminSys.Group(prefixWebhookSubscription,
+		app.auth.Authenticate(authenticatedTokenTypes...),
+		middlewares.RequireScope(auth.ScopeWebhookAdmin),
+	)
+	openapi.Handle(subscription, http.MethodPost, "", openapi.Operation{
+		Summary: "Create a webhook subscription", Tags: []string{"webhook"}, Scopes: []string{auth.ScopeWebhookAdmin},
+		Request: models.WebhookSubscriptionRequest{}, Response: domains.WebhookSubscription{},
+	}, app.handlers.CreateSubscription)
+	openapi.Handle(subscription, http.MethodGet, "", openapi.Operation{
+		Summary: "List the webhook subscriptions", Tags: []string{"webhook"}, Scopes: []string{auth.ScopeWebhookAdmin},
+		Request: models.GetWebhookSubscriptionsReq{}, Response: []domains.WebhookSubscription{},
+	}, app.handlers.GetSubscriptions)
+	openapi.Handle(subscription, http.MethodGet, prefixWebhookSubscriptionIDPath, openapi.Operation{
+		Summary: "Get a webhook subscription", Tags: []string{"webhook"}, Scopes: []string{auth.ScopeWebhookAdmin},
+		Request: models.WebhookSubscriptionIDRequest{}, Response: domains.WebhookSubscription{},
+	}, app.handlers.GetSubscription)
+	openapi.Handle(subscription, http.MethodPut, prefixWebhookSubscriptionIDPath, openapi.Operation{
+		Summary: "Update a webhook subscription", Tags: []string{"webhook"}, Scopes: []string{auth.ScopeWebhookAdmin},
+		Request: models.WebhookSubscriptionRequest{}, Response: domains.WebhookSubscription{},
+	}, app.handlers.UpdateSubscription)
+	openapi.Handle(subscription, http.MethodDelete, prefixWebhookSubscriptionIDPath, openapi.Operation{
+		Summary: "Delete a webhook subscription", Tags: []string{"webhook"}, Scopes: []string{auth.ScopeWebhookAdmin},
+		Request: models.WebhookSubscriptionIDRequest{},
+	}, app.handlers.DeleteSubscription)
+	openapi.Handle(subscription, http.MethodGet, prefixWebhookSubscriptionIDPath+prefixWebhookDeliveriesPath, openapi.Operation{
+		Summary: "List the deliveries of a subscription", Tags: []string{"webhook"}, Scopes: []string{auth.ScopeWebhookAdmin},
+		Request: models.GetWebhookDeliveriesReq{}, Response: []domains.WebhookDelivery{},
+	}, app.handlers.GetDeliveries)
+
+	delivery := app.adminSys.Group(prefixWebhookDelivery,
+		app.auth.Authenticate(authenticatedTokenTypes...),
+		middlewares.RequireScope(auth.ScopeWebhookAdmin),
+	)
+	openapi.Handle(delivery, http.MethodGet, prefixWebhookDeliveryIDPath, openapi.Operation{
+		Summary: "Get a webhook delivery", Tags: []string{"webhook"}, Scopes: []string{auth.ScopeWebhookAdmin},
+		Request: models.WebhookDeliveryIDRequest{}, Response: domains.WebhookDelivery{},
+	}, app.handlers.GetDelivery)
+	openapi.Handle(delivery, http.MethodPost, prefixWebhookDeliveryIDPath+prefixWebhookRedeliverPath, openapi.Operation{
+		Summary: "Send a delivery again", Tags: []string{"webhook"}, Scopes: []string{auth.ScopeWebhookAdmin},
+		Request: models.WebhookDeliveryIDRequest{}, Response: domains.WebhookDelivery{},
+	}, app.handlers.Redeliver)
+}
@@ -4,26 +4,22 @@ import (
 	"build-service-gin/api/http/handlers"
 	"build-service-gin/common/logger"
 	"build-service-gin/config"
+	"build-service-gin/pkg/health"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"net/http"
-	"sync"
+	"os"
+	"time"
 )
 
-var (
-	healthCheck bool
-	mu          sync.RWMutex
-)
-
-func SetHealthCheck(status bool) {
-	mu.Lock()
-	defer mu.Unlock()
-	healthCheck = status
-}
-
 type HttpServInterface interface {
 	Start(g *gin.Engine)
+	Drain()
 	Shutdown(ctx context.Context) error
 }
 
@@ -31,7 +27,13 @@ type httpServ struct {
 	conf           *config.SystemConfig
 	profileHandler *handlers.ProfileHandler
 	pointHandler   *handlers.PointHandler
+	webhookHandler *handlers.WebhookHandler
+	flagHandler    *handlers.FeatureFlagHandler
+	graphQLHandler *handlers.GraphQLHandler
+	streamHandler  *handlers.StreamHandler
+	health         *health.Registry
 	httpServer     *http.Server
+	adminServer    *http.Server
 	//coreHandler    *order.OrderHandler
 	//earnHandler    *core_handle_point.CorePointHandler
 }
@@ -40,6 +42,11 @@ func NewHttpServe(
 	conf *config.SystemConfig,
 	profileHandler *handlers.ProfileHandler,
 	pointHandler *handlers.PointHandler,
+	webhookHandler *handlers.WebhookHandler,
+	flagHandler *handlers.FeatureFlagHandler,
+	graphQLHandler *handlers.GraphQLHandler,
+	streamHandler *handlers.StreamHandler,
+	health *health.Registry,
 	// coreHandler *order.OrderHandler,
 	// earnHandler *core_handle_point.CorePointHandler,
 ) *httpServ {
@@ -47,33 +54,115 @@ func NewHttpServe(
 		conf:           conf,
 		profileHandler: profileHandler,
 		pointHandler:   pointHandler,
+		webhookHandler: webhookHandler,
+		flagHandler:    flagHandler,
+		graphQLHandler: graphQLHandler,
+		streamHandler:  streamHandler,
+		health:         health,
 		httpServer: &http.Server{ // Initialize the HTTP server
-			Addr: fmt.Sprintf(":%d", conf.HttpPort),
+			Addr:              fmt.Sprintf(":%d", conf.HttpPort),
+			ReadTimeout:       conf.HttpServerConfig.ReadTimeout,
+			ReadHeaderTimeout: conf.HttpServerConfig.ReadHeaderTimeout,
+			WriteTimeout:      conf.HttpServerConfig.WriteTimeout,
+			IdleTimeout:       conf.HttpServerConfig.IdleTimeout,
+		},
+		adminServer: &http.Server{ // Initialize the admin server, scraped for the metrics
+			Addr:              fmt.Sprintf(":%d", conf.HttpServerConfig.AdminPort),
+			Handler:           adminHandler(),
+			ReadHeaderTimeout: conf.HttpServerConfig.ReadHeaderTimeout,
 		},
 		//coreHandler:    coreHandler,
 		//earnHandler:    earnHandler,
 	}
 }
 
+// Start serves g on the HTTP port, over TLS when a certificate is configured, and the metrics on the admin port.
 func (app *httpServ) Start(g *gin.Engine) {
 	log := logger.GetLogger()
 	app.InitRouters(g)
-	httpPort := app.conf.HttpPort
+	app.httpServer.Handler = g
+
+	server := app.conf.HttpServerConfig
+	tlsConfig, err := newTLSConfig(server)
+	if err != nil {
+		log.Fatal().Msgf("can't load TLS config: %v", err)
+	}
+	app.httpServer.TLSConfig = tlsConfig
+	// Shutdown waits for the running requests, streams only end when told to
+	app.httpServer.RegisterOnShutdown(app.streamHandler.Close)
+
 	go func() {
-		err := g.Run(fmt.Sprintf(":%d", httpPort))
-		if err != nil && err != http.ErrServerClosed {
+		var err error
+		if tlsConfig != nil {
+			err = app.httpServer.ListenAndServeTLS(server.TLSCertFile, server.TLSKeyFile)
+		} else {
+			err = app.httpServer.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatal().Msgf("can't start gin: %v", err)
 		}
 	}()
-	log.Info().Msg("HTTP server started on port: " + fmt.Sprintf("%d", httpPort))
+	go func() {
+		if err := app.adminServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal().Msgf("can't start admin server: %v", err)
+		}
+	}()
+	log.Info().Bool("tls", tlsConfig != nil).Msg("HTTP server started on port: " + fmt.Sprintf("%d", app.conf.HttpPort))
+	log.Info().Msg("Admin server started on port: " + fmt.Sprintf("%d", server.AdminPort))
 }
 
+// Drain fails the readiness probe so that the load balancer stops sending requests, and waits PreStopDelay for it to
+// notice before the server is shut down.
+func (app *httpServ) Drain() {
+	app.health.SetDraining()
+	logger.GetLogger().Info().Msgf("Draining for %s", app.conf.HttpServerConfig.PreStopDelay)
+	time.Sleep(app.conf.HttpServerConfig.PreStopDelay)
+}
+
+// Shutdown ends the streams, stops accepting requests and waits for the running ones, then stops the admin server,
+// even when the requests outlived ctx.
 func (app *httpServ) Shutdown(ctx context.Context) error {
 	log := logger.GetLogger()
-	if err := app.httpServer.Shutdown(ctx); err != nil {
-		log.Error().Msgf("Server shutdown failed: %v", err)
+	errServer := app.httpServer.Shutdown(ctx)
+	if errServer != nil {
+		log.Error().Msgf("Server shutdown failed: %v", errServer)
+	}
+	errAdmin := app.adminServer.Shutdown(ctx)
+	if errAdmin != nil {
+		log.Error().Msgf("Admin server shutdown failed: %v", errAdmin)
+	}
+	if err := errors.Join(errServer, errAdmin); err != nil {
 		return err
 	}
 	log.Info().Msg("Server shutdown gracefully")
 	return nil
 }
+
+func adminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", promhttp.Handler())
+	return mux
+}
+
+// newTLSConfig returns the TLS config of the server, nil without a certificate. With a client CA the clients must
+// present a certificate it signed.
+func newTLSConfig(conf config.HttpServerConfig) (*tls.Config, error) {
+	if conf.TLSCertFile == "" {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if conf.TLSClientCAFile != "" {
+		pem, err := os.ReadFile(conf.TLSClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate in %s", conf.TLSClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
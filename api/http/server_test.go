@@ -0,0 +1,141 @@
+package http
+
+import (
+	"bufio"
+	"build-service-gin/api/http/handlers"
+	"build-service-gin/common/utils"
+	"build-service-gin/config"
+	"build-service-gin/internal/services"
+	"build-service-gin/pkg/auth"
+	"build-service-gin/pkg/stream"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testSecret = "server-test-secret"
+
+func freePort(t *testing.T) uint64 {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer l.Close()
+	return uint64(l.Addr().(*net.TCPAddr).Port)
+}
+
+func signToken(t *testing.T, subject string, scopes ...string) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Type:  utils.IASTypeClient,
+		Scope: strings.Join(scopes, " "),
+	})
+	signed, err := token.SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+// TestShutdownEndsStreams checks a running stream neither holds the shutdown until its timeout nor keeps the admin
+// server up.
+func TestShutdownEndsStreams(t *testing.T) {
+	conf := &config.SystemConfig{}
+	conf.HttpPort = freePort(t)
+	conf.HttpServerConfig.AdminPort = freePort(t)
+	conf.AuthConfig.HS256Secret = testSecret
+	conf.StreamConfig = config.StreamConfig{HeartbeatInterval: time.Minute, RetryInterval: time.Second, MaxConnections: 10, MaxProfileConnections: 10}
+
+	hub := stream.NewHub(nil, conf.StreamConfig)
+	streamHandler := handlers.NewStreamHandler(services.NewStreamService(conf, hub), conf.StreamConfig)
+	srv := NewHttpServe(conf, nil, nil, nil, nil, nil, streamHandler, nil)
+	srv.Start(gin.New())
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/build-service-gin/api-main/v1/profile/stream", conf.HttpPort)
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("Authorization", "Bearer "+signToken(t, "alice", auth.ScopeHistoryRead))
+
+	var res *http.Response
+	var err error
+	for deadline := time.Now().Add(2 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if res, err = http.DefaultClient.Do(req); err == nil || time.Now().After(deadline) {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", res.StatusCode, http.StatusOK)
+	}
+	body := bufio.NewReader(res.Body)
+	if line, err := body.ReadString('\n'); err != nil || !strings.HasPrefix(line, "retry:") {
+		t.Fatalf("first line = %q, %v, want the retry field", line, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Shutdown() took %s, the stream held it", elapsed)
+	}
+	if _, err := io.ReadAll(body); err != nil {
+		t.Errorf("read the end of the stream: %v", err)
+	}
+	if conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", conf.HttpServerConfig.AdminPort)); err == nil {
+		conn.Close()
+		t.Error("admin server still accepts connections")
+	}
+}
+
+// TestShutdownStopsAdminServerAfterTimeout checks the admin server is stopped even when the requests outlive the
+// shutdown timeout.
+func TestShutdownStopsAdminServerAfterTimeout(t *testing.T) {
+	conf := &config.SystemConfig{}
+	conf.HttpPort = freePort(t)
+	conf.HttpServerConfig.AdminPort = freePort(t)
+	srv := NewHttpServe(conf, nil, nil, nil, nil, nil, nil, nil)
+
+	release := make(chan struct{})
+	defer close(release)
+	srv.httpServer.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { <-release })
+	go srv.httpServer.ListenAndServe()
+	go srv.adminServer.ListenAndServe()
+
+	addr := fmt.Sprintf("127.0.0.1:%d", conf.HttpPort)
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			break
+		}
+	}
+	go http.Get("http://" + addr)
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err == nil {
+		t.Fatal("Shutdown() succeeded while a request was running")
+	}
+	if conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", conf.HttpServerConfig.AdminPort)); err == nil {
+		conn.Close()
+		t.Error("admin server still accepts connections")
+	}
+}
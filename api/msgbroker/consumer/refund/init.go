@@ -0,0 +1,50 @@
+package refund
+
+import (
+	"build-service-gin/api/msgbroker/models"
+	"build-service-gin/common/logger"
+	queuekafka "build-service-gin/pkg/queue/kafka"
+	"context"
+)
+
+type ConsumerRefund struct {
+	cs            *queuekafka.Consumer
+	refundHandler *RefundHandler
+}
+
+func NewConsumerRefund(
+	cs *queuekafka.Consumer,
+	refundHandler *RefundHandler,
+) *ConsumerRefund {
+	return &ConsumerRefund{
+		cs:            cs,
+		refundHandler: refundHandler,
+	}
+}
+
+func (s *ConsumerRefund) Start(ctx context.Context) error {
+	s.cs.OnEvent(func(ctx context.Context, key, value []byte) error {
+		log := logger.GetLogger().AddTraceInfoContextRequest(ctx)
+		var refundData models.RefundEvent
+		ev, err := models.GetEventRegistry().DecodeInto(value, models.EventTypeRefund, &refundData)
+		if err != nil {
+			// a malformed message will never decode, commit it instead of blocking the partition
+			log.Err(err).Str("key", string(key)).Str("value", string(value)).Msg("decode failed")
+			return nil
+		}
+		log.Info().Any("refund callback received", ev).Msg("event data")
+
+		if err = s.refundHandler.RefundHandle(ctx, refundData); err != nil {
+			log.Err(err).Msg("handling refund event failed")
+			return err
+		}
+
+		return nil
+	})
+
+	if err := s.cs.Start(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package refund
+
+import (
+	"build-service-gin/api/msgbroker/models"
+	"build-service-gin/common/logger"
+	"build-service-gin/internal/services"
+	"build-service-gin/pkg/helpers/adapters"
+	"build-service-gin/pkg/helpers/resp"
+	"context"
+	"errors"
+)
+
+type RefundHandler struct {
+	pointService services.IPointService
+}
+
+func NewRefundHandler(pointService services.IPointService) *RefundHandler {
+	return &RefundHandler{
+		pointService: pointService,
+	}
+}
+
+func (h *RefundHandler) RefundHandle(ctx context.Context, data models.RefundEvent) error {
+	log := logger.GetLogger().AddTraceInfoContextRequest(ctx)
+	dataServ := adapters.AdapterLPPoint{}.ConvertEventRefundToDomain(&data)
+	if _, err := h.pointService.ReverseTransactionPoint(ctx, dataServ); err != nil {
+		// a redelivered refund has already been applied, commit it instead of retrying forever
+		if err.ErrorCode == resp.ErrHandleTxAlreadyReversed {
+			log.Warn().Str("refund_id", data.RefundID).Msg("Refund event already processed")
+			return nil
+		}
+		log.Err(err).Msg("Failed to reverse transaction from refund event")
+		return errors.New(err.Error())
+	}
+	log.Info().Msg("Refund event processed successfully")
+	return nil
+}
@@ -54,3 +54,12 @@ type OrderEventData struct {
 type RawData struct {
 	PaymentTransactionID string `json:"paymentTransID"`
 }
+
+type RefundEvent struct {
+	RefundID             string  `json:"refundID"`
+	TransactionID        string  `json:"transactionID"`
+	PaymentTransactionID string  `json:"paymentTransactionID"`
+	Amount               float64 `json:"amount"`
+	Currency             string  `json:"currency"`
+	Reason               string  `json:"reason"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"build-service-gin/pkg/queue/envelope"
+	"sync"
+)
+
+const (
+	EventTypeOrderSuccess     = "order.success"
+	EventTypeEarnPointSuccess = "point.earn_success"
+	EventTypeRefund           = "order.refund"
+
+	EventTypeTransactionCreated   = "transaction.created"
+	EventTypeTransactionCompleted = "transaction.completed"
+	EventTypeTransactionUpdated   = "transaction.updated"
+	EventTypeTransactionDeleted   = "transaction.deleted"
+	EventTypeBalanceChanged       = "balance.changed"
+)
+
+var (
+	eventRegistry     *envelope.Registry
+	onceEventRegistry sync.Once
+)
+
+// GetEventRegistry returns the registry of every event consumed or produced by the service.
+// Version 0 is the legacy CallbackMessage payload; its upcaster converges the profile ID spellings
+// used by older producers on the name of version 1.
+func GetEventRegistry() *envelope.Registry {
+	onceEventRegistry.Do(func() {
+		eventRegistry = envelope.NewRegistry()
+
+		eventRegistry.Register(EventTypeOrderSuccess, 1, OrderEventData{})
+		eventRegistry.RegisterUpcaster(EventTypeOrderSuccess, envelope.LegacyVersion, envelope.RenameFields(map[string]string{
+			"profile_id": "profileID",
+			"profileId":  "profileID",
+		}))
+
+		eventRegistry.Register(EventTypeEarnPointSuccess, 1, EarnPointOrderEvent{})
+		eventRegistry.RegisterUpcaster(EventTypeEarnPointSuccess, envelope.LegacyVersion, envelope.RenameFields(map[string]string{
+			"profile_id": "profileId",
+			"profileID":  "profileId",
+		}))
+
+		eventRegistry.Register(EventTypeRefund, 1, RefundEvent{})
+		eventRegistry.RegisterUpcaster(EventTypeRefund, envelope.LegacyVersion, envelope.RenameFields(map[string]string{
+			"refund_id":              "refundID",
+			"transaction_id":         "transactionID",
+			"payment_transaction_id": "paymentTransactionID",
+		}))
+
+		eventRegistry.Register(EventTypeTransactionCreated, 1, TransactionEvent{})
+		eventRegistry.Register(EventTypeTransactionCompleted, 1, TransactionEvent{})
+		eventRegistry.Register(EventTypeTransactionUpdated, 1, TransactionEvent{})
+		eventRegistry.Register(EventTypeTransactionDeleted, 1, TransactionEvent{})
+		eventRegistry.Register(EventTypeBalanceChanged, 1, BalanceChangedEvent{})
+	})
+
+	return eventRegistry
+}
@@ -0,0 +1,26 @@
+package models
+
+import "testing"
+
+func TestDecodeEarnPointSuccess(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "legacy callback message", value: `{"eventType":"point.earn_success","data":{"transactionID":"tx-1","pointAmount":10,"profile_id":"p-1"}}`},
+		{name: "legacy callback message without event type", value: `{"data":{"transactionID":"tx-1","pointAmount":10,"profileID":"p-1"}}`},
+		{name: "legacy callback message of the core producer", value: `{"eventType":"point_sync_from_chain_success","data":{"transactionID":"tx-1","transactionType":"EARN","pointType":1,"referenceCode":"ref-1","region":"VN","pointAmount":10,"totalAmount":100000,"currency":"VND","profileId":"p-1","status":"SUCCESS"}}`},
+		{name: "versioned envelope", value: `{"id":"1","type":"point.earn_success","version":1,"data":{"transactionID":"tx-1","pointAmount":10,"profileId":"p-1"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got EarnPointOrderEvent
+			if _, err := GetEventRegistry().DecodeInto([]byte(tt.value), EventTypeEarnPointSuccess, &got); err != nil {
+				t.Fatalf("DecodeInto() error = %v", err)
+			}
+			if got.TransactionID != "tx-1" || got.PointAmount != 10 || got.ProfileID != "p-1" {
+				t.Errorf("DecodeInto() data = %+v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+type TransactionEvent struct {
+	TransactionID        string     `json:"transactionID"`
+	TransactionType      string     `json:"transactionType"`
+	ProfileID            string     `json:"profileID"`
+	Status               string     `json:"status"`
+	PreviousStatus       string     `json:"previousStatus,omitempty"`
+	PointAmount          int64      `json:"pointAmount"`
+	PointType            int64      `json:"pointType"`
+	TotalAmount          float64    `json:"totalAmount"`
+	Currency             string     `json:"currency"`
+	PaymentTransactionID string     `json:"paymentTransactionID"`
+	OriginalTxID         string     `json:"originalTransactionID,omitempty"`
+	Source               string     `json:"source"`
+	SourceType           string     `json:"sourceType"`
+	CreatedAt            *time.Time `json:"createdAt"`
+	UpdatedAt            *time.Time `json:"updatedAt"`
+	OccurredAt           time.Time  `json:"occurredAt"`
+}
+
+type BalanceChangedEvent struct {
+	ProfileID     string    `json:"profileID"`
+	TransactionID string    `json:"transactionID"`
+	Reason        string    `json:"reason"`
+	PointDelta    int64     `json:"pointDelta"`
+	PointType     int64     `json:"pointType"`
+	OccurredAt    time.Time `json:"occurredAt"`
+}
@@ -0,0 +1,216 @@
+package eventpublisher
+
+import (
+	"build-service-gin/api/msgbroker/models"
+	"build-service-gin/common/logger"
+	"build-service-gin/config"
+	"build-service-gin/internal/domains"
+	"build-service-gin/pkg/helpers/adapters"
+	"build-service-gin/pkg/queue/envelope"
+	"build-service-gin/pkg/queue/kafka"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// listenerQueueSize is the number of events waiting for the listeners.
+	listenerQueueSize = 1024
+	// listenerQueueTimeout is how long a publish waits for room in a full queue, holding the request back until the
+	// listeners catch up. An event still not queued then is not given to the listeners.
+	listenerQueueTimeout = 10 * time.Second
+)
+
+// Listener receives every published domain event in process, whether or not its Kafka topic is enabled. Listeners
+// run one event at a time in the background, in the order the events were published.
+type Listener func(ctx context.Context, env *envelope.Envelope)
+
+type listenerEvent struct {
+	ctx context.Context
+	env *envelope.Envelope
+}
+
+type EventPublisher struct {
+	producer     kafka.ProducerInterface
+	registry     *envelope.Registry
+	topics       map[string]string
+	listenersMu  sync.RWMutex
+	listeners    []Listener
+	mu           sync.RWMutex
+	events       chan listenerEvent
+	queueTimeout time.Duration
+	closed       bool
+	done         chan struct{}
+}
+
+type IEventPublisher interface {
+	PublishTransactionEvent(ctx context.Context, eventType string, tx *domains.UserTransactionHistory) error
+	PublishBalanceChanged(ctx context.Context, tx *domains.UserTransactionHistory, reason string, pointDelta int64) error
+	AddListener(listener Listener)
+	Close(ctx context.Context) error
+}
+
+var (
+	instanceEventPublisher *EventPublisher
+	onceEventPublisher     sync.Once
+)
+
+// NewEventPublisher returns the publisher of the transaction domain events. Without Kafka bootstrap servers
+// events are dropped, so the service still runs where no broker is available.
+func NewEventPublisher() IEventPublisher {
+	onceEventPublisher.Do(func() {
+		conf := config.GetInstance()
+		topicConf := conf.KafkaTopicConfig
+
+		instanceEventPublisher = newEventPublisher(models.GetEventRegistry(), map[string]string{
+			models.EventTypeTransactionCreated:   topicConf.TopicsTransactionCreated,
+			models.EventTypeTransactionCompleted: topicConf.TopicsTransactionCompleted,
+			models.EventTypeTransactionUpdated:   topicConf.TopicsTransactionUpdated,
+			models.EventTypeTransactionDeleted:   topicConf.TopicsTransactionDeleted,
+			models.EventTypeBalanceChanged:       topicConf.TopicsBalanceChanged,
+		}, listenerQueueSize)
+
+		if conf.KafkaConfig.BootstrapServers == "" {
+			logger.GetLogger().Warn().Msg("kafka bootstrap servers not set, domain events are disabled")
+			return
+		}
+		instanceEventPublisher.producer = kafka.NewProducer(conf.KafkaConfig, "")
+	})
+
+	return instanceEventPublisher
+}
+
+func newEventPublisher(registry *envelope.Registry, topics map[string]string, queueSize int) *EventPublisher {
+	p := &EventPublisher{
+		registry:     registry,
+		topics:       topics,
+		events:       make(chan listenerEvent, queueSize),
+		queueTimeout: listenerQueueTimeout,
+		done:         make(chan struct{}),
+	}
+	go p.dispatch()
+	return p
+}
+
+func (p *EventPublisher) PublishTransactionEvent(ctx context.Context, eventType string, tx *domains.UserTransactionHistory) error {
+	event := adapters.AdapterEvent{}.ConvertDomainToTransactionEvent(tx)
+	return p.publish(ctx, eventType, tx.ProfileID, event)
+}
+
+func (p *EventPublisher) PublishBalanceChanged(ctx context.Context, tx *domains.UserTransactionHistory, reason string, pointDelta int64) error {
+	event := adapters.AdapterEvent{}.ConvertDomainToBalanceChangedEvent(tx, reason, pointDelta)
+	return p.publish(ctx, models.EventTypeBalanceChanged, tx.ProfileID, event)
+}
+
+func (p *EventPublisher) AddListener(listener Listener) {
+	p.listenersMu.Lock()
+	defer p.listenersMu.Unlock()
+	p.listeners = append(p.listeners, listener)
+}
+
+// publish sends data as the latest version of eventType to the listeners and to its topic, keyed by profile ID
+// so the events of one profile stay ordered within a partition.
+func (p *EventPublisher) publish(ctx context.Context, eventType, profileID string, data interface{}) error {
+	topic, ok := p.topics[eventType]
+	if !ok {
+		return fmt.Errorf("%w: %s", envelope.ErrUnknownEventType, eventType)
+	}
+
+	env, err := p.registry.NewEnvelope(ctx, eventType, envelope.ContentTypeJSON, data)
+	if err != nil {
+		return err
+	}
+
+	p.notify(ctx, env)
+
+	if topic == "" || p.producer == nil {
+		return nil
+	}
+	return p.producer.PublishEnvelopeWithTopic(ctx, topic, profileID, env)
+}
+
+// Close stops giving events to the listeners once they handled the queued ones, then delivers the messages still
+// buffered by the producer and closes it. Both are bounded by ctx.
+func (p *EventPublisher) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.events)
+	p.mu.Unlock()
+
+	var err error
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		err = fmt.Errorf("event listeners not drained: %w", ctx.Err())
+	}
+
+	if p.producer != nil {
+		err = errors.Join(err, p.producer.Close(ctx))
+	}
+	return err
+}
+
+// notify queues env for the listeners without waiting for them to run, only for room in the queue when it is full.
+// The listeners get a context that is not canceled with the request, which is over by the time they run.
+func (p *EventPublisher) notify(ctx context.Context, env *envelope.Envelope) {
+	p.listenersMu.RLock()
+	noListener := len(p.listeners) == 0
+	p.listenersMu.RUnlock()
+	if noListener {
+		return
+	}
+
+	// Close waits for the publishes queueing an event, which the dispatch keeps making room for
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return
+	}
+
+	event := listenerEvent{ctx: context.WithoutCancel(ctx), env: env}
+	select {
+	case p.events <- event:
+		return
+	default:
+	}
+
+	log := logger.GetLogger().AddTraceInfoContextRequest(ctx)
+	log.Warn().Str("event", env.Type).Msg("event listeners queue full, waiting for the listeners")
+	timer := time.NewTimer(p.queueTimeout)
+	defer timer.Stop()
+	select {
+	case p.events <- event:
+	case <-timer.C:
+		log.Error().Str("event", env.Type).Msgf("event listeners queue still full after %s, event not given to the listeners", p.queueTimeout)
+	}
+}
+
+// dispatch gives the queued events to the listeners until the publisher is closed.
+func (p *EventPublisher) dispatch() {
+	defer close(p.done)
+	for event := range p.events {
+		p.listenersMu.RLock()
+		listeners := p.listeners
+		p.listenersMu.RUnlock()
+
+		for _, listener := range listeners {
+			runListener(listener, event)
+		}
+	}
+}
+
+// runListener runs listener on event, a listener panicking must not stop the others.
+func runListener(listener Listener, event listenerEvent) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			logger.GetLogger().AddTraceInfoContextRequest(event.ctx).Error().Interface("panic", rec).Str("event", event.env.Type).Msg("event listener panicked")
+		}
+	}()
+	listener(event.ctx, event.env)
+}
@@ -0,0 +1,166 @@
+package eventpublisher
+
+import (
+	"build-service-gin/api/msgbroker/models"
+	"build-service-gin/common/logger"
+	"build-service-gin/internal/domains"
+	"build-service-gin/pkg/queue/envelope"
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLog("test")
+	os.Exit(m.Run())
+}
+
+func newTestPublisher() *EventPublisher {
+	return newEventPublisher(models.GetEventRegistry(), map[string]string{
+		models.EventTypeTransactionCreated: "",
+		models.EventTypeBalanceChanged:     "",
+	}, listenerQueueSize)
+}
+
+func TestPublishDoesNotWaitForListeners(t *testing.T) {
+	p := newTestPublisher()
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var got []string
+	p.AddListener(func(ctx context.Context, env *envelope.Envelope) {
+		<-release
+		if ctx.Err() != nil {
+			t.Errorf("listener context canceled: %v", ctx.Err())
+		}
+		mu.Lock()
+		got = append(got, env.Type)
+		mu.Unlock()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tx := &domains.UserTransactionHistory{TransactionID: "tx-1", ProfileID: "p-1"}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = p.PublishTransactionEvent(ctx, models.EventTypeTransactionCreated, tx)
+		_ = p.PublishBalanceChanged(ctx, tx, models.EventTypeTransactionCreated, 10)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publish waited for the listener")
+	}
+
+	// the request is over before the listeners run
+	cancel()
+	close(release)
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 2 || got[0] != models.EventTypeTransactionCreated || got[1] != models.EventTypeBalanceChanged {
+		t.Errorf("listener got %v", got)
+	}
+}
+
+func TestListenerPanicDoesNotStopOthers(t *testing.T) {
+	p := newTestPublisher()
+	var got int
+	p.AddListener(func(context.Context, *envelope.Envelope) { panic("listener failed") })
+	p.AddListener(func(context.Context, *envelope.Envelope) { got++ })
+
+	_ = p.PublishTransactionEvent(context.Background(), models.EventTypeTransactionCreated, &domains.UserTransactionHistory{})
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if got != 1 {
+		t.Errorf("second listener ran %d times, want 1", got)
+	}
+}
+
+func TestCloseTimesOut(t *testing.T) {
+	p := newTestPublisher()
+	release := make(chan struct{})
+	defer close(release)
+	p.AddListener(func(context.Context, *envelope.Envelope) { <-release })
+	_ = p.PublishTransactionEvent(context.Background(), models.EventTypeTransactionCreated, &domains.UserTransactionHistory{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.Close(ctx); err == nil {
+		t.Fatal("Close() returned before the listeners were drained")
+	}
+	// publishing after Close drops the events for the listeners
+	if err := p.PublishTransactionEvent(context.Background(), models.EventTypeTransactionCreated, &domains.UserTransactionHistory{}); err != nil {
+		t.Errorf("publish after Close error = %v", err)
+	}
+}
+
+func TestPublishWaitsForRoomInAFullQueue(t *testing.T) {
+	p := newEventPublisher(models.GetEventRegistry(), map[string]string{models.EventTypeTransactionCreated: ""}, 1)
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var got int
+	p.AddListener(func(context.Context, *envelope.Envelope) {
+		<-release
+		mu.Lock()
+		got++
+		mu.Unlock()
+	})
+
+	tx := &domains.UserTransactionHistory{TransactionID: "tx-1", ProfileID: "p-1"}
+	// the first event is held by the listener, the second fills the queue
+	_ = p.PublishTransactionEvent(context.Background(), models.EventTypeTransactionCreated, tx)
+	time.Sleep(10 * time.Millisecond)
+	_ = p.PublishTransactionEvent(context.Background(), models.EventTypeTransactionCreated, tx)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = p.PublishTransactionEvent(context.Background(), models.EventTypeTransactionCreated, tx)
+	}()
+	select {
+	case <-done:
+		t.Fatal("publish returned while the queue was full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	<-done
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if got != 3 {
+		t.Errorf("listener got %d events, want 3", got)
+	}
+}
+
+func TestPublishGivesUpOnAFullQueue(t *testing.T) {
+	p := newEventPublisher(models.GetEventRegistry(), map[string]string{models.EventTypeTransactionCreated: ""}, 1)
+	p.queueTimeout = 20 * time.Millisecond
+	release := make(chan struct{})
+	defer close(release)
+	p.AddListener(func(context.Context, *envelope.Envelope) { <-release })
+
+	tx := &domains.UserTransactionHistory{TransactionID: "tx-1", ProfileID: "p-1"}
+	_ = p.PublishTransactionEvent(context.Background(), models.EventTypeTransactionCreated, tx)
+	time.Sleep(10 * time.Millisecond)
+	_ = p.PublishTransactionEvent(context.Background(), models.EventTypeTransactionCreated, tx)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = p.PublishTransactionEvent(context.Background(), models.EventTypeTransactionCreated, tx)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publish kept waiting after the queue timeout")
+	}
+}
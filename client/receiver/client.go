@@ -9,6 +9,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -17,8 +18,9 @@ const (
 )
 
 type ReceiverClient struct {
-	conf *config.SystemConfig
-	cl   *client.Client
+	conf  *config.SystemConfig
+	cl    *client.Client
+	token atomic.Pointer[string]
 }
 
 type IReceiverClient interface {
@@ -38,6 +40,12 @@ func NewReceiverClient() IReceiverClient {
 			conf: conf,
 			cl:   cl,
 		}
+		instanceReceiverClient.token.Store(&conf.InternalToken)
+
+		// pick up a rotated internal token without a restart
+		config.OnSecretChange("InternalToken", func(token string) {
+			instanceReceiverClient.token.Store(&token)
+		})
 	})
 
 	return instanceReceiverClient
@@ -46,7 +54,7 @@ func NewReceiverClient() IReceiverClient {
 func (c *ReceiverClient) PostOrder(ctx context.Context, message domains.OrderMessage) (*OrderResp, error) {
 	region := ctx.Value(utils.KeyRegion).(string)
 	headers := map[string]string{
-		"Authorization":   fmt.Sprintf("Bearer %s", c.conf.InternalToken),
+		"Authorization":   fmt.Sprintf("Bearer %s", *c.token.Load()),
 		"X-Client-Region": region,
 	}
 	// Create action and source send order
@@ -0,0 +1,135 @@
+package webhook
+
+import (
+	"build-service-gin/common/client"
+	"build-service-gin/common/logger"
+	"build-service-gin/config"
+	"build-service-gin/pkg/netguard"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	HeaderWebhookSignature = "X-Webhook-Signature"
+	HeaderWebhookTimestamp = "X-Webhook-Timestamp"
+	HeaderWebhookEvent     = "X-Webhook-Event"
+	HeaderWebhookDelivery  = "X-Webhook-Delivery"
+)
+
+type WebhookClient struct {
+	conf *config.SystemConfig
+	cl   *client.Client
+}
+
+type IWebhookClient interface {
+	Deliver(ctx context.Context, req DeliveryReq) (*DeliveryResp, error)
+}
+
+var (
+	instanceWebhookClient *WebhookClient
+	onceWebhookClient     sync.Once
+)
+
+func NewWebhookClient() IWebhookClient {
+	onceWebhookClient.Do(func() {
+		cl, err := newWebhookClient(config.GetInstance())
+		if err != nil {
+			logger.GetLogger().Fatal().Err(err).Msg("init webhook client failed")
+		}
+		instanceWebhookClient = cl
+	})
+
+	return instanceWebhookClient
+}
+
+func newWebhookClient(conf *config.SystemConfig) (*WebhookClient, error) {
+	tlsConfig, err := newTLSConfig(conf.WebhookConfig.CAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// retries are scheduled by the webhook service so that every attempt reaches the delivery log
+	cl := client.NewClient("", conf.WebhookConfig.Timeout, 0, 0, nil)
+	// the payloads are signed for the subscriber, the certificate of its endpoint is always verified
+	cl.SetTLSClientConfig(tlsConfig)
+	if transport, ok := cl.GetClient().Transport.(*http.Transport); ok && !conf.WebhookConfig.AllowPrivateTargets {
+		// the host of a subscription may resolve to an internal address after it was checked
+		transport.DialContext = (&net.Dialer{Timeout: conf.WebhookConfig.Timeout, Control: netguard.Control}).DialContext
+	}
+	return &WebhookClient{
+		conf: conf,
+		cl:   cl,
+	}, nil
+}
+
+// newTLSConfig returns the TLS config verifying the subscribers with the system roots, and the certificates of
+// caFile when set.
+func newTLSConfig(caFile string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile == "" {
+		return tlsConfig, nil
+	}
+
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate in %s", caFile)
+	}
+	tlsConfig.RootCAs = pool
+	return tlsConfig, nil
+}
+
+// Deliver posts the payload to the subscriber URL, signed with the subscription secret. Only transport failures
+// are returned as errors; any HTTP status is reported in DeliveryResp.
+func (c *WebhookClient) Deliver(ctx context.Context, req DeliveryReq) (*DeliveryResp, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	headers := map[string]string{
+		"Content-Type":         "application/json",
+		HeaderWebhookEvent:     req.EventType,
+		HeaderWebhookDelivery:  req.DeliveryID,
+		HeaderWebhookTimestamp: timestamp,
+		HeaderWebhookSignature: "t=" + timestamp + ",v1=" + Sign(req.Secret, timestamp, req.Payload),
+	}
+
+	res, err := c.cl.R().SetContext(ctx).SetHeaders(headers).SetBody(req.Payload).Post(req.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	body := res.Body()
+	if maxSize := c.conf.WebhookConfig.MaxResponseBodySize; maxSize > 0 && len(body) > maxSize {
+		body = body[:maxSize]
+	}
+
+	return &DeliveryResp{
+		StatusCode: res.StatusCode(),
+		Body:       string(body),
+	}, nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "timestamp.payload". Receivers recompute it from the
+// X-Webhook-Timestamp header and the raw body to authenticate the request.
+func Sign(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
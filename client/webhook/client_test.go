@@ -0,0 +1,74 @@
+package webhook
+
+import (
+	"build-service-gin/common/logger"
+	"build-service-gin/config"
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLog("test")
+	os.Exit(m.Run())
+}
+
+func TestDeliverVerifiesTheCertificate(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		caFile  string
+		wantErr bool
+	}{
+		{name: "untrusted certificate", wantErr: true},
+		{name: "certificate signed by the configured CA", caFile: caFile},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := &config.SystemConfig{}
+			conf.WebhookConfig = config.WebhookConfig{Timeout: 5 * time.Second, AllowPrivateTargets: true, CAFile: tt.caFile}
+			cl, err := newWebhookClient(conf)
+			if err != nil {
+				t.Fatalf("newWebhookClient() error = %v", err)
+			}
+
+			res, err := cl.Deliver(context.Background(), DeliveryReq{URL: server.URL, Secret: "secret", Payload: []byte(`{}`)})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Deliver() = %+v, want a certificate error", res)
+				}
+				return
+			}
+			if err != nil || res.StatusCode != http.StatusNoContent {
+				t.Fatalf("Deliver() = %+v, %v", res, err)
+			}
+		})
+	}
+}
+
+func TestNewWebhookClientRejectsABadCAFile(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	conf := &config.SystemConfig{}
+	conf.WebhookConfig.CAFile = caFile
+	if _, err := newWebhookClient(conf); err == nil {
+		t.Error("newWebhookClient() succeeded with a CA file without certificate")
+	}
+}
@@ -0,0 +1,14 @@
+package webhook
+
+type DeliveryReq struct {
+	URL        string
+	Secret     string
+	DeliveryID string
+	EventType  string
+	Payload    []byte
+}
+
+type DeliveryResp struct {
+	StatusCode int
+	Body       string
+}
@@ -0,0 +1,69 @@
+// Command rotate-field-keys re-encrypts the columns encrypted at rest with the encryption key. Run it after adding a
+// key version and switching CRYPTO_ENCRYPT_VERSION to it, then drop the old version from CRYPTO_KEYS once every
+// store is rotated.
+package main
+
+import (
+	"build-service-gin/common/crypto"
+	"build-service-gin/common/logger"
+	"build-service-gin/common/mongodb"
+	postgres "build-service-gin/common/postgresql"
+	"build-service-gin/config"
+	"build-service-gin/repositories/user_transaction_history"
+	"build-service-gin/repositories/user_transaction_history_postgresql"
+	"context"
+	"flag"
+	"os"
+)
+
+func main() {
+	batchSize := flag.Int("batch", 500, "number of records re-encrypted per batch")
+	store := flag.String("store", "all", "store to rotate: mongo, postgres or all")
+	config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	logger.InitLog(os.Getenv("SERVICE_ID"))
+	log := logger.GetLogger()
+
+	conf, err := config.LoadConfig()
+	if err != nil {
+		log.Fatal().Msgf("load config fail! %s", err)
+	}
+
+	keyring, err := crypto.NewKeyring(conf.CryptoConfig.Keys, conf.CryptoConfig.EncryptVersion, conf.CryptoConfig.LegacyKey)
+	if err != nil {
+		log.Fatal().Msgf("load crypto keys fail! %s", err)
+	}
+	if keyring == nil {
+		log.Fatal().Msg("encryption is disabled, nothing to rotate")
+	}
+	crypto.SetDefault(keyring)
+
+	ctx := context.Background()
+
+	if *store == "all" || *store == "mongo" {
+		dbStorage, err := mongodb.ConnectMongoDB(ctx, &conf.MongoDBConfig)
+		if err != nil {
+			log.Fatal().Msgf("connect mongodb failed! %s", err)
+		}
+
+		rotated, err := user_transaction_history.NewRepoUserTransactionHistory(dbStorage).RotateEncryptedFields(ctx, int64(*batchSize))
+		if err != nil {
+			log.Fatal().Err(err).Int64("rotated", rotated).Msg("rotate mongo fields failed")
+		}
+		log.Info().Int64("rotated", rotated).Int("version", keyring.EncryptVersion()).Msg("mongo fields rotated")
+	}
+
+	if *store == "all" || *store == "postgres" {
+		postgresql, err := postgres.ConnectPostgresql(ctx, &conf.PostgresConfig)
+		if err != nil {
+			log.Fatal().Msgf("connect postgresql failed! %s", err)
+		}
+
+		rotated, err := user_transaction_history_postgresql.NewRepoUserTransactionHistoryPostgresql(postgresql).RotateEncryptedFields(ctx, *batchSize)
+		if err != nil {
+			log.Fatal().Err(err).Int64("rotated", rotated).Msg("rotate postgres fields failed")
+		}
+		log.Info().Int64("rotated", rotated).Int("version", keyring.EncryptVersion()).Msg("postgres fields rotated")
+	}
+}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"build-service-gin/common/crypto"
 	"build-service-gin/common/logger"
 	"build-service-gin/common/utils"
 	"crypto/tls"
@@ -8,7 +9,6 @@ import (
 	"github.com/go-resty/resty/v2"
 	"net/http"
 	neturl "net/url"
-	"os"
 	"time"
 
 	"github.com/tidwall/gjson"
@@ -60,9 +60,6 @@ func NewClient(baseURL string, timeout time.Duration, maxRetry int, waitTime tim
 		}
 	}
 
-	// get key encrypt from env
-	keyEncrypt := os.Getenv(utils.VGREncryptKey)
-
 	client := resty.New()
 	client.SetBaseURL(baseURL)
 	client.SetTimeout(timeout)
@@ -86,21 +83,21 @@ func NewClient(baseURL string, timeout time.Duration, maxRetry int, waitTime tim
 		reqBaseURL := client.BaseURL + request.URL
 		reqQueryParams := copyQueryParams(request.QueryParam)
 
-		if keyEncrypt != "" {
+		if keyring := crypto.Default(); keyring != nil {
 			// encrypt request body fields
 			var reqBodyEnc []string
 			for _, be := range reqBodyEncrypt {
 				reqBodyEnc = append(reqBodyEnc, string(be))
 			}
 
-			if result, err := encryptBodyFields(reqBody, reqBodyEnc, keyEncrypt); err != nil {
+			if result, err := encryptBodyFields(reqBody, reqBodyEnc, keyring); err != nil {
 				log.Error().Err(err).Msg("encrypt request body fields error")
 			} else {
 				reqBody = result
 			}
 
 			if value, ok := ctx.Value(KeyRestRequestBodyEncrypt).([]string); ok {
-				if result, err := encryptBodyFields(reqBody, value, keyEncrypt); err != nil {
+				if result, err := encryptBodyFields(reqBody, value, keyring); err != nil {
 					log.Error().Err(err).Msg("encrypt request body fields error")
 				} else {
 					reqBody = result
@@ -115,14 +112,14 @@ func NewClient(baseURL string, timeout time.Duration, maxRetry int, waitTime tim
 				queryParamsEnc = append(queryParamsEnc, string(qe))
 			}
 
-			if result, err := encryptQueryParams(reqQueryParams, queryParamsEnc, keyEncrypt); err != nil {
+			if result, err := encryptQueryParams(reqQueryParams, queryParamsEnc, keyring); err != nil {
 				log.Error().Err(err).Msg("encrypt request query params error")
 			} else {
 				reqQueryParams = result
 			}
 
 			if value, ok := ctx.Value(KeyRestQueryParamsEncrypt).([]string); ok {
-				if result, err := encryptQueryParams(reqQueryParams, value, keyEncrypt); err != nil {
+				if result, err := encryptQueryParams(reqQueryParams, value, keyring); err != nil {
 					log.Error().Err(err).Msg("encrypt request query params error")
 				} else {
 					reqQueryParams = result
@@ -177,21 +174,21 @@ func NewClient(baseURL string, timeout time.Duration, maxRetry int, waitTime tim
 			log.Error().Err(err).Msg("parse request url error")
 		}
 
-		if keyEncrypt != "" {
+		if keyring := crypto.Default(); keyring != nil {
 			// encrypt request body fields
 			var reqBodyEnc []string
 			for _, be := range reqBodyEncrypt {
 				reqBodyEnc = append(reqBodyEnc, string(be))
 			}
 
-			if result, err := encryptBodyFields(reqBody, reqBodyEnc, keyEncrypt); err != nil {
+			if result, err := encryptBodyFields(reqBody, reqBodyEnc, keyring); err != nil {
 				log.Error().Err(err).Msg("encrypt request body fields error")
 			} else {
 				reqBody = result
 			}
 
 			if value, ok := ctx.Value(KeyRestRequestBodyEncrypt).([]string); ok {
-				if result, err := encryptBodyFields(reqBody, value, keyEncrypt); err != nil {
+				if result, err := encryptBodyFields(reqBody, value, keyring); err != nil {
 					log.Error().Err(err).Msg("encrypt request body fields error")
 				} else {
 					reqBody = result
@@ -206,14 +203,14 @@ func NewClient(baseURL string, timeout time.Duration, maxRetry int, waitTime tim
 				respBodyEnc = append(respBodyEnc, string(be))
 			}
 
-			if result, err := encryptBodyFields(respBody, respBodyEnc, keyEncrypt); err != nil {
+			if result, err := encryptBodyFields(respBody, respBodyEnc, keyring); err != nil {
 				log.Error().Err(err).Msg("encrypt response body fields error")
 			} else {
 				respBody = result
 			}
 
 			if value, ok := ctx.Value(KeyRestResponseBodyEncrypt).([]string); ok {
-				if result, err := encryptBodyFields(respBody, value, keyEncrypt); err != nil {
+				if result, err := encryptBodyFields(respBody, value, keyring); err != nil {
 					log.Error().Err(err).Msg("encrypt response body fields error")
 				} else {
 					respBody = result
@@ -228,14 +225,14 @@ func NewClient(baseURL string, timeout time.Duration, maxRetry int, waitTime tim
 				queryParamsEnc = append(queryParamsEnc, string(qe))
 			}
 
-			if result, err := encryptQueryParams(reqQueryParams, queryParamsEnc, keyEncrypt); err != nil {
+			if result, err := encryptQueryParams(reqQueryParams, queryParamsEnc, keyring); err != nil {
 				log.Error().Err(err).Msg("encrypt request query params error")
 			} else {
 				reqQueryParams = result
 			}
 
 			if value, ok := ctx.Value(KeyRestQueryParamsEncrypt).([]string); ok {
-				if result, err := encryptQueryParams(reqQueryParams, value, keyEncrypt); err != nil {
+				if result, err := encryptQueryParams(reqQueryParams, value, keyring); err != nil {
 					log.Error().Err(err).Msg("encrypt request query params error")
 				} else {
 					reqQueryParams = result
@@ -274,12 +271,12 @@ func NewClient(baseURL string, timeout time.Duration, maxRetry int, waitTime tim
 	}
 }
 
-func encryptBodyFields(body interface{}, bodyFields []string, secretKey string) (interface{}, error) {
+func encryptBodyFields(body interface{}, bodyFields []string, keyring *crypto.Keyring) (interface{}, error) {
 	if len(bodyFields) == 0 {
 		return body, nil
 	}
 
-	if secretKey == "" {
+	if keyring == nil {
 		return body, nil
 	}
 
@@ -305,7 +302,7 @@ func encryptBodyFields(body interface{}, bodyFields []string, secretKey string)
 			continue
 		}
 
-		encryptedValue, err := utils.Encrypt(fmt.Sprintf("%v", value), secretKey)
+		encryptedValue, err := keyring.Encrypt(fmt.Sprintf("%v", value))
 		if err != nil {
 			return "", err
 		}
@@ -334,7 +331,7 @@ func parseURL(_url string) (string, neturl.Values, error) {
 	return path, queryParams, nil
 }
 
-func encryptQueryParams(queryParams neturl.Values, fields []string, secretKey string) (neturl.Values, error) {
+func encryptQueryParams(queryParams neturl.Values, fields []string, keyring *crypto.Keyring) (neturl.Values, error) {
 	if len(queryParams) == 0 {
 		return queryParams, nil
 	}
@@ -348,7 +345,7 @@ func encryptQueryParams(queryParams neturl.Values, fields []string, secretKey st
 
 	for key, value := range queryParams {
 		if _, ok := mappingFields[key]; ok {
-			encryptedValue, err := utils.Encrypt(value[0], secretKey)
+			encryptedValue, err := keyring.Encrypt(value[0])
 			if err != nil {
 				return nil, err
 			}
@@ -0,0 +1,231 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	prefix            = "enc:"
+	modeDeterministic = "d"
+	modeRandomized    = "r"
+)
+
+var (
+	ErrUnknownKeyVersion = errors.New("crypto: unknown key version")
+	ErrMalformed         = errors.New("crypto: malformed ciphertext")
+)
+
+// Keyring encrypts with one key version and decrypts with every configured version. It is the one key set of the
+// service: it encrypts the log and outbound request fields as well as the columns encrypted at rest. Ciphertexts are
+// written as enc:v<version>:<mode>:<base64 nonce and AES-256-GCM ciphertext>.
+type Keyring struct {
+	encryptVersion int
+	keys           map[int]*versionKey
+	legacy         []byte
+}
+
+type versionKey struct {
+	aead     cipher.AEAD
+	nonceKey []byte
+}
+
+var (
+	defaultKeyring *Keyring
+	mu             sync.RWMutex
+)
+
+// NewKeyring builds the keyring from hex encoded 32 bytes keys by version. legacyKeyHex is the former
+// VGR_ENCRYPT_KEY, it is only used by DecryptLegacy. Without any key nil is returned and callers skip encryption.
+func NewKeyring(keys map[int]string, encryptVersion int, legacyKeyHex string) (*Keyring, error) {
+	if len(keys) == 0 {
+		if legacyKeyHex != "" {
+			return nil, errors.New("crypto: a legacy key only decrypts, configure an encryption key")
+		}
+		return nil, nil
+	}
+
+	k := &Keyring{
+		encryptVersion: encryptVersion,
+		keys:           make(map[int]*versionKey, len(keys)),
+	}
+	for version, keyHex := range keys {
+		master, err := hex.DecodeString(keyHex)
+		if err != nil {
+			return nil, fmt.Errorf("crypto: decode key v%d: %w", version, err)
+		}
+		if len(master) != 32 {
+			return nil, fmt.Errorf("crypto: key v%d has %d bytes, 32 are required", version, len(master))
+		}
+
+		block, err := aes.NewCipher(derive(master, "field-encryption"))
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		k.keys[version] = &versionKey{aead: aead, nonceKey: derive(master, "field-nonce")}
+	}
+	if _, ok := k.keys[encryptVersion]; !ok {
+		return nil, fmt.Errorf("crypto: encryption key v%d is not configured", encryptVersion)
+	}
+
+	if legacyKeyHex != "" {
+		legacy, err := hex.DecodeString(legacyKeyHex)
+		if err != nil {
+			return nil, fmt.Errorf("crypto: decode legacy key: %w", err)
+		}
+		if _, err = aes.NewCipher(legacy); err != nil {
+			return nil, fmt.Errorf("crypto: legacy key: %w", err)
+		}
+		k.legacy = legacy
+	}
+
+	return k, nil
+}
+
+// SetDefault installs the keyring used by the logger, the rest client, the struct tag encryptors and the field
+// types of pkg/fieldcrypt, nil disables encryption.
+func SetDefault(k *Keyring) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultKeyring = k
+}
+
+// Default returns the installed keyring, nil when encryption is not configured.
+func Default() *Keyring {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultKeyring
+}
+
+func (k *Keyring) EncryptVersion() int {
+	return k.encryptVersion
+}
+
+// EncryptPrefix is the prefix of every value encrypted with the encryption key.
+func (k *Keyring) EncryptPrefix() string {
+	return versionPrefix(k.encryptVersion)
+}
+
+// Encrypt encrypts plaintext with a random nonce.
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	return k.encrypt(k.encryptVersion, plaintext, false)
+}
+
+// EncryptDeterministic derives the nonce from the plaintext, so equal values give equal ciphertexts and can still be
+// filtered on, see Candidates.
+func (k *Keyring) EncryptDeterministic(plaintext string) (string, error) {
+	return k.encrypt(k.encryptVersion, plaintext, true)
+}
+
+func (k *Keyring) encrypt(version int, plaintext string, deterministic bool) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	key := k.keys[version]
+	nonce := make([]byte, key.aead.NonceSize())
+	mode := modeRandomized
+	if deterministic {
+		mode = modeDeterministic
+		copy(nonce, derive(key.nonceKey, plaintext))
+	} else if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := key.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return versionPrefix(version) + mode + ":" + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts the values written by Encrypt and EncryptDeterministic. A value without the enc: prefix was
+// written before encryption was enabled and is returned unchanged, legacy AES-CBC payloads are only read by
+// DecryptLegacy.
+func (k *Keyring) Decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, prefix) {
+		return value, nil
+	}
+
+	version, mode, payload, err := parse(value)
+	if err != nil {
+		return "", err
+	}
+	key, ok := k.keys[version]
+	if !ok {
+		return "", fmt.Errorf("%w v%d", ErrUnknownKeyVersion, version)
+	}
+	if mode != modeDeterministic && mode != modeRandomized {
+		return "", ErrMalformed
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil || len(sealed) < key.aead.NonceSize() {
+		return "", ErrMalformed
+	}
+	plaintext, err := key.aead.Open(nil, sealed[:key.aead.NonceSize()], sealed[key.aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("crypto: decrypt v%d: %w", version, err)
+	}
+	return string(plaintext), nil
+}
+
+// Candidates lists every stored form of a deterministic value: its ciphertext under each key version and the
+// plaintext of values not encrypted yet. Filtering on all of them keeps equality lookups working during a rotation.
+func (k *Keyring) Candidates(plaintext string) []string {
+	if plaintext == "" {
+		return []string{plaintext}
+	}
+
+	versions := make([]int, 0, len(k.keys))
+	for version := range k.keys {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+
+	candidates := []string{plaintext}
+	for _, version := range versions {
+		ciphertext, _ := k.encrypt(version, plaintext, true)
+		candidates = append(candidates, ciphertext)
+	}
+	return candidates
+}
+
+// NeedsRotation reports whether the stored value is not encrypted with the encryption key yet.
+func (k *Keyring) NeedsRotation(value string) bool {
+	return value != "" && !strings.HasPrefix(value, k.EncryptPrefix())
+}
+
+func parse(value string) (version int, mode, payload string, err error) {
+	parts := strings.SplitN(strings.TrimPrefix(value, prefix), ":", 3)
+	if len(parts) != 3 || !strings.HasPrefix(parts[0], "v") {
+		return 0, "", "", ErrMalformed
+	}
+	version, err = strconv.Atoi(parts[0][1:])
+	if err != nil {
+		return 0, "", "", ErrMalformed
+	}
+	return version, parts[1], parts[2], nil
+}
+
+func versionPrefix(version int) string {
+	return prefix + "v" + strconv.Itoa(version) + ":"
+}
+
+func derive(key []byte, label string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(label))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,162 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var (
+	testKeyV1 = strings.Repeat("ab", 32)
+	testKeyV2 = strings.Repeat("cd", 32)
+)
+
+func newTestKeyring(t *testing.T, keys map[int]string, version int, legacy string) *Keyring {
+	t.Helper()
+	k, err := NewKeyring(keys, version, legacy)
+	if err != nil {
+		t.Fatalf("NewKeyring() = %v", err)
+	}
+	return k
+}
+
+func TestNewKeyring(t *testing.T) {
+	if k, err := NewKeyring(nil, 1, ""); k != nil || err != nil {
+		t.Errorf("NewKeyring() without keys = %v, %v, want encryption disabled", k, err)
+	}
+
+	tests := []struct {
+		name    string
+		keys    map[int]string
+		version int
+		legacy  string
+	}{
+		{name: "key not hex", keys: map[int]string{1: "zz"}, version: 1},
+		{name: "key too short", keys: map[int]string{1: "abcd"}, version: 1},
+		{name: "encryption key missing", keys: map[int]string{1: testKeyV1}, version: 2},
+		{name: "legacy key without keys", version: 1, legacy: testKeyV1},
+		{name: "legacy key not hex", keys: map[int]string{1: testKeyV1}, version: 1, legacy: "zz"},
+		{name: "legacy key of a bad size", keys: map[int]string{1: testKeyV1}, version: 1, legacy: "abcd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewKeyring(tt.keys, tt.version, tt.legacy); err == nil {
+				t.Error("NewKeyring() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestKeyringEncrypt(t *testing.T) {
+	k := newTestKeyring(t, map[int]string{1: testKeyV1}, 1, "")
+
+	for name, encrypt := range map[string]func(string) (string, error){
+		"randomized":    k.Encrypt,
+		"deterministic": k.EncryptDeterministic,
+	} {
+		t.Run(name, func(t *testing.T) {
+			first, err := encrypt("pay-1")
+			if err != nil {
+				t.Fatalf("encrypt = %v", err)
+			}
+			second, _ := encrypt("pay-1")
+			if !strings.HasPrefix(first, k.EncryptPrefix()) {
+				t.Errorf("ciphertext %q lacks the prefix %q", first, k.EncryptPrefix())
+			}
+			if (first == second) != (name == "deterministic") {
+				t.Errorf("equal ciphertexts = %v", first == second)
+			}
+
+			plaintext, err := k.Decrypt(first)
+			if err != nil || plaintext != "pay-1" {
+				t.Errorf("Decrypt() = %q, %v, want pay-1", plaintext, err)
+			}
+			if ciphertext, _ := encrypt(""); ciphertext != "" {
+				t.Errorf("encrypt(\"\") = %q, want empty", ciphertext)
+			}
+		})
+	}
+}
+
+func TestKeyringDecrypt(t *testing.T) {
+	k := newTestKeyring(t, map[int]string{1: testKeyV1}, 1, "")
+	unknown, _ := newTestKeyring(t, map[int]string{3: testKeyV2}, 3, "").Encrypt("pay-1")
+	tampered, _ := k.Encrypt("pay-1")
+	tampered = tampered[:len(tampered)-2] + "AA"
+
+	// a value without the prefix was written before encryption, whatever it looks like
+	for _, plaintext := range []string{"pay-1", "v1:pay-1", "aGVsbG8gd29ybGQhISEhISE="} {
+		if got, err := k.Decrypt(plaintext); err != nil || got != plaintext {
+			t.Errorf("Decrypt(%q) = %q, %v, want it unchanged", plaintext, got, err)
+		}
+	}
+	if _, err := k.Decrypt(unknown); !errors.Is(err, ErrUnknownKeyVersion) {
+		t.Errorf("Decrypt() with an unknown version = %v, want %v", err, ErrUnknownKeyVersion)
+	}
+	for _, malformed := range []string{"enc:v1", "enc:x1:r:AAAA", "enc:v1:z:AAAA", "enc:v1:r:%%%"} {
+		if _, err := k.Decrypt(malformed); !errors.Is(err, ErrMalformed) {
+			t.Errorf("Decrypt(%q) = %v, want %v", malformed, err, ErrMalformed)
+		}
+	}
+	if _, err := k.Decrypt(tampered); err == nil {
+		t.Error("Decrypt() of a tampered ciphertext succeeded")
+	}
+}
+
+func TestKeyringRotation(t *testing.T) {
+	before := newTestKeyring(t, map[int]string{1: testKeyV1}, 1, "")
+	after := newTestKeyring(t, map[int]string{1: testKeyV1, 2: testKeyV2}, 2, "")
+
+	old, _ := before.EncryptDeterministic("pay-1")
+	if plaintext, err := after.Decrypt(old); err != nil || plaintext != "pay-1" {
+		t.Fatalf("Decrypt() of a v1 value after the rotation = %q, %v", plaintext, err)
+	}
+	if !after.NeedsRotation(old) || !after.NeedsRotation("pay-1") {
+		t.Error("NeedsRotation() = false for values not encrypted with the encryption key")
+	}
+	rotated, _ := after.EncryptDeterministic("pay-1")
+	if after.NeedsRotation(rotated) || after.NeedsRotation("") {
+		t.Error("NeedsRotation() = true for a value encrypted with the encryption key or empty")
+	}
+
+	candidates := after.Candidates("pay-1")
+	want := []string{"pay-1", old, rotated}
+	if strings.Join(candidates, ",") != strings.Join(want, ",") {
+		t.Errorf("Candidates() = %v, want %v", candidates, want)
+	}
+}
+
+// encryptLegacy writes a payload like the former utils.Encrypt: AES-CBC with the first block of the key as IV.
+func encryptLegacy(t *testing.T, keyHex, plaintext string) string {
+	t.Helper()
+	key, _ := hex.DecodeString(keyHex)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	padding := aes.BlockSize - len(plaintext)%aes.BlockSize
+	data := append([]byte(plaintext), []byte(strings.Repeat(string(rune(padding)), padding))...)
+	cipher.NewCBCEncrypter(block, key[:aes.BlockSize]).CryptBlocks(data, data)
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func TestKeyringDecryptLegacy(t *testing.T) {
+	legacy := encryptLegacy(t, testKeyV2, "pay-1")
+
+	k := newTestKeyring(t, map[int]string{1: testKeyV1}, 1, testKeyV2)
+	if plaintext, err := k.DecryptLegacy(legacy); err != nil || plaintext != "pay-1" {
+		t.Errorf("DecryptLegacy() = %q, %v, want pay-1", plaintext, err)
+	}
+	if _, err := k.DecryptLegacy("not base64!"); !errors.Is(err, ErrMalformed) {
+		t.Errorf("DecryptLegacy() of a malformed payload = %v, want %v", err, ErrMalformed)
+	}
+
+	withoutLegacy := newTestKeyring(t, map[int]string{1: testKeyV1}, 1, "")
+	if _, err := withoutLegacy.DecryptLegacy(legacy); !errors.Is(err, ErrNoLegacyKey) {
+		t.Errorf("DecryptLegacy() without a legacy key = %v, want %v", err, ErrNoLegacyKey)
+	}
+}
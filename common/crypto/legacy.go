@@ -0,0 +1,39 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+)
+
+var ErrNoLegacyKey = errors.New("crypto: legacy payload without a legacy key")
+
+// DecryptLegacy reads the base64 AES-CBC payloads of the former utils.Encrypt, which used the first block of the
+// key as IV and PKCS5 padding. Nothing is written in this format anymore and the payloads carry no prefix, so only
+// callers knowing they hold one read it.
+func (k *Keyring) DecryptLegacy(ciphertext string) (string, error) {
+	if k.legacy == nil {
+		return "", ErrNoLegacyKey
+	}
+
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", ErrMalformed
+	}
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return "", ErrMalformed
+	}
+
+	block, err := aes.NewCipher(k.legacy)
+	if err != nil {
+		return "", err
+	}
+	cipher.NewCBCDecrypter(block, k.legacy[:aes.BlockSize]).CryptBlocks(data, data)
+
+	padding := int(data[len(data)-1])
+	if padding == 0 || padding > aes.BlockSize || padding > len(data) {
+		return "", ErrMalformed
+	}
+	return string(data[:len(data)-padding]), nil
+}
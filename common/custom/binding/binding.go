@@ -12,15 +12,18 @@ var (
 
 type CustomBinder struct{}
 
+// Bind binds the request to i and validates it, the pagination limits of Paginated requests included.
 func (cb *CustomBinder) Bind(c *gin.Context, i interface{}) error {
 	if err := c.ShouldBind(i); err != nil {
 		return err
 	}
 
-	if err := validate.ValidateStruct(i); err != nil {
-		return err
-	}
-	return nil
+	return Validate(i)
+}
+
+// Validate validates i like Bind does once the request is bound, for requests decoded elsewhere, e.g. from gRPC.
+func Validate(i interface{}) error {
+	return joinValidation(validate.ValidateStruct(i), checkLimit(i))
 }
 
 func GetBinding() *CustomBinder {
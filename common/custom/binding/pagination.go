@@ -0,0 +1,62 @@
+package binding
+
+import (
+	validate2 "build-service-gin/common/custom/validate"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+const (
+	// DefaultLimit is the limit of paginated requests sent without one.
+	DefaultLimit int64 = 20
+	// MaxLimit is the largest limit a paginated request may ask for.
+	MaxLimit int64 = 100
+)
+
+// Paginated is implemented by requests with a limit query parameter, Bind sets it to DefaultLimit when it is missing
+// and rejects it above MaxLimit.
+type Paginated interface {
+	PageLimit() *int64
+}
+
+// checkLimit applies the pagination limits to i when it is a Paginated request, possibly behind a pointer.
+func checkLimit(i interface{}) error {
+	paginated := asPaginated(i)
+	if paginated == nil {
+		return nil
+	}
+
+	limit := paginated.PageLimit()
+	if *limit == 0 {
+		*limit = DefaultLimit
+		return nil
+	}
+	return validate.ValidateVar("limit", *limit, fmt.Sprintf("min=1,max=%d", MaxLimit))
+}
+
+func asPaginated(i interface{}) Paginated {
+	for value := reflect.ValueOf(i); value.Kind() == reflect.Ptr && !value.IsNil(); value = value.Elem() {
+		if paginated, ok := value.Interface().(Paginated); ok {
+			return paginated
+		}
+	}
+	return nil
+}
+
+// joinValidation returns the field errors of both errors as one validate2.ValidationErrors, any other error is
+// returned first.
+func joinValidation(first, second error) error {
+	var firstErrs, secondErrs validate2.ValidationErrors
+	switch {
+	case first == nil:
+		return second
+	case second == nil:
+		return first
+	case !errors.As(first, &firstErrs):
+		return first
+	case !errors.As(second, &secondErrs):
+		return second
+	}
+	return append(firstErrs, secondErrs...)
+}
@@ -0,0 +1,99 @@
+package binding
+
+import (
+	validate2 "build-service-gin/common/custom/validate"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type pageReq struct {
+	ProfileID string `form:"profileID" validate:"omitempty,profile_id"`
+	Offset    int64  `form:"offset" validate:"min=0"`
+	Limit     int64  `form:"limit"`
+}
+
+func (r *pageReq) PageLimit() *int64 {
+	return &r.Limit
+}
+
+type unpagedReq struct {
+	Limit int64 `form:"limit"`
+}
+
+func TestBindPaginationBounds(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantLimit  int64
+		wantFields map[string]string
+	}{
+		{name: "missing limit", query: "", wantLimit: DefaultLimit},
+		{name: "zero limit", query: "limit=0", wantLimit: DefaultLimit},
+		{name: "smallest limit", query: "limit=1", wantLimit: 1},
+		{name: "largest limit", query: "limit=100", wantLimit: MaxLimit},
+		{name: "limit above the maximum", query: "limit=101", wantFields: map[string]string{"limit": "max"}},
+		{name: "negative limit", query: "limit=-1", wantFields: map[string]string{"limit": "min"}},
+		{
+			name:       "field errors joined",
+			query:      "limit=500&offset=-1&profileID=no%20spaces",
+			wantFields: map[string]string{"limit": "max", "offset": "min", "profileID": "profile_id"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/history?"+tt.query, nil)
+
+			req := &pageReq{}
+			err := GetBinding().Bind(c, req)
+			if tt.wantFields == nil {
+				if err != nil {
+					t.Fatalf("Bind() = %v", err)
+				}
+				if req.Limit != tt.wantLimit {
+					t.Errorf("Limit = %d, want %d", req.Limit, tt.wantLimit)
+				}
+				return
+			}
+
+			var errs validate2.ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("Bind() = %v, want ValidationErrors", err)
+			}
+			got := make(map[string]string, len(errs))
+			for _, e := range errs {
+				got[e.Field] = e.Rule
+			}
+			if !reflect.DeepEqual(got, tt.wantFields) {
+				t.Errorf("Bind() fields = %v, want %v", got, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestValidateLeavesUnpagedRequests(t *testing.T) {
+	req := &unpagedReq{Limit: 1000}
+	if err := Validate(req); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+	if req.Limit != 1000 {
+		t.Errorf("Limit = %d, want it untouched", req.Limit)
+	}
+}
+
+func TestValidatePointerToPaginatedRequest(t *testing.T) {
+	req := &pageReq{}
+	if err := Validate(&req); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+	if req.Limit != DefaultLimit {
+		t.Errorf("Limit = %d, want %d", req.Limit, DefaultLimit)
+	}
+}
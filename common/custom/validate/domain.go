@@ -0,0 +1,80 @@
+package validate
+
+import (
+	"build-service-gin/internal/domains"
+	"build-service-gin/pkg/helpers/constants"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ProfileIDPattern is the format of profile and VGA user IDs.
+var ProfileIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$`)
+
+// isMoney validates money=<decimals>, a positive amount with at most decimals digits after the point.
+func isMoney(fl validator.FieldLevel) bool {
+	var amount float64
+	switch fl.Field().Kind() {
+	case reflect.Float32, reflect.Float64:
+		amount = fl.Field().Float()
+	default:
+		return false
+	}
+	if amount <= 0 {
+		return false
+	}
+
+	decimals, err := strconv.Atoi(fl.Param())
+	if err != nil {
+		return false
+	}
+	formatted := strconv.FormatFloat(amount, 'f', -1, 64)
+	if point := strings.IndexByte(formatted, '.'); point >= 0 {
+		return len(formatted)-point-1 <= decimals
+	}
+	return true
+}
+
+func isSourceType(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	for _, sourceType := range constants.SourceTypes {
+		if sourceType == value {
+			return true
+		}
+	}
+	return false
+}
+
+func isTxType(fl validator.FieldLevel) bool {
+	return domains.IsValidTxType(fl.Field().String())
+}
+
+func isTxStatus(fl validator.FieldLevel) bool {
+	return domains.IsValidTxStatus(domains.NormalizeTxStatus(fl.Field().String()))
+}
+
+func isProfileID(fl validator.FieldLevel) bool {
+	return ProfileIDPattern.MatchString(fl.Field().String())
+}
+
+// requiredIfFold validates required_if_fold=<Field> <value>, the field is required when the sibling Field equals
+// value ignoring case and surrounding spaces, e.g. a status sent in lower case.
+func requiredIfFold(fl validator.FieldLevel) bool {
+	params := strings.Fields(fl.Param())
+	if len(params) != 2 {
+		return false
+	}
+
+	parent := reflect.Indirect(fl.Parent())
+	other := parent.FieldByName(params[0])
+	if !other.IsValid() || other.Kind() != reflect.String {
+		return false
+	}
+	if !strings.EqualFold(strings.TrimSpace(other.String()), params[1]) {
+		return true
+	}
+	return !fl.Field().IsZero()
+}
@@ -0,0 +1,33 @@
+package validate
+
+import (
+	"build-service-gin/pkg/helpers/resp"
+	"sort"
+	"strings"
+)
+
+// ruleUnknown is the rule broken by keys of ValidateMap without a rule.
+const ruleUnknown = "unknown"
+
+// ValidationErrors lists every field that failed validation. resp.WrapError copies it to the Fields of the error,
+// rendered as the errors array of the response.
+type ValidationErrors []resp.FieldError
+
+// Error joins the messages of the fields with " | ".
+func (errs ValidationErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, e := range errs {
+		messages[i] = e.Message
+	}
+	return strings.Join(messages, " | ")
+}
+
+func (errs ValidationErrors) FieldErrors() []resp.FieldError {
+	return errs
+}
+
+// sorted orders errs by field, the errors of ValidateMap come out of a map.
+func (errs ValidationErrors) sorted() ValidationErrors {
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return errs
+}
@@ -1,6 +1,8 @@
 package validate
 
 import (
+	"build-service-gin/pkg/helpers/resp"
+	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
@@ -48,37 +50,64 @@ func (v *Validate) registerCustomValidations() {
 		_, err := time.Parse("2006-01-02", dateStr)
 		return err == nil
 	})
+
+	// Domain rules of the point requests
+	v.validate.RegisterAlias("currency", "iso4217")
+	v.validate.RegisterValidation("money", isMoney)
+	v.validate.RegisterValidation("source_type", isSourceType)
+	v.validate.RegisterValidation("tx_type", isTxType)
+	v.validate.RegisterValidation("tx_status", isTxStatus)
+	v.validate.RegisterValidation("profile_id", isProfileID)
+	v.validate.RegisterValidation("required_if_fold", requiredIfFold, true)
 }
 
+// ValidateStruct validates obj by its validate tags, the failures are returned as ValidationErrors. obj may be a
+// pointer to the pointer handlers bind to.
 func (v *Validate) ValidateStruct(obj interface{}) error {
-	err := v.validate.Struct(obj)
-	if err != nil {
-		err = v.customError(err.(validator.ValidationErrors), "")
+	value := reflect.ValueOf(obj)
+	for value.Kind() == reflect.Ptr && value.Elem().Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+
+	err := v.validate.Struct(value.Interface())
+	var errs validator.ValidationErrors
+	if errors.As(err, &errs) {
+		return v.customError(errs, "")
+	}
+	return err
+}
+
+// ValidateVar validates value by tag, the failures are returned as ValidationErrors of the field called field.
+func (v *Validate) ValidateVar(field string, value interface{}, tag string) error {
+	err := v.validate.Var(value, tag)
+	var errs validator.ValidationErrors
+	if errors.As(err, &errs) {
+		return v.customError(errs, field)
 	}
 	return err
 }
 
+// ValidateMap validates data by the rules of its keys, keys without a rule fail with the rule unknown. The failures
+// are returned as ValidationErrors.
 func (v *Validate) ValidateMap(data, rules map[string]interface{}) error {
-	for key, _ := range data {
+	var errs ValidationErrors
+	for key := range data {
 		if _, ok := rules[key]; !ok {
-			return fmt.Errorf("key %v in programData not register", key)
+			errs = append(errs, resp.FieldError{
+				Field:   key,
+				Rule:    ruleUnknown,
+				Message: fmt.Sprintf("key %v in programData not register", key),
+			})
 		}
 	}
-
-	errs := v.validate.ValidateMap(data, rules)
-
 	if len(errs) > 0 {
-		errCustom := ""
-		for fieldName, e := range errs {
-			errCustom += v.customError(e.(validator.ValidationErrors), fieldName).Error() + " | "
-		}
-
-		n := len(errCustom)
-		if n > 0 {
-			return fmt.Errorf(errCustom[:n-3])
-		}
+		return errs.sorted()
 	}
 
+	errs = v.mapErrors(v.validate.ValidateMap(data, rules), "")
+	if len(errs) > 0 {
+		return errs.sorted()
+	}
 	return nil
 }
 
@@ -86,34 +115,61 @@ func (v *Validate) RegisterStructValidation(fn validator.StructLevelFunc, valida
 	v.validate.RegisterStructValidation(fn, validateStruct)
 }
 
-func (v *Validate) customError(errs validator.ValidationErrors, fieldName string) error {
-	if len(errs) > 0 {
-		errCustom := ""
-		for _, e := range errs {
-			errCustom += v.mapValidatorError(e, fieldName) + " | "
+// mapErrors flattens the errors of ValidateMap, nested maps are joined to their key with a dot.
+func (v *Validate) mapErrors(errs map[string]interface{}, prefix string) ValidationErrors {
+	var out ValidationErrors
+	for key, e := range errs {
+		fieldName := joinPath(prefix, key)
+		switch e := e.(type) {
+		case validator.ValidationErrors:
+			out = append(out, v.customError(e, fieldName)...)
+		case map[string]interface{}:
+			out = append(out, v.mapErrors(e, fieldName)...)
 		}
+	}
+	return out
+}
+
+func (v *Validate) customError(errs validator.ValidationErrors, fieldName string) ValidationErrors {
+	out := make(ValidationErrors, 0, len(errs))
+	for _, e := range errs {
+		field := fieldPath(e, fieldName)
+		out = append(out, resp.FieldError{
+			Field:   field,
+			Rule:    e.Tag(),
+			Param:   e.Param(),
+			Message: v.mapValidatorError(e, field),
+		})
+	}
+	return out
+}
 
-		n := len(errCustom)
-		if n > 0 {
-			return fmt.Errorf(errCustom[:n-3])
+// fieldPath is the JSON path of the field of e, the namespace without the name of the validated struct. Errors of
+// ValidateMap have no struct, their path is fieldName plus the index of the element that failed.
+func fieldPath(e validator.FieldError, fieldName string) string {
+	if fieldName != "" {
+		if ok, _ := regexp.MatchString(`^\[.*\]$`, e.Field()); ok {
+			return fieldName + e.Field()
 		}
+		return fieldName
 	}
 
-	return nil
+	namespace := e.Namespace()
+	if i := strings.IndexByte(namespace, '.'); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
 }
 
-// Define custom error message for each tag
-func (v *Validate) mapValidatorError(errMap validator.FieldError, fieldName string) string {
-	field := errMap.Field()
-	if field == "" {
-		field = fieldName
-	} else {
-		ok, _ := regexp.MatchString(`^\[.*\]$`, field)
-		if ok {
-			field = fieldName
-		}
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
 	}
+	return prefix + "." + key
+}
 
+// Define custom error message for each tag
+func (v *Validate) mapValidatorError(errMap validator.FieldError, field string) string {
 	switch errMap.Tag() {
 	case "required":
 		return fmt.Sprintf("%v is required", field)
@@ -141,18 +197,39 @@ func (v *Validate) mapValidatorError(errMap validator.FieldError, fieldName stri
 		return fmt.Sprintf("%v - invalid base64", field)
 	case "hexadecimal":
 		return fmt.Sprintf("%v - invalid hex string", field)
+	case "currency":
+		return fmt.Sprintf("%v - invalid currency: '%v'", field, errMap.Value())
+	case "money":
+		return fmt.Sprintf("%v - must be positive with at most %v decimals", field, errMap.Param())
+	case "source_type":
+		return fmt.Sprintf("%v - unknown source type: '%v'", field, errMap.Value())
+	case "tx_type":
+		return fmt.Sprintf("%v - unknown transaction type: '%v'", field, errMap.Value())
+	case "tx_status":
+		return fmt.Sprintf("%v - unknown transaction status: '%v'", field, errMap.Value())
+	case "profile_id":
+		return fmt.Sprintf("%v - invalid profile id", field)
+	case "required_with":
+		return fmt.Sprintf("%v is required with %v", field, errMap.Param())
+	case "required_without":
+		return fmt.Sprintf("%v is required without %v", field, errMap.Param())
+	case "required_if_fold":
+		return fmt.Sprintf("%v is required when %v", field, strings.Replace(errMap.Param(), " ", " = ", 1))
 	}
 
 	return errMap.Error()
 }
 
-// to use the names which json tag in struct, rather than normal Go field names
+// to use the names which json tag in struct, rather than normal Go field names. Fields bound from the query or the
+// path have no json name, their form or uri name is used instead.
 func (v *Validate) registerTagJson() {
 	v.validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
-		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
-		if name == "-" {
-			return ""
+		for _, tag := range []string{"json", "form", "uri"} {
+			name := strings.SplitN(fld.Tag.Get(tag), ",", 2)[0]
+			if name != "" && name != "-" {
+				return name
+			}
 		}
-		return name
+		return ""
 	})
 }
@@ -0,0 +1,172 @@
+package validate
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type testItem struct {
+	Amount   float64 `json:"amount" validate:"money=2"`
+	Currency string  `json:"currency" validate:"required,currency"`
+}
+
+type testOrder struct {
+	ProfileID string     `json:"profileID" validate:"required,profile_id"`
+	Status    string     `json:"status" validate:"omitempty,tx_status"`
+	Point     int64      `json:"point" validate:"required_if_fold=Status SUCCESS"`
+	Items     []testItem `json:"items" validate:"dive"`
+	Tags      []string   `json:"tags" validate:"dive,max=3"`
+	Month     int        `form:"month" validate:"min=0"`
+	Meta      testMeta   `json:"meta"`
+}
+
+type testMeta struct {
+	Source string `json:"source" validate:"omitempty,source_type"`
+}
+
+// fieldRules returns the field paths of err with the rule each broke.
+func fieldRules(t *testing.T, err error) map[string]string {
+	t.Helper()
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("error %v is %T, want ValidationErrors", err, err)
+	}
+	rules := make(map[string]string, len(errs))
+	for _, e := range errs {
+		rules[e.Field] = e.Rule
+	}
+	return rules
+}
+
+func TestValidateStructFieldPaths(t *testing.T) {
+	valid := func() testOrder {
+		return testOrder{
+			ProfileID: "profile-1",
+			Items:     []testItem{{Amount: 10.5, Currency: "USD"}, {Amount: 1, Currency: "VND"}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(o *testOrder)
+		want   map[string]string
+	}{
+		{name: "valid", mutate: func(o *testOrder) {}},
+		{name: "top level field", mutate: func(o *testOrder) { o.ProfileID = "" }, want: map[string]string{"profileID": "required"}},
+		{
+			name:   "element of a list of structs",
+			mutate: func(o *testOrder) { o.Items[1].Amount = 1.005 },
+			want:   map[string]string{"items[1].amount": "money"},
+		},
+		{
+			name: "several elements",
+			mutate: func(o *testOrder) {
+				o.Items[0].Currency = "usd"
+				o.Items[1].Currency = ""
+			},
+			want: map[string]string{"items[0].currency": "currency", "items[1].currency": "required"},
+		},
+		{name: "element of a list of strings", mutate: func(o *testOrder) { o.Tags = []string{"a", "long"} }, want: map[string]string{"tags[1]": "max"}},
+		{name: "query field by its form name", mutate: func(o *testOrder) { o.Month = -1 }, want: map[string]string{"month": "min"}},
+		{name: "nested struct", mutate: func(o *testOrder) { o.Meta.Source = "fax" }, want: map[string]string{"meta.source": "source_type"}},
+		{
+			name:   "required by a status in lower case",
+			mutate: func(o *testOrder) { o.Status = "success" },
+			want:   map[string]string{"point": "required_if_fold"},
+		},
+	}
+	v := NewValidate()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := valid()
+			tt.mutate(&order)
+
+			err := v.ValidateStruct(&order)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("ValidateStruct() = %v", err)
+				}
+				return
+			}
+			if got := fieldRules(t, err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateStruct() fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateStructThroughPointerToPointer(t *testing.T) {
+	order := &testOrder{}
+	err := NewValidate().ValidateStruct(&order)
+	if got := fieldRules(t, err); got["profileID"] != "required" {
+		t.Errorf("ValidateStruct() fields = %v, want profileID required", got)
+	}
+}
+
+func TestValidateVarFieldPaths(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		tag   string
+		want  map[string]string
+	}{
+		{name: "scalar", value: int64(101), tag: "min=1,max=100", want: map[string]string{"limit": "max"}},
+		{name: "element of a list", value: []string{"ok", ""}, tag: "dive,required", want: map[string]string{"limit[1]": "required"}},
+	}
+	v := NewValidate()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fieldRules(t, v.ValidateVar("limit", tt.value, tt.tag)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateVar() fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateMapFieldPaths(t *testing.T) {
+	rules := map[string]interface{}{
+		"name":  "required",
+		"codes": "dive,len=3",
+		"limits": map[string]interface{}{
+			"daily": "min=1",
+		},
+	}
+
+	tests := []struct {
+		name string
+		data map[string]interface{}
+		want []string
+	}{
+		{name: "valid", data: map[string]interface{}{"name": "a", "codes": []string{"abc"}, "limits": map[string]interface{}{"daily": 2}}},
+		{
+			name: "nested and listed",
+			data: map[string]interface{}{"name": "", "codes": []string{"abc", "ab"}, "limits": map[string]interface{}{"daily": 0}},
+			want: []string{"codes[1]", "limits.daily", "name"},
+		},
+		{name: "unknown key", data: map[string]interface{}{"name": "a", "extra": 1}, want: []string{"extra"}},
+	}
+	v := NewValidate()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.ValidateMap(tt.data, rules)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("ValidateMap() = %v", err)
+				}
+				return
+			}
+			var errs ValidationErrors
+			if !errors.As(err, &errs) {
+				t.Fatalf("ValidateMap() = %v, want ValidationErrors", err)
+			}
+			got := make([]string, len(errs))
+			for i, e := range errs {
+				got[i] = e.Field
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateMap() fields = %v, want %v, in order", got, tt.want)
+			}
+		})
+	}
+}
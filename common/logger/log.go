@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"build-service-gin/common/crypto"
 	"build-service-gin/common/utils"
 	"context"
 	"github.com/gin-gonic/gin"
@@ -17,7 +18,6 @@ import (
 var (
 	loggerInstance *Logger
 	mu             sync.RWMutex
-	keyEncrypt     *string
 )
 
 const (
@@ -51,10 +51,14 @@ func InitLog(serviceName string) {
 	loggerInstance = &Logger{lg}
 }
 
-func SetKeyEncrypt(key string) {
-	if keyEncrypt == nil {
-		keyEncrypt = &key
+// SetLevel changes the minimum level logged by every logger, e.g. debug or warn.
+func SetLevel(level string) error {
+	lvl, err := zerolog.ParseLevel(level)
+	if err != nil {
+		return err
 	}
+	zerolog.SetGlobalLevel(lvl)
+	return nil
 }
 
 func GetLogger() *Logger {
@@ -66,13 +70,14 @@ func GetLogger() *Logger {
 }
 
 func SetGinReqEncrLog(c *gin.Context, req interface{}) {
-	if keyEncrypt == nil || *keyEncrypt == "" {
+	keyring := crypto.Default()
+	if keyring == nil {
 		return
 	}
 
 	ctx := c.Request.Context()
 	if req != nil {
-		if newReq, err := utils.StructEncryptTagInterface(req, *keyEncrypt, utils.TagNameEncrypt, utils.TagValEncrypt); err == nil {
+		if newReq, err := utils.StructEncryptTagInterface(req, keyring, utils.TagNameEncrypt, utils.TagValEncrypt); err == nil {
 			if str, err := utils.AnyToString(newReq); err == nil {
 				ctx = context.WithValue(ctx, utils.KeyRequestBody, str)
 				c.Request = c.Request.WithContext(ctx)
@@ -82,7 +87,8 @@ func SetGinReqEncrLog(c *gin.Context, req interface{}) {
 }
 
 func SetGinRespEncrLog(c *gin.Context, resp interface{}) {
-	if keyEncrypt == nil || *keyEncrypt == "" {
+	keyring := crypto.Default()
+	if keyring == nil {
 		return
 	}
 
@@ -103,7 +109,7 @@ func SetGinRespEncrLog(c *gin.Context, resp interface{}) {
 				data = data.Elem()
 			}
 
-			if newRes, err := utils.InterfaceEncryptTagInterface(data.Interface(), *keyEncrypt, utils.TagNameEncrypt, utils.TagValEncrypt); err == nil {
+			if newRes, err := utils.InterfaceEncryptTagInterface(data.Interface(), keyring, utils.TagNameEncrypt, utils.TagValEncrypt); err == nil {
 				if str, err := utils.AnyToString(newRes); err == nil {
 					ctx = context.WithValue(ctx, utils.KeyResponseBody, str)
 					c.Request = c.Request.WithContext(ctx)
@@ -114,13 +120,14 @@ func SetGinRespEncrLog(c *gin.Context, resp interface{}) {
 }
 
 func Encrypt[T any](data T) (T, error) {
-	if keyEncrypt == nil || *keyEncrypt == "" {
+	keyring := crypto.Default()
+	if keyring == nil {
 		return data, nil
 	}
 
 	switch v := interface{}(data).(type) {
 	case string:
-		res, err := utils.Encrypt(v, *keyEncrypt)
+		res, err := keyring.Encrypt(v)
 		if err != nil {
 			return data, err
 		}
@@ -128,7 +135,7 @@ func Encrypt[T any](data T) (T, error) {
 		var result interface{} = res
 		return result.(T), nil
 	case *string:
-		res, err := utils.Encrypt(*v, *keyEncrypt)
+		res, err := keyring.Encrypt(*v)
 		if err != nil {
 			return data, err
 		}
@@ -137,20 +144,21 @@ func Encrypt[T any](data T) (T, error) {
 		return result.(T), nil
 	}
 
-	return utils.InterfaceEncryptTag(data, *keyEncrypt, utils.TagNameEncrypt, utils.TagValEncrypt)
+	return utils.InterfaceEncryptTag(data, keyring, utils.TagNameEncrypt, utils.TagValEncrypt)
 }
 
 func EncryptInterface(data interface{}) (interface{}, error) {
-	if keyEncrypt == nil || *keyEncrypt == "" {
+	keyring := crypto.Default()
+	if keyring == nil {
 		return data, nil
 	}
 
 	switch v := data.(type) {
 	case string:
-		return utils.Encrypt(v, *keyEncrypt)
+		return keyring.Encrypt(v)
 	case *string:
-		return utils.Encrypt(*v, *keyEncrypt)
+		return keyring.Encrypt(*v)
 	}
 
-	return utils.InterfaceEncryptTagInterface(data, *keyEncrypt, utils.TagNameEncrypt, utils.TagValEncrypt)
+	return utils.InterfaceEncryptTagInterface(data, keyring, utils.TagNameEncrypt, utils.TagValEncrypt)
 }
@@ -329,6 +329,25 @@ func (r *Repository[T]) DeleteOneDoc(ctx context.Context, opts ...*options.Delet
 	return r.Collection.DeleteOne(ctx, r.filter, opts...)
 }
 
+func (r *Repository[T]) FindOneAndDeleteDoc(ctx context.Context, opts ...*options.FindOneAndDeleteOptions) (*T, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	res := r.Collection.FindOneAndDelete(ctx, r.filter, opts...)
+	if res.Err() != nil {
+		return nil, res.Err()
+	}
+
+	var m T
+	err := res.Decode(&m)
+	if err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
 func (r *Repository[T]) DeleteManyDocs(ctx context.Context, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
 	if r.err != nil {
 		return nil, r.err
@@ -345,6 +364,25 @@ func (r *Repository[T]) DistinctDocs(ctx context.Context, fieldName string, opts
 	return r.Collection.Distinct(ctx, fieldName, r.filter, opts...)
 }
 
+// AggregateDocs runs pipeline on the documents matching the filter and decodes the results into results, a pointer
+// to a slice.
+func (r *Repository[T]) AggregateDocs(ctx context.Context, pipeline mongo.Pipeline, results interface{}, opts ...*options.AggregateOptions) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	if len(r.filter) > 0 {
+		pipeline = append(mongo.Pipeline{{{Key: "$match", Value: r.filter}}}, pipeline...)
+	}
+
+	cs, err := r.Collection.Aggregate(ctx, pipeline, opts...)
+	if err != nil {
+		return err
+	}
+
+	return cs.All(ctx, results)
+}
+
 func (r *Repository[T]) SetLimit(limit int64) *Repository[T] {
 	r.optsFind.Limit = &limit
 	return r
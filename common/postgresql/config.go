@@ -4,7 +4,7 @@ type PostgresqlConfig struct {
 	Host     string `env:"HOST,required,notEmpty"`
 	Port     int    `env:"PORT,required,notEmpty"`
 	Username string `env:"USERNAME,required,notEmpty"`
-	Password string `env:"PASSWORD,required,notEmpty"`
+	Password string `env:"PASSWORD,required,notEmpty" secret:"true"`
 	DBName   string `env:"DBNAME,required,notEmpty"`
 	LogLevel int    `env:"LOG_LEVEL,required,notEmpty"`
 }
@@ -7,8 +7,11 @@ import (
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"gorm.io/gorm/logger"
 )
 
@@ -18,8 +21,16 @@ type DatabasePostgresql struct {
 
 var (
 	dbStorage *gorm.DB
+
+	// password is read by every new connection, SetPassword rotates it without a restart
+	password atomic.Pointer[string]
 )
 
+// SetPassword replaces the password of the connections opened from now on.
+func SetPassword(value string) {
+	password.Store(&value)
+}
+
 func ConnectPostgresql(ctx context.Context, cfg *PostgresqlConfig) (*DatabasePostgresql, error) {
 	if dbStorage != nil {
 		return &DatabasePostgresql{db: dbStorage}, nil
@@ -37,8 +48,18 @@ func ConnectPostgresql(ctx context.Context, cfg *PostgresqlConfig) (*DatabasePos
 		cfg.Host = cfg.Host[:len(cfg.Host)-1]
 	}
 
-	dsn := fmt.Sprintf("host=%v port=%v user=%v password=%v dbname=%v sslmode=disable", cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.DBName)
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+	dsn := fmt.Sprintf("host=%v port=%v user=%v dbname=%v sslmode=disable", cfg.Host, cfg.Port, cfg.Username, cfg.DBName)
+	connConfig, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	password.CompareAndSwap(nil, &cfg.Password)
+	conn := stdlib.OpenDB(*connConfig, stdlib.OptionBeforeConnect(func(_ context.Context, cc *pgx.ConnConfig) error {
+		cc.Password = *password.Load()
+		return nil
+	}))
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: conn}), &gorm.Config{
 		Logger: logger.Default.LogMode(func() logger.LogLevel {
 			switch cfg.LogLevel {
 			case int(logger.Silent):
@@ -24,10 +24,16 @@ func NewRepository[T ModelInterface](dbStorage *DatabasePostgresql) *Repository[
 		log.Fatal().Err(err).Msgf("create table_name=%s failed", t.TableName())
 	}
 
+	return NewRepositoryFromDB[T](dbStorage.db)
+}
+
+// NewRepositoryFromDB returns the repository of T on db without migrating its table.
+func NewRepositoryFromDB[T ModelInterface](db *gorm.DB) *Repository[T] {
+	var t T
 	return &Repository[T]{
-		DB:        dbStorage.db.Table(t.TableName()),
+		DB:        db.Table(t.TableName()),
 		tableName: t.TableName(),
-		db:        dbStorage.db,
+		db:        db,
 	}
 }
 
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-redsync/redsync/v4"
@@ -29,8 +30,30 @@ type Client struct {
 var (
 	instanceRedisClient *Client
 	onceRedisClient     sync.Once
+
+	// password is read by every new connection, SetPassword rotates it without a restart
+	password atomic.Pointer[string]
 )
 
+// NewClient returns a client of cfg. Its connections authenticate with the password last given to SetPassword, so a
+// rotated password applies to the connections opened after the rotation.
+func NewClient(cfg *RedisConfig) *redis.Client {
+	password.CompareAndSwap(nil, &cfg.Password)
+	user := cfg.User
+	return redis.NewClient(&redis.Options{
+		Addr: cfg.Addr,
+		DB:   0,
+		CredentialsProvider: func() (string, string) {
+			return user, *password.Load()
+		},
+	})
+}
+
+// SetPassword replaces the password of the connections opened from now on.
+func SetPassword(value string) {
+	password.Store(&value)
+}
+
 func ConnectRedis(ctx context.Context, cfg *RedisConfig) (*Client, error) {
 	log := logger.GetLogger()
 
@@ -42,12 +65,7 @@ func ConnectRedis(ctx context.Context, cfg *RedisConfig) (*Client, error) {
 	}
 
 	onceRedisClient.Do(func() {
-		redisClient := redis.NewClient(&redis.Options{
-			Addr:     cfg.Addr,
-			DB:       0,
-			Password: cfg.Password,
-			Username: cfg.User,
-		})
+		redisClient := NewClient(cfg)
 
 		_, err := redisClient.Ping(ctx).Result()
 		if err != nil {
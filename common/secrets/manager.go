@@ -0,0 +1,156 @@
+package secrets
+
+import (
+	"build-service-gin/common/logger"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// referencePattern matches ${secret:scheme://name} and ${secret:name}, the latter is read by the default provider.
+var referencePattern = regexp.MustCompile(`\$\{secret:([^}]+)\}`)
+
+// ChangeFunc receives the new value of a watched template.
+type ChangeFunc func(value string)
+
+type Manager struct {
+	defaultScheme string
+
+	mu        sync.RWMutex
+	providers map[string]Provider
+	watches   []*watch
+}
+
+type watch struct {
+	template string
+	value    string
+	fns      []ChangeFunc
+}
+
+func NewManager(defaultScheme string, providers ...Provider) *Manager {
+	m := &Manager{
+		defaultScheme: defaultScheme,
+		providers:     make(map[string]Provider),
+	}
+	for _, p := range providers {
+		m.Register(p)
+	}
+	return m
+}
+
+// Register adds or replaces the provider of its scheme.
+func (m *Manager) Register(p Provider) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.providers[p.Scheme()] = p
+}
+
+// SetDefaultScheme changes the provider reading the references without a scheme.
+func (m *Manager) SetDefaultScheme(scheme string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.defaultScheme = scheme
+}
+
+// HasReference reports whether value holds a ${secret:...} reference.
+func HasReference(value string) bool {
+	return referencePattern.MatchString(value)
+}
+
+// Resolve reads the secret of a scheme://name reference, or of a bare name from the default provider.
+func (m *Manager) Resolve(ctx context.Context, ref string) (string, error) {
+	m.mu.RLock()
+	scheme, name, ok := strings.Cut(ref, "://")
+	if !ok {
+		scheme, name = m.defaultScheme, ref
+	}
+	p, found := m.providers[scheme]
+	m.mu.RUnlock()
+	if !found {
+		return "", fmt.Errorf("secrets: no provider for scheme %q", scheme)
+	}
+	return p.Get(ctx, name)
+}
+
+// Expand replaces every ${secret:...} reference of template by its secret.
+func (m *Manager) Expand(ctx context.Context, template string) (string, error) {
+	var firstErr error
+	expanded := referencePattern.ReplaceAllStringFunc(template, func(match string) string {
+		ref := referencePattern.FindStringSubmatch(match)[1]
+		value, err := m.Resolve(ctx, ref)
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("resolve %s: %w", ref, err)
+		}
+		return value
+	})
+	if firstErr != nil {
+		return "", firstErr
+	}
+	return expanded, nil
+}
+
+// Watch calls fn whenever Refresh expands template to a different value than value.
+func (m *Manager) Watch(template, value string, fn ChangeFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, w := range m.watches {
+		if w.template == template {
+			w.fns = append(w.fns, fn)
+			return
+		}
+	}
+	m.watches = append(m.watches, &watch{template: template, value: value, fns: []ChangeFunc{fn}})
+}
+
+// Refresh expands every watched template again and notifies the changed ones. A secret failing to resolve keeps
+// its last value.
+func (m *Manager) Refresh(ctx context.Context) {
+	log := logger.GetLogger()
+
+	m.mu.RLock()
+	watches := append([]*watch(nil), m.watches...)
+	m.mu.RUnlock()
+
+	for _, w := range watches {
+		value, err := m.Expand(ctx, w.template)
+		if err != nil {
+			log.Warn().Err(err).Msg("refresh secret failed, keeping the previous value")
+			continue
+		}
+
+		m.mu.Lock()
+		changed := value != w.value
+		w.value = value
+		fns := append([]ChangeFunc(nil), w.fns...)
+		m.mu.Unlock()
+
+		if changed {
+			log.Info().Msg("secret rotated")
+			for _, fn := range fns {
+				fn(value)
+			}
+		}
+	}
+}
+
+// Start refreshes the watched secrets every interval until ctx is done.
+func (m *Manager) Start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Refresh(ctx)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	SchemeEnv  = "env"
+	SchemeFile = "file"
+)
+
+var ErrNotFound = errors.New("secrets: secret not found")
+
+// Provider reads secrets of one scheme. Vault-style backends are added by registering a Provider on the Manager.
+type Provider interface {
+	Scheme() string
+	Get(ctx context.Context, name string) (string, error)
+}
+
+// EnvProvider reads env://NAME from the environment variable NAME.
+type EnvProvider struct{}
+
+func (EnvProvider) Scheme() string {
+	return SchemeEnv
+}
+
+func (EnvProvider) Get(_ context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("%w: env %s", ErrNotFound, name)
+	}
+	return value, nil
+}
+
+// FileProvider reads file://path from a file, as mounted by Docker or Kubernetes secrets. Relative paths are read
+// from Dir and the trailing new line is dropped.
+type FileProvider struct {
+	Dir string
+}
+
+func (FileProvider) Scheme() string {
+	return SchemeFile
+}
+
+func (p FileProvider) Get(_ context.Context, name string) (string, error) {
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(p.Dir, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("%w: file %s", ErrNotFound, path)
+		}
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
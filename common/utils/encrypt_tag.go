@@ -1,13 +1,14 @@
 package utils
 
 import (
+	"build-service-gin/common/crypto"
 	"fmt"
 	"reflect"
 )
 
 // StructEncryptTag encrypts fields of a struct based on the tag `tagName:"tagVal"`
-func StructEncryptTag[T any](input T, key, tagName, tagVal string) (T, error) {
-	if key == "" {
+func StructEncryptTag[T any](input T, keyring *crypto.Keyring, tagName, tagVal string) (T, error) {
+	if keyring == nil {
 		return input, nil
 	}
 
@@ -50,7 +51,7 @@ func StructEncryptTag[T any](input T, key, tagName, tagVal string) (T, error) {
 		tag := t.Field(i).Tag.Get(tagName)
 
 		if tag == tagVal && field.Kind() == reflect.String {
-			encryptedValue, err := Encrypt(field.String(), key)
+			encryptedValue, err := keyring.Encrypt(field.String())
 			if err != nil {
 				return input, err
 			}
@@ -59,7 +60,7 @@ func StructEncryptTag[T any](input T, key, tagName, tagVal string) (T, error) {
 		}
 
 		if tag == tagVal && (field.Kind() == reflect.Ptr && field.Elem().Kind() == reflect.String) {
-			encryptedValue, err := Encrypt(field.Elem().String(), key)
+			encryptedValue, err := keyring.Encrypt(field.Elem().String())
 			if err != nil {
 				return input, err
 			}
@@ -68,7 +69,7 @@ func StructEncryptTag[T any](input T, key, tagName, tagVal string) (T, error) {
 		}
 
 		if field.Kind() == reflect.Struct {
-			encryptedField, err := StructEncryptTag(field.Interface(), key, tagName, tagVal)
+			encryptedField, err := StructEncryptTag(field.Interface(), keyring, tagName, tagVal)
 			if err != nil {
 				return input, err
 			}
@@ -77,7 +78,7 @@ func StructEncryptTag[T any](input T, key, tagName, tagVal string) (T, error) {
 		}
 
 		if field.Kind() == reflect.Ptr && field.Elem().Kind() == reflect.Struct {
-			encryptedField, err := StructEncryptTag(field.Elem().Interface(), key, tagName, tagVal)
+			encryptedField, err := StructEncryptTag(field.Elem().Interface(), keyring, tagName, tagVal)
 			if err != nil {
 				return input, err
 			}
@@ -93,8 +94,8 @@ func StructEncryptTag[T any](input T, key, tagName, tagVal string) (T, error) {
 }
 
 // StructSliceEncryptTag encrypts fields of a slice of struct based on the tag `tagName:"tagVal"`
-func StructSliceEncryptTag[T any](input T, key, tagName, tagVal string) (T, error) {
-	if key == "" {
+func StructSliceEncryptTag[T any](input T, keyring *crypto.Keyring, tagName, tagVal string) (T, error) {
+	if keyring == nil {
 		return input, nil
 	}
 
@@ -112,7 +113,7 @@ func StructSliceEncryptTag[T any](input T, key, tagName, tagVal string) (T, erro
 
 		// check if item is a struct
 		if item.Kind() == reflect.Struct {
-			encryptedItem, err := StructEncryptTag(item.Interface(), key, tagName, tagVal)
+			encryptedItem, err := StructEncryptTag(item.Interface(), keyring, tagName, tagVal)
 			if err != nil {
 				return input, err
 			}
@@ -122,7 +123,7 @@ func StructSliceEncryptTag[T any](input T, key, tagName, tagVal string) (T, erro
 
 		// check if item is a pointer struct
 		if item.Kind() == reflect.Ptr && item.Elem().Kind() == reflect.Struct {
-			encryptedItem, err := StructEncryptTag(item.Interface(), key, tagName, tagVal)
+			encryptedItem, err := StructEncryptTag(item.Interface(), keyring, tagName, tagVal)
 			if err != nil {
 				return input, err
 			}
@@ -134,8 +135,8 @@ func StructSliceEncryptTag[T any](input T, key, tagName, tagVal string) (T, erro
 }
 
 // InterfaceEncryptTag encrypts fields of a struct based on the tag `tagName:"tagVal"`
-func InterfaceEncryptTag[T any](input T, key, tagName, tagVal string) (T, error) {
-	if key == "" {
+func InterfaceEncryptTag[T any](input T, keyring *crypto.Keyring, tagName, tagVal string) (T, error) {
+	if keyring == nil {
 		return input, nil
 	}
 
@@ -143,7 +144,7 @@ func InterfaceEncryptTag[T any](input T, key, tagName, tagVal string) (T, error)
 
 	// check if input is a struct
 	if v.Kind() == reflect.Struct {
-		if result, err := StructEncryptTag(v.Interface(), key, tagName, tagVal); err != nil {
+		if result, err := StructEncryptTag(v.Interface(), keyring, tagName, tagVal); err != nil {
 			return input, err
 		} else {
 			return result.(T), nil
@@ -152,7 +153,7 @@ func InterfaceEncryptTag[T any](input T, key, tagName, tagVal string) (T, error)
 
 	// check if item is a pointer struct
 	if v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Struct {
-		if result, err := StructEncryptTag(v.Interface(), key, tagName, tagVal); err != nil {
+		if result, err := StructEncryptTag(v.Interface(), keyring, tagName, tagVal); err != nil {
 			return input, err
 		} else {
 			return result.(T), nil
@@ -161,7 +162,7 @@ func InterfaceEncryptTag[T any](input T, key, tagName, tagVal string) (T, error)
 
 	// check if input is a slice
 	if v.Kind() == reflect.Slice {
-		if result, err := StructSliceEncryptTag(v.Interface(), key, tagName, tagVal); err != nil {
+		if result, err := StructSliceEncryptTag(v.Interface(), keyring, tagName, tagVal); err != nil {
 			return input, err
 		} else {
 			return result.(T), nil
@@ -172,8 +173,8 @@ func InterfaceEncryptTag[T any](input T, key, tagName, tagVal string) (T, error)
 }
 
 // StructDecryptTag decrypts fields of a struct based on the tag `tagName:"tagVal"`
-func StructDecryptTag[T any](input T, key, tagName, tagVal string) (T, error) {
-	if key == "" {
+func StructDecryptTag[T any](input T, keyring *crypto.Keyring, tagName, tagVal string) (T, error) {
+	if keyring == nil {
 		return input, nil
 	}
 
@@ -216,7 +217,7 @@ func StructDecryptTag[T any](input T, key, tagName, tagVal string) (T, error) {
 		tag := t.Field(i).Tag.Get(tagName)
 
 		if tag == tagVal && field.Kind() == reflect.String {
-			encryptedValue, err := Decrypt(field.String(), key)
+			encryptedValue, err := keyring.Decrypt(field.String())
 			if err != nil {
 				return input, err
 			}
@@ -225,7 +226,7 @@ func StructDecryptTag[T any](input T, key, tagName, tagVal string) (T, error) {
 		}
 
 		if tag == tagVal && (field.Kind() == reflect.Ptr && field.Elem().Kind() == reflect.String) {
-			encryptedValue, err := Decrypt(field.Elem().String(), key)
+			encryptedValue, err := keyring.Decrypt(field.Elem().String())
 			if err != nil {
 				return input, err
 			}
@@ -234,7 +235,7 @@ func StructDecryptTag[T any](input T, key, tagName, tagVal string) (T, error) {
 		}
 
 		if field.Kind() == reflect.Struct {
-			encryptedField, err := StructDecryptTag(field.Interface(), key, tagName, tagVal)
+			encryptedField, err := StructDecryptTag(field.Interface(), keyring, tagName, tagVal)
 			if err != nil {
 				return input, err
 			}
@@ -243,7 +244,7 @@ func StructDecryptTag[T any](input T, key, tagName, tagVal string) (T, error) {
 		}
 
 		if field.Kind() == reflect.Ptr && field.Elem().Kind() == reflect.Struct {
-			encryptedField, err := StructDecryptTag(field.Elem().Interface(), key, tagName, tagVal)
+			encryptedField, err := StructDecryptTag(field.Elem().Interface(), keyring, tagName, tagVal)
 			if err != nil {
 				return input, err
 			}
@@ -259,8 +260,8 @@ func StructDecryptTag[T any](input T, key, tagName, tagVal string) (T, error) {
 }
 
 // StructSliceDecryptTag decrypts fields of a slice of struct based on the tag `tagName:"tagVal"`
-func StructSliceDecryptTag[T any](input T, key, tagName, tagVal string) (T, error) {
-	if key == "" {
+func StructSliceDecryptTag[T any](input T, keyring *crypto.Keyring, tagName, tagVal string) (T, error) {
+	if keyring == nil {
 		return input, nil
 	}
 
@@ -278,7 +279,7 @@ func StructSliceDecryptTag[T any](input T, key, tagName, tagVal string) (T, erro
 
 		// check if item is a struct
 		if item.Kind() == reflect.Struct {
-			encryptedItem, err := StructDecryptTag(item.Interface(), key, tagName, tagVal)
+			encryptedItem, err := StructDecryptTag(item.Interface(), keyring, tagName, tagVal)
 			if err != nil {
 				return input, err
 			}
@@ -288,7 +289,7 @@ func StructSliceDecryptTag[T any](input T, key, tagName, tagVal string) (T, erro
 
 		// check if item is a pointer struct
 		if item.Kind() == reflect.Ptr && item.Elem().Kind() == reflect.Struct {
-			encryptedItem, err := StructDecryptTag(item.Interface(), key, tagName, tagVal)
+			encryptedItem, err := StructDecryptTag(item.Interface(), keyring, tagName, tagVal)
 			if err != nil {
 				return input, err
 			}
@@ -300,8 +301,8 @@ func StructSliceDecryptTag[T any](input T, key, tagName, tagVal string) (T, erro
 }
 
 // InterfaceDecryptTag decrypts fields of a struct based on the tag `tagName:"tagVal"`
-func InterfaceDecryptTag[T any](input T, key, tagName, tagVal string) (T, error) {
-	if key == "" {
+func InterfaceDecryptTag[T any](input T, keyring *crypto.Keyring, tagName, tagVal string) (T, error) {
+	if keyring == nil {
 		return input, nil
 	}
 
@@ -309,7 +310,7 @@ func InterfaceDecryptTag[T any](input T, key, tagName, tagVal string) (T, error)
 
 	// check if input is a struct
 	if v.Kind() == reflect.Struct {
-		if result, err := StructDecryptTag(v.Interface(), key, tagName, tagVal); err != nil {
+		if result, err := StructDecryptTag(v.Interface(), keyring, tagName, tagVal); err != nil {
 			return input, err
 		} else {
 			return result.(T), nil
@@ -318,7 +319,7 @@ func InterfaceDecryptTag[T any](input T, key, tagName, tagVal string) (T, error)
 
 	// check if item is a pointer struct
 	if v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Struct {
-		if result, err := StructDecryptTag(v.Interface(), key, tagName, tagVal); err != nil {
+		if result, err := StructDecryptTag(v.Interface(), keyring, tagName, tagVal); err != nil {
 			return input, err
 		} else {
 			return result.(T), nil
@@ -327,7 +328,7 @@ func InterfaceDecryptTag[T any](input T, key, tagName, tagVal string) (T, error)
 
 	// check if input is a slice
 	if v.Kind() == reflect.Slice {
-		if result, err := StructSliceDecryptTag(v.Interface(), key, tagName, tagVal); err != nil {
+		if result, err := StructSliceDecryptTag(v.Interface(), keyring, tagName, tagVal); err != nil {
 			return input, err
 		} else {
 			return result.(T), nil
@@ -338,8 +339,8 @@ func InterfaceDecryptTag[T any](input T, key, tagName, tagVal string) (T, error)
 }
 
 // StructEncryptTagInterface encrypts fields of a struct based on the tag `tagName:"tagVal"`
-func StructEncryptTagInterface(input interface{}, key, tagName, tagVal string) (interface{}, error) {
-	if key == "" {
+func StructEncryptTagInterface(input interface{}, keyring *crypto.Keyring, tagName, tagVal string) (interface{}, error) {
+	if keyring == nil {
 		return input, nil
 	}
 
@@ -382,7 +383,7 @@ func StructEncryptTagInterface(input interface{}, key, tagName, tagVal string) (
 		tag := t.Field(i).Tag.Get(tagName)
 
 		if tag == tagVal && field.Kind() == reflect.String {
-			encryptedValue, err := Encrypt(field.String(), key)
+			encryptedValue, err := keyring.Encrypt(field.String())
 			if err != nil {
 				return input, err
 			}
@@ -391,7 +392,7 @@ func StructEncryptTagInterface(input interface{}, key, tagName, tagVal string) (
 		}
 
 		if tag == tagVal && (field.Kind() == reflect.Ptr && field.Elem().Kind() == reflect.String) {
-			encryptedValue, err := Encrypt(field.Elem().String(), key)
+			encryptedValue, err := keyring.Encrypt(field.Elem().String())
 			if err != nil {
 				return input, err
 			}
@@ -400,7 +401,7 @@ func StructEncryptTagInterface(input interface{}, key, tagName, tagVal string) (
 		}
 
 		if field.Kind() == reflect.Struct {
-			encryptedField, err := StructEncryptTagInterface(field.Interface(), key, tagName, tagVal)
+			encryptedField, err := StructEncryptTagInterface(field.Interface(), keyring, tagName, tagVal)
 			if err != nil {
 				return input, err
 			}
@@ -409,7 +410,7 @@ func StructEncryptTagInterface(input interface{}, key, tagName, tagVal string) (
 		}
 
 		if field.Kind() == reflect.Ptr && field.Elem().Kind() == reflect.Struct {
-			encryptedField, err := StructEncryptTagInterface(field.Elem().Interface(), key, tagName, tagVal)
+			encryptedField, err := StructEncryptTagInterface(field.Elem().Interface(), keyring, tagName, tagVal)
 			if err != nil {
 				return input, err
 			}
@@ -425,8 +426,8 @@ func StructEncryptTagInterface(input interface{}, key, tagName, tagVal string) (
 }
 
 // StructSliceEncryptTagInterface encrypts fields of a slice of struct based on the tag `tagName:"tagVal"`
-func StructSliceEncryptTagInterface(input interface{}, key, tagName, tagVal string) (interface{}, error) {
-	if key == "" {
+func StructSliceEncryptTagInterface(input interface{}, keyring *crypto.Keyring, tagName, tagVal string) (interface{}, error) {
+	if keyring == nil {
 		return input, nil
 	}
 
@@ -444,7 +445,7 @@ func StructSliceEncryptTagInterface(input interface{}, key, tagName, tagVal stri
 
 		// check if item is a struct
 		if item.Kind() == reflect.Struct {
-			encryptedItem, err := StructEncryptTag(item.Interface(), key, tagName, tagVal)
+			encryptedItem, err := StructEncryptTag(item.Interface(), keyring, tagName, tagVal)
 			if err != nil {
 				return input, err
 			}
@@ -454,7 +455,7 @@ func StructSliceEncryptTagInterface(input interface{}, key, tagName, tagVal stri
 
 		// check if item is a pointer struct
 		if item.Kind() == reflect.Ptr && item.Elem().Kind() == reflect.Struct {
-			encryptedItem, err := StructEncryptTag(item.Interface(), key, tagName, tagVal)
+			encryptedItem, err := StructEncryptTag(item.Interface(), keyring, tagName, tagVal)
 			if err != nil {
 				return input, err
 			}
@@ -466,8 +467,8 @@ func StructSliceEncryptTagInterface(input interface{}, key, tagName, tagVal stri
 }
 
 // InterfaceEncryptTagInterface encrypts fields of a struct based on the tag `tagName:"tagVal"`
-func InterfaceEncryptTagInterface(input interface{}, key, tagName, tagVal string) (interface{}, error) {
-	if key == "" {
+func InterfaceEncryptTagInterface(input interface{}, keyring *crypto.Keyring, tagName, tagVal string) (interface{}, error) {
+	if keyring == nil {
 		return input, nil
 	}
 
@@ -475,7 +476,7 @@ func InterfaceEncryptTagInterface(input interface{}, key, tagName, tagVal string
 
 	// check if input is a struct
 	if v.Kind() == reflect.Struct {
-		if result, err := StructEncryptTagInterface(v.Interface(), key, tagName, tagVal); err != nil {
+		if result, err := StructEncryptTagInterface(v.Interface(), keyring, tagName, tagVal); err != nil {
 			return input, err
 		} else {
 			return result, nil
@@ -484,7 +485,7 @@ func InterfaceEncryptTagInterface(input interface{}, key, tagName, tagVal string
 
 	// check if item is a pointer struct
 	if v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Struct {
-		if result, err := StructEncryptTagInterface(v.Interface(), key, tagName, tagVal); err != nil {
+		if result, err := StructEncryptTagInterface(v.Interface(), keyring, tagName, tagVal); err != nil {
 			return input, err
 		} else {
 			return result, nil
@@ -493,7 +494,7 @@ func InterfaceEncryptTagInterface(input interface{}, key, tagName, tagVal string
 
 	// check if input is a slice
 	if v.Kind() == reflect.Slice {
-		if result, err := StructSliceEncryptTagInterface(v.Interface(), key, tagName, tagVal); err != nil {
+		if result, err := StructSliceEncryptTagInterface(v.Interface(), keyring, tagName, tagVal); err != nil {
 			return input, err
 		} else {
 			return result, nil
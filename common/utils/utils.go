@@ -1,12 +1,7 @@
 package utils
 
 import (
-	"bytes"
 	"context"
-	"crypto/aes"
-	"crypto/cipher"
-	"encoding/base64"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"reflect"
@@ -28,11 +23,14 @@ const (
 	KeyResponseBody            = "response_body"
 	JwtSub                     = "sub"
 	JwtExp                     = "exp"
+	JwtType                    = "type"
+	KeyPrincipal               = "principal"
 	KeyEchoContextRequestBody  = "echo_context_request_body"
 	KeyEchoContextResponseBody = "echo_context_response_body"
 	KeyMongoMultiConnName      = "mongo_multi_conn_name"
 	KeyRegion                  = "X-Client-Region"
 	KeySignature               = "signature"
+	KeySignatureNonce          = "signature-nonce"
 	KeyXTicketId               = "X-Ticket-Id"
 )
 
@@ -54,12 +52,20 @@ const (
 )
 
 const (
-	VGREncryptKey = "VGR_ENCRYPT_KEY"
+	XIASCode     = "X-IAS-Code"
+	XRequestData = "X-Request-Data"
 )
 
 const (
-	XIASCode     = "X-IAS-Code"
-	XRequestData = "X-Request-Data"
+	HeaderXSignature          = "X-Signature"
+	HeaderXSignatureKeyID     = "X-Signature-Key-Id"
+	HeaderXSignatureTimestamp = "X-Signature-Timestamp"
+	HeaderXSignatureNonce     = "X-Signature-Nonce"
+)
+
+const (
+	HeaderLastEventID = "Last-Event-ID"
+	MIMEEventStream   = "text/event-stream"
 )
 
 type TraceInfo struct {
@@ -276,70 +282,3 @@ func BuildMessageFromJsonByte(jsonByte []byte, messageFields []string, sep strin
 
 	return strings.Join(msgVals, sep), nil
 }
-
-func Encrypt(plaintext, secretKeyHex string) (string, error) {
-	if plaintext == "" {
-		return "", nil
-	}
-
-	secretKey, err := hex.DecodeString(secretKeyHex)
-	if err != nil {
-		return "", err
-	}
-
-	iv := secretKey[:aes.BlockSize]
-
-	bPlaintext := PKCS5Padding([]byte(plaintext), aes.BlockSize)
-
-	block, err := aes.NewCipher(secretKey)
-	if err != nil {
-		return "", err
-	}
-
-	ciphertext := make([]byte, len(bPlaintext))
-	mode := cipher.NewCBCEncrypter(block, iv)
-	mode.CryptBlocks(ciphertext, bPlaintext)
-
-	// encode base64 and return
-	return base64.StdEncoding.EncodeToString(ciphertext), nil
-}
-
-func Decrypt(ciphertextBase64, secretKeyHex string) (string, error) {
-	if ciphertextBase64 == "" {
-		return "", nil
-	}
-
-	secretKey, err := hex.DecodeString(secretKeyHex)
-	if err != nil {
-		return "", err
-	}
-
-	ciphertextByte, err := base64.StdEncoding.DecodeString(ciphertextBase64)
-	if err != nil {
-		return "", err
-	}
-
-	iv := secretKey[:aes.BlockSize]
-
-	block, err := aes.NewCipher(secretKey)
-	if err != nil {
-		return "", err
-	}
-
-	mode := cipher.NewCBCDecrypter(block, iv)
-	mode.CryptBlocks(ciphertextByte, ciphertextByte)
-
-	return string(PKCS5UnPadding(ciphertextByte)), nil
-}
-
-func PKCS5Padding(ciphertext []byte, blockSize int) []byte {
-	padding := blockSize - len(ciphertext)%blockSize
-	padtext := bytes.Repeat([]byte{byte(padding)}, padding)
-	return append(ciphertext, padtext...)
-}
-
-func PKCS5UnPadding(src []byte) []byte {
-	length := len(src)
-	unpadding := int(src[length-1])
-	return src[:(length - unpadding)]
-}
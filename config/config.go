@@ -1,42 +1,56 @@
 package config
 
 import (
-	logger2 "build-service-gin/common/logger"
 	"build-service-gin/common/mongodb"
 	"build-service-gin/common/postgresql"
-	"github.com/caarlos0/env/v7"
+	"time"
 )
 
 type SystemConfig struct {
 	Env       string `env:"ENV,required,notEmpty"`
 	HttpPort  uint64 `env:"HTTP_PORT,required,notEmpty"`
 	ServiceID string `env:"SERVICE_ID,required,notEmpty"`
+	LogLevel  string `env:"LOG_LEVEL" envDefault:"info"`
 
 	KafkaConfig          KafkaConfig                 `envPrefix:"KAFKA_"`
 	KafkaTopicConfig     KafkaTopicConfig            `envPrefix:"TOPICS_"`
 	MongoDBConfig        mongodb.MongoDBConfig       `envPrefix:"MONGODB_"`
-	InternalToken        string                      `env:"INTERNAL_TOKEN,required,notEmpty"`
+	InternalToken        string                      `env:"INTERNAL_TOKEN,required,notEmpty" secret:"true"`
 	RewardIntegrationUrl string                      `env:"REWARD_INTEGRATION_URL,required,notEmpty"`
 	PostgresConfig       postgresql.PostgresqlConfig `envPrefix:"POSTGRES_"`
 	RedisConfig          RedisConfig                 `envPrefix:"REDIS_"`
+	WebhookConfig        WebhookConfig               `envPrefix:"WEBHOOK_"`
+	AuthConfig           AuthConfig                  `envPrefix:"AUTH_"`
+	RateLimitConfig      RateLimitConfig             `envPrefix:"RATE_LIMIT_"`
+	SignatureConfig      SignatureConfig             `envPrefix:"SIGNATURE_"`
+	CryptoConfig         CryptoConfig                `envPrefix:"CRYPTO_"`
+	SecretsConfig        SecretsConfig               `envPrefix:"SECRETS_"`
+	FeatureFlagConfig    FeatureFlagConfig           `envPrefix:"FEATURE_FLAG_"`
+	ReloadConfig         ReloadConfig                `envPrefix:"CONFIG_"`
+	OpenAPIConfig        OpenAPIConfig               `envPrefix:"OPENAPI_"`
+	GrpcConfig           GrpcConfig                  `envPrefix:"GRPC_"`
+	GraphQLConfig        GraphQLConfig               `envPrefix:"GRAPHQL_"`
+	StreamConfig         StreamConfig                `envPrefix:"STREAM_"`
+	HttpServerConfig     HttpServerConfig            `envPrefix:"HTTP_"`
+	HealthConfig         HealthConfig                `envPrefix:"HEALTH_"`
 }
 
 var configSingletonObj *SystemConfig
 
+// LoadConfig loads the configuration once, see Load, and keeps it as the process configuration.
 func LoadConfig() (cf *SystemConfig, err error) {
-	log := logger2.GetLogger()
-
 	if configSingletonObj != nil {
 		cf = configSingletonObj
 		return
 	}
 
-	cf = &SystemConfig{}
-	if err = env.Parse(cf); err != nil {
-		log.Fatal().Err(err).Msg("failed to unmarshal config")
+	cf, err = Load()
+	if err != nil {
+		return nil, err
 	}
 
 	configSingletonObj = cf
+	initReloadable(cf)
 	return
 }
 
@@ -46,17 +60,155 @@ func GetInstance() *SystemConfig {
 
 type RedisConfig struct {
 	Addr     string `env:"ADDRESS,required,notEmpty"`
-	Password string `env:"PASS,required,notEmpty"`
+	Password string `env:"PASS,required,notEmpty" secret:"true"`
 	User     string `env:"USER"`
 }
 
+type AuthConfig struct {
+	JWKSFile      string        `env:"JWKS_FILE"`
+	PublicKeyFile string        `env:"PUBLIC_KEY_FILE"`
+	HS256Secret   string        `env:"HS256_SECRET" secret:"true"`
+	Issuer        string        `env:"ISSUER"`
+	Audience      string        `env:"AUDIENCE"`
+	Leeway        time.Duration `env:"LEEWAY" envDefault:"30s"`
+}
+
+// RateLimitConfig overrides the limits of routes, Rules maps a route limit name to <limit>/<window>[/<key>], e.g.
+// RATE_LIMIT_RULES=point.create:10/1m/subject,history.read:120/1m.
+type RateLimitConfig struct {
+	Enabled bool              `env:"ENABLED" envDefault:"true"`
+	Rules   map[string]string `env:"RULES"`
+}
+
+// SignatureConfig holds the keys of signed requests. Inbound keys map a key id to an HMAC secret or to a base64
+// Ed25519 public key, e.g. SIGNATURE_HMAC_KEYS=partner-a:secret. The outbound key signs the orders posted to the
+// receiver, it is the raw HMAC secret or the base64 Ed25519 seed. Inbound requests are only verified once
+// SIGNATURE_ENABLED is set, along with their keys.
+type SignatureConfig struct {
+	Enabled           bool              `env:"ENABLED" envDefault:"false"`
+	HMACKeys          map[string]string `env:"HMAC_KEYS" secret:"true"`
+	Ed25519Keys       map[string]string `env:"ED25519_KEYS"`
+	MaxSkew           time.Duration     `env:"MAX_SKEW" envDefault:"5m"`
+	NonceTTL          time.Duration     `env:"NONCE_TTL" envDefault:"10m"`
+	OutboundAlgorithm string            `env:"OUTBOUND_ALGORITHM" envDefault:"HMAC-SHA256"`
+	OutboundKey       string            `env:"OUTBOUND_KEY" secret:"true"`
+}
+
+// CryptoConfig holds the keys encrypting log and outbound request fields and the columns encrypted at rest. Keys maps
+// a key version to a hex 32 bytes key, e.g. CRYPTO_KEYS=1:<hex>,2:<hex>. Values are written with EncryptVersion and
+// read with any version, encryption is disabled without keys. LegacyKey is the former VGR_ENCRYPT_KEY, kept to
+// decrypt old payloads.
+type CryptoConfig struct {
+	Keys           map[int]string `env:"KEYS" secret:"true"`
+	EncryptVersion int            `env:"ENCRYPT_VERSION" envDefault:"1"`
+	LegacyKey      string         `env:"LEGACY_KEY" secret:"true"`
+}
+
+// OpenAPIConfig serves the OpenAPI document of the routes and the Swagger UI reading it when Enabled.
+type OpenAPIConfig struct {
+	Enabled bool `env:"ENABLED" envDefault:"true"`
+}
+
+// GraphQLConfig serves the GraphQL read API when Enabled. Queries nested deeper than MaxDepth or costing more than
+// MaxComplexity are rejected before they run, a profiles query reads at most MaxProfiles profiles.
+type GraphQLConfig struct {
+	Enabled       bool `env:"ENABLED" envDefault:"true"`
+	MaxDepth      int  `env:"MAX_DEPTH" envDefault:"8"`
+	MaxComplexity int  `env:"MAX_COMPLEXITY" envDefault:"1000"`
+	MaxProfiles   int  `env:"MAX_PROFILES" envDefault:"50"`
+}
+
+// StreamConfig is the stream of the point updates of profiles. Events are fanned out to every instance on Channel and
+// the last BufferSize events of a profile are kept for BufferTTL so that clients resume after a reconnect. Every
+// instance serves at most MaxConnections streams, MaxProfileConnections of them for the same profile, each sent a
+// heartbeat every HeartbeatInterval.
+type StreamConfig struct {
+	Channel               string        `env:"CHANNEL" envDefault:"profile-stream"`
+	BufferSize            int64         `env:"BUFFER_SIZE" envDefault:"100"`
+	BufferTTL             time.Duration `env:"BUFFER_TTL" envDefault:"10m"`
+	HeartbeatInterval     time.Duration `env:"HEARTBEAT_INTERVAL" envDefault:"15s"`
+	RetryInterval         time.Duration `env:"RETRY_INTERVAL" envDefault:"3s"`
+	MaxConnections        int           `env:"MAX_CONNECTIONS" envDefault:"1000"`
+	MaxProfileConnections int           `env:"MAX_PROFILE_CONNECTIONS" envDefault:"5"`
+}
+
+// HttpServerConfig bounds the time a connection may take on each step of a request, a zero timeout is no limit.
+// The server serves TLS with TLSCertFile and TLSKeyFile, and asks the clients for a certificate signed by
+// TLSClientCAFile when set. On shutdown the health check fails for PreStopDelay before the server stops accepting
+// requests, then the running requests get ShutdownTimeout to finish. The metrics are served on AdminPort.
+type HttpServerConfig struct {
+	ReadTimeout       time.Duration `env:"READ_TIMEOUT" envDefault:"30s"`
+	ReadHeaderTimeout time.Duration `env:"READ_HEADER_TIMEOUT" envDefault:"5s"`
+	WriteTimeout      time.Duration `env:"WRITE_TIMEOUT" envDefault:"30s"`
+	IdleTimeout       time.Duration `env:"IDLE_TIMEOUT" envDefault:"2m"`
+	TLSCertFile       string        `env:"TLS_CERT_FILE"`
+	TLSKeyFile        string        `env:"TLS_KEY_FILE"`
+	TLSClientCAFile   string        `env:"TLS_CLIENT_CA_FILE"`
+	PreStopDelay      time.Duration `env:"PRE_STOP_DELAY" envDefault:"5s"`
+	ShutdownTimeout   time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"15s"`
+	AdminPort         uint64        `env:"ADMIN_PORT" envDefault:"9100"`
+}
+
+// HealthConfig checks the dependencies every Interval, each check bounded by its entry in Timeouts, keyed by the
+// name of the dependency, or by Timeout. A state older than CacheTTL is unknown, the checks stopped running.
+type HealthConfig struct {
+	Interval time.Duration            `env:"INTERVAL" envDefault:"10s"`
+	Timeout  time.Duration            `env:"TIMEOUT" envDefault:"2s"`
+	Timeouts map[string]time.Duration `env:"TIMEOUTS"`
+	CacheTTL time.Duration            `env:"CACHE_TTL" envDefault:"30s"`
+}
+
+// GrpcConfig serves the profile and point operations over gRPC on Port next to the HTTP server when Enabled,
+// Reflection lets tools like grpcurl list the services.
+type GrpcConfig struct {
+	Enabled    bool   `env:"ENABLED" envDefault:"false"`
+	Port       uint64 `env:"PORT" envDefault:"9090"`
+	Reflection bool   `env:"REFLECTION" envDefault:"false"`
+}
+
+// FeatureFlagConfig picks where the feature flags are kept, mongo or file. Every instance refreshes its flags each
+// RefreshInterval and as soon as a change is published on Channel.
+type FeatureFlagConfig struct {
+	Source          string        `env:"SOURCE" envDefault:"mongo"`
+	File            string        `env:"FILE" envDefault:"config/files/flags.yaml"`
+	RefreshInterval time.Duration `env:"REFRESH_INTERVAL" envDefault:"1m"`
+	Channel         string        `env:"CHANNEL" envDefault:"feature-flags"`
+}
+
+type WebhookConfig struct {
+	Timeout              time.Duration `env:"TIMEOUT" envDefault:"10s"`
+	MaxAttempts          int           `env:"MAX_ATTEMPTS" envDefault:"5"`
+	BackoffBase          time.Duration `env:"BACKOFF_BASE" envDefault:"2s"`
+	BackoffMax           time.Duration `env:"BACKOFF_MAX" envDefault:"5m"`
+	DisableAfterFailures int           `env:"DISABLE_AFTER_FAILURES" envDefault:"10"`
+	MaxResponseBodySize  int           `env:"MAX_RESPONSE_BODY_SIZE" envDefault:"4096"`
+	// RetryInterval is how often the deliveries whose next attempt is due are looked for, DeliveryLease how long
+	// an instance owns a delivery it attempts before another instance may attempt it again.
+	RetryInterval time.Duration `env:"RETRY_INTERVAL" envDefault:"5s"`
+	DeliveryLease time.Duration `env:"DELIVERY_LEASE" envDefault:"1m"`
+	// AllowPrivateTargets lets subscriptions target loopback and private addresses, for local development only.
+	AllowPrivateTargets bool `env:"ALLOW_PRIVATE_TARGETS" envDefault:"false"`
+	// CAFile adds the certificates of a PEM bundle to the system roots the certificates of the subscribers are
+	// verified with.
+	CAFile string `env:"CA_FILE"`
+}
+
 type KafkaConfig struct {
 	BootstrapServers string `env:"BOOTSTRAP_SERVERS"`
 	GroupID          string `env:"GROUP_ID"`
 	AutoOffsetReset  string `env:"AUTO_OFFSET_RESET"`
+	ConsumersEnabled bool   `env:"CONSUMERS_ENABLED"`
 }
 
 type KafkaTopicConfig struct {
 	TopicsRewardsPoint                string `env:"REWARDS_POINT,required,notEmpty"`
 	TopicsCoreTransactionPointSuccess string `env:"CORE_TRANSACTION_POINT_SUCCESS,required,notEmpty"`
+	TopicsRefundPoint                 string `env:"REFUND_POINT"`
+
+	// Outgoing domain events, an empty topic disables the event.
+	TopicsTransactionCreated   string `env:"TRANSACTION_CREATED"`
+	TopicsTransactionCompleted string `env:"TRANSACTION_COMPLETED"`
+	TopicsTransactionUpdated   string `env:"TRANSACTION_UPDATED"`
+	TopicsTransactionDeleted   string `env:"TRANSACTION_DELETED"`
+	TopicsBalanceChanged       string `env:"BALANCE_CHANGED"`
 }
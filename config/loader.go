@@ -0,0 +1,234 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caarlos0/env/v7"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	envName       = "ENV"
+	envConfigDir  = "CONFIG_DIR"
+	envConfigFile = "CONFIG_FILE"
+
+	defaultConfigDir = "config/files"
+)
+
+var configFileExtensions = []string{".yaml", ".yml", ".toml"}
+
+type ReloadConfig struct {
+	Dir            string        `env:"DIR" envDefault:"config/files"`
+	File           string        `env:"FILE"`
+	ReloadInterval time.Duration `env:"RELOAD_INTERVAL" envDefault:"30s"`
+}
+
+// overrides are the KEY=VALUE pairs given by -set, they win over every other layer.
+type overrides map[string]string
+
+func (o overrides) String() string {
+	keys := make([]string, 0, len(o))
+	for key := range o {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+func (o overrides) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("%q must look like KEY=VALUE", value)
+	}
+	o[key] = val
+	return nil
+}
+
+var (
+	flagFile      string
+	flagOverrides = make(overrides)
+
+	loadedFileMu sync.RWMutex
+	loadedFile   string
+)
+
+// RegisterFlags adds -config, the configuration file overriding the one picked by ENV, and -set KEY=VALUE, which
+// overrides a single variable, to fs. Call it before fs is parsed.
+func RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&flagFile, "config", "", "configuration file, defaults to $CONFIG_DIR/$ENV.{yaml,yml,toml}")
+	fs.Var(flagOverrides, "set", "configuration override as KEY=VALUE, may be repeated")
+}
+
+// Load builds the configuration from its layers, lowest first: the envDefault tags, the file of ENV, the
+// environment and the -set flags. The ${secret:...} references are resolved and the result is validated. Unlike
+// LoadConfig it does not touch the process configuration.
+func Load() (*SystemConfig, error) {
+	environment, file, err := layeredEnvironment()
+	if err != nil {
+		return nil, err
+	}
+
+	cf := &SystemConfig{}
+	if err = env.Parse(cf, env.Options{Environment: environment}); err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+
+	if err = resolveSecrets(context.Background(), cf); err != nil {
+		return nil, err
+	}
+
+	if err = cf.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	loadedFileMu.Lock()
+	loadedFile = file
+	loadedFileMu.Unlock()
+	return cf, nil
+}
+
+// LoadedFile is the configuration file of the last Load, empty when none was found.
+func LoadedFile() string {
+	loadedFileMu.RLock()
+	defer loadedFileMu.RUnlock()
+	return loadedFile
+}
+
+// layeredEnvironment merges the layers into the variables env.Parse reads.
+func layeredEnvironment() (map[string]string, string, error) {
+	processEnv := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			processEnv[key] = value
+		}
+	}
+
+	lookup := func(key string) string {
+		if value, ok := flagOverrides[key]; ok {
+			return value
+		}
+		return processEnv[key]
+	}
+
+	file, err := configFile(lookup)
+	if err != nil {
+		return nil, "", err
+	}
+
+	environment := make(map[string]string)
+	if file != "" {
+		fileVars, err := readConfigFile(file)
+		if err != nil {
+			return nil, "", err
+		}
+		for key, value := range fileVars {
+			environment[key] = value
+		}
+	}
+	for key, value := range processEnv {
+		environment[key] = value
+	}
+	for key, value := range flagOverrides {
+		environment[key] = value
+	}
+	return environment, file, nil
+}
+
+// configFile picks -config, then CONFIG_FILE, then the first $CONFIG_DIR/$ENV file found. An explicit file has to
+// exist, the file of ENV is optional.
+func configFile(lookup func(string) string) (string, error) {
+	explicit := flagFile
+	if explicit == "" {
+		explicit = lookup(envConfigFile)
+	}
+	if explicit != "" {
+		if _, err := os.Stat(explicit); err != nil {
+			return "", fmt.Errorf("config file: %w", err)
+		}
+		return explicit, nil
+	}
+
+	envValue := lookup(envName)
+	if envValue == "" {
+		return "", nil
+	}
+	dir := lookup(envConfigDir)
+	if dir == "" {
+		dir = defaultConfigDir
+	}
+	for _, ext := range configFileExtensions {
+		path := filepath.Join(dir, envValue+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", nil
+}
+
+// readConfigFile flattens a YAML or TOML file into variables: nested keys are joined by _ and upper cased, so
+// rate_limit.rules sets RATE_LIMIT_RULES. Lists are joined by commas.
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]interface{}
+	switch filepath.Ext(path) {
+	case ".toml":
+		err = toml.Unmarshal(data, &doc)
+	default:
+		err = yaml.Unmarshal(data, &doc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	vars := make(map[string]string)
+	if err = flatten(vars, "", doc); err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	return vars, nil
+}
+
+func flatten(vars map[string]string, prefix string, value interface{}) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			name := strings.ToUpper(key)
+			if prefix != "" {
+				name = prefix + "_" + name
+			}
+			if err := flatten(vars, name, child); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			if _, nested := item.(map[string]interface{}); nested {
+				return fmt.Errorf("%s: lists of tables are not supported", prefix)
+			}
+			items = append(items, fmt.Sprint(item))
+		}
+		vars[prefix] = strings.Join(items, ",")
+	case nil:
+		vars[prefix] = ""
+	default:
+		if prefix == "" {
+			return errors.New("the file must hold a table of settings")
+		}
+		vars[prefix] = fmt.Sprint(v)
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// baseEnvironment sets the required variables, so that Load succeeds without a file, and points CONFIG_DIR at an
+// empty directory.
+func baseEnvironment(t *testing.T) {
+	t.Helper()
+	vars := map[string]string{
+		"ENV":                                   "test",
+		"CONFIG_DIR":                            t.TempDir(),
+		"HTTP_PORT":                             "3004",
+		"SERVICE_ID":                            "build-service-gin",
+		"INTERNAL_TOKEN":                        "internal-token",
+		"REWARD_INTEGRATION_URL":                "https://reward.example.com",
+		"REDIS_ADDRESS":                         "localhost:6379",
+		"REDIS_PASS":                            "redis-pass",
+		"TOPICS_REWARDS_POINT":                  "rewards_point",
+		"TOPICS_CORE_TRANSACTION_POINT_SUCCESS": "core_transaction_point_success",
+		"MONGODB_DATABASE_URI":                  "mongodb://localhost:27017",
+		"MONGODB_DATABASE_NAME":                 "build-service-gin",
+		"POSTGRES_HOST":                         "localhost",
+		"POSTGRES_PORT":                         "5432",
+		"POSTGRES_USERNAME":                     "postgres",
+		"POSTGRES_PASSWORD":                     "postgres-pass",
+		"POSTGRES_DBNAME":                       "postgres",
+		"POSTGRES_LOG_LEVEL":                    "1",
+		"AUTH_HS256_SECRET":                     "hs256-secret",
+	}
+	for key, value := range vars {
+		t.Setenv(key, value)
+	}
+	setenv(t, "CONFIG_FILE", "")
+	setenv(t, "LOG_LEVEL", "")
+}
+
+// setenv sets key for the test, an empty value unsets it. The previous value is restored by the cleanup of Setenv.
+func setenv(t *testing.T, key, value string) {
+	t.Helper()
+	t.Setenv(key, value)
+	if value == "" {
+		if err := os.Unsetenv(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// setFlags replaces the -config and -set flags for the test.
+func setFlags(t *testing.T, file string, sets map[string]string) {
+	t.Helper()
+	previousFile, previousOverrides := flagFile, flagOverrides
+	t.Cleanup(func() { flagFile, flagOverrides = previousFile, previousOverrides })
+
+	flagFile, flagOverrides = file, make(overrides)
+	for key, value := range sets {
+		flagOverrides[key] = value
+	}
+}
+
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadLayerPrecedence(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		env  string
+		flag string
+		want string
+	}{
+		{name: "envDefault", want: "info"},
+		{name: "file over envDefault", file: "debug", want: "debug"},
+		{name: "env over file", file: "debug", env: "warn", want: "warn"},
+		{name: "flag over env and file", file: "debug", env: "warn", flag: "error", want: "error"},
+		{name: "flag over file", file: "debug", flag: "error", want: "error"},
+		{name: "flag over envDefault", flag: "trace", want: "trace"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseEnvironment(t)
+			setFlags(t, "", nil)
+			if tt.file != "" {
+				writeConfigFile(t, filepath.Join(os.Getenv("CONFIG_DIR"), "test.yaml"), "log_level: "+tt.file+"\n")
+			}
+			setenv(t, "LOG_LEVEL", tt.env)
+			if tt.flag != "" {
+				flagOverrides["LOG_LEVEL"] = tt.flag
+			}
+
+			cf, err := Load()
+			if err != nil {
+				t.Fatalf("Load() = %v", err)
+			}
+			if cf.LogLevel != tt.want {
+				t.Errorf("LogLevel = %q, want %q", cf.LogLevel, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadPicksConfigFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		envFile    bool
+		configFile bool
+		flagFile   bool
+		want       string
+	}{
+		{name: "no file", want: ""},
+		{name: "file of ENV", envFile: true, want: "test.yaml"},
+		{name: "CONFIG_FILE over the file of ENV", envFile: true, configFile: true, want: "config-file.toml"},
+		{name: "-config over CONFIG_FILE", envFile: true, configFile: true, flagFile: true, want: "flag.yml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseEnvironment(t)
+			dir := os.Getenv("CONFIG_DIR")
+			setFlags(t, "", nil)
+			if tt.envFile {
+				writeConfigFile(t, filepath.Join(dir, "test.yaml"), "service_id: from-test-yaml\n")
+			}
+			if tt.configFile {
+				path := filepath.Join(dir, "config-file.toml")
+				writeConfigFile(t, path, "service_id = \"from-config-file-toml\"\n")
+				t.Setenv("CONFIG_FILE", path)
+			}
+			if tt.flagFile {
+				path := filepath.Join(dir, "flag.yml")
+				writeConfigFile(t, path, "service_id: from-flag-yml\n")
+				flagFile = path
+			}
+			setenv(t, "SERVICE_ID", "")
+
+			cf, err := Load()
+			if tt.want == "" {
+				// SERVICE_ID is required and only the files set it.
+				if err == nil {
+					t.Fatalf("Load() = %+v, want the missing SERVICE_ID error", cf.ServiceID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() = %v", err)
+			}
+			if got := filepath.Base(LoadedFile()); got != tt.want {
+				t.Errorf("LoadedFile() = %q, want %q", got, tt.want)
+			}
+			if want := "from-" + strings.NewReplacer(".", "-").Replace(tt.want); cf.ServiceID != want {
+				t.Errorf("ServiceID = %q, want %q", cf.ServiceID, want)
+			}
+		})
+	}
+}
+
+func TestLoadFlattensNestedKeys(t *testing.T) {
+	baseEnvironment(t)
+	setFlags(t, "", nil)
+	writeConfigFile(t, filepath.Join(os.Getenv("CONFIG_DIR"), "test.yaml"), `
+rate_limit:
+  enabled: false
+  rules:
+    - point.create:30/1m/subject
+    - history.read:240/1m/profile
+webhook:
+  max_attempts: 7
+`)
+
+	cf, err := Load()
+	if err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	if cf.RateLimitConfig.Enabled {
+		t.Error("RateLimitConfig.Enabled = true, want false")
+	}
+	if len(cf.RateLimitConfig.Rules) != 2 || cf.RateLimitConfig.Rules["history.read"] != "240/1m/profile" {
+		t.Errorf("RateLimitConfig.Rules = %v", cf.RateLimitConfig.Rules)
+	}
+	if cf.WebhookConfig.MaxAttempts != 7 {
+		t.Errorf("WebhookConfig.MaxAttempts = %d, want 7", cf.WebhookConfig.MaxAttempts)
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		missing bool
+		wantErr string
+	}{
+		{name: "missing explicit file", missing: true, wantErr: "config file"},
+		{name: "list of tables", content: "webhook:\n  - max_attempts: 1\n", wantErr: "lists of tables are not supported"},
+		{name: "not a table", content: "- debug\n", wantErr: "config file"},
+		{name: "malformed", content: "log_level: [debug\n", wantErr: "config file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseEnvironment(t)
+			path := filepath.Join(os.Getenv("CONFIG_DIR"), "explicit.yaml")
+			if !tt.missing {
+				writeConfigFile(t, path, tt.content)
+			}
+			setFlags(t, path, nil)
+
+			if _, err := Load(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOverridesSet(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantKey string
+		wantVal string
+		wantErr bool
+	}{
+		{value: "LOG_LEVEL=debug", wantKey: "LOG_LEVEL", wantVal: "debug"},
+		{value: "RATE_LIMIT_RULES=a:1/1m,b:2/1m", wantKey: "RATE_LIMIT_RULES", wantVal: "a:1/1m,b:2/1m"},
+		{value: "CRYPTO_LEGACY_KEY=", wantKey: "CRYPTO_LEGACY_KEY", wantVal: ""},
+		{value: "LOG_LEVEL", wantErr: true},
+		{value: "=debug", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			o := make(overrides)
+			err := o.Set(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Set(%q) = nil, want an error", tt.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Set(%q) = %v", tt.value, err)
+			}
+			if got, ok := o[tt.wantKey]; !ok || got != tt.wantVal {
+				t.Errorf("overrides = %v, want %s=%s", o, tt.wantKey, tt.wantVal)
+			}
+		})
+	}
+}
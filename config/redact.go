@@ -0,0 +1,70 @@
+package config
+
+import (
+	"net/url"
+	"reflect"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Redacted returns cf as nested maps keyed by field name, safe to show. Fields tagged secret:"true" and fields
+// resolved from ${secret:...} references are replaced by [REDACTED], passwords inside URLs are masked.
+func Redacted(cf *SystemConfig) map[string]interface{} {
+	secretMu.Lock()
+	paths := make(map[string]bool, len(secretTemplates))
+	for path := range secretTemplates {
+		paths[path] = true
+	}
+	secretMu.Unlock()
+
+	return redactStruct(reflect.ValueOf(cf).Elem(), "", paths)
+}
+
+func redactStruct(v reflect.Value, path string, secretPaths map[string]bool) map[string]interface{} {
+	out := make(map[string]interface{}, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldPath := field.Name
+		if path != "" {
+			fieldPath = path + "." + field.Name
+		}
+
+		value := v.Field(i)
+		switch {
+		case field.Tag.Get("secret") == "true" || secretPaths[fieldPath]:
+			if value.IsZero() {
+				out[field.Name] = value.Interface()
+			} else {
+				out[field.Name] = redacted
+			}
+		case value.Kind() == reflect.Struct:
+			out[field.Name] = redactStruct(value, fieldPath, secretPaths)
+		case value.Type() == durationType:
+			out[field.Name] = value.Interface().(time.Duration).String()
+		case value.Kind() == reflect.String:
+			out[field.Name] = redactURL(value.String())
+		default:
+			out[field.Name] = value.Interface()
+		}
+	}
+	return out
+}
+
+// redactURL masks the password of s when it is a URL with credentials, e.g. a mongo uri.
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil || u.User == nil {
+		return s
+	}
+	if _, ok := u.User.Password(); !ok {
+		return s
+	}
+	u.User = url.UserPassword(u.User.Username(), "xxxxx")
+	return u.String()
+}
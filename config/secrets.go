@@ -0,0 +1,114 @@
+package config
+
+import (
+	"build-service-gin/common/secrets"
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// SecretsConfig sets up the providers of the ${secret:...} references. A rotated secret only applies to the fields
+// subscribed with OnSecretChange: InternalToken and the Postgres and Redis passwords. The other fields keep the
+// value they resolved to at startup and need a restart.
+type SecretsConfig struct {
+	DefaultProvider string        `env:"DEFAULT_PROVIDER" envDefault:"env"`
+	FileDir         string        `env:"FILE_DIR" envDefault:"/run/secrets"`
+	RefreshInterval time.Duration `env:"REFRESH_INTERVAL" envDefault:"1m"`
+}
+
+// secretTemplate is the raw value of a field referencing secrets and the value it resolved to.
+type secretTemplate struct {
+	template string
+	value    string
+}
+
+var (
+	secretManager   *secrets.Manager
+	secretTemplates = make(map[string]secretTemplate)
+	secretMu        sync.Mutex
+)
+
+// SecretManager returns the manager resolving the ${secret:...} references of the configuration, providers of
+// other backends can be registered on it.
+func SecretManager() *secrets.Manager {
+	return secretManager
+}
+
+// resolveSecrets replaces the ${secret:...} references held by the string fields of cf.
+// The original templates are kept by field path, e.g. InternalToken or PostgresConfig.Password, for OnSecretChange.
+// The manager is created by the first load, a reload applies its default provider and file directory to it and
+// keeps the watches and the providers registered since.
+func resolveSecrets(ctx context.Context, cf *SystemConfig) error {
+	secretMu.Lock()
+	if secretManager == nil {
+		secretManager = secrets.NewManager(cf.SecretsConfig.DefaultProvider, secrets.EnvProvider{})
+	}
+	secretManager.SetDefaultScheme(cf.SecretsConfig.DefaultProvider)
+	secretManager.Register(secrets.FileProvider{Dir: cf.SecretsConfig.FileDir})
+	secretMu.Unlock()
+
+	return resolveValue(ctx, reflect.ValueOf(cf).Elem(), "")
+}
+
+func resolveValue(ctx context.Context, v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fieldPath := field.Name
+			if path != "" {
+				fieldPath = path + "." + field.Name
+			}
+			if err := resolveValue(ctx, v.Field(i), fieldPath); err != nil {
+				return err
+			}
+		}
+	case reflect.String:
+		value, err := resolveTemplate(ctx, v.String(), path)
+		if err != nil {
+			return err
+		}
+		v.SetString(value)
+	}
+	return nil
+}
+
+func resolveTemplate(ctx context.Context, template, path string) (string, error) {
+	if !secrets.HasReference(template) {
+		return template, nil
+	}
+
+	value, err := secretManager.Expand(ctx, template)
+	if err != nil {
+		return "", fmt.Errorf("config %s: %w", path, err)
+	}
+
+	secretMu.Lock()
+	secretTemplates[path] = secretTemplate{template: template, value: value}
+	secretMu.Unlock()
+	return value, nil
+}
+
+// OnSecretChange calls fn with the new value of the field at path, e.g. InternalToken, whenever a secret it
+// references is rotated. It reports false when the field does not reference any secret.
+func OnSecretChange(path string, fn func(value string)) bool {
+	secretMu.Lock()
+	resolved, ok := secretTemplates[path]
+	secretMu.Unlock()
+	if !ok {
+		return false
+	}
+
+	secretManager.Watch(resolved.template, resolved.value, fn)
+	return true
+}
+
+// StartSecretRefresh refreshes the referenced secrets until ctx is done.
+func StartSecretRefresh(ctx context.Context) {
+	secretManager.Start(ctx, configSingletonObj.SecretsConfig.RefreshInterval)
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeSecret(t *testing.T, dir, name, value string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(value+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveSecretsAppliesReloadedProviders(t *testing.T) {
+	secretManager = nil
+	t.Cleanup(func() { secretManager = nil })
+
+	first, second := t.TempDir(), t.TempDir()
+	writeSecret(t, first, "internal-token", "first-token")
+	writeSecret(t, second, "internal-token", "second-token")
+	t.Setenv("internal-token", "env-token")
+
+	tests := []struct {
+		name     string
+		provider string
+		dir      string
+		want     string
+	}{
+		{name: "first load", provider: "file", dir: first, want: "first-token"},
+		{name: "file directory changed", provider: "file", dir: second, want: "second-token"},
+		{name: "default provider changed", provider: "env", dir: second, want: "env-token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf := &SystemConfig{
+				InternalToken: "${secret:internal-token}",
+				SecretsConfig: SecretsConfig{DefaultProvider: tt.provider, FileDir: tt.dir},
+			}
+			if err := resolveSecrets(context.Background(), cf); err != nil {
+				t.Fatalf("resolveSecrets() = %v", err)
+			}
+			if cf.InternalToken != tt.want {
+				t.Errorf("InternalToken = %q, want %q", cf.InternalToken, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"build-service-gin/common/utils"
+	"build-service-gin/pkg/featureflag"
+	"build-service-gin/pkg/ratelimit"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
+)
+
+// Validate checks what the env tags can't express, every problem found is reported at once.
+func (c *SystemConfig) Validate() error {
+	var errs []error
+
+	if c.HttpPort == 0 || c.HttpPort > 65535 {
+		errs = append(errs, fmt.Errorf("HTTP_PORT %d is not a valid port", c.HttpPort))
+	}
+	if server := c.HttpServerConfig; server.AdminPort == 0 || server.AdminPort > 65535 || server.AdminPort == c.HttpPort ||
+		c.GrpcConfig.Enabled && server.AdminPort == c.GrpcConfig.Port {
+		errs = append(errs, fmt.Errorf("HTTP_ADMIN_PORT %d is not a valid port or is the port of another server", server.AdminPort))
+	}
+	if server := c.HttpServerConfig; (server.TLSCertFile == "") != (server.TLSKeyFile == "") ||
+		server.TLSClientCAFile != "" && server.TLSCertFile == "" {
+		errs = append(errs, errors.New("HTTP_TLS_CERT_FILE and HTTP_TLS_KEY_FILE go together, HTTP_TLS_CLIENT_CA_FILE needs both"))
+	}
+	if c.HttpServerConfig.ShutdownTimeout <= 0 || c.HttpServerConfig.PreStopDelay < 0 {
+		errs = append(errs, errors.New("HTTP_SHUTDOWN_TIMEOUT must be positive and HTTP_PRE_STOP_DELAY not negative"))
+	}
+	if c.HealthConfig.Interval <= 0 || c.HealthConfig.Timeout <= 0 {
+		errs = append(errs, errors.New("HEALTH_INTERVAL and HEALTH_TIMEOUT must be positive"))
+	}
+	if c.HealthConfig.CacheTTL <= c.HealthConfig.Interval {
+		errs = append(errs, errors.New("HEALTH_CACHE_TTL must be longer than HEALTH_INTERVAL"))
+	}
+	if c.GrpcConfig.Enabled && (c.GrpcConfig.Port == 0 || c.GrpcConfig.Port > 65535 || c.GrpcConfig.Port == c.HttpPort) {
+		errs = append(errs, fmt.Errorf("GRPC_PORT %d is not a valid port or is the HTTP_PORT", c.GrpcConfig.Port))
+	}
+	if c.GraphQLConfig.Enabled && (c.GraphQLConfig.MaxDepth <= 0 || c.GraphQLConfig.MaxComplexity <= 0 || c.GraphQLConfig.MaxProfiles <= 0) {
+		errs = append(errs, errors.New("GRAPHQL_MAX_DEPTH, GRAPHQL_MAX_COMPLEXITY and GRAPHQL_MAX_PROFILES must be positive"))
+	}
+	if c.StreamConfig.BufferSize <= 0 || c.StreamConfig.MaxConnections <= 0 || c.StreamConfig.MaxProfileConnections <= 0 || c.StreamConfig.HeartbeatInterval <= 0 {
+		errs = append(errs, errors.New("STREAM_BUFFER_SIZE, STREAM_MAX_CONNECTIONS, STREAM_MAX_PROFILE_CONNECTIONS and STREAM_HEARTBEAT_INTERVAL must be positive"))
+	}
+	if _, err := zerolog.ParseLevel(c.LogLevel); err != nil {
+		errs = append(errs, fmt.Errorf("LOG_LEVEL: %w", err))
+	}
+	if _, err := connstring.ParseAndValidate(c.MongoDBConfig.DatabaseURI); err != nil {
+		errs = append(errs, errors.New("MONGODB_DATABASE_URI is not a valid mongo uri"))
+	}
+	if u, err := url.ParseRequestURI(c.RewardIntegrationUrl); err != nil || u.Host == "" {
+		errs = append(errs, errors.New("REWARD_INTEGRATION_URL is not a valid url"))
+	}
+
+	if c.KafkaConfig.ConsumersEnabled {
+		if c.KafkaConfig.BootstrapServers == "" {
+			errs = append(errs, errors.New("KAFKA_BOOTSTRAP_SERVERS is required when consumers are enabled"))
+		}
+		if c.KafkaConfig.GroupID == "" {
+			errs = append(errs, errors.New("KAFKA_GROUP_ID is required when consumers are enabled"))
+		}
+	}
+
+	if c.AuthConfig.JWKSFile == "" && c.AuthConfig.PublicKeyFile == "" && c.AuthConfig.HS256Secret == "" {
+		errs = append(errs, errors.New("one of AUTH_JWKS_FILE, AUTH_PUBLIC_KEY_FILE or AUTH_HS256_SECRET is required"))
+	}
+
+	errs = append(errs, c.RateLimitConfig.Validate())
+
+	if c.SignatureConfig.Enabled && len(c.SignatureConfig.HMACKeys)+len(c.SignatureConfig.Ed25519Keys) == 0 {
+		errs = append(errs, errors.New("SIGNATURE_ENABLED needs SIGNATURE_HMAC_KEYS or SIGNATURE_ED25519_KEYS"))
+	}
+	if c.SignatureConfig.NonceTTL < 2*c.SignatureConfig.MaxSkew {
+		errs = append(errs, errors.New("SIGNATURE_NONCE_TTL must be at least twice SIGNATURE_MAX_SKEW"))
+	}
+
+	if _, ok := c.CryptoConfig.Keys[c.CryptoConfig.EncryptVersion]; len(c.CryptoConfig.Keys) > 0 && !ok {
+		errs = append(errs, fmt.Errorf("CRYPTO_KEYS has no key v%d", c.CryptoConfig.EncryptVersion))
+	}
+
+	switch c.FeatureFlagConfig.Source {
+	case featureflag.SourceMongo, featureflag.SourceFile:
+	default:
+		errs = append(errs, fmt.Errorf("FEATURE_FLAG_SOURCE %q must be mongo or file", c.FeatureFlagConfig.Source))
+	}
+	if c.FeatureFlagConfig.RefreshInterval <= 0 {
+		errs = append(errs, errors.New("FEATURE_FLAG_REFRESH_INTERVAL must be positive"))
+	}
+
+	if c.WebhookConfig.MaxAttempts < 1 {
+		errs = append(errs, errors.New("WEBHOOK_MAX_ATTEMPTS must be at least 1"))
+	}
+	if c.WebhookConfig.BackoffBase <= 0 || c.WebhookConfig.BackoffMax < c.WebhookConfig.BackoffBase {
+		errs = append(errs, errors.New("WEBHOOK_BACKOFF_BASE must be positive and at most WEBHOOK_BACKOFF_MAX"))
+	}
+	if c.WebhookConfig.RetryInterval <= 0 {
+		errs = append(errs, errors.New("WEBHOOK_RETRY_INTERVAL must be positive"))
+	}
+	if c.WebhookConfig.DeliveryLease <= c.WebhookConfig.Timeout {
+		errs = append(errs, errors.New("WEBHOOK_DELIVERY_LEASE must be longer than WEBHOOK_TIMEOUT"))
+	}
+
+	return utils.CombineErrors(errs)
+}
+
+// Validate checks every rule override parses.
+func (c RateLimitConfig) Validate() error {
+	var errs []error
+	for name, raw := range c.Rules {
+		if _, err := ratelimit.ParseRule(raw, ratelimit.KeyByIP); err != nil {
+			errs = append(errs, fmt.Errorf("RATE_LIMIT_RULES %s: %w", name, err))
+		}
+	}
+	return utils.CombineErrors(errs)
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func validConfig(t *testing.T) *SystemConfig {
+	t.Helper()
+	baseEnvironment(t)
+	setFlags(t, "", nil)
+	cf, err := Load()
+	if err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	return cf
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(cf *SystemConfig)
+		wantErr []string
+	}{
+		{name: "valid", mutate: func(cf *SystemConfig) {}},
+		{
+			name:    "http port out of range",
+			mutate:  func(cf *SystemConfig) { cf.HttpPort = 70000 },
+			wantErr: []string{"HTTP_PORT 70000 is not a valid port"},
+		},
+		{
+			name:    "admin port is the http port",
+			mutate:  func(cf *SystemConfig) { cf.HttpServerConfig.AdminPort = cf.HttpPort },
+			wantErr: []string{"HTTP_ADMIN_PORT"},
+		},
+		{
+			name: "grpc port is the admin port",
+			mutate: func(cf *SystemConfig) {
+				cf.GrpcConfig.Enabled = true
+				cf.GrpcConfig.Port = cf.HttpServerConfig.AdminPort
+			},
+			wantErr: []string{"HTTP_ADMIN_PORT"},
+		},
+		{
+			name:    "tls key without cert",
+			mutate:  func(cf *SystemConfig) { cf.HttpServerConfig.TLSKeyFile = "key.pem" },
+			wantErr: []string{"HTTP_TLS_CERT_FILE and HTTP_TLS_KEY_FILE go together"},
+		},
+		{
+			name:    "unknown log level",
+			mutate:  func(cf *SystemConfig) { cf.LogLevel = "loud" },
+			wantErr: []string{"LOG_LEVEL"},
+		},
+		{
+			name:    "malformed mongo uri",
+			mutate:  func(cf *SystemConfig) { cf.MongoDBConfig.DatabaseURI = "localhost:27017" },
+			wantErr: []string{"MONGODB_DATABASE_URI"},
+		},
+		{
+			name:    "relative reward url",
+			mutate:  func(cf *SystemConfig) { cf.RewardIntegrationUrl = "/rewards" },
+			wantErr: []string{"REWARD_INTEGRATION_URL"},
+		},
+		{
+			name: "consumers without kafka",
+			mutate: func(cf *SystemConfig) {
+				cf.KafkaConfig.ConsumersEnabled = true
+				cf.KafkaConfig.BootstrapServers = ""
+				cf.KafkaConfig.GroupID = ""
+			},
+			wantErr: []string{"KAFKA_BOOTSTRAP_SERVERS", "KAFKA_GROUP_ID"},
+		},
+		{
+			name:    "no auth key",
+			mutate:  func(cf *SystemConfig) { cf.AuthConfig.HS256Secret = "" },
+			wantErr: []string{"AUTH_JWKS_FILE, AUTH_PUBLIC_KEY_FILE or AUTH_HS256_SECRET"},
+		},
+		{
+			name:    "malformed rate limit rule",
+			mutate:  func(cf *SystemConfig) { cf.RateLimitConfig.Rules = map[string]string{"point.create": "fast"} },
+			wantErr: []string{"RATE_LIMIT_RULES point.create"},
+		},
+		{
+			name:    "signature without keys",
+			mutate:  func(cf *SystemConfig) { cf.SignatureConfig.Enabled = true },
+			wantErr: []string{"SIGNATURE_ENABLED"},
+		},
+		{
+			name:    "nonce ttl shorter than the skew window",
+			mutate:  func(cf *SystemConfig) { cf.SignatureConfig.NonceTTL = cf.SignatureConfig.MaxSkew },
+			wantErr: []string{"SIGNATURE_NONCE_TTL"},
+		},
+		{
+			name:    "no key of the encrypt version",
+			mutate:  func(cf *SystemConfig) { cf.CryptoConfig.Keys = map[int]string{2: "key"} },
+			wantErr: []string{"CRYPTO_KEYS has no key v1"},
+		},
+		{
+			name:    "unknown feature flag source",
+			mutate:  func(cf *SystemConfig) { cf.FeatureFlagConfig.Source = "redis" },
+			wantErr: []string{"FEATURE_FLAG_SOURCE"},
+		},
+		{
+			name:    "lease not longer than the webhook timeout",
+			mutate:  func(cf *SystemConfig) { cf.WebhookConfig.DeliveryLease = cf.WebhookConfig.Timeout },
+			wantErr: []string{"WEBHOOK_DELIVERY_LEASE"},
+		},
+		{
+			name:    "health state expiring before the next check",
+			mutate:  func(cf *SystemConfig) { cf.HealthConfig.CacheTTL = cf.HealthConfig.Interval },
+			wantErr: []string{"HEALTH_CACHE_TTL"},
+		},
+		{
+			name: "every problem at once",
+			mutate: func(cf *SystemConfig) {
+				cf.HealthConfig.Timeout = 0
+				cf.WebhookConfig.MaxAttempts = 0
+				cf.WebhookConfig.BackoffMax = time.Millisecond
+			},
+			wantErr: []string{"HEALTH_INTERVAL", "WEBHOOK_MAX_ATTEMPTS", "WEBHOOK_BACKOFF_BASE"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cf := validConfig(t)
+			tt.mutate(cf)
+
+			err := cf.Validate()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() = nil, want %v", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate() = %v, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadRejectsInvalidConfig(t *testing.T) {
+	baseEnvironment(t)
+	setFlags(t, "", map[string]string{"WEBHOOK_MAX_ATTEMPTS": "0"})
+
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "invalid config") {
+		t.Errorf("Load() = %v, want an invalid config error", err)
+	}
+}
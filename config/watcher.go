@@ -0,0 +1,127 @@
+package config
+
+import (
+	"build-service-gin/common/logger"
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Reloadable is the part of the configuration that is safe to change while running. Everything else keeps the
+// value it had at startup, a change to it needs a restart.
+type Reloadable struct {
+	LogLevel        string
+	RateLimitConfig RateLimitConfig
+}
+
+func reloadableOf(cf *SystemConfig) Reloadable {
+	return Reloadable{
+		LogLevel:        cf.LogLevel,
+		RateLimitConfig: cf.RateLimitConfig,
+	}
+}
+
+// Apply copies the reloadable values onto cf.
+func (r Reloadable) Apply(cf *SystemConfig) {
+	cf.LogLevel = r.LogLevel
+	cf.RateLimitConfig = r.RateLimitConfig
+}
+
+var (
+	reloadable atomic.Pointer[Reloadable]
+
+	subscribersMu sync.Mutex
+	subscribers   []func(Reloadable)
+)
+
+func initReloadable(cf *SystemConfig) {
+	r := reloadableOf(cf)
+	reloadable.Store(&r)
+}
+
+// CurrentReloadable returns the reloadable values in effect.
+func CurrentReloadable() Reloadable {
+	if r := reloadable.Load(); r != nil {
+		return *r
+	}
+	return Reloadable{}
+}
+
+// Subscribe registers fn to be called with the new values after a reload changed any of them.
+func Subscribe(fn func(Reloadable)) {
+	subscribersMu.Lock()
+	defer subscribersMu.Unlock()
+	subscribers = append(subscribers, fn)
+}
+
+// StartWatcher reloads the configuration when its file changes, checked every CONFIG_RELOAD_INTERVAL, or on
+// SIGHUP, until ctx is done. A configuration that fails to load or validate is logged and ignored.
+func StartWatcher(ctx context.Context) {
+	if configSingletonObj == nil {
+		return
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(configSingletonObj.ReloadConfig.ReloadInterval)
+	defer ticker.Stop()
+
+	modTime := fileModTime(LoadedFile())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			modTime = fileModTime(LoadedFile())
+			reload(ctx)
+		case <-ticker.C:
+			current := fileModTime(LoadedFile())
+			if current.Equal(modTime) {
+				continue
+			}
+			modTime = current
+			reload(ctx)
+		}
+	}
+}
+
+func reload(ctx context.Context) {
+	log := logger.GetLogger().AddTraceInfoContextRequest(ctx)
+
+	cf, err := Load()
+	if err != nil {
+		log.Error().Err(err).Msg("reload config failed, keeping the current config")
+		return
+	}
+
+	next := reloadableOf(cf)
+	if previous := reloadable.Swap(&next); previous != nil && reflect.DeepEqual(*previous, next) {
+		return
+	}
+	log.Info().Str("file", LoadedFile()).Msg("config reloaded")
+
+	subscribersMu.Lock()
+	fns := append([]func(Reloadable){}, subscribers...)
+	subscribersMu.Unlock()
+	for _, fn := range fns {
+		fn(next)
+	}
+}
+
+func fileModTime(path string) time.Time {
+	if path == "" {
+		return time.Time{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
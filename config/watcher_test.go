@@ -0,0 +1,127 @@
+package config
+
+import (
+	"build-service-gin/common/logger"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLog("test")
+	os.Exit(m.Run())
+}
+
+// watchedConfig loads a configuration from a file of ENV holding content and makes it the process configuration,
+// it returns the path of the file and the reloads received by a subscriber.
+func watchedConfig(t *testing.T, content string) (string, chan Reloadable) {
+	t.Helper()
+	baseEnvironment(t)
+	setFlags(t, "", nil)
+	t.Setenv("CONFIG_RELOAD_INTERVAL", "10ms")
+	path := filepath.Join(os.Getenv("CONFIG_DIR"), "test.yaml")
+	writeConfigFile(t, path, content)
+
+	cf, err := Load()
+	if err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	previous := configSingletonObj
+	configSingletonObj = cf
+	initReloadable(cf)
+
+	reloads := make(chan Reloadable, 10)
+	subscribersMu.Lock()
+	previousSubscribers := subscribers
+	subscribers = []func(Reloadable){func(r Reloadable) { reloads <- r }}
+	subscribersMu.Unlock()
+
+	t.Cleanup(func() {
+		configSingletonObj = previous
+		reloadable.Store(nil)
+		subscribersMu.Lock()
+		subscribers = previousSubscribers
+		subscribersMu.Unlock()
+	})
+	return path, reloads
+}
+
+// rewriteConfigFile replaces the file and moves its modification time forward, so that the change is seen even
+// within the resolution of the file system clock.
+func rewriteConfigFile(t *testing.T, path, content string, at time.Time) {
+	t.Helper()
+	writeConfigFile(t, path, content)
+	if err := os.Chtimes(path, at, at); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStartWatcherReloadsOnFileChange(t *testing.T) {
+	path, reloads := watchedConfig(t, "log_level: debug\n")
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go StartWatcher(ctx)
+
+	// The watcher reads the modification time when it starts, the file is touched again until the change is seen.
+	content := "log_level: warn\nrate_limit:\n  rules:\n    - point.create:5/1m\n"
+	deadline := time.After(2 * time.Second)
+	for i := 1; ; i++ {
+		rewriteConfigFile(t, path, content, time.Now().Add(time.Duration(i)*time.Hour))
+		select {
+		case r := <-reloads:
+			if r.LogLevel != "warn" || r.RateLimitConfig.Rules["point.create"] != "5/1m" {
+				t.Errorf("reloaded %+v, want the values of the new file", r)
+			}
+		case <-time.After(50 * time.Millisecond):
+			continue
+		case <-deadline:
+			t.Fatal("the change of the file was not reloaded")
+		}
+		break
+	}
+	if got := CurrentReloadable().LogLevel; got != "warn" {
+		t.Errorf("CurrentReloadable().LogLevel = %q, want warn", got)
+	}
+	if got := GetInstance().LogLevel; got != "debug" {
+		t.Errorf("GetInstance().LogLevel = %q, a reload must not touch the process configuration", got)
+	}
+}
+
+func TestReload(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantLevel  string
+		wantNotify bool
+	}{
+		{name: "changed value", content: "log_level: warn\n", wantLevel: "warn", wantNotify: true},
+		{name: "unchanged values", content: "log_level: debug\n", wantLevel: "debug"},
+		{name: "change needing a restart only", content: "log_level: debug\nwebhook:\n  max_attempts: 7\n", wantLevel: "debug"},
+		{name: "invalid config kept out", content: "log_level: loud\n", wantLevel: "debug"},
+		{name: "unreadable file kept out", content: "log_level: [debug\n", wantLevel: "debug"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, reloads := watchedConfig(t, "log_level: debug\n")
+			writeConfigFile(t, path, tt.content)
+
+			reload(context.Background())
+
+			if got := CurrentReloadable().LogLevel; got != tt.wantLevel {
+				t.Errorf("CurrentReloadable().LogLevel = %q, want %q", got, tt.wantLevel)
+			}
+			select {
+			case r := <-reloads:
+				if !tt.wantNotify {
+					t.Errorf("subscriber notified with %+v, want no call", r)
+				}
+			default:
+				if tt.wantNotify {
+					t.Error("subscriber not notified")
+				}
+			}
+		})
+	}
+}
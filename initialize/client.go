@@ -1,14 +1,45 @@
 package initialize
 
-import "build-service-gin/client/receiver"
+import (
+	"build-service-gin/client/eventpublisher"
+	"build-service-gin/client/receiver"
+	"build-service-gin/client/webhook"
+	"build-service-gin/common/logger"
+	"build-service-gin/config"
+	"build-service-gin/pkg/signature"
+)
 
 type Clients struct {
 	ReceiverClient receiver.IReceiverClient
+	EventPublisher eventpublisher.IEventPublisher
+	WebhookClient  webhook.IWebhookClient
+	OrderSigner    signature.Signer
 }
 
 func NewClients() *Clients {
 	receiverClient := receiver.NewReceiverClient()
+	eventPublisher := eventpublisher.NewEventPublisher()
+	webhookClient := webhook.NewWebhookClient()
 	return &Clients{
 		ReceiverClient: receiverClient,
+		EventPublisher: eventPublisher,
+		WebhookClient:  webhookClient,
+		OrderSigner:    newOrderSigner(config.GetInstance().SignatureConfig),
 	}
 }
+
+// newOrderSigner builds the signer of the orders posted to the receiver, orders are sent unsigned without an
+// outbound key.
+func newOrderSigner(conf config.SignatureConfig) signature.Signer {
+	log := logger.GetLogger()
+	if conf.OutboundKey == "" {
+		log.Warn().Msg("no outbound signing key configured, orders are sent unsigned")
+		return nil
+	}
+
+	signer, err := signature.NewSigner(conf.OutboundAlgorithm, conf.OutboundKey)
+	if err != nil {
+		log.Fatal().Err(err).Msg("init order signer failed")
+	}
+	return signer
+}
@@ -0,0 +1,40 @@
+package initialize
+
+import (
+	"build-service-gin/common/logger"
+	"build-service-gin/common/redis"
+	"build-service-gin/config"
+	"build-service-gin/pkg/featureflag"
+	"build-service-gin/pkg/helpers/adapters"
+	"build-service-gin/repositories/feature_flag"
+	"context"
+)
+
+// mongoFlagStore serves the flags kept in the feature_flag collection.
+type mongoFlagStore struct {
+	repo feature_flag.IFeatureFlagRepo
+}
+
+func (s mongoFlagStore) ListFlags(ctx context.Context) ([]featureflag.Flag, error) {
+	flags, err := s.repo.ListFlags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return adapters.AdapterFeatureFlag{}.ConvRepoToArrayFlag(flags), nil
+}
+
+// NewFeatureFlags loads the flags from the configured source and makes them the default of the featureflag
+// package. Every flag is off when the first load fails, they are loaded again with the next refresh.
+func NewFeatureFlags(ctx context.Context, conf *config.SystemConfig, repo *Repositories) *featureflag.Service {
+	var store featureflag.Store = mongoFlagStore{repo: repo.IFeatureFlagRepo}
+	if conf.FeatureFlagConfig.Source == featureflag.SourceFile {
+		store = featureflag.FileStore{Path: conf.FeatureFlagConfig.File}
+	}
+
+	flags := featureflag.NewService(store, redis.GetInstance(), conf.FeatureFlagConfig.Channel)
+	if err := flags.Refresh(ctx); err != nil {
+		logger.GetLogger().AddTraceInfoContextRequest(ctx).Error().Err(err).Msg("load feature flags failed, every flag is off")
+	}
+	featureflag.SetDefault(flags)
+	return flags
+}
@@ -0,0 +1,27 @@
+package initialize
+
+import (
+	"build-service-gin/api/grpc/handlers"
+)
+
+type GrpcHandlers struct {
+	ProfileServer *handlers.ProfileServer
+	PointServer   *handlers.PointServer
+}
+
+func NewGrpcHandlers(services *Services) *GrpcHandlers {
+
+	profileServer := handlers.NewProfileServer(
+		services.profileService,
+		services.flags,
+	)
+
+	pointServer := handlers.NewPointServer(
+		services.pointService,
+	)
+
+	return &GrpcHandlers{
+		ProfileServer: profileServer,
+		PointServer:   pointServer,
+	}
+}
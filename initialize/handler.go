@@ -4,24 +4,50 @@ import (
 	"build-service-gin/api/http/handlers"
 	"build-service-gin/api/msgbroker/consumer/core_handle_point"
 	"build-service-gin/api/msgbroker/consumer/order"
+	"build-service-gin/api/msgbroker/consumer/refund"
+	"build-service-gin/config"
 )
 
 type Handlers struct {
 	ProfileHandler   *handlers.ProfileHandler
 	PointHandler     *handlers.PointHandler
+	WebhookHandler   *handlers.WebhookHandler
+	FlagHandler      *handlers.FeatureFlagHandler
+	GraphQLHandler   *handlers.GraphQLHandler
+	StreamHandler    *handlers.StreamHandler
 	OrderHandler     *order.OrderHandler
 	CorePointHandler *core_handle_point.CorePointHandler
+	RefundHandler    *refund.RefundHandler
 }
 
 func NewHandlers(services *Services) *Handlers {
 
 	profileHandler := handlers.NewProfileHandler(
 		services.profileService,
+		services.flags,
 	)
 
 	pointHandler := handlers.NewPointHandler(
 		services.pointService)
 
+	webhookHandler := handlers.NewWebhookHandler(
+		services.webhookService,
+	)
+
+	flagHandler := handlers.NewFeatureFlagHandler(
+		services.flagService,
+	)
+
+	graphQLHandler := handlers.NewGraphQLHandler(
+		services.profileService,
+		config.GetInstance().GraphQLConfig,
+	)
+
+	streamHandler := handlers.NewStreamHandler(
+		services.streamService,
+		config.GetInstance().StreamConfig,
+	)
+
 	orderHandler := order.NewOrderHandler(
 		services.profileService,
 	)
@@ -30,10 +56,19 @@ func NewHandlers(services *Services) *Handlers {
 		services.profileService,
 	)
 
+	refundHandler := refund.NewRefundHandler(
+		services.pointService,
+	)
+
 	return &Handlers{
 		ProfileHandler:   profileHandler,
 		PointHandler:     pointHandler,
+		WebhookHandler:   webhookHandler,
+		FlagHandler:      flagHandler,
+		GraphQLHandler:   graphQLHandler,
+		StreamHandler:    streamHandler,
 		OrderHandler:     orderHandler,
 		CorePointHandler: corePointHandler,
+		RefundHandler:    refundHandler,
 	}
 }
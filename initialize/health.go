@@ -0,0 +1,45 @@
+package initialize
+
+import (
+	"build-service-gin/api/http/middlewares"
+	"build-service-gin/common/logger"
+	"build-service-gin/common/mongodb"
+	postgres "build-service-gin/common/postgresql"
+	"build-service-gin/common/redis"
+	"build-service-gin/config"
+	"build-service-gin/pkg/health"
+	"database/sql"
+	"net/http"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+)
+
+// NewHealthRegistry returns the health checks of the dependencies. The service is not ready without Mongo,
+// Postgres, or Kafka when it consumes from it; Redis, which the rate limiter and the streams can do without, and the
+// receiver only degrade it.
+func NewHealthRegistry(conf *config.SystemConfig, dbStorage *mongodb.DatabaseStorage, postgresql *postgres.DatabasePostgresql) *health.Registry {
+	log := logger.GetLogger()
+	registry := health.NewRegistry(conf.HealthConfig, middlewares.ObserveDependency)
+
+	registry.Register("mongo", true, health.Mongo(dbStorage.GetClient()))
+
+	var sqlDB *sql.DB
+	if db, err := postgresql.GetDB().DB(); err == nil {
+		sqlDB = db
+	}
+	registry.Register("postgres", true, health.SQL(sqlDB))
+
+	registry.Register("redis", false, health.Redis(redis.GetInstance().GetClient()))
+
+	if conf.KafkaConfig.BootstrapServers != "" {
+		admin, err := kafka.NewAdminClient(&kafka.ConfigMap{"bootstrap.servers": conf.KafkaConfig.BootstrapServers})
+		if err != nil {
+			log.Fatal().Err(err).Msg("init kafka health check failed")
+		}
+		registry.Register("kafka", conf.KafkaConfig.ConsumersEnabled, health.Kafka(admin))
+	}
+
+	registry.Register("receiver", false, health.HTTP(http.DefaultClient, conf.RewardIntegrationUrl))
+
+	return registry
+}
@@ -3,9 +3,12 @@ package initialize
 import (
 	"build-service-gin/common/mongodb"
 	postgres "build-service-gin/common/postgresql"
+	"build-service-gin/repositories/feature_flag"
 	"build-service-gin/repositories/mongotx"
 	"build-service-gin/repositories/user_transaction_history"
 	"build-service-gin/repositories/user_transaction_history_postgresql"
+	"build-service-gin/repositories/webhook_delivery"
+	"build-service-gin/repositories/webhook_subscription"
 )
 
 var (
@@ -16,6 +19,9 @@ type Repositories struct {
 	IUserTransactionHistoryRepo         user_transaction_history.IUserTransactionHistoryRepo
 	IUserTransactionHistoryPostgresRepo user_transaction_history_postgresql.IUserTransactionHistoryPostgresSQLRepo
 	IMongoTxRepository                  mongotx.IMongoTxRepository
+	IWebhookSubscriptionRepo            webhook_subscription.IWebhookSubscriptionRepo
+	IWebhookDeliveryRepo                webhook_delivery.IWebhookDeliveryRepo
+	IFeatureFlagRepo                    feature_flag.IFeatureFlagRepo
 }
 
 func NewRepositories(dbStorage *mongodb.DatabaseStorage, postgres *postgres.DatabasePostgresql) *Repositories {
@@ -23,6 +29,9 @@ func NewRepositories(dbStorage *mongodb.DatabaseStorage, postgres *postgres.Data
 		IUserTransactionHistoryRepo:         user_transaction_history.NewRepoUserTransactionHistory(dbStorage),
 		IUserTransactionHistoryPostgresRepo: user_transaction_history_postgresql.NewRepoUserTransactionHistoryPostgresql(postgres),
 		IMongoTxRepository:                  mongotx.IMongoTxRepository(dbStorage),
+		IWebhookSubscriptionRepo:            webhook_subscription.NewRepoWebhookSubscription(dbStorage),
+		IWebhookDeliveryRepo:                webhook_delivery.NewRepoWebhookDelivery(dbStorage),
+		IFeatureFlagRepo:                    feature_flag.NewRepoFeatureFlag(dbStorage),
 	}
 	return repositories
 }
@@ -3,17 +3,26 @@ package initialize
 import (
 	"build-service-gin/config"
 	"build-service-gin/internal/services"
+	"build-service-gin/pkg/featureflag"
+	"build-service-gin/pkg/stream"
+	"context"
 )
 
 type Services struct {
 	profileService services.IProfileService
 	pointService   services.IPointService
+	webhookService services.IWebhookService
+	flagService    services.IFeatureFlagService
+	streamService  services.IStreamService
+	flags          *featureflag.Service
 }
 
 func NewServices(
 	config *config.SystemConfig,
 	clients *Clients,
 	repo *Repositories,
+	flags *featureflag.Service,
+	hub *stream.Hub,
 	// redisClient *redis.Client,
 	// redisClient *redis.Client,
 	// chain *chain.Client,
@@ -23,18 +32,52 @@ func NewServices(
 		repo.IMongoTxRepository,
 		repo.IUserTransactionHistoryRepo,
 		repo.IUserTransactionHistoryPostgresRepo,
+		clients.EventPublisher,
 		//redisClient,
 	)
 
 	pointService := services.NewPointService(
 		config,
 		clients.ReceiverClient,
+		repo.IMongoTxRepository,
+		repo.IUserTransactionHistoryRepo,
+		clients.EventPublisher,
+		clients.OrderSigner,
 	)
 
+	webhookService := services.NewWebhookService(
+		config,
+		clients.WebhookClient,
+		repo.IWebhookSubscriptionRepo,
+		repo.IWebhookDeliveryRepo,
+	)
+	clients.EventPublisher.AddListener(webhookService.Dispatch)
+
+	flagService := services.NewFeatureFlagService(
+		config,
+		flags,
+		repo.IFeatureFlagRepo,
+	)
+
+	streamService := services.NewStreamService(
+		config,
+		hub,
+	)
+	clients.EventPublisher.AddListener(streamService.Dispatch)
+
 	service := &Services{
 		profileService: profileService,
 		pointService:   pointService,
+		webhookService: webhookService,
+		flagService:    flagService,
+		streamService:  streamService,
+		flags:          flags,
 	}
 
 	return service
 }
+
+// Start runs the background work of the services until ctx is done.
+func (s *Services) Start(ctx context.Context) {
+	go s.webhookService.StartRetries(ctx)
+}
@@ -0,0 +1,12 @@
+package initialize
+
+import (
+	"build-service-gin/common/redis"
+	"build-service-gin/config"
+	"build-service-gin/pkg/stream"
+)
+
+// NewStreamHub returns the hub of the streams of profiles, fanned out through Redis when it is connected.
+func NewStreamHub(conf *config.SystemConfig) *stream.Hub {
+	return stream.NewHub(redis.GetInstance(), conf.StreamConfig)
+}
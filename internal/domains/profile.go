@@ -3,20 +3,24 @@ package domains
 import "time"
 
 type UserTransactionHistory struct {
-	TransactionID        string     `json:"transactionID"`
-	TransactionType      string     `json:"transactionType"`
-	ProfileID            string     `json:"profileID"`
-	Status               string     `json:"status"`
-	PointAmount          int64      `json:"pointAmount"`
-	PointType            int64      `json:"pointType"`
-	TotalAmount          float64    `json:"totalAmount"`
-	Currency             string     `json:"currency"`
-	PaymentTransactionID string     `json:"paymentTransactionID"`
-	Source               string     `json:"source"`
-	SourceTime           *time.Time `json:"sourceTime"`
-	SourceType           string     `json:"sourceType"`
-	CreatedAt            *time.Time `json:"createdAt"`
-	UpdatedAt            *time.Time `json:"updatedAt"`
+	TransactionID        string            `json:"transactionID"`
+	TransactionType      string            `json:"transactionType"`
+	ProfileID            string            `json:"profileID"`
+	Status               string            `json:"status"`
+	PointAmount          int64             `json:"pointAmount"`
+	PointType            int64             `json:"pointType"`
+	TotalAmount          float64           `json:"totalAmount"`
+	Currency             string            `json:"currency"`
+	PaymentTransactionID string            `json:"paymentTransactionID"`
+	Source               string            `json:"source"`
+	SourceTime           *time.Time        `json:"sourceTime"`
+	SourceType           string            `json:"sourceType"`
+	StatusHistory        []TxStatusHistory `json:"statusHistory"`
+	OriginalTxID         string            `json:"originalTransactionID,omitempty"`
+	ReversedAmount       float64           `json:"reversedAmount"`
+	ReversedPointAmount  int64             `json:"reversedPointAmount"`
+	CreatedAt            *time.Time        `json:"createdAt"`
+	UpdatedAt            *time.Time        `json:"updatedAt"`
 }
 
 type GetUserTransactionHistoryReq struct {
@@ -0,0 +1,52 @@
+package domains
+
+import "time"
+
+// ProfileBalance is the point balance of a profile: the points of its transactions counting toward the balance, and
+// the points of the transactions still pending.
+type ProfileBalance struct {
+	ProfileID     string `json:"profileID"`
+	Points        int64  `json:"points"`
+	PendingPoints int64  `json:"pendingPoints"`
+}
+
+// ProfileStats aggregates the transactions of a profile created in the last RecentMonth months, all of them when
+// RecentMonth is 0. PointsReversed are the points taken back by reversals.
+type ProfileStats struct {
+	ProfileID         string     `json:"profileID"`
+	RecentMonth       int        `json:"recentMonth"`
+	TransactionCount  int64      `json:"transactionCount"`
+	SuccessCount      int64      `json:"successCount"`
+	FailedCount       int64      `json:"failedCount"`
+	PointsEarned      int64      `json:"pointsEarned"`
+	PointsReversed    int64      `json:"pointsReversed"`
+	LastTransactionAt *time.Time `json:"lastTransactionAt"`
+}
+
+// ProfileSummary is the balance and the stats of a profile, read together in one aggregation.
+type ProfileSummary struct {
+	Balance ProfileBalance `json:"balance"`
+	Stats   ProfileStats   `json:"stats"`
+}
+
+// HistoryCursor positions a page of the history of a profile, newest first, after the transaction it was taken from.
+type HistoryCursor struct {
+	CreatedAt     time.Time `json:"createdAt"`
+	TransactionID string    `json:"transactionID"`
+}
+
+// GetUserTransactionHistoriesReq reads the First newest transactions of several profiles at once, older than After
+// when set.
+type GetUserTransactionHistoriesReq struct {
+	ProfileIDs  []string       `json:"profileIDs"`
+	TxType      string         `json:"txType"`
+	Status      string         `json:"status"`
+	RecentMonth int            `json:"recentMonth"`
+	After       *HistoryCursor `json:"after"`
+	First       int64          `json:"first"`
+}
+
+// IsPending reports whether the transaction has not reached a final status yet.
+func (r *UserTransactionHistory) IsPending() bool {
+	return r.Status == TxStatusPending || r.Status == TxStatusProcessing
+}
@@ -0,0 +1,66 @@
+package domains
+
+import "math"
+
+const (
+	TxTypeReversal = "REVERSAL"
+)
+
+type ReverseTransaction struct {
+	ReversalID           string  `json:"reversalID"`
+	ProfileID            string  `json:"profileID"`
+	TransactionID        string  `json:"transactionID"`
+	PaymentTransactionID string  `json:"paymentTransactionID"`
+	Amount               float64 `json:"amount"`
+	Currency             string  `json:"currency"`
+	Reason               string  `json:"reason"`
+}
+
+// amountScale is the number of minor units in a unit of the amounts, which have at most 2 decimals.
+const amountScale = 100
+
+// MinorUnits converts amount to minor units, amounts are compared in minor units so that the rounding of the float
+// sums never rejects a valid amount.
+func MinorUnits(amount float64) int64 {
+	return int64(math.Round(amount * amountScale))
+}
+
+// RemainingAmount is the part of TotalAmount that has not been reversed yet.
+func (r *UserTransactionHistory) RemainingAmount() float64 {
+	return r.TotalAmount - r.ReversedAmount
+}
+
+// RemainingMinorUnits is RemainingAmount in minor units.
+func (r *UserTransactionHistory) RemainingMinorUnits() int64 {
+	return MinorUnits(r.TotalAmount) - MinorUnits(r.ReversedAmount)
+}
+
+// ReversalPointAmount returns the points to take back when reversing amount of the transaction. The points reversed
+// so far are PointAmount prorated on the amount reversed so far, rounded, so that the rounding of each partial
+// reversal never adds up; reversing the whole remaining amount returns every remaining point.
+func (r *UserTransactionHistory) ReversalPointAmount(amount float64) int64 {
+	total := MinorUnits(r.TotalAmount)
+	reversed := MinorUnits(r.ReversedAmount) + MinorUnits(amount)
+	if total <= 0 || reversed >= total {
+		return r.PointAmount - r.ReversedPointAmount
+	}
+
+	target := int64(math.Round(float64(r.PointAmount) * float64(reversed) / float64(total)))
+	return max(target-r.ReversedPointAmount, 0)
+}
+
+// BuildReversalTransaction builds the reversal record linked to the original transaction.
+func (r *UserTransactionHistory) BuildReversalTransaction(original *UserTransactionHistory, transactionID string, amount float64, pointAmount int64) {
+	r.TransactionID = transactionID
+	r.TransactionType = TxTypeReversal
+	r.OriginalTxID = original.TransactionID
+	r.ProfileID = original.ProfileID
+	r.Status = TxStatusSuccess
+	r.PointAmount = -pointAmount
+	r.PointType = original.PointType
+	r.TotalAmount = -amount
+	r.Currency = original.Currency
+	r.PaymentTransactionID = original.PaymentTransactionID
+	r.Source = original.Source
+	r.SourceType = original.SourceType
+}
@@ -0,0 +1,79 @@
+package domains
+
+import "testing"
+
+func TestMinorUnits(t *testing.T) {
+	tests := []struct {
+		amount float64
+		want   int64
+	}{
+		{amount: 0, want: 0},
+		{amount: 0.1 + 0.2, want: 30},
+		{amount: 19.99, want: 1999},
+		{amount: 1.005, want: 100},
+	}
+	for _, tt := range tests {
+		if got := MinorUnits(tt.amount); got != tt.want {
+			t.Errorf("MinorUnits(%v) = %d, want %d", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestRemainingMinorUnits(t *testing.T) {
+	tx := &UserTransactionHistory{TotalAmount: 0.3, ReversedAmount: 0.1 + 0.1}
+	if got := tx.RemainingMinorUnits(); got != 10 {
+		t.Errorf("RemainingMinorUnits() = %d, want 10", got)
+	}
+}
+
+func TestReversalPointAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		tx       UserTransactionHistory
+		amounts  []float64
+		want     []int64
+		wantLeft int64
+	}{
+		{
+			name:    "partial reversals round on the cumulated amount",
+			tx:      UserTransactionHistory{PointAmount: 10, TotalAmount: 3},
+			amounts: []float64{1, 1, 1},
+			want:    []int64{3, 4, 3},
+		},
+		{
+			name:     "partial reversals never take back more than prorated",
+			tx:       UserTransactionHistory{PointAmount: 100, TotalAmount: 0.3},
+			amounts:  []float64{0.1, 0.1},
+			want:     []int64{33, 34},
+			wantLeft: 33,
+		},
+		{
+			name:    "the whole amount returns every point",
+			tx:      UserTransactionHistory{PointAmount: 7, TotalAmount: 9.99},
+			amounts: []float64{9.99},
+			want:    []int64{7},
+		},
+		{
+			name:    "a transaction without amount returns every point",
+			tx:      UserTransactionHistory{PointAmount: 5},
+			amounts: []float64{1},
+			want:    []int64{5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := tt.tx
+			for i, amount := range tt.amounts {
+				got := tx.ReversalPointAmount(amount)
+				if got != tt.want[i] {
+					t.Fatalf("reversal %d: ReversalPointAmount(%v) = %d, want %d", i, amount, got, tt.want[i])
+				}
+				tx.ReversedAmount += amount
+				tx.ReversedPointAmount += got
+			}
+			if left := tx.PointAmount - tx.ReversedPointAmount; left != tt.wantLeft {
+				t.Errorf("%d points left, want %d", left, tt.wantLeft)
+			}
+		})
+	}
+}
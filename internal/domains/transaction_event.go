@@ -0,0 +1,28 @@
+package domains
+
+const (
+	TxEventCreated   = "transaction.created"
+	TxEventCompleted = "transaction.completed"
+	TxEventUpdated   = "transaction.updated"
+	TxEventDeleted   = "transaction.deleted"
+
+	BalanceEventChanged = "balance.changed"
+)
+
+// DomainEventTypes lists the events published for transaction-history changes.
+var DomainEventTypes = []string{TxEventCreated, TxEventCompleted, TxEventUpdated, TxEventDeleted, BalanceEventChanged}
+
+func IsDomainEventType(eventType string) bool {
+	for _, t := range DomainEventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// CountsTowardBalance reports whether the points of the transaction are part of the profile balance.
+// Reversed transactions still count; the REVERSAL records linked to them carry the negative points.
+func (r *UserTransactionHistory) CountsTowardBalance() bool {
+	return r.Status == TxStatusSuccess || r.Status == TxStatusReversed
+}
@@ -0,0 +1,73 @@
+package domains
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	TxStatusPending    = "PENDING"
+	TxStatusProcessing = "PROCESSING"
+	TxStatusSuccess    = "SUCCESS"
+	TxStatusFailed     = "FAILED"
+	TxStatusReversed   = "REVERSED"
+)
+
+// txStatusTransitions lists, for every status, the statuses a transaction may move to.
+// PENDING -> PROCESSING -> SUCCESS/FAILED, SUCCESS -> REVERSED. FAILED and REVERSED are terminal.
+// PENDING may complete directly because the core point event is not always preceded by PROCESSING.
+var txStatusTransitions = map[string][]string{
+	TxStatusPending:    {TxStatusProcessing, TxStatusSuccess, TxStatusFailed},
+	TxStatusProcessing: {TxStatusSuccess, TxStatusFailed},
+	TxStatusSuccess:    {TxStatusReversed},
+	TxStatusFailed:     {},
+	TxStatusReversed:   {},
+}
+
+type TxStatusHistory struct {
+	Status    string     `json:"status"`
+	ChangedAt *time.Time `json:"changedAt"`
+}
+
+func NormalizeTxStatus(status string) string {
+	return strings.ToUpper(strings.TrimSpace(status))
+}
+
+// TxStatuses returns every transaction status in the order of the lifecycle.
+func TxStatuses() []string {
+	return []string{TxStatusPending, TxStatusProcessing, TxStatusSuccess, TxStatusFailed, TxStatusReversed}
+}
+
+func IsValidTxStatus(status string) bool {
+	_, ok := txStatusTransitions[status]
+	return ok
+}
+
+// CanTransitTxStatus reports whether a transaction in status "from" may move to status "to".
+func CanTransitTxStatus(from, to string) bool {
+	for _, next := range txStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowedPrevTxStatuses returns the statuses a transaction must currently be in to move to "to", in the order of
+// the lifecycle. The result is used by the repositories as a conditional filter on the update, an empty result
+// rejects every update: no transaction may move to "to", e.g. back to PENDING.
+func AllowedPrevTxStatuses(to string) []string {
+	prev := []string{}
+	for _, from := range TxStatuses() {
+		if CanTransitTxStatus(from, to) {
+			prev = append(prev, from)
+		}
+	}
+	return prev
+}
+
+// IsCompletionTxStatus reports whether status ends the processing of a transaction, the statuses a complete event
+// may carry.
+func IsCompletionTxStatus(status string) bool {
+	return status == TxStatusSuccess || status == TxStatusFailed
+}
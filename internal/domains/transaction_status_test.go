@@ -0,0 +1,55 @@
+package domains
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAllowedPrevTxStatuses(t *testing.T) {
+	tests := []struct {
+		to   string
+		want []string
+	}{
+		{to: TxStatusPending, want: []string{}},
+		{to: TxStatusProcessing, want: []string{TxStatusPending}},
+		{to: TxStatusSuccess, want: []string{TxStatusPending, TxStatusProcessing}},
+		{to: TxStatusFailed, want: []string{TxStatusPending, TxStatusProcessing}},
+		{to: TxStatusReversed, want: []string{TxStatusSuccess}},
+		{to: "UNKNOWN", want: []string{}},
+	}
+	for _, tt := range tests {
+		got := AllowedPrevTxStatuses(tt.to)
+		if got == nil || !slices.Equal(got, tt.want) {
+			t.Errorf("AllowedPrevTxStatuses(%q) = %#v, want %#v", tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestCanTransitTxStatus(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{TxStatusPending, TxStatusSuccess, true},
+		{TxStatusProcessing, TxStatusFailed, true},
+		{TxStatusSuccess, TxStatusReversed, true},
+		{TxStatusSuccess, TxStatusPending, false},
+		{TxStatusSuccess, TxStatusSuccess, false},
+		{TxStatusFailed, TxStatusSuccess, false},
+		{TxStatusReversed, TxStatusSuccess, false},
+	}
+	for _, tt := range tests {
+		if got := CanTransitTxStatus(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransitTxStatus(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestIsCompletionTxStatus(t *testing.T) {
+	for _, status := range TxStatuses() {
+		want := status == TxStatusSuccess || status == TxStatusFailed
+		if got := IsCompletionTxStatus(status); got != want {
+			t.Errorf("IsCompletionTxStatus(%q) = %v, want %v", status, got, want)
+		}
+	}
+}
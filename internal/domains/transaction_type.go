@@ -0,0 +1,31 @@
+package domains
+
+import "strings"
+
+const (
+	TxTypeEarn = "TX_EARN"
+)
+
+// txTypes lists every transaction type, see also TxTypeReversal.
+var txTypes = []string{TxTypeEarn, TxTypeReversal}
+
+// NormalizeTxType maps a transaction type sent in any case to its canonical upper case form.
+func NormalizeTxType(txType string) string {
+	return strings.ToUpper(strings.TrimSpace(txType))
+}
+
+// IsValidTxType reports whether txType, normalized, is a known transaction type.
+func IsValidTxType(txType string) bool {
+	txType = NormalizeTxType(txType)
+	for _, known := range txTypes {
+		if known == txType {
+			return true
+		}
+	}
+	return false
+}
+
+// TxTypes returns every transaction type.
+func TxTypes() []string {
+	return append([]string(nil), txTypes...)
+}
@@ -0,0 +1,22 @@
+package domains
+
+import "testing"
+
+func TestIsValidTxType(t *testing.T) {
+	tests := []struct {
+		txType string
+		want   bool
+	}{
+		{TxTypeEarn, true},
+		{TxTypeReversal, true},
+		{"tx_earn", true},
+		{" Reversal ", true},
+		{TxStatusSuccess, false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsValidTxType(tt.txType); got != tt.want {
+			t.Errorf("IsValidTxType(%q) = %v, want %v", tt.txType, got, tt.want)
+		}
+	}
+}
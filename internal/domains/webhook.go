@@ -0,0 +1,73 @@
+package domains
+
+import "time"
+
+const (
+	WebhookDeliveryPending  = "PENDING"
+	WebhookDeliveryRetrying = "RETRYING"
+	WebhookDeliverySuccess  = "SUCCESS"
+	WebhookDeliveryFailed   = "FAILED"
+)
+
+type WebhookSubscription struct {
+	SubscriptionID      string     `json:"subscriptionID"`
+	Name                string     `json:"name"`
+	URL                 string     `json:"url"`
+	Secret              string     `json:"secret,omitempty"`
+	EventTypes          []string   `json:"eventTypes"`
+	Enabled             bool       `json:"enabled"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	DisabledAt          *time.Time `json:"disabledAt"`
+	DisabledReason      string     `json:"disabledReason"`
+	CreatedAt           *time.Time `json:"createdAt"`
+	UpdatedAt           *time.Time `json:"updatedAt"`
+}
+
+type WebhookDelivery struct {
+	DeliveryID     string                   `json:"deliveryID"`
+	SubscriptionID string                   `json:"subscriptionID"`
+	EventID        string                   `json:"eventID"`
+	EventType      string                   `json:"eventType"`
+	Payload        string                   `json:"payload"`
+	Status         string                   `json:"status"`
+	Attempts       int                      `json:"attempts"`
+	ResponseStatus int                      `json:"responseStatus"`
+	ResponseBody   string                   `json:"responseBody"`
+	Error          string                   `json:"error"`
+	AttemptLogs    []WebhookDeliveryAttempt `json:"attemptLogs"`
+	RedeliveryOf   string                   `json:"redeliveryOf,omitempty"`
+	NextAttemptAt  *time.Time               `json:"nextAttemptAt"`
+	DeliveredAt    *time.Time               `json:"deliveredAt"`
+	CreatedAt      *time.Time               `json:"createdAt"`
+	UpdatedAt      *time.Time               `json:"updatedAt"`
+}
+
+type WebhookDeliveryAttempt struct {
+	Attempt        int        `json:"attempt"`
+	ResponseStatus int        `json:"responseStatus"`
+	ResponseBody   string     `json:"responseBody"`
+	Error          string     `json:"error"`
+	DurationMs     int64      `json:"durationMs"`
+	AttemptedAt    *time.Time `json:"attemptedAt"`
+}
+
+type GetWebhookDeliveriesReq struct {
+	SubscriptionID string `json:"subscriptionID"`
+	Status         string `json:"status"`
+	Offset         int64  `json:"offset"`
+	Limit          int64  `json:"limit"`
+}
+
+// Succeeded reports whether the receiver acknowledged the attempt with a 2xx status.
+func (a *WebhookDeliveryAttempt) Succeeded() bool {
+	return a.Error == "" && a.ResponseStatus >= 200 && a.ResponseStatus < 300
+}
+
+// Retryable reports whether a failed attempt may succeed later. Client errors other than timeouts and
+// throttling are permanent.
+func (a *WebhookDeliveryAttempt) Retryable() bool {
+	if a.ResponseStatus == 0 || a.ResponseStatus >= 500 {
+		return true
+	}
+	return a.ResponseStatus == 408 || a.ResponseStatus == 429
+}
@@ -0,0 +1,26 @@
+package services
+
+import (
+	"build-service-gin/pkg/helpers/resp"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gorm.io/gorm"
+)
+
+// repoError maps an error reading from a repository: a missing document is NotFound, anything else is an internal
+// failure.
+func repoError(err error) *resp.CustomError {
+	if errors.Is(err, mongo.ErrNoDocuments) || errors.Is(err, gorm.ErrRecordNotFound) {
+		return resp.WrapError(resp.NotFound, resp.ErrNotFound, err)
+	}
+	return resp.WrapError(resp.Internal, resp.ErrSystem, err)
+}
+
+// storeError maps an error writing to a repository.
+func storeError(err error) *resp.CustomError {
+	if errors.Is(err, mongo.ErrNoDocuments) || errors.Is(err, gorm.ErrRecordNotFound) {
+		return resp.WrapError(resp.NotFound, resp.ErrNotFound, err)
+	}
+	return resp.WrapError(resp.Internal, resp.ErrStoreDataFailed, err)
+}
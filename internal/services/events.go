@@ -0,0 +1,35 @@
+package services
+
+import (
+	"build-service-gin/client/eventpublisher"
+	"build-service-gin/common/logger"
+	modelsServ "build-service-gin/internal/domains"
+	"context"
+)
+
+// publishTransactionEvent emits eventType for tx, followed by balance.changed when pointDelta is not zero.
+// Events are sent once the change is committed; a failure is logged and never fails the request.
+func publishTransactionEvent(ctx context.Context, publisher eventpublisher.IEventPublisher, eventType string, tx *modelsServ.UserTransactionHistory, pointDelta int64) {
+	log := logger.GetLogger().AddTraceInfoContextRequest(ctx)
+
+	if err := publisher.PublishTransactionEvent(ctx, eventType, tx); err != nil {
+		log.Error().Err(err).Str("event", eventType).Str("transactionID", tx.TransactionID).Msg("publish transaction event failed")
+	}
+
+	if pointDelta == 0 {
+		return
+	}
+
+	if err := publisher.PublishBalanceChanged(ctx, tx, eventType, pointDelta); err != nil {
+		log.Error().Err(err).Str("event", eventType).Str("transactionID", tx.TransactionID).Msg("publish balance changed failed")
+	}
+}
+
+// creditedPoints returns the points added to the balance by a transaction that has just reached its status.
+// Only reaching SUCCESS changes the balance, reversals are booked through their own REVERSAL records.
+func creditedPoints(tx *modelsServ.UserTransactionHistory) int64 {
+	if tx.Status != modelsServ.TxStatusSuccess {
+		return 0
+	}
+	return tx.PointAmount
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"build-service-gin/common/logger"
+	"build-service-gin/config"
+	"build-service-gin/pkg/featureflag"
+	"build-service-gin/pkg/helpers/adapters"
+	"build-service-gin/pkg/helpers/resp"
+	"build-service-gin/repositories/feature_flag"
+	"context"
+)
+
+type FeatureFlagService struct {
+	conf     *config.SystemConfig
+	flags    *featureflag.Service
+	flagRepo feature_flag.IFeatureFlagRepo
+}
+
+type IFeatureFlagService interface {
+	ListFlags(ctx context.Context) ([]featureflag.Flag, *resp.CustomError)
+	UpsertFlag(ctx context.Context, flag *featureflag.Flag) (*featureflag.Flag, *resp.CustomError)
+	DeleteFlag(ctx context.Context, key string) *resp.CustomError
+}
+
+func NewFeatureFlagService(conf *config.SystemConfig, flags *featureflag.Service, flagRepo feature_flag.IFeatureFlagRepo) IFeatureFlagService {
+	return &FeatureFlagService{
+		conf:     conf,
+		flags:    flags,
+		flagRepo: flagRepo,
+	}
+}
+
+// ListFlags returns the flags this instance evaluates, which may lag the store until the next refresh.
+func (s *FeatureFlagService) ListFlags(_ context.Context) ([]featureflag.Flag, *resp.CustomError) {
+	return s.flags.Flags(), nil
+}
+
+func (s *FeatureFlagService) UpsertFlag(ctx context.Context, flag *featureflag.Flag) (*featureflag.Flag, *resp.CustomError) {
+	if errCustom := s.checkWritable(); errCustom != nil {
+		return nil, errCustom
+	}
+
+	saved, err := s.flagRepo.UpsertFlag(ctx, adapters.AdapterFeatureFlag{}.ConvFlagToRepo(flag))
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	s.notify(ctx)
+	return adapters.AdapterFeatureFlag{}.ConvRepoToFlag(saved), nil
+}
+
+func (s *FeatureFlagService) DeleteFlag(ctx context.Context, key string) *resp.CustomError {
+	if errCustom := s.checkWritable(); errCustom != nil {
+		return errCustom
+	}
+
+	if err := s.flagRepo.DeleteFlag(ctx, key); err != nil {
+		return storeError(err)
+	}
+
+	s.notify(ctx)
+	return nil
+}
+
+// checkWritable rejects changes while the flags are read from a file, they are edited in the file instead.
+func (s *FeatureFlagService) checkWritable() *resp.CustomError {
+	if s.conf.FeatureFlagConfig.Source != featureflag.SourceMongo {
+		return &resp.CustomError{ErrorCode: resp.ErrDataInvalid, Description: "feature flags are read from " + s.conf.FeatureFlagConfig.File}
+	}
+	return nil
+}
+
+// notify makes every instance pick up the change, the change is saved either way and reaches the others with
+// their next periodic refresh.
+func (s *FeatureFlagService) notify(ctx context.Context) {
+	if err := s.flags.Notify(ctx); err != nil {
+		logger.GetLogger().AddTraceInfoContextRequest(ctx).Error().Err(err).Msg("notify feature flag change failed")
+	}
+}
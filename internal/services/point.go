@@ -1,33 +1,55 @@
 package services
 
 import (
+	"build-service-gin/client/eventpublisher"
 	"build-service-gin/client/receiver"
 	"build-service-gin/common/logger"
 	"build-service-gin/common/utils"
 	"build-service-gin/config"
 	modelsServ "build-service-gin/internal/domains"
+	"build-service-gin/pkg/helpers/adapters"
 	"build-service-gin/pkg/helpers/resp"
+	"build-service-gin/pkg/signature"
+	"build-service-gin/repositories/mongotx"
+	"build-service-gin/repositories/user_transaction_history"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type PointService struct {
 	conf           *config.SystemConfig
 	receiverClient receiver.IReceiverClient
+	mongoRepo      mongotx.IMongoTxRepository
+	profileRepo    user_transaction_history.IUserTransactionHistoryRepo
+	eventPublisher eventpublisher.IEventPublisher
+	orderSigner    signature.Signer
 }
 
 type IPointService interface {
 	CreatePointTransaction(ctx context.Context, order *modelsServ.Order) *resp.CustomError
+	ReverseTransactionPoint(ctx context.Context, req *modelsServ.ReverseTransaction) (*modelsServ.UserTransactionHistory, *resp.CustomError)
 }
 
 func NewPointService(
 	conf *config.SystemConfig,
 	receiverClient receiver.IReceiverClient,
+	mongoRepo mongotx.IMongoTxRepository,
+	profileRepo user_transaction_history.IUserTransactionHistoryRepo,
+	eventPublisher eventpublisher.IEventPublisher,
+	orderSigner signature.Signer,
 ) IPointService {
 	return &PointService{
 		conf:           conf,
 		receiverClient: receiverClient,
+		mongoRepo:      mongoRepo,
+		profileRepo:    profileRepo,
+		eventPublisher: eventPublisher,
+		orderSigner:    orderSigner,
 	}
 }
 
@@ -52,24 +74,158 @@ func (s *PointService) CreatePointTransaction(ctx context.Context, order *models
 		return &resp.CustomError{ErrorCode: resp.ErrSystem, Description: "Failed to process order data"}
 	}
 
-	//signature, err := s.chain.GetContractLPPoint().GenSig(string(orderPointJSON))
-	//if err != nil {
-	//	log.Error().Err(err).Msg("Failed to generate signature")
-	//	return &resp.CustomError{ErrorCode: resp.ErrSystem, Description: "Failed to sign order data"}
-	//}
-
 	signedOrder := modelsServ.OrderMessage{
 		SourceType: order.SourceType,
 		RawData:    string(orderPointJSON),
-		//Signature:  signature,
+	}
+	if s.orderSigner != nil {
+		signedOrder.Signature, err = s.orderSigner.Sign(orderPointJSON)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to generate signature")
+			return &resp.CustomError{ErrorCode: resp.ErrSystem, Description: "Failed to sign order data"}
+		}
 	}
 
 	_, err = s.receiverClient.PostOrder(ctx, signedOrder)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to send order request")
-		return &resp.CustomError{ErrorCode: resp.ErrSystem, Description: "error create order point"}
+		return resp.WrapError(resp.Unavailable, resp.ErrCallExternalService, err)
 	}
 	log.Info().Msg("Order created successfully")
 	return nil
 
 }
+
+// ReverseTransactionPoint reverses all or part of a completed transaction. A linked REVERSAL record is created and
+// the reversed totals of the original are updated in the same Mongo transaction. When the whole TotalAmount has
+// been reversed the original moves to REVERSED. ReversalID makes the reversal idempotent, a reversal replayed with
+// the same ID returns the reversal already created. A request naming ProfileID only reverses the transactions of
+// that profile, the others are reported not found.
+func (s *PointService) ReverseTransactionPoint(ctx context.Context, req *modelsServ.ReverseTransaction) (*modelsServ.UserTransactionHistory, *resp.CustomError) {
+	log := logger.GetLogger().AddTraceInfoContextRequest(ctx)
+	log.Info().Interface("reversal", req).Msg("ReverseTransactionPoint - Start")
+
+	if req.ReversalID == "" {
+		return nil, &resp.CustomError{ErrorCode: resp.ErrDataInvalid, Description: "reversalID is required"}
+	}
+	if req.TransactionID == "" && req.PaymentTransactionID == "" {
+		return nil, &resp.CustomError{ErrorCode: resp.ErrDataInvalid, Description: "transactionID or paymentTransactionID is required"}
+	}
+	if req.Amount < 0 {
+		return nil, &resp.CustomError{ErrorCode: resp.ErrHandleAmountInvalid, Description: "amount must not be negative"}
+	}
+
+	var original, reversal *modelsServ.UserTransactionHistory
+	var errCustom *resp.CustomError
+	replayed := false
+	err := s.mongoRepo.ExecTransaction(ctx, func(sessionCtx mongo.SessionContext) (interface{}, error) {
+		existing, err := s.profileRepo.FindReversal(sessionCtx, req.ReversalID)
+		switch {
+		case err == nil:
+			reversal = adapters.AdapterProfile{}.ConvRepoToDomain(existing)
+			if !reversalOf(reversal, req) {
+				errCustom = &resp.CustomError{ErrorCode: resp.ErrDataInvalid, Description: "reversalID is already used by another transaction"}
+				return nil, user_transaction_history.ErrReversalConflict
+			}
+			replayed = true
+			return nil, nil
+		case !errors.Is(err, mongo.ErrNoDocuments):
+			return nil, err
+		}
+
+		originalRepo, err := s.profileRepo.FindOriginalTransaction(sessionCtx, req.TransactionID, req.PaymentTransactionID)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				errCustom = &resp.CustomError{ErrorCode: resp.ErrNotFound, Description: "original transaction not found"}
+			}
+			return nil, err
+		}
+
+		original = adapters.AdapterProfile{}.ConvRepoToDomain(originalRepo)
+		if req.ProfileID != "" && original.ProfileID != req.ProfileID {
+			errCustom = &resp.CustomError{ErrorCode: resp.ErrNotFound, Description: "original transaction not found"}
+			return nil, mongo.ErrNoDocuments
+		}
+		if original.Status == modelsServ.TxStatusReversed {
+			errCustom = &resp.CustomError{ErrorCode: resp.ErrHandleTxAlreadyReversed}
+			return nil, user_transaction_history.ErrReversalConflict
+		}
+		if original.Status != modelsServ.TxStatusSuccess {
+			errCustom = &resp.CustomError{ErrorCode: resp.ErrHandleTxStatusTransitionInvalid, Description: "only SUCCESS transactions can be reversed"}
+			return nil, user_transaction_history.ErrStatusTransitionRejected
+		}
+		if req.Currency != "" && req.Currency != original.Currency {
+			errCustom = &resp.CustomError{ErrorCode: resp.ErrHandleCurrencyInvalid}
+			return nil, fmt.Errorf("currency %s does not match %s", req.Currency, original.Currency)
+		}
+
+		amount := req.Amount
+		if amount == 0 {
+			amount = original.RemainingAmount()
+		}
+		remaining := original.RemainingMinorUnits()
+		if units := modelsServ.MinorUnits(amount); units <= 0 || units > remaining {
+			errCustom = &resp.CustomError{ErrorCode: resp.ErrHandleAmountInvalid, Description: fmt.Sprintf("amount must be in (0, %v]", original.RemainingAmount())}
+			return nil, fmt.Errorf("reversal amount %v out of range", amount)
+		}
+
+		pointAmount := original.ReversalPointAmount(amount)
+		nextStatus := ""
+		if modelsServ.MinorUnits(amount) == remaining {
+			nextStatus = modelsServ.TxStatusReversed
+		}
+
+		if err = s.profileRepo.ApplyReversal(sessionCtx, originalRepo, amount, pointAmount, modelsServ.AllowedPrevTxStatuses(modelsServ.TxStatusReversed), nextStatus); err != nil {
+			return nil, err
+		}
+
+		newReversal := &modelsServ.UserTransactionHistory{}
+		newReversal.BuildReversalTransaction(original, req.ReversalID, amount, pointAmount)
+		reversalRepo := adapters.AdapterProfile{}.ConvDomainToRepo(newReversal)
+		created, err := s.profileRepo.CreateUserTransactionHistory(sessionCtx, &reversalRepo)
+		if err != nil {
+			return nil, err
+		}
+
+		reversal = adapters.AdapterProfile{}.ConvRepoToDomain(created)
+
+		original.ReversedAmount += amount
+		original.ReversedPointAmount += pointAmount
+		if nextStatus != "" {
+			original.Status = nextStatus
+		}
+		return nil, nil
+	})
+
+	if err != nil {
+		log.Error().Err(err).Str("reason", req.Reason).Msg("ReverseTransactionPoint - Transaction failed")
+		if errCustom != nil {
+			return nil, errCustom
+		}
+		if errors.Is(err, user_transaction_history.ErrReversalConflict) || mongo.IsDuplicateKeyError(err) {
+			return nil, resp.WrapError(resp.Conflict, resp.ErrHandleTxAlreadyReversed, err)
+		}
+		return nil, resp.WrapError(resp.Internal, resp.ErrSystem, err)
+	}
+
+	if replayed {
+		log.Info().Str("reversal_id", req.ReversalID).Msg("ReverseTransactionPoint - Reversal already created, replay ignored")
+		return reversal, nil
+	}
+
+	log.Info().Str("reason", req.Reason).Msg("ReverseTransactionPoint - Reversal created successfully")
+	publishTransactionEvent(ctx, s.eventPublisher, modelsServ.TxEventUpdated, original, 0)
+	publishTransactionEvent(ctx, s.eventPublisher, modelsServ.TxEventCreated, reversal, creditedPoints(reversal))
+	return reversal, nil
+}
+
+// reversalOf tells whether reversal reverses the transaction req designates.
+func reversalOf(reversal *modelsServ.UserTransactionHistory, req *modelsServ.ReverseTransaction) bool {
+	if req.ProfileID != "" && reversal.ProfileID != req.ProfileID {
+		return false
+	}
+	if req.TransactionID != "" && reversal.OriginalTxID != req.TransactionID {
+		return false
+	}
+	return req.PaymentTransactionID == "" || reversal.PaymentTransactionID == req.PaymentTransactionID
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"build-service-gin/config"
+	modelsServ "build-service-gin/internal/domains"
+	"build-service-gin/pkg/helpers/resp"
+	"build-service-gin/repositories/user_transaction_history"
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// fakeReversalRepo keeps one original transaction and the reversals created against it.
+type fakeReversalRepo struct {
+	user_transaction_history.IUserTransactionHistoryRepo
+	original  *user_transaction_history.UserTransactionHistory
+	reversals map[string]*user_transaction_history.UserTransactionHistory
+}
+
+func (r *fakeReversalRepo) FindReversal(_ context.Context, reversalID string) (*user_transaction_history.UserTransactionHistory, error) {
+	if reversal, ok := r.reversals[reversalID]; ok {
+		return reversal, nil
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (r *fakeReversalRepo) FindOriginalTransaction(_ context.Context, transactionID, _ string) (*user_transaction_history.UserTransactionHistory, error) {
+	if r.original.TransactionID != transactionID {
+		return nil, mongo.ErrNoDocuments
+	}
+	found := *r.original
+	return &found, nil
+}
+
+func (r *fakeReversalRepo) ApplyReversal(_ context.Context, _ *user_transaction_history.UserTransactionHistory, amount float64, pointAmount int64, _ []string, nextStatus string) error {
+	r.original.ReversedAmount += amount
+	r.original.ReversedPointAmount += pointAmount
+	if nextStatus != "" {
+		r.original.Status = nextStatus
+	}
+	return nil
+}
+
+func (r *fakeReversalRepo) CreateUserTransactionHistory(_ context.Context, data *user_transaction_history.UserTransactionHistory) (*user_transaction_history.UserTransactionHistory, error) {
+	r.reversals[data.TransactionID] = data
+	return data, nil
+}
+
+func newTestPointService(repo *fakeReversalRepo, publisher *fakePublisher) *PointService {
+	return NewPointService(&config.SystemConfig{}, nil, fakeTx{}, repo, publisher, nil).(*PointService)
+}
+
+func newFakeReversalRepo() *fakeReversalRepo {
+	return &fakeReversalRepo{
+		original: &user_transaction_history.UserTransactionHistory{
+			TransactionID: "tx-1",
+			ProfileID:     "p-1",
+			Status:        modelsServ.TxStatusSuccess,
+			PointAmount:   30,
+			TotalAmount:   0.3,
+			Currency:      "USD",
+		},
+		reversals: map[string]*user_transaction_history.UserTransactionHistory{},
+	}
+}
+
+func TestReverseTransactionPoint(t *testing.T) {
+	repo := newFakeReversalRepo()
+	publisher := &fakePublisher{}
+	s := newTestPointService(repo, publisher)
+	ctx := context.Background()
+
+	// 0.1 + 0.2 is not 0.3 in floats, the second reversal still reverses the whole remaining amount
+	for i, reversal := range []struct {
+		id     string
+		amount float64
+		points int64
+	}{{"r-1", 0.1, 10}, {"r-2", 0.2, 20}} {
+		got, errCustom := s.ReverseTransactionPoint(ctx, &modelsServ.ReverseTransaction{ReversalID: reversal.id, ProfileID: "p-1", TransactionID: "tx-1", Amount: reversal.amount})
+		if errCustom != nil {
+			t.Fatalf("reversal %d: ReverseTransactionPoint() = %v", i, errCustom)
+		}
+		if got.PointAmount != -reversal.points {
+			t.Errorf("reversal %d: PointAmount = %d, want %d", i, got.PointAmount, -reversal.points)
+		}
+	}
+	if repo.original.Status != modelsServ.TxStatusReversed {
+		t.Errorf("original status = %s, want %s", repo.original.Status, modelsServ.TxStatusReversed)
+	}
+	if len(publisher.events) != 4 {
+		t.Errorf("published %v, want 4 events", publisher.events)
+	}
+
+	// a replayed reversal returns the reversal created the first time without applying it again
+	got, errCustom := s.ReverseTransactionPoint(ctx, &modelsServ.ReverseTransaction{ReversalID: "r-1", TransactionID: "tx-1", Amount: 0.1})
+	if errCustom != nil {
+		t.Fatalf("replay: ReverseTransactionPoint() = %v", errCustom)
+	}
+	if got.TransactionID != "r-1" || got.PointAmount != -10 {
+		t.Errorf("replay returned %+v", got)
+	}
+	if len(publisher.events) != 4 {
+		t.Errorf("replay published %v", publisher.events[4:])
+	}
+}
+
+func TestReverseTransactionPointInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      modelsServ.ReverseTransaction
+		wantCode int64
+	}{
+		{name: "missing reversal id", req: modelsServ.ReverseTransaction{TransactionID: "tx-1"}, wantCode: resp.ErrDataInvalid},
+		{name: "missing transaction", req: modelsServ.ReverseTransaction{ReversalID: "r-1"}, wantCode: resp.ErrDataInvalid},
+		{name: "more than the remaining amount", req: modelsServ.ReverseTransaction{ReversalID: "r-1", TransactionID: "tx-1", Amount: 0.31}, wantCode: resp.ErrHandleAmountInvalid},
+		{name: "other currency", req: modelsServ.ReverseTransaction{ReversalID: "r-1", TransactionID: "tx-1", Currency: "EUR"}, wantCode: resp.ErrHandleCurrencyInvalid},
+		{name: "unknown transaction", req: modelsServ.ReverseTransaction{ReversalID: "r-1", TransactionID: "tx-2"}, wantCode: resp.ErrNotFound},
+		{name: "transaction of another profile", req: modelsServ.ReverseTransaction{ReversalID: "r-1", ProfileID: "p-2", TransactionID: "tx-1"}, wantCode: resp.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestPointService(newFakeReversalRepo(), &fakePublisher{})
+			_, errCustom := s.ReverseTransactionPoint(context.Background(), &tt.req)
+			if errCustom == nil || errCustom.ErrorCode != tt.wantCode {
+				t.Fatalf("ReverseTransactionPoint() = %v, want code %d", errCustom, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestReverseTransactionPointReusedID(t *testing.T) {
+	repo := newFakeReversalRepo()
+	repo.reversals["r-1"] = &user_transaction_history.UserTransactionHistory{TransactionID: "r-1", OriginalTxID: "tx-0"}
+	s := newTestPointService(repo, &fakePublisher{})
+
+	_, errCustom := s.ReverseTransactionPoint(context.Background(), &modelsServ.ReverseTransaction{ReversalID: "r-1", TransactionID: "tx-1"})
+	if errCustom == nil || errCustom.ErrorCode != resp.ErrDataInvalid {
+		t.Fatalf("ReverseTransactionPoint() = %v, want code %d", errCustom, resp.ErrDataInvalid)
+	}
+	if repo.original.ReversedAmount != 0 {
+		t.Errorf("reused reversal id reversed %v", repo.original.ReversedAmount)
+	}
+}
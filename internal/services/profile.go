@@ -1,6 +1,7 @@
 package services
 
 import (
+	"build-service-gin/client/eventpublisher"
 	"build-service-gin/common/logger"
 	"build-service-gin/config"
 	modelsServ "build-service-gin/internal/domains"
@@ -10,6 +11,7 @@ import (
 	"build-service-gin/repositories/user_transaction_history"
 	"build-service-gin/repositories/user_transaction_history_postgresql"
 	"context"
+	"errors"
 	"strings"
 	"time"
 
@@ -21,6 +23,7 @@ type ProfileService struct {
 	mongoRepo             mongotx.IMongoTxRepository
 	profileRepo           user_transaction_history.IUserTransactionHistoryRepo
 	profileRepoPostgresql user_transaction_history_postgresql.IUserTransactionHistoryPostgresSQLRepo
+	eventPublisher        eventpublisher.IEventPublisher
 	//redisClient           *redis.Client
 }
 
@@ -34,14 +37,18 @@ type IProfileService interface {
 
 	GetUserHistoryByProfilePostgresql(ctx context.Context, req modelsServ.GetUserTransactionHistoryReq) ([]modelsServ.UserTransactionHistory, int64, *resp.CustomError)
 	CreateUserTransactionHistoryPostgresql(ctx context.Context, order *modelsServ.UserTransactionHistory) (*modelsServ.UserTransactionHistory, *resp.CustomError)
+
+	GetUserHistoryByProfiles(ctx context.Context, req modelsServ.GetUserTransactionHistoriesReq) (map[string][]modelsServ.UserTransactionHistory, *resp.CustomError)
+	GetSummaryByProfiles(ctx context.Context, profileIDs []string, recentMonth int) (map[string]*modelsServ.ProfileSummary, *resp.CustomError)
 }
 
-func NewProfileService(conf *config.SystemConfig, mongoRepo mongotx.IMongoTxRepository, profileRepo user_transaction_history.IUserTransactionHistoryRepo, profileRepoPostgresql user_transaction_history_postgresql.IUserTransactionHistoryPostgresSQLRepo) IProfileService {
+func NewProfileService(conf *config.SystemConfig, mongoRepo mongotx.IMongoTxRepository, profileRepo user_transaction_history.IUserTransactionHistoryRepo, profileRepoPostgresql user_transaction_history_postgresql.IUserTransactionHistoryPostgresSQLRepo, eventPublisher eventpublisher.IEventPublisher) IProfileService {
 	return &ProfileService{
 		conf:                  conf,
 		mongoRepo:             mongoRepo,
 		profileRepo:           profileRepo,
 		profileRepoPostgresql: profileRepoPostgresql,
+		eventPublisher:        eventPublisher,
 		//redisClient:           redisClient,
 	}
 }
@@ -67,7 +74,7 @@ func (p *ProfileService) GetUserHistoryByProfile(ctx context.Context, req models
 	//get info user history
 	userHistoryTxs, totalTxs, err := p.profileRepo.GetUserTransactionHistoryByProfile(ctx, profileID, txTypes, recentMonth, req.Offset, req.Limit, req.Status)
 	if err != nil {
-		return nil, 0, &resp.CustomError{ErrorCode: resp.ErrNotFound, Description: err.Error()}
+		return nil, 0, repoError(err)
 	}
 
 	pointTxsServ := adapters.AdapterProfile{}.ConvRepo2DomainServArrayUserTransactionHistoryTx(userHistoryTxs)
@@ -76,32 +83,61 @@ func (p *ProfileService) GetUserHistoryByProfile(ctx context.Context, req models
 
 func (p *ProfileService) CreateUserTransactionHistory(ctx context.Context, order *modelsServ.UserTransactionHistory) (*modelsServ.UserTransactionHistory, *resp.CustomError) {
 	if order == nil {
-		return nil, &resp.CustomError{ErrorCode: resp.ErrNotFound, Description: "order cannot be nil"}
+		return nil, resp.NewError(resp.Invalid, resp.ErrDataInvalid, "order cannot be nil")
+	}
+	if errCustom := p.initTxStatus(order); errCustom != nil {
+		return nil, errCustom
 	}
 	pointTxsServ := adapters.AdapterProfile{}.ConvDomainToRepo(order)
 	userHistory, err := p.profileRepo.CreateUserTransactionHistory(ctx, &pointTxsServ)
 	if err != nil {
-		return nil, &resp.CustomError{ErrorCode: resp.ErrNotFound, Description: err.Error()}
+		return nil, storeError(err)
 	}
 	pointTxsServToDomain := adapters.AdapterProfile{}.ConvRepoToDomain(userHistory)
+	publishTransactionEvent(ctx, p.eventPublisher, modelsServ.TxEventCreated, pointTxsServToDomain, creditedPoints(pointTxsServToDomain))
 	return pointTxsServToDomain, nil
 }
 
 func (p *ProfileService) UpdateUserTransactionHistoryByProfile(ctx context.Context, order *modelsServ.UserTransactionHistory, profileID string) (*modelsServ.UserTransactionHistory, *resp.CustomError) {
+	order.Status = modelsServ.NormalizeTxStatus(order.Status)
+	if !modelsServ.IsValidTxStatus(order.Status) {
+		return nil, &resp.CustomError{ErrorCode: resp.ErrDataInvalid, Description: "status invalid: " + order.Status}
+	}
+	// a reversal also books its REVERSAL record and the balance, only ReverseTransactionPoint moves a transaction there
+	if order.Status == modelsServ.TxStatusReversed {
+		return nil, resp.NewError(resp.Invalid, resp.ErrDataInvalid, "status REVERSED is set by reversing the transaction")
+	}
+
 	pointTxsServ := adapters.AdapterProfile{}.ConvDomainToRepo(order)
-	userHistory, err := p.profileRepo.UpdateUserTransactionHistoryByProfile(ctx, &pointTxsServ, profileID)
+	userHistory, statusChanged, err := p.profileRepo.UpdateUserTransactionHistoryByProfile(ctx, &pointTxsServ, profileID, modelsServ.AllowedPrevTxStatuses(order.Status))
 	if err != nil {
-		return order, nil
+		if errors.Is(err, user_transaction_history.ErrStatusTransitionRejected) {
+			return nil, resp.WrapError(resp.Conflict, resp.ErrHandleTxStatusTransitionInvalid, err)
+		}
+		return nil, storeError(err)
 	}
 	pointTxsServToDomain := adapters.AdapterProfile{}.ConvRepoToDomain(userHistory)
+	// the points are credited when the transaction moves to its status, not again on each edit
+	var pointDelta int64
+	if statusChanged {
+		pointDelta = creditedPoints(pointTxsServToDomain)
+	}
+	publishTransactionEvent(ctx, p.eventPublisher, modelsServ.TxEventUpdated, pointTxsServToDomain, pointDelta)
 	return pointTxsServToDomain, nil
 }
 
 func (p *ProfileService) DeleteUserTransactionHistoryByProfile(ctx context.Context, profileID string) *resp.CustomError {
-	err := p.profileRepo.DeleteUserTransactionHistoryByProfile(ctx, profileID)
+	deleted, err := p.profileRepo.DeleteUserTransactionHistoryByProfile(ctx, profileID)
 	if err != nil {
-		return nil
+		return storeError(err)
+	}
+
+	deletedDomain := adapters.AdapterProfile{}.ConvRepoToDomain(deleted)
+	var pointDelta int64
+	if deletedDomain.CountsTowardBalance() {
+		pointDelta = -deletedDomain.PointAmount
 	}
+	publishTransactionEvent(ctx, p.eventPublisher, modelsServ.TxEventDeleted, deletedDomain, pointDelta)
 	return nil
 }
 
@@ -109,13 +145,16 @@ func (p *ProfileService) CreateOrderTransactionPoint(ctx context.Context, order
 	log := logger.GetLogger().AddTraceInfoContextRequest(ctx)
 	log.Info().Interface("order", order).Msg("CreateOrderTransactionPoint - Start")
 
+	var upserted *user_transaction_history.UserTransactionHistory
 	err := p.mongoRepo.ExecTransaction(ctx, func(sessionCtx mongo.SessionContext) (interface{}, error) {
 		// Convert order to repo models
 		newOrder := &modelsServ.OrderSuccessEvent{}
 		newOrder.BuildCreateOrderTransaction(order)
+		newOrder.Status = modelsServ.TxStatusPending
 		orderRepoModel := adapters.AdapterProfile{}.ConvertOrderCreateDomainToRepo(newOrder)
 
-		if err := p.profileRepo.UpsertCreateOrderTransaction(sessionCtx, orderRepoModel); err != nil {
+		var err error
+		if upserted, err = p.profileRepo.UpsertCreateOrderTransaction(sessionCtx, orderRepoModel); err != nil {
 			log.Error().Err(err).Msg("CreateOrderTransactionPoint - Failed to upsert order")
 			return nil, err
 		}
@@ -125,9 +164,15 @@ func (p *ProfileService) CreateOrderTransactionPoint(ctx context.Context, order
 	})
 
 	if err != nil {
-		return &resp.CustomError{ErrorCode: resp.ErrSystem, Description: err.Error()}
+		return resp.WrapError(resp.Internal, resp.ErrSystem, err)
 	}
 
+	// created and updated times only match when the upsert inserted the transaction
+	eventType := modelsServ.TxEventUpdated
+	if upserted.CreatedAt != nil && upserted.UpdatedAt != nil && upserted.CreatedAt.Equal(*upserted.UpdatedAt) {
+		eventType = modelsServ.TxEventCreated
+	}
+	publishTransactionEvent(ctx, p.eventPublisher, eventType, adapters.AdapterProfile{}.ConvRepoToDomain(upserted), 0)
 	return nil
 }
 
@@ -135,21 +180,40 @@ func (p *ProfileService) CompleteTransactionPoint(ctx context.Context, order *mo
 	log := logger.GetLogger().AddTraceInfoContextRequest(ctx)
 	log.Info().Interface("order", order).Msg("CompleteOrderEarnPoint - Start")
 
+	order.Status = modelsServ.NormalizeTxStatus(order.Status)
+	if order.Status == "" {
+		order.Status = modelsServ.TxStatusSuccess
+	}
+	if !modelsServ.IsCompletionTxStatus(order.Status) {
+		return &resp.CustomError{ErrorCode: resp.ErrDataInvalid, Description: "status invalid for a completion: " + order.Status}
+	}
+
+	var completed *user_transaction_history.UserTransactionHistory
 	err := p.mongoRepo.ExecTransaction(ctx, func(sessionCtx mongo.SessionContext) (interface{}, error) {
 		orderRepoModel := adapters.AdapterProfile{}.ConvertCompleteOrderDomainToRepo(order)
 
-		if err := p.profileRepo.UpsertCompleteOrderTransaction(sessionCtx, orderRepoModel); err != nil {
+		var err error
+		if completed, err = p.profileRepo.UpsertCompleteOrderTransaction(sessionCtx, orderRepoModel, modelsServ.AllowedPrevTxStatuses(order.Status)); err != nil {
 			return nil, err
 		}
 		return nil, nil
 	})
 
+	if errors.Is(err, user_transaction_history.ErrStatusUnchanged) {
+		log.Info().Str("status", order.Status).Msg("CompleteOrderEarnPoint - Order already completed, redelivery ignored")
+		return nil
+	}
 	if err != nil {
 		log.Error().Err(err).Msg("CompleteOrderEarnPoint - Transaction failed")
-		return &resp.CustomError{ErrorCode: resp.ErrSystem, Description: err.Error()}
+		if errors.Is(err, user_transaction_history.ErrStatusTransitionRejected) {
+			return resp.WrapError(resp.Conflict, resp.ErrHandleTxStatusTransitionInvalid, err)
+		}
+		return resp.WrapError(resp.Internal, resp.ErrSystem, err)
 	}
 
 	log.Info().Msg("CompleteOrderEarnPoint - Order upsert successfully")
+	completedDomain := adapters.AdapterProfile{}.ConvRepoToDomain(completed)
+	publishTransactionEvent(ctx, p.eventPublisher, modelsServ.TxEventCompleted, completedDomain, creditedPoints(completedDomain))
 	return nil
 }
 
@@ -185,7 +249,7 @@ func (p *ProfileService) GetUserHistoryByProfilePostgresql(ctx context.Context,
 	// Step 3: Query user transaction history from the PostgreSQL database
 	userHistoryTxs, totalTxs, err := p.profileRepoPostgresql.GetUserTransactionHistoryByProfile(ctx, profileID, txTypes, recentMonth, req.Offset, req.Limit, req.Status)
 	if err != nil {
-		return nil, 0, &resp.CustomError{ErrorCode: resp.ErrNotFound, Description: err.Error()}
+		return nil, 0, repoError(err)
 	}
 
 	// Step 4: Convert repository model to service model
@@ -201,13 +265,89 @@ func (p *ProfileService) GetUserHistoryByProfilePostgresql(ctx context.Context,
 
 func (p *ProfileService) CreateUserTransactionHistoryPostgresql(ctx context.Context, order *modelsServ.UserTransactionHistory) (*modelsServ.UserTransactionHistory, *resp.CustomError) {
 	if order == nil {
-		return nil, &resp.CustomError{ErrorCode: resp.ErrNotFound, Description: "order cannot be nil"}
+		return nil, resp.NewError(resp.Invalid, resp.ErrDataInvalid, "order cannot be nil")
+	}
+	if errCustom := p.initTxStatus(order); errCustom != nil {
+		return nil, errCustom
 	}
 	pointTxsServ := adapters.AdapterProfile{}.ConvDomainToRepoPostgresql(order)
 	userHistory, err := p.profileRepoPostgresql.CreateUserTransactionHistory(ctx, &pointTxsServ)
 	if err != nil {
-		return nil, &resp.CustomError{ErrorCode: resp.ErrNotFound, Description: err.Error()}
+		return nil, storeError(err)
 	}
 	pointTxsServToDomain := adapters.AdapterProfile{}.ConvRepoToDomainPostgresql(userHistory)
+	publishTransactionEvent(ctx, p.eventPublisher, modelsServ.TxEventCreated, pointTxsServToDomain, creditedPoints(pointTxsServToDomain))
 	return pointTxsServToDomain, nil
 }
+
+// initTxStatus normalizes the status of a new transaction, defaulting to PENDING.
+func (p *ProfileService) initTxStatus(order *modelsServ.UserTransactionHistory) *resp.CustomError {
+	order.Status = modelsServ.NormalizeTxStatus(order.Status)
+	if order.Status == "" {
+		order.Status = modelsServ.TxStatusPending
+	}
+	if !modelsServ.IsValidTxStatus(order.Status) {
+		return &resp.CustomError{ErrorCode: resp.ErrDataInvalid, Description: "status invalid: " + order.Status}
+	}
+	return nil
+}
+
+// GetUserHistoryByProfiles reads the history of every profile of the request in one query, by profile ID.
+func (p *ProfileService) GetUserHistoryByProfiles(ctx context.Context, req modelsServ.GetUserTransactionHistoriesReq) (map[string][]modelsServ.UserTransactionHistory, *resp.CustomError) {
+	var txTypes []string
+	var recentMonth time.Time
+
+	if req.TxType != "" {
+		txTypes = []string{modelsServ.NormalizeTxType(req.TxType)}
+	}
+	if req.RecentMonth > 0 {
+		recentMonth = time.Now().AddDate(0, -req.RecentMonth, 0)
+	}
+
+	var after *user_transaction_history.HistoryCursor
+	if req.After != nil {
+		after = &user_transaction_history.HistoryCursor{CreatedAt: req.After.CreatedAt, TransactionID: req.After.TransactionID}
+	}
+
+	histories, err := p.profileRepo.GetUserTransactionHistoryByProfiles(ctx, req.ProfileIDs, txTypes, recentMonth, modelsServ.NormalizeTxStatus(req.Status), after, req.First)
+	if err != nil {
+		return nil, repoError(err)
+	}
+
+	byProfile := make(map[string][]modelsServ.UserTransactionHistory, len(histories))
+	for _, history := range histories {
+		byProfile[history.ProfileID] = adapters.AdapterProfile{}.ConvRepo2DomainServArrayUserTransactionHistoryTx(history.Items)
+	}
+	return byProfile, nil
+}
+
+// GetSummaryByProfiles reads the balance and the stats of the last recentMonth months of every profile of profileIDs
+// in one aggregation, by profile ID. Profiles without transactions get an empty summary.
+func (p *ProfileService) GetSummaryByProfiles(ctx context.Context, profileIDs []string, recentMonth int) (map[string]*modelsServ.ProfileSummary, *resp.CustomError) {
+	var since time.Time
+	if recentMonth > 0 {
+		since = time.Now().AddDate(0, -recentMonth, 0)
+	}
+
+	statusSummaries, err := p.profileRepo.SummarizeByProfiles(ctx, profileIDs, since)
+	if err != nil {
+		return nil, repoError(err)
+	}
+
+	summaries := make(map[string]*modelsServ.ProfileSummary, len(profileIDs))
+	for _, profileID := range profileIDs {
+		summaries[profileID] = &modelsServ.ProfileSummary{
+			Balance: modelsServ.ProfileBalance{ProfileID: profileID},
+			Stats:   modelsServ.ProfileStats{ProfileID: profileID, RecentMonth: recentMonth},
+		}
+	}
+
+	for _, item := range statusSummaries {
+		summary, ok := summaries[item.ProfileID]
+		if !ok {
+			continue
+		}
+		adapters.AdapterProfile{}.AddStatusSummary2Domain(summary, item)
+	}
+	return summaries, nil
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"build-service-gin/client/eventpublisher"
+	"build-service-gin/common/logger"
+	"build-service-gin/config"
+	modelsServ "build-service-gin/internal/domains"
+	"build-service-gin/pkg/helpers/resp"
+	"build-service-gin/repositories/user_transaction_history"
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLog("test")
+	os.Exit(m.Run())
+}
+
+// fakeTx runs the callback of a transaction without a session.
+type fakeTx struct{}
+
+func (fakeTx) ExecTransaction(ctx context.Context, callback func(sessCtx mongo.SessionContext) (interface{}, error)) error {
+	_, err := callback(mongo.NewSessionContext(ctx, nil))
+	return err
+}
+
+// fakeHistoryRepo answers the status guarded writes with err, the other methods are not expected to be called.
+type fakeHistoryRepo struct {
+	user_transaction_history.IUserTransactionHistoryRepo
+	current      *user_transaction_history.UserTransactionHistory
+	err          error
+	sameStatus   bool
+	fromStatuses []string
+	txTypes      []string
+	status       string
+}
+
+func (r *fakeHistoryRepo) UpsertCompleteOrderTransaction(_ context.Context, data *user_transaction_history.UserTransactionHistory, fromStatuses []string) (*user_transaction_history.UserTransactionHistory, error) {
+	r.fromStatuses = fromStatuses
+	if r.err != nil {
+		return r.current, r.err
+	}
+	return data, nil
+}
+
+func (r *fakeHistoryRepo) UpdateUserTransactionHistoryByProfile(_ context.Context, data *user_transaction_history.UserTransactionHistory, _ string, fromStatuses []string) (*user_transaction_history.UserTransactionHistory, bool, error) {
+	r.fromStatuses = fromStatuses
+	if r.err != nil {
+		return r.current, false, r.err
+	}
+	return data, !r.sameStatus, nil
+}
+
+func (r *fakeHistoryRepo) GetUserTransactionHistoryByProfiles(_ context.Context, _ []string, txTypes []string, _ time.Time, status string, _ *user_transaction_history.HistoryCursor, _ int64) ([]*user_transaction_history.ProfileTransactions, error) {
+	r.txTypes, r.status = txTypes, status
+	return nil, r.err
+}
+
+type fakePublisher struct {
+	eventpublisher.IEventPublisher
+	events      []string
+	pointDeltas []int64
+}
+
+func (p *fakePublisher) PublishTransactionEvent(_ context.Context, eventType string, _ *modelsServ.UserTransactionHistory) error {
+	p.events = append(p.events, eventType)
+	return nil
+}
+
+func (p *fakePublisher) PublishBalanceChanged(_ context.Context, _ *modelsServ.UserTransactionHistory, _ string, pointDelta int64) error {
+	p.pointDeltas = append(p.pointDeltas, pointDelta)
+	return nil
+}
+
+func newTestProfileService(repo *fakeHistoryRepo, publisher *fakePublisher) *ProfileService {
+	return NewProfileService(&config.SystemConfig{}, fakeTx{}, repo, nil, publisher).(*ProfileService)
+}
+
+func TestCompleteTransactionPoint(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     string
+		repoErr    error
+		wantCode   int64
+		wantEvents int
+	}{
+		{name: "success completes", status: modelsServ.TxStatusSuccess, wantEvents: 1},
+		{name: "empty status completes as success", status: "", wantEvents: 1},
+		{name: "pending is not a completion", status: modelsServ.TxStatusPending, wantCode: resp.ErrDataInvalid},
+		{name: "reversed is not a completion", status: modelsServ.TxStatusReversed, wantCode: resp.ErrDataInvalid},
+		{name: "late completion of a reversed transaction", status: modelsServ.TxStatusSuccess, repoErr: user_transaction_history.ErrStatusTransitionRejected, wantCode: resp.ErrHandleTxStatusTransitionInvalid},
+		{name: "redelivered completion is a no-op", status: modelsServ.TxStatusSuccess, repoErr: user_transaction_history.ErrStatusUnchanged},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeHistoryRepo{err: tt.repoErr, current: &user_transaction_history.UserTransactionHistory{Status: modelsServ.TxStatusSuccess}}
+			publisher := &fakePublisher{}
+			s := newTestProfileService(repo, publisher)
+
+			errCustom := s.CompleteTransactionPoint(context.Background(), &modelsServ.EarnPointOrderEvent{TransactionID: "tx-1", Status: tt.status})
+			switch {
+			case tt.wantCode == 0 && errCustom != nil:
+				t.Fatalf("CompleteTransactionPoint() = %v, want nil", errCustom)
+			case tt.wantCode != 0 && (errCustom == nil || errCustom.ErrorCode != tt.wantCode):
+				t.Fatalf("CompleteTransactionPoint() = %v, want code %d", errCustom, tt.wantCode)
+			}
+			if len(publisher.events) != tt.wantEvents {
+				t.Errorf("published %v, want %d events", publisher.events, tt.wantEvents)
+			}
+		})
+	}
+}
+
+func TestUpdateUserTransactionHistoryByProfile(t *testing.T) {
+	tests := []struct {
+		name            string
+		status          string
+		sameStatus      bool
+		repoErr         error
+		wantErr         error
+		wantEvents      int
+		wantPointDeltas int
+	}{
+		{name: "allowed transition credits the points", status: modelsServ.TxStatusSuccess, wantEvents: 1, wantPointDeltas: 1},
+		{name: "rejected transition", status: modelsServ.TxStatusPending, repoErr: user_transaction_history.ErrStatusTransitionRejected, wantErr: resp.Conflict},
+		{name: "same status edit does not credit the points again", status: modelsServ.TxStatusSuccess, sameStatus: true, wantEvents: 1},
+		{name: "unknown status", status: "DONE", wantErr: resp.Invalid},
+		{name: "reversed is only reached by a reversal", status: modelsServ.TxStatusReversed, wantErr: resp.Invalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeHistoryRepo{err: tt.repoErr, sameStatus: tt.sameStatus, current: &user_transaction_history.UserTransactionHistory{TransactionID: "tx-1", Status: modelsServ.TxStatusSuccess}}
+			publisher := &fakePublisher{}
+			s := newTestProfileService(repo, publisher)
+
+			got, errCustom := s.UpdateUserTransactionHistoryByProfile(context.Background(), &modelsServ.UserTransactionHistory{TransactionID: "tx-1", Status: tt.status, PointAmount: 10}, "p1")
+			if tt.wantErr == nil {
+				if errCustom != nil || got == nil {
+					t.Fatalf("UpdateUserTransactionHistoryByProfile() = %v, %v", got, errCustom)
+				}
+			} else if !errors.Is(errCustom, tt.wantErr) {
+				t.Fatalf("UpdateUserTransactionHistoryByProfile() error = %v, want %v", errCustom, tt.wantErr)
+			}
+			if len(publisher.events) != tt.wantEvents {
+				t.Errorf("published %v, want %d events", publisher.events, tt.wantEvents)
+			}
+			if len(publisher.pointDeltas) != tt.wantPointDeltas {
+				t.Errorf("published the point deltas %v, want %d", publisher.pointDeltas, tt.wantPointDeltas)
+			}
+		})
+	}
+}
+
+func TestGetUserHistoryByProfilesNormalizesFilters(t *testing.T) {
+	tests := []struct {
+		name        string
+		txType      string
+		status      string
+		wantTxTypes []string
+		wantStatus  string
+	}{
+		{name: "no filter"},
+		{name: "canonical filters", txType: modelsServ.TxTypeEarn, status: modelsServ.TxStatusSuccess, wantTxTypes: []string{modelsServ.TxTypeEarn}, wantStatus: modelsServ.TxStatusSuccess},
+		{name: "filters in lower case", txType: " tx_earn ", status: "success", wantTxTypes: []string{modelsServ.TxTypeEarn}, wantStatus: modelsServ.TxStatusSuccess},
+		{name: "reversal type", txType: "reversal", wantTxTypes: []string{modelsServ.TxTypeReversal}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeHistoryRepo{}
+			s := newTestProfileService(repo, &fakePublisher{})
+
+			_, errCustom := s.GetUserHistoryByProfiles(context.Background(), modelsServ.GetUserTransactionHistoriesReq{ProfileIDs: []string{"p1"}, TxType: tt.txType, Status: tt.status, First: 20})
+			if errCustom != nil {
+				t.Fatalf("GetUserHistoryByProfiles() = %v", errCustom)
+			}
+			if !slices.Equal(repo.txTypes, tt.wantTxTypes) || repo.status != tt.wantStatus {
+				t.Errorf("read with types %v and status %q, want %v and %q", repo.txTypes, repo.status, tt.wantTxTypes, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"build-service-gin/api/msgbroker/models"
+	"build-service-gin/common/logger"
+	"build-service-gin/config"
+	"build-service-gin/pkg/helpers/resp"
+	"build-service-gin/pkg/queue/envelope"
+	"build-service-gin/pkg/stream"
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	StreamEventTransaction = "transaction"
+	StreamEventBalance     = "balance"
+)
+
+// publishTimeout bounds the time a domain event waits for the stream, the stream must not slow the writes down.
+const publishTimeout = 2 * time.Second
+
+// streamEvents maps the domain events sent to the streams of profiles to the name of their stream event.
+var streamEvents = map[string]string{
+	models.EventTypeTransactionCreated:   StreamEventTransaction,
+	models.EventTypeTransactionCompleted: StreamEventTransaction,
+	models.EventTypeTransactionUpdated:   StreamEventTransaction,
+	models.EventTypeTransactionDeleted:   StreamEventTransaction,
+	models.EventTypeBalanceChanged:       StreamEventBalance,
+}
+
+type StreamService struct {
+	conf *config.SystemConfig
+	hub  *stream.Hub
+}
+
+type IStreamService interface {
+	Subscribe(ctx context.Context, profileID, lastEventID string) (*stream.Subscription, *resp.CustomError)
+	Dispatch(ctx context.Context, env *envelope.Envelope)
+}
+
+func NewStreamService(conf *config.SystemConfig, hub *stream.Hub) IStreamService {
+	return &StreamService{
+		conf: conf,
+		hub:  hub,
+	}
+}
+
+// Subscribe subscribes to the point updates of profileID, resuming after lastEventID when set.
+func (s *StreamService) Subscribe(ctx context.Context, profileID, lastEventID string) (*stream.Subscription, *resp.CustomError) {
+	sub, err := s.hub.Subscribe(ctx, profileID, lastEventID)
+	switch {
+	case err == nil:
+		return sub, nil
+	case errors.Is(err, stream.ErrInvalidEventID):
+		errCustom := resp.WrapError(resp.Invalid, resp.ErrDataInvalid, err)
+		errCustom.Fields = []resp.FieldError{{Field: "lastEventID", Rule: "event_id", Message: "lastEventID is not the id of an event"}}
+		return nil, errCustom
+	case errors.Is(err, stream.ErrTooManyConnections), errors.Is(err, stream.ErrClosed):
+		return nil, resp.WrapError(resp.Unavailable, resp.ErrStreamLimit, err)
+	case errors.Is(err, stream.ErrTooManyProfileConnections):
+		return nil, resp.WrapError(resp.TooManyRequests, resp.ErrStreamLimit, err)
+	}
+	return nil, resp.WrapError(resp.Internal, resp.ErrSystem, err)
+}
+
+// Dispatch sends the transaction and balance events to the stream of their profile, it is a listener of the event
+// publisher.
+func (s *StreamService) Dispatch(ctx context.Context, env *envelope.Envelope) {
+	name, ok := streamEvents[env.Type]
+	if !ok {
+		return
+	}
+	log := logger.GetLogger().AddTraceInfoContextRequest(ctx)
+
+	var target struct {
+		ProfileID string `json:"profileID"`
+	}
+	if err := json.Unmarshal(env.Data, &target); err != nil || target.ProfileID == "" {
+		log.Warn().Err(err).Str("event", env.Type).Msg("Dispatch stream - event has no profile")
+		return
+	}
+
+	data, err := json.Marshal(env)
+	if err != nil {
+		log.Error().Err(err).Str("event", env.Type).Msg("Dispatch stream - marshal envelope failed")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), publishTimeout)
+	defer cancel()
+	if err := s.hub.Publish(ctx, target.ProfileID, name, data); err != nil {
+		log.Error().Err(err).Str("event", env.Type).Msg("Dispatch stream - publish failed")
+	}
+}
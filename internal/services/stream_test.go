@@ -0,0 +1,46 @@
+package services
+
+import (
+	"build-service-gin/config"
+	"build-service-gin/pkg/helpers/resp"
+	"build-service-gin/pkg/stream"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStreamSubscribeErrors(t *testing.T) {
+	conf := &config.SystemConfig{StreamConfig: config.StreamConfig{MaxConnections: 2, MaxProfileConnections: 1}}
+	s := NewStreamService(conf, stream.NewHub(nil, conf.StreamConfig))
+
+	if _, errCustom := s.Subscribe(context.Background(), "p1", ""); errCustom != nil {
+		t.Fatalf("Subscribe() = %v", errCustom)
+	}
+
+	tests := []struct {
+		name        string
+		profileID   string
+		lastEventID string
+		wantErr     error
+		wantStatus  int
+		// opened is subscribed to before
+		opened string
+	}{
+		{name: "invalid last event id", profileID: "p2", lastEventID: "last", wantErr: resp.Invalid, wantStatus: 400},
+		{name: "profile limit", profileID: "p1", wantErr: resp.TooManyRequests, wantStatus: 429},
+		{name: "instance limit", opened: "p2", profileID: "p3", wantErr: resp.Unavailable, wantStatus: 503},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.opened != "" {
+				if _, errCustom := s.Subscribe(context.Background(), tt.opened, ""); errCustom != nil {
+					t.Fatalf("Subscribe() = %v", errCustom)
+				}
+			}
+			_, errCustom := s.Subscribe(context.Background(), tt.profileID, tt.lastEventID)
+			if !errors.Is(errCustom, tt.wantErr) || resp.HTTPStatus(errCustom) != tt.wantStatus {
+				t.Errorf("Subscribe() = %v, want %v", errCustom, tt.wantErr)
+			}
+		})
+	}
+}
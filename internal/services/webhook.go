@@ -0,0 +1,388 @@
+package services
+
+import (
+	"build-service-gin/client/webhook"
+	"build-service-gin/common/logger"
+	"build-service-gin/common/utils"
+	"build-service-gin/config"
+	modelsServ "build-service-gin/internal/domains"
+	"build-service-gin/pkg/helpers/adapters"
+	"build-service-gin/pkg/helpers/resp"
+	"build-service-gin/pkg/netguard"
+	"build-service-gin/pkg/queue/envelope"
+	"build-service-gin/repositories/webhook_delivery"
+	"build-service-gin/repositories/webhook_subscription"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	mathrand "math/rand"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// retryBatchSize bounds the deliveries attempted again at every RetryInterval.
+const retryBatchSize = 100
+
+type WebhookService struct {
+	conf             *config.SystemConfig
+	webhookClient    webhook.IWebhookClient
+	subscriptionRepo webhook_subscription.IWebhookSubscriptionRepo
+	deliveryRepo     webhook_delivery.IWebhookDeliveryRepo
+}
+
+type IWebhookService interface {
+	CreateSubscription(ctx context.Context, sub *modelsServ.WebhookSubscription) (*modelsServ.WebhookSubscription, *resp.CustomError)
+	GetSubscription(ctx context.Context, subscriptionID string) (*modelsServ.WebhookSubscription, *resp.CustomError)
+	ListSubscriptions(ctx context.Context, offset, limit int64) ([]modelsServ.WebhookSubscription, int64, *resp.CustomError)
+	UpdateSubscription(ctx context.Context, sub *modelsServ.WebhookSubscription) (*modelsServ.WebhookSubscription, *resp.CustomError)
+	DeleteSubscription(ctx context.Context, subscriptionID string) *resp.CustomError
+	ListDeliveries(ctx context.Context, req modelsServ.GetWebhookDeliveriesReq) ([]modelsServ.WebhookDelivery, int64, *resp.CustomError)
+	GetDelivery(ctx context.Context, deliveryID string) (*modelsServ.WebhookDelivery, *resp.CustomError)
+	Redeliver(ctx context.Context, deliveryID string) (*modelsServ.WebhookDelivery, *resp.CustomError)
+	Dispatch(ctx context.Context, env *envelope.Envelope)
+	StartRetries(ctx context.Context)
+}
+
+func NewWebhookService(
+	conf *config.SystemConfig,
+	webhookClient webhook.IWebhookClient,
+	subscriptionRepo webhook_subscription.IWebhookSubscriptionRepo,
+	deliveryRepo webhook_delivery.IWebhookDeliveryRepo,
+) IWebhookService {
+	return &WebhookService{
+		conf:             conf,
+		webhookClient:    webhookClient,
+		subscriptionRepo: subscriptionRepo,
+		deliveryRepo:     deliveryRepo,
+	}
+}
+
+func (s *WebhookService) CreateSubscription(ctx context.Context, sub *modelsServ.WebhookSubscription) (*modelsServ.WebhookSubscription, *resp.CustomError) {
+	if errCustom := s.validateSubscription(ctx, sub); errCustom != nil {
+		return nil, errCustom
+	}
+
+	sub.SubscriptionID = utils.GetIdGenerate().GetIDStringV2()
+	if sub.Secret == "" {
+		secret, err := generateWebhookSecret()
+		if err != nil {
+			return nil, resp.WrapError(resp.Internal, resp.ErrSystem, err)
+		}
+		sub.Secret = secret
+	}
+
+	created, err := s.subscriptionRepo.CreateSubscription(ctx, adapters.AdapterWebhook{}.ConvDomainToRepoSubscription(sub))
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	// the secret is only returned once, when the subscription is created
+	return adapters.AdapterWebhook{}.ConvRepoToDomainSubscription(created), nil
+}
+
+func (s *WebhookService) GetSubscription(ctx context.Context, subscriptionID string) (*modelsServ.WebhookSubscription, *resp.CustomError) {
+	sub, err := s.subscriptionRepo.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, repoError(err)
+	}
+
+	data := adapters.AdapterWebhook{}.ConvRepoToDomainSubscription(sub)
+	data.Secret = ""
+	return data, nil
+}
+
+func (s *WebhookService) ListSubscriptions(ctx context.Context, offset, limit int64) ([]modelsServ.WebhookSubscription, int64, *resp.CustomError) {
+	subs, total, err := s.subscriptionRepo.ListSubscriptions(ctx, offset, limit)
+	if err != nil {
+		return nil, 0, repoError(err)
+	}
+
+	data := adapters.AdapterWebhook{}.ConvRepoToDomainArraySubscription(subs)
+	for i := range data {
+		data[i].Secret = ""
+	}
+	return data, total, nil
+}
+
+func (s *WebhookService) UpdateSubscription(ctx context.Context, sub *modelsServ.WebhookSubscription) (*modelsServ.WebhookSubscription, *resp.CustomError) {
+	if errCustom := s.validateSubscription(ctx, sub); errCustom != nil {
+		return nil, errCustom
+	}
+
+	updated, err := s.subscriptionRepo.UpdateSubscription(ctx, adapters.AdapterWebhook{}.ConvDomainToRepoSubscription(sub))
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	data := adapters.AdapterWebhook{}.ConvRepoToDomainSubscription(updated)
+	data.Secret = ""
+	return data, nil
+}
+
+func (s *WebhookService) DeleteSubscription(ctx context.Context, subscriptionID string) *resp.CustomError {
+	if err := s.subscriptionRepo.DeleteSubscription(ctx, subscriptionID); err != nil {
+		return storeError(err)
+	}
+	return nil
+}
+
+func (s *WebhookService) ListDeliveries(ctx context.Context, req modelsServ.GetWebhookDeliveriesReq) ([]modelsServ.WebhookDelivery, int64, *resp.CustomError) {
+	deliveries, total, err := s.deliveryRepo.ListDeliveriesBySubscription(ctx, req.SubscriptionID, req.Status, req.Offset, req.Limit)
+	if err != nil {
+		return nil, 0, repoError(err)
+	}
+	return adapters.AdapterWebhook{}.ConvRepoToDomainArrayDelivery(deliveries), total, nil
+}
+
+func (s *WebhookService) GetDelivery(ctx context.Context, deliveryID string) (*modelsServ.WebhookDelivery, *resp.CustomError) {
+	delivery, err := s.deliveryRepo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, repoError(err)
+	}
+	return adapters.AdapterWebhook{}.ConvRepoToDomainDelivery(delivery), nil
+}
+
+// Redeliver sends the payload of a previous delivery again as a new delivery linked to it, so the log of the
+// original stays untouched. Disabled subscriptions must be re-enabled first.
+func (s *WebhookService) Redeliver(ctx context.Context, deliveryID string) (*modelsServ.WebhookDelivery, *resp.CustomError) {
+	original, err := s.deliveryRepo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, repoError(err)
+	}
+
+	sub, err := s.subscriptionRepo.GetSubscription(ctx, original.SubscriptionID)
+	if err != nil {
+		return nil, repoError(err)
+	}
+	if !sub.Enabled {
+		return nil, &resp.CustomError{ErrorCode: resp.ErrHandleWebhookDisabled, Description: sub.DisabledReason}
+	}
+
+	delivery, err := s.deliveryRepo.CreateDelivery(ctx, &webhook_delivery.WebhookDelivery{
+		DeliveryID:     utils.GetIdGenerate().GetIDStringV2(),
+		SubscriptionID: original.SubscriptionID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         modelsServ.WebhookDeliveryPending,
+		RedeliveryOf:   original.DeliveryID,
+		NextAttemptAt:  s.leaseUntil(),
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	go s.deliver(context.WithoutCancel(ctx), sub, delivery)
+	return adapters.AdapterWebhook{}.ConvRepoToDomainDelivery(delivery), nil
+}
+
+// Dispatch queues a delivery of env for every enabled subscription listening to its type. It is registered as
+// an event publisher listener, which already runs off the request path; deliveries run in the background.
+func (s *WebhookService) Dispatch(ctx context.Context, env *envelope.Envelope) {
+	log := logger.GetLogger().AddTraceInfoContextRequest(ctx)
+
+	subs, err := s.subscriptionRepo.FindActiveByEventType(ctx, env.Type)
+	if err != nil {
+		log.Error().Err(err).Str("event", env.Type).Msg("Dispatch webhook - find subscriptions failed")
+		return
+	}
+	if len(subs) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(env)
+	if err != nil {
+		log.Error().Err(err).Str("event", env.Type).Msg("Dispatch webhook - marshal envelope failed")
+		return
+	}
+
+	for _, sub := range subs {
+		delivery, err := s.deliveryRepo.CreateDelivery(ctx, &webhook_delivery.WebhookDelivery{
+			DeliveryID:     utils.GetIdGenerate().GetIDStringV2(),
+			SubscriptionID: sub.SubscriptionID,
+			EventID:        env.ID,
+			EventType:      env.Type,
+			Payload:        string(payload),
+			Status:         modelsServ.WebhookDeliveryPending,
+			NextAttemptAt:  s.leaseUntil(),
+		})
+		if err != nil {
+			log.Error().Err(err).Str("subscriptionID", sub.SubscriptionID).Msg("Dispatch webhook - create delivery failed")
+			continue
+		}
+
+		go s.deliver(ctx, sub, delivery)
+	}
+}
+
+// StartRetries attempts again, every RetryInterval, the deliveries whose next attempt is due until ctx is done. The
+// schedule is stored on the deliveries, so the retries of every instance survive a restart.
+func (s *WebhookService) StartRetries(ctx context.Context) {
+	ticker := time.NewTicker(s.conf.WebhookConfig.RetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.retryDue(ctx)
+		}
+	}
+}
+
+// retryDue claims the due deliveries and attempts them in the background. A delivery claimed by an instance that
+// stops before recording its attempt is due again once the lease is over.
+func (s *WebhookService) retryDue(ctx context.Context) {
+	log := logger.GetLogger().AddTraceInfoContextRequest(ctx)
+	statuses := []string{modelsServ.WebhookDeliveryPending, modelsServ.WebhookDeliveryRetrying}
+
+	for i := 0; i < retryBatchSize; i++ {
+		delivery, err := s.deliveryRepo.ClaimDueDelivery(ctx, statuses, time.Now(), *s.leaseUntil())
+		if err != nil {
+			if !errors.Is(err, mongo.ErrNoDocuments) && ctx.Err() == nil {
+				log.Error().Err(err).Msg("Retry webhooks - claim due delivery failed")
+			}
+			return
+		}
+
+		sub, err := s.subscriptionRepo.GetSubscription(ctx, delivery.SubscriptionID)
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			s.abandon(ctx, delivery, "subscription deleted")
+			continue
+		case err != nil:
+			log.Error().Err(err).Str("deliveryID", delivery.DeliveryID).Msg("Retry webhooks - get subscription failed")
+			continue
+		case !sub.Enabled:
+			s.abandon(ctx, delivery, "subscription disabled")
+			continue
+		}
+
+		go s.deliver(context.WithoutCancel(ctx), sub, delivery)
+	}
+}
+
+// deliver makes the next attempt of the delivery and appends it to the delivery log. A failed attempt that can be
+// retried schedules the next one with exponential backoff, StartRetries makes it once it is due.
+func (s *WebhookService) deliver(ctx context.Context, sub *webhook_subscription.WebhookSubscription, delivery *webhook_delivery.WebhookDelivery) {
+	log := logger.GetLogger().AddTraceInfoContextRequest(ctx)
+	webhookConf := s.conf.WebhookConfig
+
+	attemptNo := delivery.Attempts + 1
+	attempt := s.attempt(ctx, sub, delivery, attemptNo)
+
+	status := modelsServ.WebhookDeliveryFailed
+	var nextAttemptAt *time.Time
+	switch {
+	case attempt.Succeeded():
+		status = modelsServ.WebhookDeliverySuccess
+	case attempt.Retryable() && attemptNo < webhookConf.MaxAttempts:
+		status = modelsServ.WebhookDeliveryRetrying
+		next := time.Now().Add(s.backoff(attemptNo))
+		nextAttemptAt = &next
+	}
+
+	if err := s.deliveryRepo.RecordAttempt(ctx, delivery.DeliveryID, status, adapters.AdapterWebhook{}.ConvDomainToRepoAttempt(attempt), nextAttemptAt); err != nil {
+		log.Error().Err(err).Str("deliveryID", delivery.DeliveryID).Msg("Deliver webhook - record attempt failed")
+	}
+
+	switch status {
+	case modelsServ.WebhookDeliverySuccess:
+		if err := s.subscriptionRepo.RecordDeliverySuccess(ctx, sub.SubscriptionID); err != nil {
+			log.Error().Err(err).Str("subscriptionID", sub.SubscriptionID).Msg("Deliver webhook - reset failures failed")
+		}
+	case modelsServ.WebhookDeliveryFailed:
+		reason := fmt.Sprintf("%d consecutive failed deliveries, last %s", webhookConf.DisableAfterFailures, delivery.DeliveryID)
+		updated, err := s.subscriptionRepo.RecordDeliveryFailure(ctx, sub.SubscriptionID, webhookConf.DisableAfterFailures, reason)
+		if err != nil {
+			log.Error().Err(err).Str("subscriptionID", sub.SubscriptionID).Msg("Deliver webhook - record failure failed")
+		} else if !updated.Enabled {
+			log.Warn().Str("subscriptionID", sub.SubscriptionID).Msg("Deliver webhook - subscription disabled after repeated failures")
+		}
+	}
+}
+
+// abandon fails a delivery whose subscription can no longer receive it, without counting it against the
+// subscription.
+func (s *WebhookService) abandon(ctx context.Context, delivery *webhook_delivery.WebhookDelivery, reason string) {
+	now := time.Now()
+	attempt := webhook_delivery.DeliveryAttempt{Attempt: delivery.Attempts + 1, Error: reason, AttemptedAt: &now}
+	if err := s.deliveryRepo.RecordAttempt(ctx, delivery.DeliveryID, modelsServ.WebhookDeliveryFailed, attempt, nil); err != nil {
+		logger.GetLogger().AddTraceInfoContextRequest(ctx).Error().Err(err).Str("deliveryID", delivery.DeliveryID).Msg("Retry webhooks - abandon delivery failed")
+	}
+}
+
+// leaseUntil is the time until which a delivery attempted now belongs to this instance.
+func (s *WebhookService) leaseUntil() *time.Time {
+	until := time.Now().Add(s.conf.WebhookConfig.DeliveryLease)
+	return &until
+}
+
+func (s *WebhookService) attempt(ctx context.Context, sub *webhook_subscription.WebhookSubscription, delivery *webhook_delivery.WebhookDelivery, attemptNo int) *modelsServ.WebhookDeliveryAttempt {
+	start := time.Now()
+	res, err := s.webhookClient.Deliver(ctx, webhook.DeliveryReq{
+		URL:        sub.URL,
+		Secret:     string(sub.Secret),
+		DeliveryID: delivery.DeliveryID,
+		EventType:  delivery.EventType,
+		Payload:    []byte(delivery.Payload),
+	})
+
+	attempt := &modelsServ.WebhookDeliveryAttempt{
+		Attempt:     attemptNo,
+		DurationMs:  time.Since(start).Milliseconds(),
+		AttemptedAt: &start,
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+
+	attempt.ResponseStatus = res.StatusCode
+	attempt.ResponseBody = res.Body
+	return attempt
+}
+
+// backoff doubles the wait after every attempt up to BackoffMax, with up to 20% jitter so that retries of a
+// failing receiver do not arrive in bursts.
+func (s *WebhookService) backoff(attemptNo int) time.Duration {
+	webhookConf := s.conf.WebhookConfig
+
+	wait := webhookConf.BackoffBase << (attemptNo - 1)
+	if wait <= 0 || wait > webhookConf.BackoffMax {
+		wait = webhookConf.BackoffMax
+	}
+	return wait + time.Duration(mathrand.Int63n(int64(wait)/5+1))
+}
+
+// validateSubscription checks the event types of sub and that its URL can't reach the internal network, unless
+// AllowPrivateTargets is set.
+func (s *WebhookService) validateSubscription(ctx context.Context, sub *modelsServ.WebhookSubscription) *resp.CustomError {
+	err := netguard.CheckURL(ctx, sub.URL)
+	if errors.Is(err, netguard.ErrForbiddenAddress) && s.conf.WebhookConfig.AllowPrivateTargets {
+		err = nil
+	}
+	if err != nil {
+		return &resp.CustomError{ErrorCode: resp.ErrDataInvalid, Description: "url invalid: " + sub.URL}
+	}
+
+	for _, eventType := range sub.EventTypes {
+		if !modelsServ.IsDomainEventType(eventType) {
+			return &resp.CustomError{ErrorCode: resp.ErrDataInvalid, Description: "event type invalid: " + eventType}
+		}
+	}
+	return nil
+}
+
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
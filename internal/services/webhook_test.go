@@ -0,0 +1,216 @@
+package services
+
+import (
+	"build-service-gin/client/webhook"
+	"build-service-gin/config"
+	modelsServ "build-service-gin/internal/domains"
+	"build-service-gin/pkg/helpers/resp"
+	"build-service-gin/repositories/webhook_delivery"
+	"build-service-gin/repositories/webhook_subscription"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type fakeSubscriptionRepo struct {
+	webhook_subscription.IWebhookSubscriptionRepo
+	subs     map[string]*webhook_subscription.WebhookSubscription
+	failures int
+}
+
+func (r *fakeSubscriptionRepo) CreateSubscription(_ context.Context, data *webhook_subscription.WebhookSubscription) (*webhook_subscription.WebhookSubscription, error) {
+	r.subs[data.SubscriptionID] = data
+	return data, nil
+}
+
+func (r *fakeSubscriptionRepo) GetSubscription(_ context.Context, subscriptionID string) (*webhook_subscription.WebhookSubscription, error) {
+	if sub, ok := r.subs[subscriptionID]; ok {
+		return sub, nil
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (r *fakeSubscriptionRepo) RecordDeliverySuccess(context.Context, string) error {
+	return nil
+}
+
+func (r *fakeSubscriptionRepo) RecordDeliveryFailure(_ context.Context, subscriptionID string, _ int, _ string) (*webhook_subscription.WebhookSubscription, error) {
+	r.failures++
+	return r.subs[subscriptionID], nil
+}
+
+type recordedAttempt struct {
+	status        string
+	attempt       webhook_delivery.DeliveryAttempt
+	nextAttemptAt *time.Time
+}
+
+type fakeDeliveryRepo struct {
+	webhook_delivery.IWebhookDeliveryRepo
+	mu       sync.Mutex
+	due      []*webhook_delivery.WebhookDelivery
+	recorded map[string]recordedAttempt
+}
+
+func (r *fakeDeliveryRepo) ClaimDueDelivery(_ context.Context, _ []string, _, _ time.Time) (*webhook_delivery.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.due) == 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+	delivery := r.due[0]
+	r.due = r.due[1:]
+	return delivery, nil
+}
+
+func (r *fakeDeliveryRepo) RecordAttempt(_ context.Context, deliveryID, status string, attempt webhook_delivery.DeliveryAttempt, nextAttemptAt *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recorded[deliveryID] = recordedAttempt{status: status, attempt: attempt, nextAttemptAt: nextAttemptAt}
+	return nil
+}
+
+func (r *fakeDeliveryRepo) attempt(deliveryID string) (recordedAttempt, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	recorded, ok := r.recorded[deliveryID]
+	return recorded, ok
+}
+
+type fakeWebhookClient struct {
+	statusCode int
+}
+
+func (c fakeWebhookClient) Deliver(context.Context, webhook.DeliveryReq) (*webhook.DeliveryResp, error) {
+	return &webhook.DeliveryResp{StatusCode: c.statusCode}, nil
+}
+
+func newTestWebhookService(statusCode int, subs *fakeSubscriptionRepo, deliveries *fakeDeliveryRepo) *WebhookService {
+	conf := &config.SystemConfig{WebhookConfig: config.WebhookConfig{
+		MaxAttempts:   3,
+		BackoffBase:   time.Second,
+		BackoffMax:    time.Minute,
+		DeliveryLease: time.Minute,
+	}}
+	return NewWebhookService(conf, fakeWebhookClient{statusCode: statusCode}, subs, deliveries).(*WebhookService)
+}
+
+func TestCreateSubscriptionRejectsInternalURLs(t *testing.T) {
+	tests := []struct {
+		url      string
+		wantCode int64
+	}{
+		{url: "https://93.184.216.34/hook"},
+		{url: "http://127.0.0.1:8080/hook", wantCode: resp.ErrDataInvalid},
+		{url: "http://169.254.169.254/latest/meta-data", wantCode: resp.ErrDataInvalid},
+		{url: "http://10.0.0.5/hook", wantCode: resp.ErrDataInvalid},
+		{url: "http://[::1]/hook", wantCode: resp.ErrDataInvalid},
+	}
+	for _, tt := range tests {
+		s := newTestWebhookService(200, &fakeSubscriptionRepo{subs: map[string]*webhook_subscription.WebhookSubscription{}}, nil)
+		_, errCustom := s.CreateSubscription(context.Background(), &modelsServ.WebhookSubscription{Name: "hook", URL: tt.url})
+		switch {
+		case tt.wantCode == 0 && errCustom != nil:
+			t.Errorf("CreateSubscription(%s) = %v", tt.url, errCustom)
+		case tt.wantCode != 0 && (errCustom == nil || errCustom.ErrorCode != tt.wantCode):
+			t.Errorf("CreateSubscription(%s) = %v, want code %d", tt.url, errCustom, tt.wantCode)
+		}
+	}
+}
+
+func TestCreateSubscriptionAllowPrivateTargets(t *testing.T) {
+	s := newTestWebhookService(200, &fakeSubscriptionRepo{subs: map[string]*webhook_subscription.WebhookSubscription{}}, nil)
+	s.conf.WebhookConfig.AllowPrivateTargets = true
+
+	if _, errCustom := s.CreateSubscription(context.Background(), &modelsServ.WebhookSubscription{Name: "hook", URL: "http://127.0.0.1:8080/hook"}); errCustom != nil {
+		t.Fatalf("CreateSubscription() = %v", errCustom)
+	}
+}
+
+func TestDeliverSchedulesRetry(t *testing.T) {
+	sub := &webhook_subscription.WebhookSubscription{SubscriptionID: "s-1", Enabled: true}
+	subs := &fakeSubscriptionRepo{subs: map[string]*webhook_subscription.WebhookSubscription{"s-1": sub}}
+	deliveries := &fakeDeliveryRepo{recorded: map[string]recordedAttempt{}}
+	s := newTestWebhookService(503, subs, deliveries)
+
+	s.deliver(context.Background(), sub, &webhook_delivery.WebhookDelivery{DeliveryID: "d-1", SubscriptionID: "s-1"})
+	recorded, _ := deliveries.attempt("d-1")
+	if recorded.status != modelsServ.WebhookDeliveryRetrying || recorded.nextAttemptAt == nil || !recorded.nextAttemptAt.After(time.Now()) {
+		t.Fatalf("first attempt recorded %+v, want a scheduled retry", recorded)
+	}
+
+	s.deliver(context.Background(), sub, &webhook_delivery.WebhookDelivery{DeliveryID: "d-1", SubscriptionID: "s-1", Attempts: 2})
+	recorded, _ = deliveries.attempt("d-1")
+	if recorded.status != modelsServ.WebhookDeliveryFailed || recorded.nextAttemptAt != nil || recorded.attempt.Attempt != 3 {
+		t.Fatalf("last attempt recorded %+v, want a failure", recorded)
+	}
+	if subs.failures != 1 {
+		t.Errorf("recorded %d subscription failures, want 1", subs.failures)
+	}
+}
+
+func TestRetryDue(t *testing.T) {
+	subs := &fakeSubscriptionRepo{subs: map[string]*webhook_subscription.WebhookSubscription{
+		"enabled":  {SubscriptionID: "enabled", Enabled: true},
+		"disabled": {SubscriptionID: "disabled"},
+	}}
+	deliveries := &fakeDeliveryRepo{
+		recorded: map[string]recordedAttempt{},
+		due: []*webhook_delivery.WebhookDelivery{
+			{DeliveryID: "d-1", SubscriptionID: "enabled", Attempts: 1},
+			{DeliveryID: "d-2", SubscriptionID: "disabled", Attempts: 1},
+			{DeliveryID: "d-3", SubscriptionID: "deleted"},
+		},
+	}
+	s := newTestWebhookService(200, subs, deliveries)
+
+	s.retryDue(context.Background())
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if recorded, ok := deliveries.attempt("d-1"); ok {
+			if recorded.status != modelsServ.WebhookDeliverySuccess || recorded.attempt.Attempt != 2 {
+				t.Errorf("d-1 recorded %+v, want a second successful attempt", recorded)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("due delivery was not attempted")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	for _, deliveryID := range []string{"d-2", "d-3"} {
+		recorded, _ := deliveries.attempt(deliveryID)
+		if recorded.status != modelsServ.WebhookDeliveryFailed || recorded.attempt.Error == "" {
+			t.Errorf("%s recorded %+v, want it abandoned", deliveryID, recorded)
+		}
+	}
+	if subs.failures != 0 {
+		t.Errorf("abandoned deliveries counted %d subscription failures", subs.failures)
+	}
+}
+
+func TestRetryDueStopsOnError(t *testing.T) {
+	deliveries := &erroringDeliveryRepo{}
+	s := newTestWebhookService(200, &fakeSubscriptionRepo{}, nil)
+	s.deliveryRepo = deliveries
+
+	s.retryDue(context.Background())
+	if deliveries.claims != 1 {
+		t.Errorf("claimed %d times after an error, want 1", deliveries.claims)
+	}
+}
+
+type erroringDeliveryRepo struct {
+	webhook_delivery.IWebhookDeliveryRepo
+	claims int
+}
+
+func (r *erroringDeliveryRepo) ClaimDueDelivery(context.Context, []string, time.Time, time.Time) (*webhook_delivery.WebhookDelivery, error) {
+	r.claims++
+	return nil, errors.New("mongo unavailable")
+}
@@ -1,20 +1,25 @@
 package main
 
 import (
+	apiGrpc "build-service-gin/api/grpc"
 	apiHttp "build-service-gin/api/http"
 	"build-service-gin/api/http/middlewares"
+	msgbroker "build-service-gin/api/msgbroker/consumer"
+	"build-service-gin/common/crypto"
 	"build-service-gin/common/logger"
 	"build-service-gin/common/mongodb"
 	postgres "build-service-gin/common/postgresql"
+	"build-service-gin/common/redis"
 	"build-service-gin/config"
 	"build-service-gin/initialize"
+	"build-service-gin/pkg/featureflag"
+	"build-service-gin/pkg/helpers/resp"
 	"context"
+	"flag"
 	"github.com/gin-gonic/gin"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 )
 
 func main() {
@@ -22,24 +27,49 @@ func main() {
 	log := logger.GetLogger()
 	log.Info().Any("service", os.Getenv("SERVICE_ID")).Msg("Start services")
 
-	// Load configuration
+	// Load configuration, -config and -set override the file of ENV and the environment
+	config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
 	conf, err := config.LoadConfig()
 	if err != nil {
 		log.Fatal().Msgf("load config fail! %s", err)
 	}
+	if err = logger.SetLevel(conf.LogLevel); err != nil {
+		log.Fatal().Msgf("set log level fail! %s", err)
+	}
+
+	// Check every error code has a message in every language
+	if err = resp.CheckCatalog(); err != nil {
+		log.Fatal().Msgf("load error messages fail! %s", err)
+	}
+
+	// Load the keys encrypting log and outbound request fields and the columns encrypted at rest
+	cryptoKeyring, err := crypto.NewKeyring(conf.CryptoConfig.Keys, conf.CryptoConfig.EncryptVersion, conf.CryptoConfig.LegacyKey)
+	if err != nil {
+		log.Fatal().Msgf("load crypto keys fail! %s", err)
+	}
+	crypto.SetDefault(cryptoKeyring)
 
-	// Set health check status
-	apiHttp.SetHealthCheck(true)
-	g := gin.Default()
+	g := gin.New()
 
-	// Initialize metrics
+	// Initialize metrics, served on the admin port
 	middlewares.InitMetrics()
-	g.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	// Create context for handling shutdown signals
 	ctx, stop := signal.NotifyContext(context.Background(), os.Kill, os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	// Refresh the secrets referenced by the configuration
+	go config.StartSecretRefresh(ctx)
+
+	// Reload the log level, rate limits and other reloadable values when the config file changes or on SIGHUP
+	config.Subscribe(func(r config.Reloadable) {
+		if err := logger.SetLevel(r.LogLevel); err != nil {
+			log.Error().Err(err).Msg("reload log level failed")
+		}
+	})
+	go config.StartWatcher(ctx)
+
 	// Connect to MongoDB
 	dbStorage, err := mongodb.ConnectMongoDB(context.Background(), &conf.MongoDBConfig)
 	if err != nil {
@@ -52,10 +82,19 @@ func main() {
 		log.Fatal().Msgf("connect postgresql failed! %s", err)
 	}
 
-	//redisClient, err := redis.ConnectRedis(context.Background(), conf.RedisConfig)
-	//if err != nil {
-	//	log.Fatal().Err(err).Msg("Initialize redis client failed.")
-	//}
+	// Connect to Redis, a failed ping is only logged: the service runs without Redis and the rate limiter dials it
+	// again on its own
+	redisConfig := redis.RedisConfig(conf.RedisConfig)
+	redis.ConnectRedis(context.Background(), &redisConfig)
+
+	// Open the new database connections with a rotated password, the open ones stay authenticated. The Mongo URI
+	// is only read at startup, rotating its credentials needs a restart
+	config.OnSecretChange("PostgresConfig.Password", postgres.SetPassword)
+	config.OnSecretChange("RedisConfig.Password", redis.SetPassword)
+
+	// Check the dependencies in the background for the readiness probe
+	healthRegistry := initialize.NewHealthRegistry(conf, dbStorage, postgresql)
+	go healthRegistry.Start(ctx)
 
 	// Initialize clients
 	clients := initialize.NewClients()
@@ -63,33 +102,63 @@ func main() {
 	// Initialize repositories
 	repo := initialize.NewRepositories(dbStorage, postgresql)
 
+	// Load feature flags, refreshed periodically and when a change is published on Redis
+	flags := initialize.NewFeatureFlags(ctx, conf, repo)
+	go flags.Start(ctx, conf.FeatureFlagConfig.RefreshInterval)
+
+	// Fan the point updates of profiles out to their streams on every instance
+	hub := initialize.NewStreamHub(conf)
+	go hub.Start(ctx)
+
 	// Initialize services
 	//service := initialize.NewServices(conf, clients, repo, redisClient)
-	service := initialize.NewServices(conf, clients, repo)
+	service := initialize.NewServices(conf, clients, repo, flags, hub)
+	service.Start(ctx)
 	// Initialize handlers
 	handler := initialize.NewHandlers(service)
 
-	// Uncomment if you're using a message broker
-	// go func() {
-	// 	msgBroker := msgbroker.NewMsgBroker(conf, handler.OrderHandler, handler.CorePointHandler)
-	// 	msgBroker.Start(ctx)
-	// }()
+	// Start the Kafka consumers when they are configured and switched on
+	if conf.KafkaConfig.ConsumersEnabled && flags.Enabled(featureflag.FlagMessageBroker, featureflag.Target{}) {
+		msgBroker := msgbroker.NewMsgBroker(conf, handler.OrderHandler, handler.CorePointHandler, handler.RefundHandler)
+		msgBroker.Start(ctx)
+	}
 
 	// Create HTTP server instance
-	srv := apiHttp.NewHttpServe(conf, handler.ProfileHandler, handler.PointHandler)
+	srv := apiHttp.NewHttpServe(conf, handler.ProfileHandler, handler.PointHandler, handler.WebhookHandler, handler.FlagHandler, handler.GraphQLHandler, handler.StreamHandler, healthRegistry)
 	srv.Start(g)
 
-	// Handle graceful shutdown
+	// Create gRPC server instance, serving the profile and point operations next to HTTP
+	var grpcSrv apiGrpc.GrpcServInterface
+	if conf.GrpcConfig.Enabled {
+		grpcHandler := initialize.NewGrpcHandlers(service)
+		grpcSrv = apiGrpc.NewGrpcServe(conf, grpcHandler.ProfileServer, grpcHandler.PointServer)
+		grpcSrv.Start()
+	}
+
+	// Handle graceful shutdown, a second signal stops the service at once
 	<-ctx.Done()
-	apiHttp.SetHealthCheck(false)
+	stop()
+
+	// Fail the health check and wait for the load balancer to stop sending requests
+	srv.Drain()
 
 	// Set a timeout for shutdown
-	cancelCtx, cc := context.WithTimeout(context.Background(), 15*time.Second)
+	cancelCtx, cc := context.WithTimeout(context.Background(), conf.HttpServerConfig.ShutdownTimeout)
 	defer cc()
 
-	// Shutdown the server
+	// Shutdown the servers
+	if grpcSrv != nil {
+		if err = grpcSrv.Shutdown(cancelCtx); err != nil {
+			log.Error().Msgf("force shutdown gRPC server: %v", err)
+		}
+	}
 	if err = srv.Shutdown(cancelCtx); err != nil {
-		log.Fatal().Msgf("force shutdown services: %v", err)
+		log.Error().Msgf("force shutdown services: %v", err)
+	}
+
+	// Give the events published by the last requests to the listeners and deliver them to Kafka
+	if err = clients.EventPublisher.Close(cancelCtx); err != nil {
+		log.Error().Err(err).Msg("close event publisher failed")
 	}
 
 	log.Info().Msg("Server exited gracefully")
@@ -0,0 +1,75 @@
+package auth
+
+import "strings"
+
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+const (
+	ScopeHistoryRead    = "history:read"
+	ScopeHistoryReadAny = "history:read_any"
+	ScopeHistoryWrite   = "history:write"
+	ScopeHistoryAdmin   = "history:admin"
+	ScopePointWrite     = "point:write"
+	ScopePointReverse   = "point:reverse"
+	ScopeWebhookAdmin   = "webhook:admin"
+	ScopeConfigRead     = "config:read"
+	ScopeFlagAdmin      = "flag:admin"
+)
+
+// roleScopes lists the scopes granted by every role on top of the scopes carried by the token.
+var roleScopes = map[string][]string{
+	RoleAdmin: {
+		ScopeHistoryRead, ScopeHistoryReadAny, ScopeHistoryWrite, ScopeHistoryAdmin,
+		ScopePointWrite, ScopePointReverse, ScopeWebhookAdmin, ScopeConfigRead,
+		ScopeFlagAdmin,
+	},
+	RoleUser: {ScopeHistoryRead},
+}
+
+// resolveScopes merges the space separated scope claim, the scp claim and the scopes of roles.
+func resolveScopes(claims *Claims) map[string]struct{} {
+	scopes := make(map[string]struct{})
+	for _, scope := range strings.Fields(claims.Scope) {
+		scopes[scope] = struct{}{}
+	}
+	for _, scope := range claims.Scopes {
+		scopes[scope] = struct{}{}
+	}
+	for _, role := range claims.Roles {
+		for _, scope := range roleScopes[role] {
+			scopes[scope] = struct{}{}
+		}
+	}
+	return scopes
+}
+
+// HasScopes reports whether the caller was granted every scope.
+func (p *Principal) HasScopes(scopes ...string) bool {
+	for _, scope := range scopes {
+		if _, ok := p.scopes[scope]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Claims.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Scopes returns the granted scopes.
+func (p *Principal) Scopes() []string {
+	scopes := make([]string, 0, len(p.scopes))
+	for scope := range p.scopes {
+		scopes = append(scopes, scope)
+	}
+	return scopes
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"build-service-gin/common/utils"
+	"context"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Subject   string
+	TokenType string
+	Claims    *Claims
+	scopes    map[string]struct{}
+}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, utils.KeyPrincipal, principal)
+}
+
+// PrincipalFromContext returns the caller bound by the auth middleware, nil for unauthenticated requests.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(utils.KeyPrincipal).(*Principal)
+	return principal
+}
+
+// IsEndUser reports whether the caller acts on behalf of a single profile rather than as a trusted service.
+func (p *Principal) IsEndUser() bool {
+	return p.TokenType == utils.IASTypeClient || p.TokenType == utils.IASTypePublic
+}
+
+// ResolveProfile returns the profile a request for profileID acts on: the subject when profileID is empty, and false
+// when it names another profile. Callers granted history:read_any may address any profile.
+func (p *Principal) ResolveProfile(profileID string) (string, bool) {
+	if p.HasScopes(ScopeHistoryReadAny) {
+		return profileID, true
+	}
+	if profileID != "" && profileID != p.Subject {
+		return "", false
+	}
+	return p.Subject, true
+}
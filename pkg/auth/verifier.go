@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"build-service-gin/config"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrNoVerificationKey = errors.New("auth: no verification key configured")
+	ErrUnknownKeyID      = errors.New("auth: unknown key id")
+	ErrMissingSubject    = errors.New("auth: missing sub claim")
+	ErrMissingTokenType  = errors.New("auth: missing type claim")
+)
+
+type Claims struct {
+	jwt.RegisteredClaims
+	Type   string   `json:"type"`
+	Roles  []string `json:"roles"`
+	Scope  string   `json:"scope"`
+	Scopes []string `json:"scp"`
+}
+
+// Verifier validates RS256 tokens against the configured JWKS file or PEM key and HS256 tokens against the
+// shared secret.
+type Verifier struct {
+	rsaKeys    map[string]*rsa.PublicKey
+	hmacSecret []byte
+	parser     *jwt.Parser
+}
+
+func NewVerifier(conf config.AuthConfig) (*Verifier, error) {
+	v := &Verifier{
+		rsaKeys: make(map[string]*rsa.PublicKey),
+	}
+
+	if conf.JWKSFile != "" {
+		keys, err := loadJWKSFile(conf.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		v.rsaKeys = keys
+	}
+
+	if conf.PublicKeyFile != "" {
+		key, err := loadPublicKeyFile(conf.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		// tokens without kid are verified with the PEM key
+		v.rsaKeys[""] = key
+	}
+
+	if conf.HS256Secret != "" {
+		v.hmacSecret = []byte(conf.HS256Secret)
+	}
+
+	var methods []string
+	if len(v.rsaKeys) > 0 {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	if v.hmacSecret != nil {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if len(methods) == 0 {
+		return nil, ErrNoVerificationKey
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithLeeway(conf.Leeway),
+		jwt.WithExpirationRequired(),
+	}
+	if conf.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(conf.Issuer))
+	}
+	if conf.Audience != "" {
+		opts = append(opts, jwt.WithAudience(conf.Audience))
+	}
+	v.parser = jwt.NewParser(opts...)
+
+	return v, nil
+}
+
+// Verify checks the signature and registered claims of tokenString and returns the caller it identifies.
+func (v *Verifier) Verify(tokenString string) (*Principal, error) {
+	claims := &Claims{}
+	if _, err := v.parser.ParseWithClaims(tokenString, claims, v.keyFunc); err != nil {
+		return nil, err
+	}
+
+	if claims.Subject == "" {
+		return nil, ErrMissingSubject
+	}
+	if claims.Type == "" {
+		return nil, ErrMissingTokenType
+	}
+
+	return &Principal{
+		Subject:   claims.Subject,
+		TokenType: claims.Type,
+		Claims:    claims,
+		scopes:    resolveScopes(claims),
+	}, nil
+}
+
+func (v *Verifier) keyFunc(token *jwt.Token) (interface{}, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return v.hmacSecret, nil
+	case jwt.SigningMethodRS256.Alg():
+		kid, _ := token.Header["kid"].(string)
+		if key, ok := v.rsaKeys[kid]; ok {
+			return key, nil
+		}
+		if kid == "" && len(v.rsaKeys) == 1 {
+			for _, key := range v.rsaKeys {
+				return key, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKeyID, kid)
+	}
+	return nil, fmt.Errorf("auth: unexpected signing method %s", token.Method.Alg())
+}
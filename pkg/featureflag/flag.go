@@ -0,0 +1,78 @@
+package featureflag
+
+import (
+	"build-service-gin/common/utils"
+	"context"
+	"hash/fnv"
+	"strings"
+)
+
+// Flag switches a feature on for the targets it matches.
+//
+// A disabled flag matches nobody and a profile listed in Profiles always matches. Otherwise the target has to be
+// in one of the Regions, when any is set, and fall inside the Percentage rollout, when it is set. A flag with
+// only Profiles matches those profiles alone and a flag without any targeting is a plain boolean.
+type Flag struct {
+	Key         string   `json:"key" yaml:"key"`
+	Description string   `json:"description" yaml:"description"`
+	Enabled     bool     `json:"enabled" yaml:"enabled"`
+	Percentage  *int     `json:"percentage,omitempty" yaml:"percentage"`
+	Regions     []string `json:"regions,omitempty" yaml:"regions"`
+	Profiles    []string `json:"profiles,omitempty" yaml:"profiles"`
+}
+
+// Target is who a flag is evaluated for, the zero Target only matches flags without targeting.
+type Target struct {
+	ProfileID string
+	Region    string
+}
+
+// Evaluate reports whether the flag is on for target.
+func (f Flag) Evaluate(target Target) bool {
+	if !f.Enabled {
+		return false
+	}
+
+	if target.ProfileID != "" && contains(f.Profiles, target.ProfileID, false) {
+		return true
+	}
+
+	if len(f.Regions) > 0 && !contains(f.Regions, target.Region, true) {
+		return false
+	}
+
+	if f.Percentage != nil {
+		if *f.Percentage >= 100 {
+			return true
+		}
+		if target.ProfileID == "" {
+			return false
+		}
+		return bucket(f.Key, target.ProfileID) < *f.Percentage
+	}
+
+	return len(f.Profiles) == 0
+}
+
+// bucket places a profile in one of 100 buckets, stable per flag so a profile keeps its answer while the rollout
+// grows and different flags roll out to different profiles.
+func bucket(key, profileID string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key + ":" + profileID))
+	return int(h.Sum32() % 100)
+}
+
+func contains(values []string, value string, fold bool) bool {
+	for _, v := range values {
+		if v == value || (fold && strings.EqualFold(v, value)) {
+			return true
+		}
+	}
+	return false
+}
+
+// TargetFromContext targets profileID in the region of the request carried by ctx.
+func TargetFromContext(ctx context.Context, profileID string) Target {
+	region, _ := ctx.Value(utils.KeyRegion).(string)
+	return Target{ProfileID: profileID, Region: region}
+}
@@ -0,0 +1,8 @@
+package featureflag
+
+const (
+	// FlagMessageBroker starts the Kafka consumers, checked once at startup.
+	FlagMessageBroker = "msgbroker.consumers"
+	// FlagHistoryPostgresRead serves the transaction history of the profiles it targets from Postgres.
+	FlagHistoryPostgresRead = "history.postgres_read"
+)
@@ -0,0 +1,119 @@
+package featureflag
+
+import (
+	"build-service-gin/common/logger"
+	"build-service-gin/common/redis"
+	"context"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Service answers flag evaluations from an in-memory copy of the store. The copy is refreshed periodically and
+// whenever another instance publishes a change on the Redis channel.
+type Service struct {
+	store   Store
+	client  goredis.UniversalClient
+	channel string
+	flags   atomic.Pointer[map[string]Flag]
+}
+
+// NewService serves the flags of store, changes are only picked up periodically without a connected Redis client.
+func NewService(store Store, client *redis.Client, channel string) *Service {
+	s := &Service{
+		store:   store,
+		channel: channel,
+	}
+	if client != nil && client.GetClient() != nil {
+		s.client = client.GetClient()
+	}
+	s.flags.Store(&map[string]Flag{})
+	return s
+}
+
+// Refresh reloads the flags from the store, on error the current flags stay in use.
+func (s *Service) Refresh(ctx context.Context) error {
+	flags, err := s.store.ListFlags(ctx)
+	if err != nil {
+		return err
+	}
+
+	byKey := make(map[string]Flag, len(flags))
+	for _, flag := range flags {
+		byKey[flag.Key] = flag
+	}
+	s.flags.Store(&byKey)
+	return nil
+}
+
+// Start refreshes the flags every interval and on every change notification until ctx is done.
+func (s *Service) Start(ctx context.Context, interval time.Duration) {
+	log := logger.GetLogger().AddTraceInfoContextRequest(ctx)
+
+	var notifications <-chan *goredis.Message
+	if s.client != nil {
+		pubsub := s.client.Subscribe(ctx, s.channel)
+		defer pubsub.Close()
+		notifications = pubsub.Channel()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-notifications:
+		case <-ticker.C:
+		}
+		if err := s.Refresh(ctx); err != nil {
+			log.Error().Err(err).Msg("refresh feature flags failed, keeping the current flags")
+		}
+	}
+}
+
+// Notify tells every instance, this one included, to refresh its flags. Call it after changing the store.
+func (s *Service) Notify(ctx context.Context) error {
+	if s.client == nil {
+		return s.Refresh(ctx)
+	}
+	return s.client.Publish(ctx, s.channel, time.Now().UnixMilli()).Err()
+}
+
+// Enabled reports whether the flag called key is on for target, unknown flags are off.
+func (s *Service) Enabled(key string, target Target) bool {
+	flag, ok := (*s.flags.Load())[key]
+	return ok && flag.Evaluate(target)
+}
+
+// Flags returns the flags in use sorted by key.
+func (s *Service) Flags() []Flag {
+	current := *s.flags.Load()
+	flags := make([]Flag, 0, len(current))
+	for _, flag := range current {
+		flags = append(flags, flag)
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Key < flags[j].Key })
+	return flags
+}
+
+var defaultService atomic.Pointer[Service]
+
+// SetDefault makes s the service behind the package level Enabled.
+func SetDefault(s *Service) {
+	defaultService.Store(s)
+}
+
+// Default returns the service set by SetDefault, nil before.
+func Default() *Service {
+	return defaultService.Load()
+}
+
+// Enabled evaluates key with the default service, every flag is off until one is set.
+func Enabled(key string, target Target) bool {
+	s := Default()
+	return s != nil && s.Enabled(key, target)
+}
@@ -0,0 +1,47 @@
+package featureflag
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	SourceMongo = "mongo"
+	SourceFile  = "file"
+)
+
+// Store is where the flags are kept.
+type Store interface {
+	ListFlags(ctx context.Context) ([]Flag, error)
+}
+
+// FileStore reads the flags from a YAML or JSON file mapping a flag key to its settings, e.g.
+//
+//	history.postgres_read:
+//	  enabled: true
+//	  percentage: 10
+type FileStore struct {
+	Path string
+}
+
+func (s FileStore) ListFlags(_ context.Context) ([]Flag, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("feature flag file: %w", err)
+	}
+
+	var doc map[string]Flag
+	if err = yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("feature flag file %s: %w", s.Path, err)
+	}
+
+	flags := make([]Flag, 0, len(doc))
+	for key, flag := range doc {
+		flag.Key = key
+		flags = append(flags, flag)
+	}
+	return flags, nil
+}
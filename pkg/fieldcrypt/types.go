@@ -0,0 +1,127 @@
+// Package fieldcrypt encrypts string columns at rest with the default keyring of common/crypto, the values are stored
+// in plaintext while no keyring is installed.
+package fieldcrypt
+
+import (
+	"build-service-gin/common/crypto"
+	"database/sql/driver"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// Deterministic is a string encrypted at rest that can still be filtered on equality, see Candidates.
+type Deterministic string
+
+// Randomized is a string encrypted at rest with a random nonce, it can't be filtered on.
+type Randomized string
+
+func (s Deterministic) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return marshalBSON(string(s), true)
+}
+
+func (s *Deterministic) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	return unmarshalBSON((*string)(s), t, data)
+}
+
+func (s Deterministic) Value() (driver.Value, error) {
+	return encrypt(string(s), true)
+}
+
+func (s *Deterministic) Scan(value interface{}) error {
+	return scan((*string)(s), value)
+}
+
+func (s Randomized) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	return marshalBSON(string(s), false)
+}
+
+func (s *Randomized) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	return unmarshalBSON((*string)(s), t, data)
+}
+
+func (s Randomized) Value() (driver.Value, error) {
+	return encrypt(string(s), false)
+}
+
+func (s *Randomized) Scan(value interface{}) error {
+	return scan((*string)(s), value)
+}
+
+// Candidates lists the stored forms of a Deterministic value to filter on, see crypto.Keyring.Candidates.
+func Candidates(plaintext string) []string {
+	k := crypto.Default()
+	if k == nil {
+		return []string{plaintext}
+	}
+	return k.Candidates(plaintext)
+}
+
+func encrypt(plaintext string, deterministic bool) (string, error) {
+	k := crypto.Default()
+	if k == nil {
+		return plaintext, nil
+	}
+	if deterministic {
+		return k.EncryptDeterministic(plaintext)
+	}
+	return k.Encrypt(plaintext)
+}
+
+func decrypt(value string) (string, error) {
+	k := crypto.Default()
+	if k == nil {
+		return value, nil
+	}
+	return k.Decrypt(value)
+}
+
+func marshalBSON(plaintext string, deterministic bool) (bsontype.Type, []byte, error) {
+	value, err := encrypt(plaintext, deterministic)
+	if err != nil {
+		return 0, nil, err
+	}
+	return bson.MarshalValue(value)
+}
+
+func unmarshalBSON(dst *string, t bsontype.Type, data []byte) error {
+	raw := bson.RawValue{Type: t, Value: data}
+	if t == bsontype.Null || t == bsontype.Undefined {
+		*dst = ""
+		return nil
+	}
+
+	value, ok := raw.StringValueOK()
+	if !ok {
+		return fmt.Errorf("fieldcrypt: cannot decode %s into an encrypted string", t)
+	}
+	plaintext, err := decrypt(value)
+	if err != nil {
+		return err
+	}
+	*dst = plaintext
+	return nil
+}
+
+func scan(dst *string, value interface{}) error {
+	var stored string
+	switch v := value.(type) {
+	case nil:
+		*dst = ""
+		return nil
+	case []byte:
+		stored = string(v)
+	case string:
+		stored = v
+	default:
+		return fmt.Errorf("fieldcrypt: unsupported type %T", value)
+	}
+
+	plaintext, err := decrypt(stored)
+	if err != nil {
+		return err
+	}
+	*dst = plaintext
+	return nil
+}
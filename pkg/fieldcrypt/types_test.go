@@ -0,0 +1,73 @@
+package fieldcrypt
+
+import (
+	"build-service-gin/common/crypto"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func setTestKeyring(t *testing.T) {
+	t.Helper()
+	keyring, err := crypto.NewKeyring(map[int]string{1: strings.Repeat("ab", 32)}, 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	crypto.SetDefault(keyring)
+	t.Cleanup(func() { crypto.SetDefault(nil) })
+}
+
+func TestDeterministicSQL(t *testing.T) {
+	setTestKeyring(t)
+
+	stored, err := Deterministic("pay-1").Value()
+	if err != nil || !strings.HasPrefix(stored.(string), "enc:v1:d:") {
+		t.Fatalf("Value() = %v, %v, want a deterministic ciphertext", stored, err)
+	}
+	if again, _ := Deterministic("pay-1").Value(); again != stored {
+		t.Errorf("Value() = %v then %v, want equal ciphertexts", stored, again)
+	}
+	if candidates := Candidates("pay-1"); len(candidates) != 2 || candidates[1] != stored {
+		t.Errorf("Candidates() = %v, want the plaintext and %v", candidates, stored)
+	}
+
+	var scanned Deterministic
+	if err = scanned.Scan([]byte(stored.(string))); err != nil || scanned != "pay-1" {
+		t.Errorf("Scan() = %q, %v, want pay-1", scanned, err)
+	}
+	if err = scanned.Scan("pay-2"); err != nil || scanned != "pay-2" {
+		t.Errorf("Scan() of a plaintext row = %q, %v, want pay-2", scanned, err)
+	}
+}
+
+func TestRandomizedBSON(t *testing.T) {
+	setTestKeyring(t)
+
+	type doc struct {
+		Secret Randomized `bson:"secret"`
+	}
+	raw, err := bson.Marshal(doc{Secret: "signing-secret"})
+	if err != nil {
+		t.Fatalf("Marshal() = %v", err)
+	}
+	if strings.Contains(string(raw), "signing-secret") {
+		t.Fatal("secret stored in plaintext")
+	}
+
+	var got doc
+	if err = bson.Unmarshal(raw, &got); err != nil || got.Secret != "signing-secret" {
+		t.Errorf("Unmarshal() = %q, %v, want the plaintext back", got.Secret, err)
+	}
+}
+
+func TestWithoutKeyring(t *testing.T) {
+	crypto.SetDefault(nil)
+
+	if stored, _ := Randomized("secret").Value(); stored != "secret" {
+		t.Errorf("Value() without keyring = %v, want the plaintext", stored)
+	}
+	if candidates := Candidates("pay-1"); len(candidates) != 1 || candidates[0] != "pay-1" {
+		t.Errorf("Candidates() without keyring = %v, want the plaintext", candidates)
+	}
+}
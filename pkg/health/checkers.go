@@ -0,0 +1,91 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	goredis "github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+var (
+	ErrNotConnected = errors.New("health: not connected")
+	ErrNoBroker     = errors.New("health: no kafka broker")
+)
+
+// MetadataGetter reads the metadata of a Kafka cluster, like kafka.AdminClient and kafka.Producer.
+type MetadataGetter interface {
+	GetMetadata(topic *string, allTopics bool, timeoutMs int) (*kafka.Metadata, error)
+}
+
+// Mongo pings the primary of client.
+func Mongo(client *mongo.Client) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		if client == nil {
+			return ErrNotConnected
+		}
+		return client.Ping(ctx, readpref.Primary())
+	})
+}
+
+// SQL pings db.
+func SQL(db *sql.DB) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		if db == nil {
+			return ErrNotConnected
+		}
+		return db.PingContext(ctx)
+	})
+}
+
+// Redis pings client, nil when the service could not connect at startup.
+func Redis(client goredis.UniversalClient) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		if client == nil {
+			return ErrNotConnected
+		}
+		return client.Ping(ctx).Err()
+	})
+}
+
+// Kafka reads the brokers of the cluster, the client API takes a timeout rather than a context.
+func Kafka(client MetadataGetter) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		if client == nil {
+			return ErrNotConnected
+		}
+
+		timeout := time.Second
+		if deadline, ok := ctx.Deadline(); ok {
+			timeout = time.Until(deadline)
+		}
+		metadata, err := client.GetMetadata(nil, false, int(timeout.Milliseconds()))
+		if err != nil {
+			return err
+		}
+		if len(metadata.Brokers) == 0 {
+			return ErrNoBroker
+		}
+		return nil
+	})
+}
+
+// HTTP checks url can be reached with client, whatever the status of the response.
+func HTTP(client *http.Client, url string) Checker {
+	return CheckerFunc(func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+		if err != nil {
+			return err
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		return res.Body.Close()
+	})
+}
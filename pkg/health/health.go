@@ -0,0 +1,243 @@
+// Package health checks the dependencies of the service in the background and keeps their last state, so that the
+// probes answer without waiting on a dependency.
+package health
+
+import (
+	"build-service-gin/common/logger"
+	"build-service-gin/config"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusDegraded = "degraded"
+	StatusUnknown  = "unknown"
+	StatusDraining = "draining"
+)
+
+// ErrCheckRunning fails a check while its previous call, which outlived its timeout, has not returned.
+var ErrCheckRunning = errors.New("health: previous check still running")
+
+// Checker checks a dependency, it returns an error when the dependency can't be used.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// CheckResult is the last state of a dependency. The error of a failed check is logged rather than reported, it may
+// name hosts of the infrastructure.
+type CheckResult struct {
+	Status    string    `json:"status"`
+	Critical  bool      `json:"critical"`
+	LatencyMs float64   `json:"latencyMs"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// Report is the state of the service: down when a critical dependency is down, degraded when another one is,
+// unknown until every critical dependency was checked, or when its last check is older than the cache TTL, and
+// draining once the service shuts down.
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Ready tells whether the service takes requests.
+func (r Report) Ready() bool {
+	return r.Status == StatusUp || r.Status == StatusDegraded
+}
+
+type check struct {
+	name     string
+	checker  Checker
+	critical bool
+	timeout  time.Duration
+}
+
+type Registry struct {
+	conf    config.HealthConfig
+	observe func(name string, up bool)
+
+	mu       sync.RWMutex
+	checks   []check
+	results  map[string]CheckResult
+	running  map[string]bool
+	draining bool
+}
+
+// NewRegistry returns a registry checking its dependencies every conf.Interval, observe is told the state of each
+// dependency after every check.
+func NewRegistry(conf config.HealthConfig, observe func(name string, up bool)) *Registry {
+	return &Registry{
+		conf:    conf,
+		observe: observe,
+		results: map[string]CheckResult{},
+		running: map[string]bool{},
+	}
+}
+
+// Register adds the dependency name checked by checker. The service is not ready while a critical dependency is
+// down.
+func (r *Registry) Register(name string, critical bool, checker Checker) {
+	timeout, ok := r.conf.Timeouts[name]
+	if !ok {
+		timeout = r.conf.Timeout
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, check{name: name, checker: checker, critical: critical, timeout: timeout})
+}
+
+// Start checks every dependency now and then every Interval until ctx is done.
+func (r *Registry) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.conf.Interval)
+	defer ticker.Stop()
+
+	for {
+		r.run(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SetDraining makes the service not ready whatever the state of its dependencies, for the load balancer to stop
+// sending requests before the server shuts down.
+func (r *Registry) SetDraining() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.draining = true
+}
+
+// Report returns the last state of the service and of each dependency.
+func (r *Registry) Report() Report {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	report := Report{Status: StatusUp, Checks: make(map[string]CheckResult, len(r.checks))}
+	for _, c := range r.checks {
+		result, ok := r.results[c.name]
+		switch {
+		case !ok:
+			result = CheckResult{Status: StatusUnknown, Critical: c.critical}
+		case time.Since(result.CheckedAt) > r.conf.CacheTTL:
+			result.Status = StatusUnknown
+		}
+		report.Checks[c.name] = result
+
+		switch {
+		case result.Status == StatusUp:
+		case c.critical && result.Status == StatusDown:
+			report.Status = StatusDown
+		case c.critical && report.Status != StatusDown:
+			report.Status = StatusUnknown
+		case !c.critical && report.Status == StatusUp:
+			report.Status = StatusDegraded
+		}
+	}
+	if r.draining {
+		report.Status = StatusDraining
+	}
+	return report
+}
+
+// run checks every dependency at once, each one bounded by its own timeout.
+func (r *Registry) run(ctx context.Context) {
+	r.mu.RLock()
+	checks := append([]check(nil), r.checks...)
+	r.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, c := range checks {
+		wg.Add(1)
+		go func(c check) {
+			defer wg.Done()
+			start := time.Now()
+			err := r.checkOne(ctx, c)
+			if ctx.Err() != nil {
+				// the service shuts down, the check failed for that
+				return
+			}
+			r.record(ctx, c, time.Since(start), err)
+		}(c)
+	}
+	wg.Wait()
+}
+
+// checkOne runs the checker of c apart and gives up on it at its timeout, so that a checker ignoring its context
+// fails its own check rather than holding the others. It is not started again before it returns.
+func (r *Registry) checkOne(ctx context.Context, c check) error {
+	r.mu.Lock()
+	if r.running[c.name] {
+		r.mu.Unlock()
+		return ErrCheckRunning
+	}
+	r.running[c.name] = true
+	r.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		var err error
+		defer func() {
+			// a checker panicking must not take the service down with it
+			if rec := recover(); rec != nil {
+				err = fmt.Errorf("health: check panicked: %v", rec)
+			}
+			r.mu.Lock()
+			delete(r.running, c.name)
+			r.mu.Unlock()
+			done <- err
+		}()
+		err = c.checker.Check(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *Registry) record(ctx context.Context, c check, latency time.Duration, err error) {
+	log := logger.GetLogger().AddTraceInfoContextRequest(ctx)
+	result := CheckResult{
+		Status:    StatusUp,
+		Critical:  c.critical,
+		LatencyMs: float64(latency.Microseconds()) / 1000,
+		CheckedAt: time.Now(),
+	}
+	if err != nil {
+		result.Status = StatusDown
+	}
+
+	r.mu.Lock()
+	previous := r.results[c.name]
+	r.results[c.name] = result
+	r.mu.Unlock()
+
+	if r.observe != nil {
+		r.observe(c.name, err == nil)
+	}
+	switch {
+	case err != nil && previous.Status != StatusDown:
+		log.Warn().Err(err).Str("dependency", c.name).Bool("critical", c.critical).Msg("dependency is down")
+	case err == nil && previous.Status == StatusDown:
+		log.Info().Str("dependency", c.name).Msg("dependency is up again")
+	}
+}
@@ -0,0 +1,262 @@
+package health
+
+import (
+	"build-service-gin/common/logger"
+	"build-service-gin/config"
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLog("test")
+	os.Exit(m.Run())
+}
+
+func testConfig() config.HealthConfig {
+	return config.HealthConfig{Interval: time.Hour, Timeout: time.Second, CacheTTL: time.Minute}
+}
+
+func up(context.Context) error { return nil }
+
+func down(context.Context) error { return errors.New("connection refused") }
+
+// hung returns a checker which ignores its context until release is closed, and counts its calls.
+func hung(release <-chan struct{}, calls *int, mu *sync.Mutex) Checker {
+	return CheckerFunc(func(context.Context) error {
+		mu.Lock()
+		*calls++
+		mu.Unlock()
+		<-release
+		return nil
+	})
+}
+
+func TestReport(t *testing.T) {
+	tests := []struct {
+		name      string
+		checks    map[string]CheckerFunc
+		critical  map[string]bool
+		run       bool
+		draining  bool
+		want      string
+		wantReady bool
+	}{
+		{name: "no dependency", want: StatusUp, wantReady: true},
+		{name: "not checked yet", checks: map[string]CheckerFunc{"mongo": up}, critical: map[string]bool{"mongo": true}, want: StatusUnknown},
+		{
+			name:      "every dependency up",
+			checks:    map[string]CheckerFunc{"mongo": up, "redis": up},
+			critical:  map[string]bool{"mongo": true},
+			run:       true,
+			want:      StatusUp,
+			wantReady: true,
+		},
+		{
+			name:      "optional dependency down",
+			checks:    map[string]CheckerFunc{"mongo": up, "redis": down},
+			critical:  map[string]bool{"mongo": true},
+			run:       true,
+			want:      StatusDegraded,
+			wantReady: true,
+		},
+		{
+			name:     "critical dependency down",
+			checks:   map[string]CheckerFunc{"mongo": down, "redis": down},
+			critical: map[string]bool{"mongo": true},
+			run:      true,
+			want:     StatusDown,
+		},
+		{
+			name:     "draining",
+			checks:   map[string]CheckerFunc{"mongo": up},
+			critical: map[string]bool{"mongo": true},
+			run:      true,
+			draining: true,
+			want:     StatusDraining,
+		},
+		{
+			name:     "panicking check",
+			checks:   map[string]CheckerFunc{"mongo": func(context.Context) error { panic("nil client") }},
+			critical: map[string]bool{"mongo": true},
+			run:      true,
+			want:     StatusDown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observed := map[string]bool{}
+			var mu sync.Mutex
+			r := NewRegistry(testConfig(), func(name string, up bool) {
+				mu.Lock()
+				defer mu.Unlock()
+				observed[name] = up
+			})
+			for name, checker := range tt.checks {
+				r.Register(name, tt.critical[name], checker)
+			}
+			if tt.run {
+				r.run(context.Background())
+			}
+			if tt.draining {
+				r.SetDraining()
+			}
+
+			report := r.Report()
+			if report.Status != tt.want || report.Ready() != tt.wantReady {
+				t.Errorf("Report() = %s, ready %v, want %s, ready %v", report.Status, report.Ready(), tt.want, tt.wantReady)
+			}
+			for name := range tt.checks {
+				result := report.Checks[name]
+				if result.Critical != tt.critical[name] {
+					t.Errorf("check %s critical = %v, want %v", name, result.Critical, tt.critical[name])
+				}
+				if tt.run && observed[name] != (result.Status == StatusUp) {
+					t.Errorf("check %s observed up = %v, reported %s", name, observed[name], result.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestHungCheckTimesOut(t *testing.T) {
+	conf := testConfig()
+	conf.Timeouts = map[string]time.Duration{"kafka": 20 * time.Millisecond}
+	r := NewRegistry(conf, nil)
+
+	release := make(chan struct{})
+	var calls int
+	var mu sync.Mutex
+	r.Register("mongo", true, CheckerFunc(up))
+	r.Register("kafka", true, hung(release, &calls, &mu))
+
+	start := time.Now()
+	r.run(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("run() took %s, the hung check held it", elapsed)
+	}
+	report := r.Report()
+	if report.Status != StatusDown || report.Ready() || report.Checks["kafka"].Status != StatusDown || report.Checks["mongo"].Status != StatusUp {
+		t.Fatalf("Report() = %+v, want kafka down and not ready", report)
+	}
+
+	// the next run does not pile a second call on the hung one
+	r.run(context.Background())
+	mu.Lock()
+	if calls != 1 {
+		t.Errorf("checker called %d times while hung, want 1", calls)
+	}
+	mu.Unlock()
+	if r.Report().Checks["kafka"].Status != StatusDown {
+		t.Errorf("kafka = %s while hung, want down", r.Report().Checks["kafka"].Status)
+	}
+
+	// once it returns, the check runs again
+	close(release)
+	deadline := time.Now().Add(2 * time.Second)
+	for r.run(context.Background()); r.Report().Status != StatusUp; r.run(context.Background()) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Report() = %s after the check returned, want up", r.Report().Status)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCheckHonoringItsContextTimesOut(t *testing.T) {
+	conf := testConfig()
+	conf.Timeout = 10 * time.Millisecond
+	r := NewRegistry(conf, nil)
+
+	ended := make(chan error, 1)
+	r.Register("mongo", true, CheckerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		ended <- ctx.Err()
+		return ctx.Err()
+	}))
+	r.run(context.Background())
+
+	if got := <-ended; !errors.Is(got, context.DeadlineExceeded) {
+		t.Errorf("checker context ended with %v, want %v", got, context.DeadlineExceeded)
+	}
+	if report := r.Report(); report.Ready() {
+		t.Errorf("Report() = %s, want not ready", report.Status)
+	}
+}
+
+func TestReportCacheTTL(t *testing.T) {
+	tests := []struct {
+		name      string
+		age       time.Duration
+		want      string
+		wantReady bool
+	}{
+		{name: "fresh state", age: time.Second, want: StatusUp, wantReady: true},
+		{name: "state at the ttl", age: time.Minute - time.Second, want: StatusUp, wantReady: true},
+		{name: "stale state", age: time.Minute + time.Second, want: StatusUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry(testConfig(), nil)
+			r.Register("mongo", true, CheckerFunc(up))
+			checkedAt := time.Now().Add(-tt.age)
+			r.results["mongo"] = CheckResult{Status: StatusUp, Critical: true, CheckedAt: checkedAt}
+
+			report := r.Report()
+			if report.Status != tt.want || report.Ready() != tt.wantReady {
+				t.Errorf("Report() = %s, ready %v, want %s, ready %v", report.Status, report.Ready(), tt.want, tt.wantReady)
+			}
+			if result := report.Checks["mongo"]; result.Status != tt.want || !result.CheckedAt.Equal(checkedAt) {
+				t.Errorf("check = %+v, want %s checked at %s", result, tt.want, checkedAt)
+			}
+		})
+	}
+}
+
+func TestStartChecksUntilDone(t *testing.T) {
+	conf := testConfig()
+	conf.Interval = 5 * time.Millisecond
+	r := NewRegistry(conf, nil)
+
+	var mu sync.Mutex
+	var calls int
+	r.Register("mongo", true, CheckerFunc(func(context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		return nil
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Start(ctx)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		n := calls
+		mu.Unlock()
+		if n >= 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("checked %d times, want a check every interval", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if !r.Report().Ready() {
+		t.Errorf("Report() = %s, want ready", r.Report().Status)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Start() did not return once its context was done")
+	}
+}
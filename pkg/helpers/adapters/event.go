@@ -0,0 +1,40 @@
+package adapters
+
+import (
+	model2 "build-service-gin/api/msgbroker/models"
+	modelsServ "build-service-gin/internal/domains"
+	"time"
+)
+
+type AdapterEvent struct{}
+
+func (a AdapterEvent) ConvertDomainToTransactionEvent(d *modelsServ.UserTransactionHistory) model2.TransactionEvent {
+	return model2.TransactionEvent{
+		TransactionID:        d.TransactionID,
+		TransactionType:      d.TransactionType,
+		ProfileID:            d.ProfileID,
+		Status:               d.Status,
+		PointAmount:          d.PointAmount,
+		PointType:            d.PointType,
+		TotalAmount:          d.TotalAmount,
+		Currency:             d.Currency,
+		PaymentTransactionID: d.PaymentTransactionID,
+		OriginalTxID:         d.OriginalTxID,
+		Source:               d.Source,
+		SourceType:           d.SourceType,
+		CreatedAt:            d.CreatedAt,
+		UpdatedAt:            d.UpdatedAt,
+		OccurredAt:           time.Now().UTC(),
+	}
+}
+
+func (a AdapterEvent) ConvertDomainToBalanceChangedEvent(d *modelsServ.UserTransactionHistory, reason string, pointDelta int64) model2.BalanceChangedEvent {
+	return model2.BalanceChangedEvent{
+		ProfileID:     d.ProfileID,
+		TransactionID: d.TransactionID,
+		Reason:        reason,
+		PointDelta:    pointDelta,
+		PointType:     d.PointType,
+		OccurredAt:    time.Now().UTC(),
+	}
+}
@@ -0,0 +1,50 @@
+package adapters
+
+import (
+	modelsHandler "build-service-gin/api/http/models"
+	"build-service-gin/pkg/featureflag"
+	modelsFlag "build-service-gin/repositories/feature_flag"
+)
+
+type AdapterFeatureFlag struct{}
+
+func (a AdapterFeatureFlag) ConvReq2Flag(d *modelsHandler.FeatureFlagRequest) *featureflag.Flag {
+	return &featureflag.Flag{
+		Key:         d.Key,
+		Description: d.Description,
+		Enabled:     d.Enabled,
+		Percentage:  d.Percentage,
+		Regions:     d.Regions,
+		Profiles:    d.Profiles,
+	}
+}
+
+func (a AdapterFeatureFlag) ConvFlagToRepo(d *featureflag.Flag) *modelsFlag.FeatureFlag {
+	return &modelsFlag.FeatureFlag{
+		Key:         d.Key,
+		Description: d.Description,
+		Enabled:     d.Enabled,
+		Percentage:  d.Percentage,
+		Regions:     d.Regions,
+		Profiles:    d.Profiles,
+	}
+}
+
+func (a AdapterFeatureFlag) ConvRepoToFlag(d *modelsFlag.FeatureFlag) *featureflag.Flag {
+	return &featureflag.Flag{
+		Key:         d.Key,
+		Description: d.Description,
+		Enabled:     d.Enabled,
+		Percentage:  d.Percentage,
+		Regions:     d.Regions,
+		Profiles:    d.Profiles,
+	}
+}
+
+func (a AdapterFeatureFlag) ConvRepoToArrayFlag(d []*modelsFlag.FeatureFlag) []featureflag.Flag {
+	flags := make([]featureflag.Flag, 0, len(d))
+	for _, flag := range d {
+		flags = append(flags, *a.ConvRepoToFlag(flag))
+	}
+	return flags
+}
@@ -0,0 +1,130 @@
+package adapters
+
+import (
+	"build-service-gin/api/grpc/pb"
+	modelsHandler "build-service-gin/api/http/models"
+	modelsServ "build-service-gin/internal/domains"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// AdapterGrpc converts the protobuf messages of the gRPC API. Requests become the models of the HTTP API, so both
+// APIs validate them alike, and the domain models become messages.
+type AdapterGrpc struct{}
+
+func (a AdapterGrpc) ConvProto2ModelGetUserTransactionHistoryReq(d *pb.ListUserTransactionHistoryRequest) modelsHandler.GetUserTransactionHistoryReq {
+	return modelsHandler.GetUserTransactionHistoryReq{
+		ProfileID:   d.GetProfileId(),
+		Offset:      d.GetOffset(),
+		Limit:       d.GetLimit(),
+		TxType:      d.GetTxType(),
+		Status:      d.GetStatus(),
+		RecentMonth: int(d.GetRecentMonth()),
+	}
+}
+
+func (a AdapterGrpc) ConvProto2ModelDeleteUserTransactionHistoryReq(d *pb.DeleteUserTransactionHistoryRequest) modelsHandler.GetUserTransactionHistoryByProfileReq {
+	return modelsHandler.GetUserTransactionHistoryByProfileReq{
+		ProfileID: d.GetProfileId(),
+	}
+}
+
+func (a AdapterGrpc) ConvProto2ModelUserTransactionHistory(d *pb.UserTransactionHistory) modelsHandler.UserTransactionHistory {
+	return modelsHandler.UserTransactionHistory{
+		TransactionID:        d.GetTransactionId(),
+		TransactionType:      d.GetTransactionType(),
+		ProfileID:            d.GetProfileId(),
+		Status:               d.GetStatus(),
+		PointAmount:          d.GetPointAmount(),
+		PointType:            d.GetPointType(),
+		TotalAmount:          d.GetTotalAmount(),
+		Currency:             d.GetCurrency(),
+		PaymentTransactionID: d.GetPaymentTransactionId(),
+		Source:               d.GetSource(),
+		SourceTime:           a.convProto2Time(d.GetSourceTime()),
+		SourceType:           d.GetSourceType(),
+		CreatedAt:            a.convProto2Time(d.GetCreatedAt()),
+		UpdatedAt:            a.convProto2Time(d.GetUpdatedAt()),
+	}
+}
+
+func (a AdapterGrpc) ConvProto2ModelOrderRequest(d *pb.CreatePointTransactionRequest) *modelsHandler.OrderRequest {
+	return &modelsHandler.OrderRequest{
+		OrderNumber: d.GetOrderNumber(),
+		CreateTime:  d.GetCreateTime(),
+		Amount:      d.GetAmount(),
+		Currency:    d.GetCurrency(),
+		VGAUserID:   d.GetVgaUserId(),
+		SourceType:  d.GetSourceType(),
+	}
+}
+
+func (a AdapterGrpc) ConvProto2ModelReverseRequest(d *pb.ReverseTransactionRequest) *modelsHandler.ReverseTransactionRequest {
+	return &modelsHandler.ReverseTransactionRequest{
+		ReversalID:           d.GetReversalId(),
+		TransactionID:        d.GetTransactionId(),
+		PaymentTransactionID: d.GetPaymentTransactionId(),
+		Amount:               d.GetAmount(),
+		Currency:             d.GetCurrency(),
+		Reason:               d.GetReason(),
+	}
+}
+
+func (a AdapterGrpc) ConvDomain2ProtoUserTransactionHistory(d *modelsServ.UserTransactionHistory) *pb.UserTransactionHistory {
+	if d == nil {
+		return &pb.UserTransactionHistory{}
+	}
+	return &pb.UserTransactionHistory{
+		TransactionId:         d.TransactionID,
+		TransactionType:       d.TransactionType,
+		ProfileId:             d.ProfileID,
+		Status:                d.Status,
+		PointAmount:           d.PointAmount,
+		PointType:             d.PointType,
+		TotalAmount:           d.TotalAmount,
+		Currency:              d.Currency,
+		PaymentTransactionId:  d.PaymentTransactionID,
+		Source:                d.Source,
+		SourceTime:            a.convTime2Proto(d.SourceTime),
+		SourceType:            d.SourceType,
+		StatusHistory:         a.convDomainStatusHistory2Proto(d.StatusHistory),
+		OriginalTransactionId: d.OriginalTxID,
+		ReversedAmount:        d.ReversedAmount,
+		ReversedPointAmount:   d.ReversedPointAmount,
+		CreatedAt:             a.convTime2Proto(d.CreatedAt),
+		UpdatedAt:             a.convTime2Proto(d.UpdatedAt),
+	}
+}
+
+func (a AdapterGrpc) ConvDomain2ProtoArrayUserTransactionHistory(listDataDomain []modelsServ.UserTransactionHistory) (data []*pb.UserTransactionHistory) {
+	for i := range listDataDomain {
+		data = append(data, a.ConvDomain2ProtoUserTransactionHistory(&listDataDomain[i]))
+	}
+	return data
+}
+
+func (a AdapterGrpc) convDomainStatusHistory2Proto(histories []modelsServ.TxStatusHistory) (data []*pb.TxStatusHistory) {
+	for _, item := range histories {
+		data = append(data, &pb.TxStatusHistory{
+			Status:    item.Status,
+			ChangedAt: a.convTime2Proto(item.ChangedAt),
+		})
+	}
+	return data
+}
+
+func (a AdapterGrpc) convTime2Proto(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func (a AdapterGrpc) convProto2Time(t *timestamppb.Timestamp) *time.Time {
+	if t == nil {
+		return nil
+	}
+	converted := t.AsTime()
+	return &converted
+}
@@ -2,6 +2,7 @@ package adapters
 
 import (
 	modelsHandler "build-service-gin/api/http/models"
+	model2 "build-service-gin/api/msgbroker/models"
 	modelsServ "build-service-gin/internal/domains"
 )
 
@@ -17,3 +18,26 @@ func (a AdapterLPPoint) ConvertOrderHandler2Domain(d *modelsHandler.OrderRequest
 		SourceType:  d.SourceType,
 	}
 }
+
+func (a AdapterLPPoint) ConvertReverseRequest2Domain(d *modelsHandler.ReverseTransactionRequest) (data *modelsServ.ReverseTransaction) {
+	return &modelsServ.ReverseTransaction{
+		ReversalID:           d.ReversalID,
+		ProfileID:            d.ProfileID,
+		TransactionID:        d.TransactionID,
+		PaymentTransactionID: d.PaymentTransactionID,
+		Amount:               d.Amount,
+		Currency:             d.Currency,
+		Reason:               d.Reason,
+	}
+}
+
+func (a AdapterLPPoint) ConvertEventRefundToDomain(event *model2.RefundEvent) *modelsServ.ReverseTransaction {
+	return &modelsServ.ReverseTransaction{
+		ReversalID:           event.RefundID,
+		TransactionID:        event.TransactionID,
+		PaymentTransactionID: event.PaymentTransactionID,
+		Amount:               event.Amount,
+		Currency:             event.Currency,
+		Reason:               event.Reason,
+	}
+}
@@ -4,6 +4,7 @@ import (
 	modelsHandler "build-service-gin/api/http/models"
 	model2 "build-service-gin/api/msgbroker/models"
 	modelsServ "build-service-gin/internal/domains"
+	"build-service-gin/pkg/fieldcrypt"
 	modelsRepo "build-service-gin/repositories/user_transaction_history"
 	modelRepoPostgres "build-service-gin/repositories/user_transaction_history_postgresql"
 )
@@ -60,10 +61,13 @@ func (a AdapterProfile) ConvDomainToRepo(d *modelsServ.UserTransactionHistory) (
 		PointType:            d.PointType,
 		TotalAmount:          d.TotalAmount,
 		Currency:             d.Currency,
-		PaymentTransactionID: d.PaymentTransactionID,
+		PaymentTransactionID: fieldcrypt.Deterministic(d.PaymentTransactionID),
 		Source:               d.Source,
 		SourceTime:           d.SourceTime,
 		SourceType:           d.SourceType,
+		OriginalTxID:         d.OriginalTxID,
+		ReversedAmount:       d.ReversedAmount,
+		ReversedPointAmount:  d.ReversedPointAmount,
 		CreatedAt:            d.CreatedAt,
 		UpdatedAt:            d.UpdatedAt,
 	}
@@ -80,10 +84,14 @@ func (a AdapterProfile) ConvRepoToDomain(d *modelsRepo.UserTransactionHistory) (
 		PointType:            d.PointType,
 		TotalAmount:          d.TotalAmount,
 		Currency:             d.Currency,
-		PaymentTransactionID: d.PaymentTransactionID,
+		PaymentTransactionID: string(d.PaymentTransactionID),
 		Source:               d.Source,
 		SourceTime:           d.SourceTime,
 		SourceType:           d.SourceType,
+		StatusHistory:        a.convRepoStatusHistory2Domain(d.StatusHistory),
+		OriginalTxID:         d.OriginalTxID,
+		ReversedAmount:       d.ReversedAmount,
+		ReversedPointAmount:  d.ReversedPointAmount,
 		CreatedAt:            d.CreatedAt,
 		UpdatedAt:            d.UpdatedAt,
 	}
@@ -100,10 +108,14 @@ func (a AdapterProfile) ConvRepo2ServPoint(d *modelsRepo.UserTransactionHistory)
 		PointType:            d.PointType,
 		TotalAmount:          d.TotalAmount,
 		Currency:             d.Currency,
-		PaymentTransactionID: d.PaymentTransactionID,
+		PaymentTransactionID: string(d.PaymentTransactionID),
 		Source:               d.Source,
 		SourceTime:           d.SourceTime,
 		SourceType:           d.SourceType,
+		StatusHistory:        a.convRepoStatusHistory2Domain(d.StatusHistory),
+		OriginalTxID:         d.OriginalTxID,
+		ReversedAmount:       d.ReversedAmount,
+		ReversedPointAmount:  d.ReversedPointAmount,
 		CreatedAt:            d.CreatedAt,
 		UpdatedAt:            d.UpdatedAt,
 	}
@@ -119,7 +131,7 @@ func (a AdapterProfile) ConvertOrderCreateDomainToRepo(data *modelsServ.OrderSuc
 		Source:               data.Source,
 		SourceTime:           data.SourceTime,
 		SourceType:           data.SourceType,
-		PaymentTransactionID: data.PaymentTransactionID,
+		PaymentTransactionID: fieldcrypt.Deterministic(data.PaymentTransactionID),
 	}
 }
 
@@ -128,7 +140,7 @@ func (a AdapterProfile) ConvertOrderUpdateDomainToRepo(data *modelsServ.OrderSuc
 		Source:               data.Source,
 		SourceTime:           data.SourceTime,
 		SourceType:           data.SourceType,
-		PaymentTransactionID: data.PaymentTransactionID,
+		PaymentTransactionID: fieldcrypt.Deterministic(data.PaymentTransactionID),
 	}
 }
 
@@ -170,6 +182,7 @@ func (a AdapterProfile) ConvertEventEarnPointToDomain(event *model2.EarnPointOrd
 		TotalAmount:     event.TotalAmount,
 		Currency:        event.Currency,
 		ProfileID:       event.ProfileID,
+		Status:          event.Status,
 	}
 }
 
@@ -183,7 +196,7 @@ func (a AdapterProfile) ConvDomainToRepoPostgresql(d *modelsServ.UserTransaction
 		PointType:            d.PointType,
 		TotalAmount:          d.TotalAmount,
 		Currency:             d.Currency,
-		PaymentTransactionID: d.PaymentTransactionID,
+		PaymentTransactionID: fieldcrypt.Deterministic(d.PaymentTransactionID),
 		Source:               d.Source,
 		SourceTime:           d.SourceTime,
 		SourceType:           d.SourceType,
@@ -203,10 +216,11 @@ func (a AdapterProfile) ConvRepoToDomainPostgresql(d *modelRepoPostgres.UserTran
 		PointType:            d.PointType,
 		TotalAmount:          d.TotalAmount,
 		Currency:             d.Currency,
-		PaymentTransactionID: d.PaymentTransactionID,
+		PaymentTransactionID: string(d.PaymentTransactionID),
 		Source:               d.Source,
 		SourceTime:           d.SourceTime,
 		SourceType:           d.SourceType,
+		StatusHistory:        a.convRepoPostgresqlStatusHistory2Domain(d.StatusHistory),
 		CreatedAt:            d.CreatedAt,
 		UpdatedAt:            d.UpdatedAt,
 	}
@@ -231,12 +245,55 @@ func (a AdapterProfile) ConvRepo2ServPointPostgresql(d *modelRepoPostgres.UserTr
 		PointType:            d.PointType,
 		TotalAmount:          d.TotalAmount,
 		Currency:             d.Currency,
-		PaymentTransactionID: d.PaymentTransactionID,
+		PaymentTransactionID: string(d.PaymentTransactionID),
 		Source:               d.Source,
 		SourceTime:           d.SourceTime,
 		SourceType:           d.SourceType,
+		StatusHistory:        a.convRepoPostgresqlStatusHistory2Domain(d.StatusHistory),
 		CreatedAt:            d.CreatedAt,
 		UpdatedAt:            d.UpdatedAt,
 	}
 	return data
 }
+
+func (a AdapterProfile) convRepoStatusHistory2Domain(histories []modelsRepo.StatusHistory) (data []modelsServ.TxStatusHistory) {
+	for _, item := range histories {
+		data = append(data, modelsServ.TxStatusHistory{
+			Status:    item.Status,
+			ChangedAt: item.ChangedAt,
+		})
+	}
+	return data
+}
+
+func (a AdapterProfile) convRepoPostgresqlStatusHistory2Domain(histories modelRepoPostgres.StatusHistories) (data []modelsServ.TxStatusHistory) {
+	for _, item := range histories {
+		data = append(data, modelsServ.TxStatusHistory{
+			Status:    item.Status,
+			ChangedAt: item.ChangedAt,
+		})
+	}
+	return data
+}
+
+// AddStatusSummary2Domain adds the transactions of a status summarized by the repository to the summary of their
+// profile, the status decides whether their points count toward the balance or are pending.
+func (a AdapterProfile) AddStatusSummary2Domain(summary *modelsServ.ProfileSummary, d *modelsRepo.StatusSummary) {
+	tx := modelsServ.UserTransactionHistory{Status: d.Status}
+	switch {
+	case tx.CountsTowardBalance():
+		summary.Balance.Points += d.Points
+		summary.Stats.SuccessCount += d.RecentCount
+		summary.Stats.PointsEarned += d.RecentCredited
+		summary.Stats.PointsReversed -= d.RecentDebited
+	case tx.IsPending():
+		summary.Balance.PendingPoints += d.Points
+	case d.Status == modelsServ.TxStatusFailed:
+		summary.Stats.FailedCount += d.RecentCount
+	}
+
+	summary.Stats.TransactionCount += d.RecentCount
+	if d.LastCreatedAt != nil && (summary.Stats.LastTransactionAt == nil || d.LastCreatedAt.After(*summary.Stats.LastTransactionAt)) {
+		summary.Stats.LastTransactionAt = d.LastCreatedAt
+	}
+}
@@ -0,0 +1,120 @@
+package adapters
+
+import (
+	modelsHandler "build-service-gin/api/http/models"
+	modelsServ "build-service-gin/internal/domains"
+	"build-service-gin/pkg/fieldcrypt"
+	modelsDelivery "build-service-gin/repositories/webhook_delivery"
+	modelsSubscription "build-service-gin/repositories/webhook_subscription"
+)
+
+type AdapterWebhook struct{}
+
+func (a AdapterWebhook) ConvReq2DomainSubscription(d *modelsHandler.WebhookSubscriptionRequest) *modelsServ.WebhookSubscription {
+	enabled := true
+	if d.Enabled != nil {
+		enabled = *d.Enabled
+	}
+	return &modelsServ.WebhookSubscription{
+		SubscriptionID: d.SubscriptionID,
+		Name:           d.Name,
+		URL:            d.URL,
+		Secret:         d.Secret,
+		EventTypes:     d.EventTypes,
+		Enabled:        enabled,
+	}
+}
+
+func (a AdapterWebhook) ConvReq2DomainDeliveries(d *modelsHandler.GetWebhookDeliveriesReq) modelsServ.GetWebhookDeliveriesReq {
+	return modelsServ.GetWebhookDeliveriesReq{
+		SubscriptionID: d.SubscriptionID,
+		Status:         d.Status,
+		Offset:         d.Offset,
+		Limit:          d.Limit,
+	}
+}
+
+func (a AdapterWebhook) ConvDomainToRepoSubscription(d *modelsServ.WebhookSubscription) *modelsSubscription.WebhookSubscription {
+	return &modelsSubscription.WebhookSubscription{
+		SubscriptionID: d.SubscriptionID,
+		Name:           d.Name,
+		URL:            d.URL,
+		Secret:         fieldcrypt.Randomized(d.Secret),
+		EventTypes:     d.EventTypes,
+		Enabled:        d.Enabled,
+	}
+}
+
+func (a AdapterWebhook) ConvRepoToDomainSubscription(d *modelsSubscription.WebhookSubscription) *modelsServ.WebhookSubscription {
+	return &modelsServ.WebhookSubscription{
+		SubscriptionID:      d.SubscriptionID,
+		Name:                d.Name,
+		URL:                 d.URL,
+		Secret:              string(d.Secret),
+		EventTypes:          d.EventTypes,
+		Enabled:             d.Enabled,
+		ConsecutiveFailures: d.ConsecutiveFailures,
+		DisabledAt:          d.DisabledAt,
+		DisabledReason:      d.DisabledReason,
+		CreatedAt:           d.CreatedAt,
+		UpdatedAt:           d.UpdatedAt,
+	}
+}
+
+func (a AdapterWebhook) ConvRepoToDomainArraySubscription(list []*modelsSubscription.WebhookSubscription) (data []modelsServ.WebhookSubscription) {
+	for _, item := range list {
+		data = append(data, *a.ConvRepoToDomainSubscription(item))
+	}
+	return data
+}
+
+func (a AdapterWebhook) ConvRepoToDomainDelivery(d *modelsDelivery.WebhookDelivery) *modelsServ.WebhookDelivery {
+	var attempts []modelsServ.WebhookDeliveryAttempt
+	for _, attempt := range d.AttemptLogs {
+		attempts = append(attempts, modelsServ.WebhookDeliveryAttempt{
+			Attempt:        attempt.Attempt,
+			ResponseStatus: attempt.ResponseStatus,
+			ResponseBody:   attempt.ResponseBody,
+			Error:          attempt.Error,
+			DurationMs:     attempt.DurationMs,
+			AttemptedAt:    attempt.AttemptedAt,
+		})
+	}
+
+	return &modelsServ.WebhookDelivery{
+		DeliveryID:     d.DeliveryID,
+		SubscriptionID: d.SubscriptionID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Payload:        d.Payload,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		ResponseBody:   d.ResponseBody,
+		Error:          d.Error,
+		AttemptLogs:    attempts,
+		RedeliveryOf:   d.RedeliveryOf,
+		NextAttemptAt:  d.NextAttemptAt,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+	}
+}
+
+func (a AdapterWebhook) ConvRepoToDomainArrayDelivery(list []*modelsDelivery.WebhookDelivery) (data []modelsServ.WebhookDelivery) {
+	for _, item := range list {
+		data = append(data, *a.ConvRepoToDomainDelivery(item))
+	}
+	return data
+}
+
+func (a AdapterWebhook) ConvDomainToRepoAttempt(d *modelsServ.WebhookDeliveryAttempt) modelsDelivery.DeliveryAttempt {
+	return modelsDelivery.DeliveryAttempt{
+		Attempt:        d.Attempt,
+		ResponseStatus: d.ResponseStatus,
+		ResponseBody:   d.ResponseBody,
+		Error:          d.Error,
+		DurationMs:     d.DurationMs,
+		AttemptedAt:    d.AttemptedAt,
+	}
+}
@@ -5,3 +5,6 @@ const (
 	SourceRewards                = "rewards"
 	SourceTypeEarnPointFromAdmin = "EARN_POINT_FROM_ADMIN"
 )
+
+// SourceTypes lists the source types an order may come from.
+var SourceTypes = []string{SourceTypeEarnPointFromAdmin}
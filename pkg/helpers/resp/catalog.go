@@ -0,0 +1,152 @@
+package resp
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed messages/*.yaml messages/rules/*.yaml
+var messageFiles embed.FS
+
+// Languages are the languages every message has to be translated to.
+var Languages = []string{LangEN, LangVI}
+
+var defaultCatalog = mustLoadCatalog(messageFiles, "messages")
+
+// Catalog holds the message templates of every error code and every validation rule per language.
+type Catalog struct {
+	messages map[string]map[int64]string
+	rules    map[string]map[string]string
+}
+
+// LoadCatalog reads one <lang>.yaml file per language from dir of fsys, mapping an error code to its message, and
+// one from dir/rules, mapping a validation rule to its message.
+func LoadCatalog(fsys fs.FS, dir string) (*Catalog, error) {
+	messages, err := loadMessageFiles[int64](fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := loadMessageFiles[string](fsys, path.Join(dir, "rules"))
+	if err != nil {
+		return nil, err
+	}
+	return &Catalog{messages: messages, rules: rules}, nil
+}
+
+func loadMessageFiles[K comparable](fsys fs.FS, dir string) (map[string]map[K]string, error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	byLang := make(map[string]map[K]string, len(files))
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		var messages map[K]string
+		if err = yaml.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("message file %s: %w", file, err)
+		}
+
+		lang := strings.ToUpper(strings.TrimSuffix(path.Base(file), path.Ext(file)))
+		byLang[lang] = messages
+	}
+	return byLang, nil
+}
+
+func mustLoadCatalog(fsys fs.FS, dir string) *Catalog {
+	c, err := LoadCatalog(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Message renders the message of code in lang, or in English when lang has none. Every {name} in the template is
+// replaced by params[name].
+func (c *Catalog) Message(code int64, lang string, params map[string]interface{}) (string, bool) {
+	template, ok := c.messages[lang][code]
+	if !ok {
+		template, ok = c.messages[LangEN][code]
+	}
+	if !ok {
+		return "", false
+	}
+	return render(template, params), true
+}
+
+// RuleMessage renders the message of the validation rule in lang like Message.
+func (c *Catalog) RuleMessage(rule, lang string, params map[string]interface{}) (string, bool) {
+	template, ok := c.rules[lang][rule]
+	if !ok {
+		template, ok = c.rules[LangEN][rule]
+	}
+	if !ok {
+		return "", false
+	}
+	return render(template, params), true
+}
+
+func render(template string, params map[string]interface{}) string {
+	if len(params) == 0 {
+		return template
+	}
+	pairs := make([]string, 0, 2*len(params))
+	for name, value := range params {
+		pairs = append(pairs, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(pairs...).Replace(template)
+}
+
+// Check reports every code of codes missing a message in one of langs, every message without a code and every rule
+// translated to English but not to one of langs.
+func (c *Catalog) Check(codes []int64, langs []string) error {
+	known := make(map[int64]bool, len(codes))
+	for _, code := range codes {
+		known[code] = true
+	}
+
+	var problems []string
+	for _, lang := range langs {
+		messages, ok := c.messages[lang]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("no message file for %s", lang))
+			continue
+		}
+		for _, code := range codes {
+			if _, ok := messages[code]; !ok {
+				problems = append(problems, fmt.Sprintf("%s has no message for %d", lang, code))
+			}
+		}
+		for code := range messages {
+			if !known[code] {
+				problems = append(problems, fmt.Sprintf("%s has a message for unknown code %d", lang, code))
+			}
+		}
+		for rule := range c.rules[LangEN] {
+			if _, ok := c.rules[lang][rule]; !ok {
+				problems = append(problems, fmt.Sprintf("%s has no message for rule %s", lang, rule))
+			}
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+
+	sort.Strings(problems)
+	return fmt.Errorf("error catalog: %s", strings.Join(problems, "; "))
+}
+
+// CheckCatalog checks the embedded messages cover every error code in every language, run it at startup.
+func CheckCatalog() error {
+	return defaultCatalog.Check(Codes(), Languages)
+}
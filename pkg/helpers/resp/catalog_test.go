@@ -0,0 +1,131 @@
+package resp
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCheckCatalog(t *testing.T) {
+	if err := CheckCatalog(); err != nil {
+		t.Fatalf("CheckCatalog() = %v", err)
+	}
+}
+
+// testCatalog loads a catalog of the codes 1 and 1000 and of the rule required in English and Vietnamese, files
+// replaces or, when empty, removes the files of the catalog.
+func testCatalog(t *testing.T, files map[string]string) *Catalog {
+	t.Helper()
+	fsys := fstest.MapFS{
+		"messages/en.yaml":       {Data: []byte("1: Success\n1000: System error\n")},
+		"messages/vi.yaml":       {Data: []byte("1: Thành công\n1000: Lỗi hệ thống\n")},
+		"messages/rules/en.yaml": {Data: []byte("required: \"{field} is required\"\n")},
+		"messages/rules/vi.yaml": {Data: []byte("required: \"{field} là bắt buộc\"\n")},
+	}
+	for name, data := range files {
+		if data == "" {
+			delete(fsys, name)
+			continue
+		}
+		fsys[name] = &fstest.MapFile{Data: []byte(data)}
+	}
+
+	c, err := LoadCatalog(fsys, "messages")
+	if err != nil {
+		t.Fatalf("LoadCatalog() = %v", err)
+	}
+	return c
+}
+
+func TestCatalogCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		codes   []int64
+		wantErr []string
+	}{
+		{name: "complete", codes: []int64{1, 1000}},
+		{
+			name:    "missing code",
+			files:   map[string]string{"messages/vi.yaml": "1: Thành công\n"},
+			codes:   []int64{1, 1000},
+			wantErr: []string{"VI has no message for 1000"},
+		},
+		{
+			name:    "code without a message in any language",
+			codes:   []int64{1, 1000, 1001},
+			wantErr: []string{"EN has no message for 1001", "VI has no message for 1001"},
+		},
+		{
+			name:    "message of an unknown code",
+			codes:   []int64{1},
+			wantErr: []string{"EN has a message for unknown code 1000", "VI has a message for unknown code 1000"},
+		},
+		{
+			name:    "missing language",
+			files:   map[string]string{"messages/vi.yaml": ""},
+			codes:   []int64{1, 1000},
+			wantErr: []string{"no message file for VI"},
+		},
+		{
+			name:    "untranslated rule",
+			files:   map[string]string{"messages/rules/en.yaml": "required: \"{field} is required\"\nmin: \"{field} min\"\n"},
+			codes:   []int64{1, 1000},
+			wantErr: []string{"VI has no message for rule min"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := testCatalog(t, tt.files).Check(tt.codes, Languages)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("Check() = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Check() = nil, want %v", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Check() = %v, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCatalogMessage(t *testing.T) {
+	c := testCatalog(t, map[string]string{
+		"messages/en.yaml": "1: Success\n1000: System error\n2000: \"{field} over {limit}\"\n",
+	})
+
+	tests := []struct {
+		name   string
+		code   int64
+		lang   string
+		params map[string]interface{}
+		want   string
+		wantOK bool
+	}{
+		{name: "language", code: 1000, lang: LangVI, want: "Lỗi hệ thống", wantOK: true},
+		{name: "english fallback", code: 2000, lang: LangVI, params: map[string]interface{}{"field": "limit", "limit": 100}, want: "limit over 100", wantOK: true},
+		{name: "unknown language", code: 1, lang: "FR", want: "Success", wantOK: true},
+		{name: "unknown code", code: 9999, lang: LangEN},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := c.Message(tt.code, tt.lang, tt.params)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Message() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGetMappingErrorFallsBackToSystemError(t *testing.T) {
+	got := GetMappingError(999999, LangEN)
+	if got.ErrorCode != ErrSystem || got.Message != "System error" {
+		t.Errorf("GetMappingError() = %+v, want the system error", got)
+	}
+}
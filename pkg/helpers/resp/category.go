@@ -0,0 +1,83 @@
+package resp
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Categories of errors. A CustomError matches its category with errors.Is, e.g. errors.Is(err, resp.NotFound),
+// and the category picks the HTTP status of the response.
+var (
+	NotFound        = errors.New("not found")
+	Conflict        = errors.New("conflict")
+	Invalid         = errors.New("invalid")
+	Unauthorized    = errors.New("unauthorized")
+	Forbidden       = errors.New("forbidden")
+	TooManyRequests = errors.New("too many requests")
+	Unavailable     = errors.New("unavailable")
+	Internal        = errors.New("internal")
+)
+
+var categoryStatus = []struct {
+	category error
+	status   int
+}{
+	{NotFound, http.StatusNotFound},
+	{Conflict, http.StatusConflict},
+	{Invalid, http.StatusBadRequest},
+	{Unauthorized, http.StatusUnauthorized},
+	{Forbidden, http.StatusForbidden},
+	{TooManyRequests, http.StatusTooManyRequests},
+	{Unavailable, http.StatusServiceUnavailable},
+	{Internal, http.StatusInternalServerError},
+}
+
+// NewError returns an error of category with the message of code, description details it for the caller.
+func NewError(category error, code int64, description string) *CustomError {
+	return &CustomError{ErrorCode: code, Description: description, Kind: category}
+}
+
+// WrapError returns an error of category with the message of code caused by cause, errors.Is and errors.As see
+// through it to cause. The invalid fields of cause, e.g. of validate.ValidationErrors, become the Fields of the error.
+func WrapError(category error, code int64, cause error) *CustomError {
+	err := &CustomError{ErrorCode: code, Description: cause.Error(), Kind: category, Cause: cause}
+	var fields fieldErrorer
+	if errors.As(cause, &fields) {
+		err.Fields = fields.FieldErrors()
+	}
+	return err
+}
+
+// HTTPStatus maps the category of err to an HTTP status, errors without a category are internal errors.
+func HTTPStatus(err error) int {
+	for _, cs := range categoryStatus {
+		if errors.Is(err, cs.category) {
+			return cs.status
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+// codeCategory is the category of errors built without one, from their code.
+func codeCategory(code int64) error {
+	switch code {
+	case ErrNotFound, ErrHandleBalanceNotFound, ErrHandleTxTypeNotFound, ErrHandleProfileIdNotFound, ErrHandleOrderNotFound:
+		return NotFound
+	case ErrHandleTxStatusTransitionInvalid, ErrHandleTxAlreadyReversed, ErrHandleWebhookDisabled:
+		return Conflict
+	case ErrAuth, ErrSignatureInvalid:
+		return Unauthorized
+	case ErrForbidden:
+		return Forbidden
+	case ErrTooManyRequest, ErrRateLimit:
+		return TooManyRequests
+	case ErrCallExternalService, ErrHandleTierClient, ErrStreamLimit, ErrServiceNotReady:
+		return Unavailable
+	case ErrSystem, ErrStoreDataFailed, ErrENVInvalid, ErrHandleTier:
+		return Internal
+	}
+	if code == ErrDataInvalid || (code >= ErrHandler1LogicA && code < errLogicEnd) {
+		return Invalid
+	}
+	return Internal
+}
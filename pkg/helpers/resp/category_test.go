@@ -0,0 +1,40 @@
+package resp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestHTTPStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "category of the code", err: &CustomError{ErrorCode: ErrHandleOrderNotFound}, want: http.StatusNotFound},
+		{name: "logic code", err: &CustomError{ErrorCode: ErrHandleAmountInvalid}, want: http.StatusBadRequest},
+		{name: "explicit category", err: NewError(Conflict, ErrDataInvalid, "taken"), want: http.StatusConflict},
+		{name: "wrapped", err: fmt.Errorf("create: %w", NewError(Unavailable, ErrCallExternalService, "down")), want: http.StatusServiceUnavailable},
+		{name: "wrapping a category", err: WrapError(Forbidden, ErrForbidden, errors.New("scope")), want: http.StatusForbidden},
+		{name: "rate limit", err: &CustomError{ErrorCode: ErrRateLimit}, want: http.StatusTooManyRequests},
+		{name: "auth", err: &CustomError{ErrorCode: ErrSignatureInvalid}, want: http.StatusUnauthorized},
+		{name: "plain error", err: errors.New("boom"), want: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HTTPStatus(tt.err); got != tt.want {
+				t.Errorf("HTTPStatus() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrapErrorKeepsCause(t *testing.T) {
+	cause := errors.New("duplicate key")
+	err := WrapError(Conflict, ErrStoreDataFailed, cause)
+	if !errors.Is(err, cause) || !errors.Is(err, Conflict) {
+		t.Errorf("WrapError() = %v, want it to match its cause and its category", err)
+	}
+}
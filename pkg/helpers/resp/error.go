@@ -18,6 +18,8 @@ const (
 	ErrDataInvalid
 	ErrStoreDataFailed
 	ErrENVInvalid
+	ErrServiceNotReady
+	errCommonEnd
 )
 
 const (
@@ -38,57 +40,55 @@ const (
 	ErrHandleProfileIdNotFound
 	ErrHandleTierClient
 	ErrHandleOrderNotFound
+	ErrHandleTxStatusTransitionInvalid
+	ErrHandleTxAlreadyReversed
+	ErrHandleWebhookDisabled
+	errLogicEnd
 )
 
 const (
 	//auth error
 	ErrAuth = 4000 + iota
+	ErrForbidden
+	ErrSignatureInvalid
+	errAuthEnd
 )
 
 const (
 	// Rate limit error
 	ErrTooManyRequest = 5000 + iota
 	ErrRateLimit
+	ErrStreamLimit
+	errRateLimitEnd
 )
 
 const (
 	// call external services error
 	ErrCallExternalService = 9000 + iota
+	errExternalEnd
 )
 
-// TODO get mapping error-message in file config json
-var (
-	mappingErrEN = map[int64]mappingError{
-		StatusOK:           {StatusOK, "Success"},
-		ErrSystem:          {ErrSystem, "System error"},
-		ErrAuth:            {ErrAuth, "Authenticate fail"},
-		ErrNotFound:        {ErrNotFound, "Data not found"},
-		ErrDataInvalid:     {ErrDataInvalid, "Data invalid"},
-		ErrStoreDataFailed: {ErrStoreDataFailed, "There was an error during the data saving process"},
-		ErrENVInvalid:      {ErrENVInvalid, "ENV invalid"},
-
-		ErrHandler1LogicA:       {ErrHandler1LogicA, "ErrHandler1LogicA"},
-		ErrHandleLogicParseData: {ErrHandleLogicParseData, "parse data fail"},
-		ErrCallExternalService:  {ErrCallExternalService, "There was an error during the call external services"},
-
-		ErrTooManyRequest:          {ErrTooManyRequest, "Too many request"},
-		ErrRateLimit:               {ErrRateLimit, "Rate limit"},
-		ErrHandleAmountInvalid:     {ErrHandleAmountInvalid, "Amount invalid"},
-		ErrHandleCurrencyInvalid:   {ErrHandleCurrencyInvalid, "Currency invalid"},
-		ErrHandleTier:              {ErrHandleTier, "Error retrieve tier"},
-		ErrHandleProfileInvalid:    {ErrHandleProfileInvalid, "Profile invalid"},
-		ErrHandleBalanceNotFound:   {ErrHandleBalanceNotFound, "Balance not found"},
-		ErrHandleTxTypeNotFound:    {ErrHandleTxTypeNotFound, "Tx type not found"},
-		ErrHandleInvalidMonth:      {ErrHandleInvalidMonth, "Invalid month"},
-		ErrHandleOffsetInvalid:     {ErrHandleOffsetInvalid, "Offset invalid"},
-		ErrHandleLimitInvalid:      {ErrHandleLimitInvalid, "Limit invalid"},
-		ErrHandleProfileIdNotFound: {ErrHandleProfileIdNotFound, "Profile Id not found"},
-		ErrHandleTierClient:        {ErrHandleTierClient, "Error retrieve tier client"},
-		ErrHandleOrderNotFound:     {ErrHandleOrderNotFound, "Order not found"},
-		ErrProfileTerritoryInvalid: {ErrProfileTerritoryInvalid, "Profile territory invalid"},
-		ErrProfileGamesInvalid:     {ErrProfileGamesInvalid, "Profile games invalid"},
+// codeRanges holds the first and the end code of every block above, a new block has to be added here for its
+// codes to be checked by CheckCatalog.
+var codeRanges = [][2]int64{
+	{StatusOK, StatusOK + 1},
+	{ErrSystem, errCommonEnd},
+	{ErrHandler1LogicA, errLogicEnd},
+	{ErrAuth, errAuthEnd},
+	{ErrTooManyRequest, errRateLimitEnd},
+	{ErrCallExternalService, errExternalEnd},
+}
+
+// Codes lists every code defined above.
+func Codes() []int64 {
+	var codes []int64
+	for _, r := range codeRanges {
+		for code := r[0]; code < r[1]; code++ {
+			codes = append(codes, code)
+		}
 	}
-)
+	return codes
+}
 
 type mappingError struct {
 	ErrorCode int64  `json:"errorCode"`
@@ -107,16 +107,17 @@ func (c *mappingError) GetErrMsg() string {
 	return c.Message
 }
 
+// GetMappingError returns the message of errCode in lang, falling back to English and then to ErrSystem.
 func GetMappingError(errCode int64, lang string) mappingError {
-	switch lang {
-	case LangEN:
-		if err, ok := mappingErrEN[errCode]; ok {
-			return err
-		}
-	default:
-		if err, ok := mappingErrEN[errCode]; ok {
-			return err
-		}
+	return GetMappingErrorParams(errCode, lang, nil)
+}
+
+// GetMappingErrorParams is GetMappingError with the {name} placeholders of the message replaced by params.
+func GetMappingErrorParams(errCode int64, lang string, params map[string]interface{}) mappingError {
+	msg, ok := defaultCatalog.Message(errCode, lang, params)
+	if !ok {
+		errCode = ErrSystem
+		msg, _ = defaultCatalog.Message(ErrSystem, lang, nil)
 	}
-	return mappingErrEN[ErrSystem]
+	return mappingError{ErrorCode: errCode, Message: msg}
 }
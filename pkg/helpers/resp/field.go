@@ -0,0 +1,31 @@
+package resp
+
+// FieldError is a field of the request that failed validation. Field is its JSON path, e.g. items[3].amount, Rule
+// the validation rule it broke and Param the parameter of the rule.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// fieldErrorer is implemented by errors listing invalid fields, e.g. validate.ValidationErrors.
+type fieldErrorer interface {
+	FieldErrors() []FieldError
+}
+
+// LocalizeFields returns fields with their messages in lang, fields of rules without a message keep theirs.
+func LocalizeFields(fields []FieldError, lang string) []FieldError {
+	if len(fields) == 0 {
+		return fields
+	}
+	localized := make([]FieldError, len(fields))
+	for i, field := range fields {
+		params := map[string]interface{}{"field": field.Field, "param": field.Param}
+		if msg, ok := defaultCatalog.RuleMessage(field.Rule, lang, params); ok {
+			field.Message = msg
+		}
+		localized[i] = field
+	}
+	return localized
+}
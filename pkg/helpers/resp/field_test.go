@@ -0,0 +1,33 @@
+package resp
+
+import "testing"
+
+func TestLocalizeFields(t *testing.T) {
+	fields := []FieldError{
+		{Field: "items[3].amount", Rule: "min", Param: "1", Message: "items[3].amount - min=1"},
+		{Field: "profileID", Rule: "no_such_rule", Message: "kept as is"},
+	}
+
+	tests := []struct {
+		lang string
+		want []string
+	}{
+		{lang: LangEN, want: []string{"items[3].amount must be at least 1", "kept as is"}},
+		{lang: LangVI, want: []string{"items[3].amount phải lớn hơn hoặc bằng 1", "kept as is"}},
+		{lang: "FR", want: []string{"items[3].amount must be at least 1", "kept as is"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			got := LocalizeFields(fields, tt.lang)
+			for i, want := range tt.want {
+				if got[i].Message != want {
+					t.Errorf("field %d message = %q, want %q", i, got[i].Message, want)
+				}
+			}
+		})
+	}
+
+	if fields[0].Message != "items[3].amount - min=1" {
+		t.Error("LocalizeFields changed its argument")
+	}
+}
@@ -0,0 +1,29 @@
+package resp
+
+import "context"
+
+// Formats of the response body.
+const (
+	// FormatEnvelope is the {errorCode, description, message, data} envelope of Resp, the default.
+	FormatEnvelope = "envelope"
+	// FormatProblem writes errors as RFC 7807 problem details and successes without the envelope.
+	FormatProblem = "problem"
+)
+
+// MIMEProblemJSON is the media type of RFC 7807 problem details, clients ask for FormatProblem by accepting it.
+const MIMEProblemJSON = "application/problem+json"
+
+type formatKey struct{}
+
+// WithFormat stores the format responses to the request of ctx are written in.
+func WithFormat(ctx context.Context, format string) context.Context {
+	return context.WithValue(ctx, formatKey{}, format)
+}
+
+// FormatFromContext returns the format stored by WithFormat, FormatEnvelope by default.
+func FormatFromContext(ctx context.Context) string {
+	if format, ok := ctx.Value(formatKey{}).(string); ok {
+		return format
+	}
+	return FormatEnvelope
+}
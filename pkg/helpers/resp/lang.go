@@ -0,0 +1,18 @@
+package resp
+
+import "context"
+
+type langKey struct{}
+
+// WithLang stores the language responses to the request of ctx are written in.
+func WithLang(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, langKey{}, lang)
+}
+
+// LangFromContext returns the language stored by WithLang, English by default.
+func LangFromContext(ctx context.Context) string {
+	if lang, ok := ctx.Value(langKey{}).(string); ok {
+		return lang
+	}
+	return LangEN
+}
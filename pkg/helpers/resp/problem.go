@@ -0,0 +1,55 @@
+package resp
+
+import (
+	"build-service-gin/common/utils"
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// problemTypePrefix prefixes the error code in the type of a Problem, e.g. urn:build-service-gin:error:1001.
+const problemTypePrefix = "urn:build-service-gin:error:"
+
+// Problem is an error response in the RFC 7807 format. Code is the error code of the legacy envelope, Errors lists
+// the fields that failed validation.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     int64        `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// Success is a successful response in FormatProblem, the data without the envelope.
+type Success struct {
+	Data   interface{} `json:"data"`
+	Paging *Paging     `json:"paging,omitempty"`
+}
+
+// ProblemType returns the type URI of the errors with code.
+func ProblemType(code int64) string {
+	return problemTypePrefix + strconv.FormatInt(code, 10)
+}
+
+// BuildProblem converts r, written with status, to problem details. The instance is the request ID of ctx.
+func BuildProblem(ctx context.Context, status int, r Resp) Problem {
+	p := Problem{
+		Type:   ProblemType(r.ErrorCode),
+		Title:  r.Message,
+		Status: status,
+		Code:   r.ErrorCode,
+		Errors: r.Errors,
+	}
+	if r.Description != r.Message {
+		p.Detail = r.Description
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(status)
+	}
+	if traceInfo := utils.GetRequestIdByContext(ctx); traceInfo != nil {
+		p.Instance = traceInfo.RequestID
+	}
+	return p
+}
@@ -0,0 +1,32 @@
+package resp
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JSON writes r with status in the format negotiated for the request, see FormatFromContext. In FormatProblem
+// errors become problem details and successes lose the envelope, otherwise r is written as is.
+func JSON(c *gin.Context, status int, r Resp) {
+	ctx := c.Request.Context()
+	if FormatFromContext(ctx) != FormatProblem {
+		c.JSON(status, r)
+		return
+	}
+
+	if status < http.StatusBadRequest {
+		c.JSON(status, Success{Data: r.Data, Paging: r.Paging})
+		return
+	}
+
+	// gin's JSON render always sends application/json, problem details have their own media type
+	body, err := json.Marshal(BuildProblem(ctx, status, r))
+	if err != nil {
+		_ = c.Error(err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.Data(status, MIMEProblemJSON+"; charset=utf-8", body)
+}
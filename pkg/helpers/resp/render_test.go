@@ -0,0 +1,113 @@
+package resp
+
+import (
+	"build-service-gin/common/utils"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of testdata")
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// checkGolden compares got, indented, with testdata/name.golden, -update rewrites the file.
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, got, "", "  "); err != nil {
+		t.Fatalf("body %s is not JSON: %v", got, err)
+	}
+	indented.WriteByte('\n')
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, indented.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(indented.Bytes(), want) {
+		t.Errorf("body of %s =\n%s\nwant\n%s", name, indented.Bytes(), want)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	invalid := BuildErrorResp(ErrHandleLimitInvalid, "limit - max=100", LangEN)
+	invalid.Errors = LocalizeFields([]FieldError{{Field: "limit", Rule: "max", Param: "100"}}, LangVI)
+	success := BuildSuccessResp(LangEN, map[string]interface{}{"profileID": "profile-1"})
+	success.Paging = &Paging{Offset: 20, Limit: 20, Total: 41}
+
+	tests := []struct {
+		name            string
+		format          string
+		status          int
+		r               Resp
+		wantContentType string
+	}{
+		{name: "envelope_error", format: FormatEnvelope, status: http.StatusBadRequest, r: invalid, wantContentType: "application/json; charset=utf-8"},
+		{name: "envelope_success", format: FormatEnvelope, status: http.StatusOK, r: success, wantContentType: "application/json; charset=utf-8"},
+		{name: "problem_error", format: FormatProblem, status: http.StatusBadRequest, r: invalid, wantContentType: "application/problem+json; charset=utf-8"},
+		{
+			name:            "problem_server_error",
+			format:          FormatProblem,
+			status:          http.StatusInternalServerError,
+			r:               BuildErrorResp(ErrSystem, "", LangVI),
+			wantContentType: "application/problem+json; charset=utf-8",
+		},
+		{
+			name:            "problem_untitled_error",
+			format:          FormatProblem,
+			status:          http.StatusServiceUnavailable,
+			r:               Resp{ErrorCode: ErrServiceNotReady},
+			wantContentType: "application/problem+json; charset=utf-8",
+		},
+		{name: "problem_success", format: FormatProblem, status: http.StatusOK, r: success, wantContentType: "application/json; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), utils.KeyTraceInfo, utils.TraceInfo{RequestID: "request-1"})
+			ctx = WithFormat(ctx, tt.format)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+
+			JSON(c, tt.status, tt.r)
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantContentType)
+			}
+			checkGolden(t, tt.name, w.Body.Bytes())
+		})
+	}
+}
+
+func TestFormatAndLangDefaults(t *testing.T) {
+	ctx := context.Background()
+	if got := FormatFromContext(ctx); got != FormatEnvelope {
+		t.Errorf("FormatFromContext() = %q, want %q", got, FormatEnvelope)
+	}
+	if got := LangFromContext(ctx); got != LangEN {
+		t.Errorf("LangFromContext() = %q, want %q", got, LangEN)
+	}
+	if got := LangFromContext(WithLang(ctx, LangVI)); got != LangVI {
+		t.Errorf("LangFromContext(WithLang(VI)) = %q, want %q", got, LangVI)
+	}
+}
@@ -3,24 +3,51 @@ package resp
 import "fmt"
 
 type Resp struct {
-	ErrorCode   int64       `json:"errorCode"`
-	Description string      `json:"description"`
-	Message     string      `json:"message"`
-	Data        interface{} `json:"data"`
-	Paging      *Paging     `json:"paging,omitempty"`
+	ErrorCode   int64        `json:"errorCode"`
+	Description string       `json:"description"`
+	Message     string       `json:"message"`
+	Data        interface{}  `json:"data"`
+	Paging      *Paging      `json:"paging,omitempty"`
+	Errors      []FieldError `json:"errors,omitempty"`
 }
 
+// CustomError is an error with the code of its message. Kind is its category, derived from the code when unset,
+// Cause is the error it wraps, Params fill the placeholders of the message and Fields are the invalid fields.
 type CustomError struct {
-	ErrorCode   int64  `json:"errorCode"`
-	Description string `json:"description"`
+	ErrorCode   int64                  `json:"errorCode"`
+	Description string                 `json:"description"`
+	Kind        error                  `json:"-"`
+	Cause       error                  `json:"-"`
+	Params      map[string]interface{} `json:"-"`
+	Fields      []FieldError           `json:"-"`
 }
 
 func (c *CustomError) Error() string {
 	return fmt.Sprintf("%v - %v", c.ErrorCode, c.Description)
 }
 
+// Category returns the category of the error, see NotFound and the others.
+func (c *CustomError) Category() error {
+	if c.Kind != nil {
+		return c.Kind
+	}
+	return codeCategory(c.ErrorCode)
+}
+
+func (c *CustomError) Unwrap() []error {
+	if c.Cause == nil {
+		return []error{c.Category()}
+	}
+	return []error{c.Category(), c.Cause}
+}
+
 func BuildErrorResp(errCode int64, description, lang string) Resp {
-	err := GetMappingError(errCode, lang)
+	return BuildErrorRespParams(errCode, description, lang, nil)
+}
+
+// BuildErrorRespParams is BuildErrorResp with the {name} placeholders of the message replaced by params.
+func BuildErrorRespParams(errCode int64, description, lang string, params map[string]interface{}) Resp {
+	err := GetMappingErrorParams(errCode, lang, params)
 	if description == "" {
 		description = err.Message
 	}
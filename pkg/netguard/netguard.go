@@ -0,0 +1,88 @@
+// Package netguard keeps the requests sent to addresses chosen by clients, like webhook URLs, away from the
+// internal network of the service.
+package netguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"syscall"
+)
+
+var (
+	ErrInvalidURL       = errors.New("netguard: invalid url")
+	ErrForbiddenAddress = errors.New("netguard: address not allowed")
+)
+
+// reservedPrefixes are the ranges that are not reachable on the internet and that the netip predicates don't
+// cover.
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+}
+
+// IsPublic tells whether addr is a unicast address of the internet: loopback, link-local, private, multicast and
+// reserved addresses are not.
+func IsPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL checks rawURL is an http or https URL whose host only resolves to public addresses.
+func CheckURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidURL
+	}
+
+	addrs, err := resolve(ctx, u.Hostname())
+	if err != nil {
+		return fmt.Errorf("%w: resolve %s: %v", ErrInvalidURL, u.Hostname(), err)
+	}
+	for _, addr := range addrs {
+		if !IsPublic(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrForbiddenAddress, u.Hostname(), addr)
+		}
+	}
+	return nil
+}
+
+// Control is a net.Dialer Control rejecting the connections to addresses that are not public. It checks the
+// address actually dialed, so a host resolving to another address after CheckURL is still rejected.
+func Control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+	}
+	if !IsPublic(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+func resolve(ctx context.Context, host string) ([]netip.Addr, error) {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return []netip.Addr{addr}, nil
+	}
+
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	return ips, nil
+}
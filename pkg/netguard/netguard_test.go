@@ -0,0 +1,67 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestIsPublic(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "93.184.216.34", want: true},
+		{addr: "2606:2800:220:1:248:1893:25c8:1946", want: true},
+		{addr: "127.0.0.1"},
+		{addr: "::1"},
+		{addr: "10.1.2.3"},
+		{addr: "172.16.0.1"},
+		{addr: "192.168.1.1"},
+		{addr: "169.254.169.254"},
+		{addr: "fe80::1"},
+		{addr: "fd00::1"},
+		{addr: "100.64.0.1"},
+		{addr: "0.0.0.0"},
+		{addr: "224.0.0.1"},
+		{addr: "::ffff:127.0.0.1"},
+		{addr: "::ffff:10.0.0.1"},
+	}
+	for _, tt := range tests {
+		if got := IsPublic(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("IsPublic(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr error
+	}{
+		{url: "https://93.184.216.34/hook"},
+		{url: "http://[2606:2800:220:1:248:1893:25c8:1946]:8080/hook"},
+		{url: "https://127.0.0.1/hook", wantErr: ErrForbiddenAddress},
+		{url: "http://169.254.169.254/latest/meta-data", wantErr: ErrForbiddenAddress},
+		{url: "http://[::1]:9090/metrics", wantErr: ErrForbiddenAddress},
+		{url: "http://localhost/hook", wantErr: ErrForbiddenAddress},
+		{url: "ftp://93.184.216.34/hook", wantErr: ErrInvalidURL},
+		{url: "/hook", wantErr: ErrInvalidURL},
+	}
+	for _, tt := range tests {
+		err := CheckURL(context.Background(), tt.url)
+		if tt.wantErr == nil && err != nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+			t.Errorf("CheckURL(%s) error = %v, want %v", tt.url, err, tt.wantErr)
+		}
+	}
+}
+
+func TestControl(t *testing.T) {
+	if err := Control("tcp", "93.184.216.34:443", nil); err != nil {
+		t.Errorf("Control() public error = %v", err)
+	}
+	if err := Control("tcp", "10.0.0.1:443", nil); !errors.Is(err, ErrForbiddenAddress) {
+		t.Errorf("Control() private error = %v, want %v", err, ErrForbiddenAddress)
+	}
+}
@@ -0,0 +1,213 @@
+package openapi
+
+import (
+	"build-service-gin/common/custom/binding"
+	"build-service-gin/pkg/helpers/resp"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	securityBearer = "bearerAuth"
+	responseError  = "Error"
+	schemaCode     = "ErrorCode"
+)
+
+// Operation describes the route Method Path of the gin engine, Path written like it is registered, e.g.
+// /v1/feature-flags/:key.
+type Operation struct {
+	Method  string
+	Path    string
+	Summary string
+	Tags    []string
+	// Scopes are the scopes the caller needs, nil for a route without authentication.
+	Scopes []string
+	// Request is the model the handler binds. Its uri fields are path parameters, its form fields the query of GET
+	// and DELETE routes and the model itself the JSON body of the others.
+	Request interface{}
+	// Headers are the headers the route reads beside the common ones.
+	Headers []Parameter
+	// Response is the data of a successful response, nil when there is none.
+	Response interface{}
+	// Stream is the media type of a successful response streamed rather than sent in the envelope, e.g.
+	// text/event-stream.
+	Stream string
+}
+
+var ginParam = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// PathOf converts the gin path of a route to an OpenAPI path, e.g. /v1/feature-flags/{key}.
+func PathOf(ginPath string) string {
+	return ginParam.ReplaceAllString(ginPath, "{$1}")
+}
+
+// Build returns the document of operations. Request and response schemas are reflected from the Go types, validate
+// rules become constraints, see RegisterRule, and the error codes of resp become the ErrorCode enum.
+func Build(info Info, operations []Operation) *Document {
+	r := newReflector()
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]PathItem),
+	}
+
+	r.schema(reflect.TypeOf(resp.Resp{}))
+	r.schema(reflect.TypeOf(resp.Problem{}))
+	r.schemas[schemaCode] = errorCodeSchema()
+	r.schemas[TypeName(reflect.TypeOf(resp.Resp{}))].Properties["errorCode"] = Ref(schemaCode)
+	r.schemas[TypeName(reflect.TypeOf(resp.Problem{}))].Properties["code"] = Ref(schemaCode)
+
+	for _, op := range operations {
+		p := PathOf(op.Path)
+		if doc.Paths[p] == nil {
+			doc.Paths[p] = PathItem{}
+		}
+		doc.Paths[p][strings.ToLower(op.Method)] = r.operation(op)
+	}
+
+	doc.Components = Components{
+		Schemas: r.schemas,
+		Responses: map[string]Response{
+			responseError: {
+				Description: "Error. The legacy envelope by default, RFC 7807 problem details when the client accepts " +
+					resp.MIMEProblemJSON + ".",
+				Content: map[string]MediaType{
+					"application/json":   {Schema: Ref(TypeName(reflect.TypeOf(resp.Resp{})))},
+					resp.MIMEProblemJSON: {Schema: Ref(TypeName(reflect.TypeOf(resp.Problem{})))},
+				},
+			},
+		},
+		SecuritySchemes: map[string]SecurityScheme{
+			securityBearer: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		},
+	}
+	return doc
+}
+
+func (r *reflector) operation(op Operation) *OperationObject {
+	o := &OperationObject{
+		OperationID: operationID(op.Method, op.Path),
+		Summary:     op.Summary,
+		Tags:        op.Tags,
+		Parameters:  append([]Parameter{}, op.Headers...),
+		Responses: map[string]Response{
+			"200":     {Description: "Success", Content: map[string]MediaType{"application/json": {Schema: r.success(op.Response)}}},
+			"default": {Ref: "#/components/responses/" + responseError},
+		},
+	}
+	if op.Stream != "" {
+		o.Responses["200"] = Response{Description: "Success, streamed", Content: map[string]MediaType{op.Stream: {Schema: &Schema{Type: "string"}}}}
+	}
+	if op.Scopes != nil {
+		o.Security = []map[string][]string{{securityBearer: {}}}
+		if len(op.Scopes) > 0 {
+			o.Description = "Requires the scopes: " + strings.Join(op.Scopes, ", ") + "."
+		}
+	}
+
+	pathParams := map[string]bool{}
+	if op.Request != nil {
+		t := reflect.TypeOf(op.Request)
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		for _, param := range r.parameters(t, "uri", "path") {
+			pathParams[param.Name] = true
+			o.Parameters = append(o.Parameters, param)
+		}
+		switch {
+		case op.Method == http.MethodGet || op.Method == http.MethodDelete:
+			o.Parameters = append(o.Parameters, r.parameters(t, "form", "query")...)
+			if reflect.PointerTo(t).Implements(reflect.TypeOf((*binding.Paginated)(nil)).Elem()) {
+				paginate(o.Parameters)
+			}
+		case hasBody(t):
+			o.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{"application/json": {Schema: r.schema(t)}},
+			}
+		}
+	}
+	for _, match := range ginParam.FindAllStringSubmatch(op.Path, -1) {
+		if !pathParams[match[1]] {
+			o.Parameters = append(o.Parameters, Parameter{Name: match[1], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+	}
+	return o
+}
+
+// hasBody reports whether struct t has a field bound from the body, one without a uri tag.
+func hasBody(t reflect.Type) bool {
+	for _, field := range fields(t, "json") {
+		if field.Tag.Get("uri") == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// success is the schema of the envelope of a successful response carrying data.
+func (r *reflector) success(data interface{}) *Schema {
+	envelope := Ref(TypeName(reflect.TypeOf(resp.Resp{})))
+	if data == nil {
+		return envelope
+	}
+	return &Schema{AllOf: []*Schema{envelope, {
+		Type:       "object",
+		Properties: map[string]*Schema{"data": r.schema(reflect.TypeOf(data))},
+	}}}
+}
+
+// parameters returns a parameter in in for every field of struct t with a tag.
+func (r *reflector) parameters(t reflect.Type, tag, in string) []Parameter {
+	var params []Parameter
+	for _, field := range fields(t, tag) {
+		schema := r.schema(field.Type)
+		required := r.applyRules(schema, field.Tag.Get("validate"))
+		params = append(params, Parameter{
+			Name:     field.name,
+			In:       in,
+			Required: required || in == "path",
+			Schema:   schema,
+		})
+	}
+	return params
+}
+
+// paginate documents the limits binding applies to the limit parameter.
+func paginate(params []Parameter) {
+	for _, param := range params {
+		if param.Name != "limit" || param.In != "query" {
+			continue
+		}
+		minLimit, maxLimit := float64(1), float64(binding.MaxLimit)
+		param.Schema.Default = binding.DefaultLimit
+		param.Schema.Minimum = &minLimit
+		param.Schema.Maximum = &maxLimit
+	}
+}
+
+// errorCodeSchema lists every error code with its English message.
+func errorCodeSchema() *Schema {
+	codes := resp.Codes()
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+
+	s := &Schema{Type: "integer", Format: "int64"}
+	lines := make([]string, 0, len(codes))
+	for _, code := range codes {
+		s.Enum = append(s.Enum, code)
+		lines = append(lines, fmt.Sprintf("* %d: %s", code, resp.GetMappingError(code, resp.LangEN).Message))
+	}
+	s.Description = "Error codes:\n" + strings.Join(lines, "\n")
+	return s
+}
+
+// operationID derives a unique id from the route, e.g. get_build-service-gin_api-admin_v1_feature-flags_key.
+func operationID(method, ginPath string) string {
+	id := strings.Trim(ginParam.ReplaceAllString(ginPath, "$1"), "/")
+	return strings.ToLower(method) + "_" + strings.ReplaceAll(id, "/", "_")
+}
@@ -0,0 +1,96 @@
+package openapi
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	routeOpsMu sync.RWMutex
+	routeOps   = make(map[string]Operation)
+)
+
+// Handle registers handlers for the route method relativePath of group, like group.Handle, and op as the operation
+// documenting it. The Method and Path of op are filled in from the route.
+func Handle(group *gin.RouterGroup, method, relativePath string, op Operation, handlers ...gin.HandlerFunc) gin.IRoutes {
+	op.Method, op.Path = method, joinPaths(group.BasePath(), relativePath)
+
+	routeOpsMu.Lock()
+	routeOps[routeKey(op.Method, op.Path)] = op
+	routeOpsMu.Unlock()
+
+	return group.Handle(method, relativePath, handlers...)
+}
+
+// Operations returns the operation of every route, the one registered by Handle or, for a route registered without
+// it, one with only the method and the path. Routes whose path starts with one of ignore are left out.
+func Operations(routes gin.RoutesInfo, ignore ...string) []Operation {
+	routeOpsMu.RLock()
+	defer routeOpsMu.RUnlock()
+
+	operations := make([]Operation, 0, len(routes))
+	for _, route := range routes {
+		if ignored(route.Path, ignore) {
+			continue
+		}
+		op, ok := routeOps[routeKey(route.Method, route.Path)]
+		if !ok {
+			op = Operation{Method: route.Method, Path: route.Path}
+		}
+		operations = append(operations, op)
+	}
+	return operations
+}
+
+// Check reports the routes registered without Handle, documented by their method and path only. Routes whose path
+// starts with one of ignore are not checked. Run it from a test once every route is registered.
+func Check(routes gin.RoutesInfo, ignore ...string) error {
+	routeOpsMu.RLock()
+	defer routeOpsMu.RUnlock()
+
+	var problems []string
+	for _, route := range routes {
+		if ignored(route.Path, ignore) {
+			continue
+		}
+		if _, ok := routeOps[routeKey(route.Method, route.Path)]; !ok {
+			problems = append(problems, fmt.Sprintf("route %s is not registered with openapi.Handle", routeKey(route.Method, route.Path)))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+
+	sort.Strings(problems)
+	return fmt.Errorf("openapi: %s", strings.Join(problems, "; "))
+}
+
+func routeKey(method, path string) string {
+	return method + " " + path
+}
+
+// joinPaths joins a relative path to the base path of a group like gin does.
+func joinPaths(basePath, relativePath string) string {
+	if relativePath == "" {
+		return basePath
+	}
+	joined := path.Join(basePath, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(joined, "/") {
+		return joined + "/"
+	}
+	return joined
+}
+
+func ignored(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package openapi
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type testFlagRequest struct {
+	Key     string `uri:"key" validate:"required"`
+	Enabled bool   `json:"enabled"`
+}
+
+func testHandler(c *gin.Context) {}
+
+// testEngine registers documented and undocumented routes under /check.
+func testEngine() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	group := engine.Group("/check/v1")
+
+	Handle(group, http.MethodGet, "", Operation{Summary: "List the flags", Scopes: []string{}}, testHandler)
+	Handle(group.Group("/flags"), http.MethodPut, "/:key", Operation{Summary: "Update a flag", Request: testFlagRequest{}}, testHandler)
+	group.DELETE("/flags/:key", testHandler)
+	group.GET("/internal", testHandler)
+	return engine
+}
+
+func TestHandleRegistersTheRoute(t *testing.T) {
+	engine := testEngine()
+
+	routes := map[string]bool{}
+	for _, route := range engine.Routes() {
+		routes[route.Method+" "+route.Path] = true
+	}
+	for _, want := range []string{"GET /check/v1", "PUT /check/v1/flags/:key"} {
+		if !routes[want] {
+			t.Errorf("route %s not registered, routes %v", want, routes)
+		}
+	}
+}
+
+func TestOperations(t *testing.T) {
+	operations := Operations(testEngine().Routes(), "/check/v1/internal")
+
+	byRoute := map[string]Operation{}
+	for _, op := range operations {
+		byRoute[op.Method+" "+op.Path] = op
+	}
+	tests := []struct {
+		route       string
+		wantSummary string
+	}{
+		{route: "GET /check/v1", wantSummary: "List the flags"},
+		{route: "PUT /check/v1/flags/:key", wantSummary: "Update a flag"},
+		{route: "DELETE /check/v1/flags/:key"},
+	}
+	if len(operations) != len(tests) {
+		t.Errorf("Operations() = %d operations, want %d", len(operations), len(tests))
+	}
+	for _, tt := range tests {
+		op, ok := byRoute[tt.route]
+		if !ok {
+			t.Errorf("no operation for %s", tt.route)
+			continue
+		}
+		if op.Summary != tt.wantSummary {
+			t.Errorf("%s summary = %q, want %q", tt.route, op.Summary, tt.wantSummary)
+		}
+	}
+
+	doc := Build(Info{Title: "check"}, operations)
+	put := doc.Paths["/check/v1/flags/{key}"]["put"]
+	if put == nil || put.RequestBody == nil || len(put.Parameters) != 1 || put.Parameters[0].Name != "key" {
+		t.Errorf("put operation = %+v, want the key path parameter and a body", put)
+	}
+	if doc.Paths["/check/v1/flags/{key}"]["delete"] == nil {
+		t.Error("the route without an operation is not documented")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		ignore  []string
+		wantErr []string
+	}{
+		{
+			name:    "routes without an operation",
+			wantErr: []string{"route DELETE /check/v1/flags/:key is not registered", "route GET /check/v1/internal is not registered"},
+		},
+		{name: "ignored", ignore: []string{"/check/v1/flags", "/check/v1/internal"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(testEngine().Routes(), tt.ignore...)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("Check() = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Check() = nil, want %v", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Check() = %v, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestJoinPaths(t *testing.T) {
+	tests := []struct {
+		base, relative, want string
+	}{
+		{base: "/v1/health", relative: "", want: "/v1/health"},
+		{base: "/v1", relative: "/flags", want: "/v1/flags"},
+		{base: "/v1", relative: "flags/", want: "/v1/flags/"},
+		{base: "/", relative: "/:key", want: "/:key"},
+	}
+	for _, tt := range tests {
+		if got := joinPaths(tt.base, tt.relative); got != tt.want {
+			t.Errorf("joinPaths(%q, %q) = %q, want %q", tt.base, tt.relative, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rule adds the constraints of a validate rule with param to s, the schema of the field carrying the rule.
+type Rule func(s *Schema, param string)
+
+var rules = map[string]Rule{
+	"min":         bound(minimum),
+	"gte":         bound(minimum),
+	"max":         bound(maximum),
+	"lte":         bound(maximum),
+	"len":         bound(func(s *Schema, n float64) { minimum(s, n); maximum(s, n) }),
+	"oneof":       oneOf,
+	"url":         Format("uri"),
+	"email":       Format("email"),
+	"date":        Format("date"),
+	"uuid":        Format("uuid"),
+	"iso4217":     Pattern("^[A-Z]{3}$"),
+	"hexadecimal": Pattern("^[0-9a-fA-F]+$"),
+}
+
+// RegisterRule makes the schemas of fields validated by the rule called name carry its constraints, call it before
+// Build. Rules without one only mark the field required when they are called required.
+func RegisterRule(name string, rule Rule) {
+	rules[name] = rule
+}
+
+// Format returns a rule setting the format of the schema.
+func Format(format string) Rule {
+	return func(s *Schema, _ string) {
+		s.Format = format
+	}
+}
+
+// Pattern returns a rule setting the pattern of the schema.
+func Pattern(pattern string) Rule {
+	return func(s *Schema, _ string) {
+		s.Pattern = pattern
+	}
+}
+
+// Enum returns a rule restricting the schema to values.
+func Enum(values ...string) Rule {
+	return func(s *Schema, _ string) {
+		s.Enum = enumOf(s, values)
+	}
+}
+
+// Describe returns a rule appending description to the one of the schema, for rules JSON Schema cannot express.
+func Describe(description string) Rule {
+	return func(s *Schema, param string) {
+		describe(s, strings.ReplaceAll(description, "{param}", param))
+	}
+}
+
+func describe(s *Schema, description string) {
+	if s.Description != "" {
+		description = s.Description + ". " + description
+	}
+	s.Description = description
+}
+
+func bound(set func(s *Schema, n float64)) Rule {
+	return func(s *Schema, param string) {
+		n, err := strconv.ParseFloat(param, 64)
+		if err == nil {
+			set(s, n)
+		}
+	}
+}
+
+// minimum sets the lower bound fitting the type of s, validator applies min to lengths of strings and slices.
+func minimum(s *Schema, n float64) {
+	switch s.Type {
+	case "string":
+		s.MinLength = intPtr(int(n))
+	case "array":
+		s.MinItems = intPtr(int(n))
+	case "integer", "number":
+		s.Minimum = &n
+	}
+}
+
+func maximum(s *Schema, n float64) {
+	switch s.Type {
+	case "string":
+		s.MaxLength = intPtr(int(n))
+	case "array":
+		s.MaxItems = intPtr(int(n))
+	case "integer", "number":
+		s.Maximum = &n
+	}
+}
+
+func oneOf(s *Schema, param string) {
+	s.Enum = enumOf(s, strings.Fields(param))
+}
+
+// enumOf converts values to the type of s.
+func enumOf(s *Schema, values []string) []interface{} {
+	enum := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		if s.Type == "integer" || s.Type == "number" {
+			if n, err := strconv.ParseFloat(value, 64); err == nil {
+				enum = append(enum, n)
+				continue
+			}
+		}
+		enum = append(enum, value)
+	}
+	return enum
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// reflector converts Go types to schemas, named structs become component schemas called <package>.<type>.
+type reflector struct {
+	schemas map[string]*Schema
+}
+
+func newReflector() *reflector {
+	return &reflector{schemas: make(map[string]*Schema)}
+}
+
+func (r *reflector) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() != reflect.Struct && (t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType)):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: r.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.object(t)
+		}
+		name := TypeName(t)
+		if _, ok := r.schemas[name]; !ok {
+			r.schemas[name] = &Schema{}
+			*r.schemas[name] = *r.object(t)
+		}
+		return Ref(name)
+	}
+	return &Schema{}
+}
+
+// TypeName is the name of the component schema of the named type t, e.g. models.OrderRequest.
+func TypeName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return path.Base(t.PkgPath()) + "." + t.Name()
+}
+
+// object returns the schema of the JSON object of struct t, embedded structs are flattened like encoding/json does.
+func (r *reflector) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, field := range fields(t, "json") {
+		property := r.schema(field.Type)
+		if r.applyRules(property, field.Tag.Get("validate")) {
+			s.Required = append(s.Required, field.name)
+		}
+		s.Properties[field.name] = property
+	}
+	return s
+}
+
+type namedField struct {
+	reflect.StructField
+	name string
+}
+
+// fields lists the exported fields of struct t named by tag, fields named "-" are left out. Without a tag json fields
+// keep their Go name and fields of other tags are left out.
+func fields(t reflect.Type, tag string) []namedField {
+	var out []namedField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+
+		embedded := field.Type
+		if embedded.Kind() == reflect.Ptr {
+			embedded = embedded.Elem()
+		}
+		if field.Anonymous && name == "" && embedded.Kind() == reflect.Struct {
+			out = append(out, fields(embedded, tag)...)
+			continue
+		}
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			if tag != "json" {
+				continue
+			}
+			name = field.Name
+		}
+		out = append(out, namedField{StructField: field, name: name})
+	}
+	return out
+}
+
+// applyRules adds the constraints of the validate tag to s and reports whether the field is required. Rules after
+// dive apply to the items of s.
+func (r *reflector) applyRules(s *Schema, tag string) bool {
+	required, target := false, s
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "", "omitempty":
+			continue
+		case "dive":
+			if target.Items == nil {
+				return required
+			}
+			target = target.Items
+			continue
+		case "required":
+			if target == s {
+				required = true
+			}
+			continue
+		}
+		if apply, ok := rules[name]; ok && target.Ref == "" {
+			apply(target, param)
+		}
+	}
+	return required
+}
@@ -0,0 +1,71 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+type Codec interface {
+	ContentType() string
+	Marshal(v interface{}) ([]byte, error)
+	// Unmarshal decodes data into v. When strict, unknown fields are rejected.
+	Unmarshal(data []byte, v interface{}, strict bool) error
+}
+
+type JSONCodec struct{}
+
+func (JSONCodec) ContentType() string {
+	return ContentTypeJSON
+}
+
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Unmarshal(data []byte, v interface{}, strict bool) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	return decoder.Decode(v)
+}
+
+// ProtobufCodec encodes the events whose registered type is a proto.Message. It is not registered by default, the
+// events of the service are plain structs: register it on a registry of proto types.
+type ProtobufCodec struct{}
+
+func (ProtobufCodec) ContentType() string {
+	return ContentTypeProtobuf
+}
+
+func (ProtobufCodec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("protobuf codec: %T is not a proto.Message", v)
+	}
+	return proto.Marshal(m)
+}
+
+func (ProtobufCodec) Unmarshal(data []byte, v interface{}, strict bool) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("protobuf codec: %T is not a proto.Message", v)
+	}
+
+	if err := proto.Unmarshal(data, m); err != nil {
+		return err
+	}
+
+	if strict && len(m.ProtoReflect().GetUnknown()) > 0 {
+		return fmt.Errorf("protobuf codec: %T has unknown fields", v)
+	}
+	return nil
+}
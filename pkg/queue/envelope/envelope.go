@@ -0,0 +1,30 @@
+package envelope
+
+import (
+	"build-service-gin/common/utils"
+	"encoding/json"
+	"time"
+)
+
+// Envelope wraps every event published on Kafka. Data is encoded with the codec named by ContentType;
+// JSON payloads are embedded as-is, binary payloads as a base64 string.
+type Envelope struct {
+	ID          string           `json:"id"`
+	Type        string           `json:"type"`
+	Version     int              `json:"version"`
+	ProducedAt  time.Time        `json:"producedAt"`
+	Trace       *utils.TraceInfo `json:"trace,omitempty"`
+	ContentType string           `json:"contentType"`
+	Data        json.RawMessage  `json:"data"`
+}
+
+// legacyEnvelope is the CallbackMessage shape produced before envelopes were versioned.
+type legacyEnvelope struct {
+	Envelope
+	EventType string `json:"eventType"`
+}
+
+// Key returns the registry key "type@version" of the envelope.
+func (e *Envelope) Key() string {
+	return key(e.Type, e.Version)
+}
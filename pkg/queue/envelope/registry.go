@@ -0,0 +1,342 @@
+package envelope
+
+import (
+	"build-service-gin/common/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// LegacyVersion is the version given to messages in the unversioned {eventType, data} shape.
+const LegacyVersion = 0
+
+var (
+	ErrInvalidEnvelope     = errors.New("envelope: invalid envelope")
+	ErrUnknownEventType    = errors.New("envelope: unknown event type")
+	ErrUnsupportedVersion  = errors.New("envelope: unsupported event version")
+	ErrMissingUpcaster     = errors.New("envelope: missing upcaster")
+	ErrUnknownContentType  = errors.New("envelope: unknown content type")
+	ErrEventTypeMismatch   = errors.New("envelope: event type mismatch")
+	ErrDestinationMismatch = errors.New("envelope: destination type mismatch")
+)
+
+// Upcaster converts the JSON payload of version N of an event into version N+1.
+type Upcaster func(data json.RawMessage) (json.RawMessage, error)
+
+// DecodeError carries the event key with the underlying decode failure.
+type DecodeError struct {
+	Key string
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("envelope: decode %s: %v", e.Key, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+type Registry struct {
+	mu        sync.RWMutex
+	types     map[string]reflect.Type
+	latest    map[string]int
+	upcasters map[string]Upcaster
+	codecs    map[string]Codec
+	legacy    map[string]string
+	strict    bool
+}
+
+type Option func(r *Registry)
+
+// WithStrict toggles rejection of unknown payload fields. Registries are lenient by default: the consumers commit the
+// messages they fail to decode, so a producer adding a field must not make them drop its events.
+func WithStrict(strict bool) Option {
+	return func(r *Registry) {
+		r.strict = strict
+	}
+}
+
+func NewRegistry(opts ...Option) *Registry {
+	r := &Registry{
+		types:     make(map[string]reflect.Type),
+		latest:    make(map[string]int),
+		upcasters: make(map[string]Upcaster),
+		codecs:    make(map[string]Codec),
+		legacy:    make(map[string]string),
+	}
+	r.RegisterCodec(JSONCodec{})
+
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func key(eventType string, version int) string {
+	return fmt.Sprintf("%s@%d", eventType, version)
+}
+
+// Register maps eventType@version to the Go type of prototype.
+func (r *Registry) Register(eventType string, version int, prototype interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := reflect.TypeOf(prototype)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	r.types[key(eventType, version)] = t
+
+	if latest, ok := r.latest[eventType]; !ok || version > latest {
+		r.latest[eventType] = version
+	}
+}
+
+// RegisterUpcaster registers the conversion from eventType@fromVersion to eventType@fromVersion+1.
+func (r *Registry) RegisterUpcaster(eventType string, fromVersion int, upcaster Upcaster) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.upcasters[key(eventType, fromVersion)] = upcaster
+}
+
+// RegisterLegacyType maps the eventType of legacy messages to the event type it was renamed to. Legacy messages
+// carrying another registered event type than the one expected are rejected, an eventType the registry does not know
+// is read as the expected type: the consumers only read the topic of their event, whatever the producers call it.
+func (r *Registry) RegisterLegacyType(legacyType, eventType string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.legacy[legacyType] = eventType
+}
+
+func (r *Registry) RegisterCodec(codec Codec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.codecs[codec.ContentType()] = codec
+}
+
+// LatestVersion returns the newest registered version of eventType.
+func (r *Registry) LatestVersion(eventType string) (int, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	version, ok := r.latest[eventType]
+	return version, ok
+}
+
+// NewEnvelope wraps data as the latest version of eventType, encoded with the codec of contentType
+// (JSON when empty). The trace info of ctx is carried along.
+func (r *Registry) NewEnvelope(ctx context.Context, eventType, contentType string, data interface{}) (*Envelope, error) {
+	version, ok := r.LatestVersion(eventType)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEventType, eventType)
+	}
+
+	if contentType == "" {
+		contentType = ContentTypeJSON
+	}
+
+	codec, err := r.codec(contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := codec.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("envelope: encode %s: %w", key(eventType, version), err)
+	}
+
+	if contentType != ContentTypeJSON {
+		// binary payloads travel as a base64 JSON string
+		if payload, err = json.Marshal(payload); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Envelope{
+		ID:          utils.RandString(),
+		Type:        eventType,
+		Version:     version,
+		ProducedAt:  time.Now().UTC(),
+		Trace:       utils.GetRequestIdByContext(ctx),
+		ContentType: contentType,
+		Data:        payload,
+	}, nil
+}
+
+// Marshal builds a JSON envelope for data and returns its encoded form.
+func (r *Registry) Marshal(ctx context.Context, eventType string, data interface{}) ([]byte, error) {
+	env, err := r.NewEnvelope(ctx, eventType, ContentTypeJSON, data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(env)
+}
+
+// Parse reads the envelope of value. Messages in the legacy {eventType, data} shape are returned as
+// LegacyVersion of their eventType when the registry knows it, of expectedType otherwise.
+func (r *Registry) Parse(value []byte, expectedType string) (*Envelope, error) {
+	var raw legacyEnvelope
+	if err := json.Unmarshal(value, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+	}
+
+	env := raw.Envelope
+	if env.Type == "" {
+		env.Type = r.legacyType(raw.EventType)
+		if env.Type == "" {
+			env.Type = expectedType
+		}
+		if env.Type == "" {
+			return nil, fmt.Errorf("%w: missing type", ErrInvalidEnvelope)
+		}
+		env.Version = LegacyVersion
+		env.ContentType = ContentTypeJSON
+	} else if env.ID == "" {
+		return nil, fmt.Errorf("%w: missing id for %s", ErrInvalidEnvelope, env.Key())
+	}
+
+	if expectedType != "" && env.Type != expectedType {
+		return nil, fmt.Errorf("%w: got %s, expected %s", ErrEventTypeMismatch, env.Type, expectedType)
+	}
+
+	if env.ContentType == "" {
+		env.ContentType = ContentTypeJSON
+	}
+
+	if len(env.Data) == 0 {
+		return nil, fmt.Errorf("%w: missing data for %s", ErrInvalidEnvelope, env.Key())
+	}
+
+	return &env, nil
+}
+
+// Decode parses value, upcasts its payload to the latest registered version and decodes it into a new value
+// of the registered type. An empty expectedType accepts any registered event type.
+func (r *Registry) Decode(value []byte, expectedType string) (*Envelope, interface{}, error) {
+	env, err := r.Parse(value, expectedType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	t, err := r.upcast(env)
+	if err != nil {
+		return env, nil, err
+	}
+
+	dest := reflect.New(t).Interface()
+	if err = r.decodeData(env, dest); err != nil {
+		return env, nil, err
+	}
+
+	return env, dest, nil
+}
+
+// DecodeInto is Decode for callers that know the destination type; dest must be a pointer to the type
+// registered for the latest version of expectedType.
+func (r *Registry) DecodeInto(value []byte, expectedType string, dest interface{}) (*Envelope, error) {
+	env, err := r.Parse(value, expectedType)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := r.upcast(env)
+	if err != nil {
+		return env, err
+	}
+
+	if dt := reflect.TypeOf(dest); dt == nil || dt.Kind() != reflect.Ptr || dt.Elem() != t {
+		return env, &DecodeError{Key: env.Key(), Err: fmt.Errorf("%w: %T is not *%s", ErrDestinationMismatch, dest, t)}
+	}
+
+	if err = r.decodeData(env, dest); err != nil {
+		return env, err
+	}
+	return env, nil
+}
+
+// legacyType returns the event type of a legacy message with eventType, empty when the registry does not know it.
+func (r *Registry) legacyType(eventType string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if renamed, ok := r.legacy[eventType]; ok {
+		return renamed
+	}
+	if _, ok := r.latest[eventType]; ok {
+		return eventType
+	}
+	return ""
+}
+
+// upcast moves env to the latest version of its type and returns the Go type registered for it.
+func (r *Registry) upcast(env *Envelope) (reflect.Type, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	latest, ok := r.latest[env.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEventType, env.Type)
+	}
+
+	if env.Version > latest {
+		return nil, fmt.Errorf("%w: %s, latest is %d", ErrUnsupportedVersion, env.Key(), latest)
+	}
+
+	for env.Version < latest {
+		if env.ContentType != ContentTypeJSON {
+			return nil, fmt.Errorf("%w: %s cannot be upcast from %s", ErrUnsupportedVersion, env.Key(), env.ContentType)
+		}
+
+		upcaster, ok := r.upcasters[env.Key()]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrMissingUpcaster, env.Key())
+		}
+
+		data, err := upcaster(env.Data)
+		if err != nil {
+			return nil, &DecodeError{Key: env.Key(), Err: err}
+		}
+
+		env.Data = data
+		env.Version++
+	}
+
+	t, ok := r.types[env.Key()]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEventType, env.Key())
+	}
+	return t, nil
+}
+
+func (r *Registry) decodeData(env *Envelope, dest interface{}) error {
+	codec, err := r.codec(env.ContentType)
+	if err != nil {
+		return err
+	}
+
+	payload := []byte(env.Data)
+	if env.ContentType != ContentTypeJSON {
+		if err = json.Unmarshal(env.Data, &payload); err != nil {
+			return &DecodeError{Key: env.Key(), Err: err}
+		}
+	}
+
+	if err = codec.Unmarshal(payload, dest, r.strict); err != nil {
+		return &DecodeError{Key: env.Key(), Err: err}
+	}
+	return nil
+}
+
+func (r *Registry) codec(contentType string) (Codec, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	codec, ok := r.codecs[contentType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownContentType, contentType)
+	}
+	return codec, nil
+}
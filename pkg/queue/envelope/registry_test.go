@@ -0,0 +1,171 @@
+package envelope
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type refund struct {
+	RefundID  string  `json:"refundID"`
+	ProfileID string  `json:"profileID"`
+	Amount    float64 `json:"amount"`
+}
+
+func newTestRegistry(opts ...Option) *Registry {
+	r := NewRegistry(opts...)
+	r.Register("order.refund", 1, refund{})
+	r.RegisterUpcaster("order.refund", LegacyVersion, RenameFields(map[string]string{
+		"refund_id":  "refundID",
+		"profile_id": "profileID",
+	}))
+	r.RegisterLegacyType("REFUND", "order.refund")
+	r.Register("order.success", 1, struct{}{})
+	return r
+}
+
+func TestRegistryRoundTrip(t *testing.T) {
+	r := newTestRegistry()
+	value, err := r.Marshal(context.Background(), "order.refund", refund{RefundID: "r-1", ProfileID: "p-1", Amount: 1.5})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var got refund
+	env, err := r.DecodeInto(value, "order.refund", &got)
+	if err != nil {
+		t.Fatalf("DecodeInto() error = %v", err)
+	}
+	if env.ID == "" || env.Version != 1 || env.ContentType != ContentTypeJSON {
+		t.Errorf("envelope = %+v", env)
+	}
+	if got != (refund{RefundID: "r-1", ProfileID: "p-1", Amount: 1.5}) {
+		t.Errorf("DecodeInto() data = %+v", got)
+	}
+}
+
+func TestRegistryLegacy(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr error
+	}{
+		{name: "without event type", value: `{"data":{"refund_id":"r-1","profile_id":"p-1"}}`},
+		{name: "with the expected event type", value: `{"eventType":"order.refund","data":{"refund_id":"r-1","profile_id":"p-1"}}`},
+		{name: "with a legacy event type", value: `{"eventType":"REFUND","data":{"refund_id":"r-1","profile_id":"p-1"}}`},
+		{name: "with an event type the registry does not know", value: `{"eventType":"refund_success","data":{"refund_id":"r-1","profile_id":"p-1"}}`},
+		{name: "with another event type", value: `{"eventType":"order.success","data":{"refund_id":"r-1"}}`, wantErr: ErrEventTypeMismatch},
+		{name: "without data", value: `{"eventType":"order.refund"}`, wantErr: ErrInvalidEnvelope},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got refund
+			env, err := newTestRegistry().DecodeInto([]byte(tt.value), "order.refund", &got)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("DecodeInto() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeInto() error = %v", err)
+			}
+			if env.Key() != "order.refund@1" || got.RefundID != "r-1" || got.ProfileID != "p-1" {
+				t.Errorf("DecodeInto() = %s, %+v", env.Key(), got)
+			}
+		})
+	}
+}
+
+func TestRegistryUnknownFields(t *testing.T) {
+	value := `{"id":"1","type":"order.refund","version":1,"data":{"refundID":"r-1","reason":"added later"}}`
+
+	var got refund
+	if _, err := newTestRegistry().DecodeInto([]byte(value), "order.refund", &got); err != nil {
+		t.Errorf("lenient DecodeInto() error = %v", err)
+	}
+
+	var decodeErr *DecodeError
+	if _, err := newTestRegistry(WithStrict(true)).DecodeInto([]byte(value), "order.refund", &got); !errors.As(err, &decodeErr) {
+		t.Errorf("strict DecodeInto() error = %v, want a DecodeError", err)
+	}
+}
+
+func TestRegistryDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		dest    interface{}
+		wantErr error
+	}{
+		{name: "newer version", value: `{"id":"1","type":"order.refund","version":2,"data":{}}`, dest: &refund{}, wantErr: ErrUnsupportedVersion},
+		{name: "unknown type", value: `{"id":"1","type":"order.other","version":1,"data":{}}`, dest: &refund{}, wantErr: ErrEventTypeMismatch},
+		{name: "missing id", value: `{"type":"order.refund","version":1,"data":{}}`, dest: &refund{}, wantErr: ErrInvalidEnvelope},
+		{name: "unknown content type", value: `{"id":"1","type":"order.refund","version":1,"contentType":"text/plain","data":"x"}`, dest: &refund{}, wantErr: ErrUnknownContentType},
+		{name: "other destination", value: `{"id":"1","type":"order.refund","version":1,"data":{}}`, dest: &struct{}{}, wantErr: ErrDestinationMismatch},
+		{name: "not json", value: `refund`, dest: &refund{}, wantErr: ErrInvalidEnvelope},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newTestRegistry().DecodeInto([]byte(tt.value), "order.refund", tt.dest); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DecodeInto() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegistryProtobuf(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterCodec(ProtobufCodec{})
+	r.Register("profile.renamed", 1, &wrapperspb.StringValue{})
+
+	env, err := r.NewEnvelope(context.Background(), "profile.renamed", ContentTypeProtobuf, wrapperspb.String("p-1"))
+	if err != nil {
+		t.Fatalf("NewEnvelope() error = %v", err)
+	}
+	value, err := json.Marshal(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got wrapperspb.StringValue
+	if _, err = r.DecodeInto(value, "profile.renamed", &got); err != nil {
+		t.Fatalf("DecodeInto() error = %v", err)
+	}
+	if got.GetValue() != "p-1" {
+		t.Errorf("DecodeInto() data = %q, want p-1", got.GetValue())
+	}
+
+	if _, err = r.NewEnvelope(context.Background(), "profile.renamed", ContentTypeProtobuf, refund{}); err == nil {
+		t.Error("NewEnvelope() of a plain struct succeeded, want an error")
+	}
+}
+
+func TestRegistryMissingUpcaster(t *testing.T) {
+	r := NewRegistry()
+	r.Register("balance.changed", 2, refund{})
+
+	_, _, err := r.Decode([]byte(`{"id":"1","type":"balance.changed","version":1,"data":{}}`), "")
+	if !errors.Is(err, ErrMissingUpcaster) {
+		t.Fatalf("Decode() error = %v, want %v", err, ErrMissingUpcaster)
+	}
+}
+
+func TestRenameFields(t *testing.T) {
+	upcast := RenameFields(map[string]string{"profile_id": "profileID", "profileId": "profileID"})
+	data, err := upcast(json.RawMessage(`{"profile_id":"old","profileID":"new"}`))
+	if err != nil {
+		t.Fatalf("upcast() error = %v", err)
+	}
+
+	var got map[string]string
+	if err = json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got["profileID"] != "new" {
+		t.Errorf("upcast() = %s, want the target to win", data)
+	}
+}
@@ -0,0 +1,44 @@
+package envelope
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// RenameFields returns an upcaster that renames top-level payload keys, e.g. to converge the
+// profile_id/profileId/profileID spellings of older producers on a single name.
+// When both an alias and its target are present the target wins.
+func RenameFields(aliases map[string]string) Upcaster {
+	return func(data json.RawMessage) (json.RawMessage, error) {
+		var payload map[string]json.RawMessage
+		if err := json.Unmarshal(data, &payload); err != nil {
+			return nil, fmt.Errorf("rename fields: %w", err)
+		}
+
+		for from, to := range aliases {
+			value, ok := payload[from]
+			if !ok {
+				continue
+			}
+			delete(payload, from)
+			if _, exists := payload[to]; !exists {
+				payload[to] = value
+			}
+		}
+
+		return json.Marshal(payload)
+	}
+}
+
+// Chain runs upcasters in order; it is useful when one version bump needs several rewrites.
+func Chain(upcasters ...Upcaster) Upcaster {
+	return func(data json.RawMessage) (json.RawMessage, error) {
+		var err error
+		for _, upcaster := range upcasters {
+			if data, err = upcaster(data); err != nil {
+				return nil, err
+			}
+		}
+		return data, nil
+	}
+}
@@ -4,14 +4,27 @@ import (
 	"build-service-gin/common/logger"
 	"build-service-gin/common/utils"
 	"build-service-gin/config"
+	"build-service-gin/pkg/queue/envelope"
 	"context"
 	"encoding/json"
+	"fmt"
+	"strconv"
 
 	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 )
 
+const (
+	HeaderEventType    = "event_type"
+	HeaderEventVersion = "event_version"
+	HeaderContentType  = "content_type"
+)
+
 type ProducerInterface interface {
 	Publish(ctx context.Context, key, value interface{}) error
+	PublishWithTopic(ctx context.Context, topic string, key, value interface{}) error
+	PublishEnvelope(ctx context.Context, key interface{}, env *envelope.Envelope) error
+	PublishEnvelopeWithTopic(ctx context.Context, topic string, key interface{}, env *envelope.Envelope) error
+	Close(ctx context.Context) error
 }
 
 type Producer struct {
@@ -32,12 +45,28 @@ func NewProducer(cfg config.KafkaConfig, topic string) *Producer {
 	}
 
 	log.Info().Msgf("init kafka producer success : TOPIC = %v", topic)
+	go handleDeliveryReports(pr)
 	return &Producer{
 		pr:    pr,
 		topic: topic,
 	}
 }
 
+// handleDeliveryReports drains the events channel so Produce never blocks on unread delivery reports.
+func handleDeliveryReports(pr *kafka.Producer) {
+	log := logger.GetLogger()
+	for ev := range pr.Events() {
+		switch e := ev.(type) {
+		case *kafka.Message:
+			if e.TopicPartition.Error != nil {
+				log.Error().Err(e.TopicPartition.Error).Str("topic", *e.TopicPartition.Topic).Msg("kafka delivery failed")
+			}
+		case kafka.Error:
+			log.Warn().Err(e).Msg("kafka producer error")
+		}
+	}
+}
+
 func marshal(val interface{}) ([]byte, error) {
 	switch v := val.(type) {
 	case string:
@@ -136,6 +165,59 @@ func (s *Producer) PublishWithTopic(ctx context.Context, topic string, key, valu
 	return nil
 }
 
+// PublishEnvelope publishes env with its type, version and content type copied into the headers,
+// so consumers can route messages without decoding the value.
+func (s *Producer) PublishEnvelope(ctx context.Context, key interface{}, env *envelope.Envelope) error {
+	return s.PublishEnvelopeWithTopic(ctx, s.topic, key, env)
+}
+
+func (s *Producer) PublishEnvelopeWithTopic(ctx context.Context, topic string, key interface{}, env *envelope.Envelope) error {
+	keyData, err := marshal(key)
+	if err != nil {
+		return err
+	}
+	valueData, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+
+	header, err := marshal(utils.GetRequestIdByContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	msg := &kafka.Message{
+		Key:   keyData,
+		Value: valueData,
+		Headers: []kafka.Header{
+			{Key: utils.KeyTraceInfo, Value: header},
+			{Key: HeaderEventType, Value: []byte(env.Type)},
+			{Key: HeaderEventVersion, Value: []byte(strconv.Itoa(env.Version))},
+			{Key: HeaderContentType, Value: []byte(env.ContentType)},
+		},
+		TopicPartition: kafka.TopicPartition{
+			Topic:     &topic,
+			Partition: kafka.PartitionAny,
+		},
+	}
+	return s.pr.Produce(msg, nil)
+}
+
 func (s *Producer) GetTopicName() string {
 	return s.topic
 }
+
+// Close waits until ctx is done for the buffered messages to be delivered, then closes the producer. The messages
+// still buffered are lost, their number is returned in the error.
+func (s *Producer) Close(ctx context.Context) error {
+	for s.pr.Len() > 0 && ctx.Err() == nil {
+		s.pr.Flush(100)
+	}
+
+	undelivered := s.pr.Len()
+	s.pr.Close()
+	if undelivered > 0 {
+		return fmt.Errorf("kafka producer closed with %d undelivered messages", undelivered)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"build-service-gin/common/logger"
+	"context"
+	"sync"
+	"time"
+)
+
+// fallbackCoolDown is how long the secondary limiter is used before the primary is tried again.
+const fallbackCoolDown = 5 * time.Second
+
+// FallbackLimiter counts on the primary limiter and switches to the secondary while the primary is failing.
+type FallbackLimiter struct {
+	primary   Limiter
+	secondary Limiter
+
+	mu            sync.Mutex
+	degradedUntil time.Time
+	now           func() time.Time
+}
+
+func NewFallbackLimiter(primary, secondary Limiter) *FallbackLimiter {
+	return &FallbackLimiter{
+		primary:   primary,
+		secondary: secondary,
+		now:       time.Now,
+	}
+}
+
+func (l *FallbackLimiter) Allow(ctx context.Context, key string, rule Rule) (*Result, error) {
+	if l.degraded() {
+		return l.secondary.Allow(ctx, key, rule)
+	}
+
+	rs, err := l.primary.Allow(ctx, key, rule)
+	if err == nil {
+		return rs, nil
+	}
+
+	l.mu.Lock()
+	l.degradedUntil = l.now().Add(fallbackCoolDown)
+	l.mu.Unlock()
+
+	logger.GetLogger().AddTraceInfoContextRequest(ctx).Warn().Err(err).
+		Dur("cool_down", fallbackCoolDown).Msg("rate limit store unavailable, counting in memory")
+	return l.secondary.Allow(ctx, key, rule)
+}
+
+func (l *FallbackLimiter) degraded() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.now().Before(l.degradedUntil)
+}
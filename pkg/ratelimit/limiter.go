@@ -0,0 +1,37 @@
+package ratelimit
+
+import (
+	"build-service-gin/common/redis"
+	"context"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Result is the outcome of a single hit against a rule.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time left until the oldest hit inside the window expires.
+	Reset time.Duration
+}
+
+type Limiter interface {
+	// Allow records a hit for key and reports whether it stays within rule.
+	Allow(ctx context.Context, key string, rule Rule) (*Result, error)
+}
+
+// NewLimiter counts hits in Redis and falls back to counting in memory while Redis is unavailable, limits are then
+// enforced per instance. When the shared client could not connect at startup the limiter dials Redis with its own
+// client from conf, go-redis connects on the first command so the limiter counts in Redis again once it is up.
+func NewLimiter(client *redis.Client, conf redis.RedisConfig) Limiter {
+	var rdb goredis.UniversalClient
+	if client != nil {
+		rdb = client.GetClient()
+	}
+	if rdb == nil {
+		rdb = redis.NewClient(&conf)
+	}
+	return NewFallbackLimiter(NewRedisLimiter(rdb), NewMemoryLimiter())
+}
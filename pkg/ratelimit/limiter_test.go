@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"build-service-gin/common/logger"
+	"build-service-gin/common/redis"
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLog("test")
+	os.Exit(m.Run())
+}
+
+// flakyLimiter fails while down and allows every hit otherwise.
+type flakyLimiter struct {
+	down  bool
+	calls int
+}
+
+func (l *flakyLimiter) Allow(_ context.Context, _ string, rule Rule) (*Result, error) {
+	l.calls++
+	if l.down {
+		return nil, errors.New("redis: connection refused")
+	}
+	return &Result{Allowed: true, Limit: rule.Limit, Remaining: rule.Limit}, nil
+}
+
+func TestFallbackLimiterProbesPrimaryAgain(t *testing.T) {
+	primary := &flakyLimiter{down: true}
+	limiter := NewFallbackLimiter(primary, NewMemoryLimiter())
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+	rule := Rule{Limit: 1, Window: time.Minute}
+	ctx := context.Background()
+
+	if rs, err := limiter.Allow(ctx, "k", rule); err != nil || !rs.Allowed {
+		t.Fatalf("Allow() = %+v, %v, want the memory limiter to allow the hit", rs, err)
+	}
+	if rs, _ := limiter.Allow(ctx, "k", rule); rs.Allowed {
+		t.Fatalf("second hit allowed, want the memory limiter to count while degraded")
+	}
+	if primary.calls != 1 {
+		t.Fatalf("primary called %d times during the cool down, want 1", primary.calls)
+	}
+
+	primary.down = false
+	now = now.Add(fallbackCoolDown)
+	if rs, err := limiter.Allow(ctx, "k", rule); err != nil || !rs.Allowed || primary.calls != 2 {
+		t.Fatalf("Allow() = %+v, %v after the cool down, want the primary to count again", rs, err)
+	}
+}
+
+func TestNewLimiterWithoutSharedClient(t *testing.T) {
+	// nothing listens on port 1: the limiter keeps counting in memory while it dials Redis again
+	limiter := NewLimiter(nil, redis.RedisConfig{Addr: "127.0.0.1:1"})
+	if _, ok := limiter.(*FallbackLimiter); !ok {
+		t.Fatalf("NewLimiter() = %T, want a limiter falling back to memory", limiter)
+	}
+
+	rs, err := limiter.Allow(context.Background(), "k", Rule{Limit: 1, Window: time.Minute})
+	if err != nil || !rs.Allowed {
+		t.Fatalf("Allow() = %+v, %v, want the hit counted in memory", rs, err)
+	}
+}
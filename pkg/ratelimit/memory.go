@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepEvery is the number of hits between two sweeps of idle keys.
+const sweepEvery = 1024
+
+// MemoryLimiter keeps the sliding window of every key in process memory.
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	windows map[string]*memoryWindow
+	hits    int
+	now     func() time.Time
+}
+
+type memoryWindow struct {
+	hits    []time.Time
+	expires time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		windows: make(map[string]*memoryWindow),
+		now:     time.Now,
+	}
+}
+
+func (l *MemoryLimiter) Allow(_ context.Context, key string, rule Rule) (*Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.hits++
+	if l.hits%sweepEvery == 0 {
+		l.sweep(now)
+	}
+
+	w, ok := l.windows[key]
+	if !ok {
+		w = &memoryWindow{}
+		l.windows[key] = w
+	}
+
+	start := now.Add(-rule.Window)
+	kept := w.hits[:0]
+	for _, hit := range w.hits {
+		if hit.After(start) {
+			kept = append(kept, hit)
+		}
+	}
+	w.hits = kept
+
+	allowed := len(w.hits) < rule.Limit
+	if allowed {
+		w.hits = append(w.hits, now)
+	}
+	w.expires = now.Add(rule.Window)
+
+	reset := rule.Window
+	if len(w.hits) > 0 {
+		reset = w.hits[0].Add(rule.Window).Sub(now)
+	}
+
+	return &Result{
+		Allowed:   allowed,
+		Limit:     rule.Limit,
+		Remaining: rule.Limit - len(w.hits),
+		Reset:     reset,
+	}, nil
+}
+
+// sweep drops the keys without a hit inside their last window.
+func (l *MemoryLimiter) sweep(now time.Time) {
+	for key, w := range l.windows {
+		if now.After(w.expires) {
+			delete(l.windows, key)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/segmentio/ksuid"
+)
+
+// slidingWindowScript keeps every hit of the window in a sorted set scored by the redis clock, so instances with
+// skewed clocks share the same window. It returns {allowed, remaining, reset in ms}.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local member = ARGV[3]
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, member)
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', key, window)
+
+local reset = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+
+return {allowed, limit - count, reset}
+`)
+
+type RedisLimiter struct {
+	client redis.UniversalClient
+}
+
+func NewRedisLimiter(client redis.UniversalClient) *RedisLimiter {
+	return &RedisLimiter{
+		client: client,
+	}
+}
+
+func (l *RedisLimiter) Allow(ctx context.Context, key string, rule Rule) (*Result, error) {
+	values, err := slidingWindowScript.Run(ctx, l.client, []string{key},
+		rule.Window.Milliseconds(), rule.Limit, ksuid.New().String()).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 3 {
+		return nil, fmt.Errorf("unexpected rate limit script result %v", values)
+	}
+
+	return &Result{
+		Allowed:   values[0] == 1,
+		Limit:     rule.Limit,
+		Remaining: int(values[1]),
+		Reset:     time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,57 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	KeyByIP      = "ip"
+	KeyBySubject = "subject"
+	KeyByProfile = "profile"
+)
+
+// Rule allows Limit requests per key inside every sliding Window.
+type Rule struct {
+	Limit  int
+	Window time.Duration
+	KeyBy  string
+}
+
+// ParseRule reads a rule written as <limit>/<window>[/<key>], e.g. 10/1m/subject. The key falls back to def when
+// it is omitted.
+func ParseRule(s string, def string) (Rule, error) {
+	parts := strings.Split(strings.TrimSpace(s), "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Rule{}, fmt.Errorf("rate limit rule %q must look like <limit>/<window>[/<key>]", s)
+	}
+
+	limit, err := strconv.Atoi(parts[0])
+	if err != nil || limit <= 0 {
+		return Rule{}, fmt.Errorf("rate limit rule %q has an invalid limit", s)
+	}
+
+	window, err := time.ParseDuration(parts[1])
+	if err != nil || window <= 0 {
+		return Rule{}, fmt.Errorf("rate limit rule %q has an invalid window", s)
+	}
+
+	keyBy := def
+	if len(parts) == 3 {
+		keyBy = parts[2]
+	}
+	switch keyBy {
+	case KeyByIP, KeyBySubject, KeyByProfile:
+	default:
+		return Rule{}, fmt.Errorf("rate limit rule %q has an unknown key %q", s, keyBy)
+	}
+
+	return Rule{Limit: limit, Window: window, KeyBy: keyBy}, nil
+}
+
+// Policy renders the rule as a RateLimit-Policy header value.
+func (r Rule) Policy() string {
+	return fmt.Sprintf("%d;w=%d", r.Limit, int64(r.Window/time.Second))
+}
@@ -0,0 +1,24 @@
+package signature
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// CanonicalRequest builds the string both sides sign: the method, the path with its query, the hex SHA-256 of the
+// body, the timestamp and the nonce, joined by new lines.
+func CanonicalRequest(method, requestURI string, body []byte, timestamp, nonce string) []byte {
+	return []byte(strings.Join([]string{
+		strings.ToUpper(method),
+		requestURI,
+		HashBody(body),
+		timestamp,
+		nonce,
+	}, "\n"))
+}
+
+func HashBody(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,36 @@
+package signature
+
+import "fmt"
+
+// KeySet holds the verifiers of inbound callers by key id.
+type KeySet map[string]Verifier
+
+// NewKeySet builds the key set from HMAC secrets and base64 Ed25519 public keys, both keyed by key id.
+func NewKeySet(hmacKeys, ed25519Keys map[string]string) (KeySet, error) {
+	keys := make(KeySet, len(hmacKeys)+len(ed25519Keys))
+	for id, secret := range hmacKeys {
+		if secret == "" {
+			return nil, fmt.Errorf("hmac key %s is empty", id)
+		}
+		keys[id] = NewHMACKey([]byte(secret))
+	}
+	for id, publicKey := range ed25519Keys {
+		if _, ok := keys[id]; ok {
+			return nil, fmt.Errorf("key id %s is configured twice", id)
+		}
+		verifier, err := NewEd25519Verifier(publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("ed25519 key %s: %w", id, err)
+		}
+		keys[id] = verifier
+	}
+	return keys, nil
+}
+
+func (s KeySet) Verify(keyID string, message []byte, signature string) error {
+	verifier, ok := s[keyID]
+	if !ok {
+		return ErrUnknownKey
+	}
+	return verifier.Verify(message, signature)
+}
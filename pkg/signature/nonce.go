@@ -0,0 +1,77 @@
+package signature
+
+import (
+	"build-service-gin/common/logger"
+	"build-service-gin/common/redis"
+	"context"
+	"sync"
+	"time"
+)
+
+// NonceStore remembers nonces for ttl so a signed request can't be replayed.
+type NonceStore interface {
+	// Claim reports false when the nonce was already claimed inside its ttl.
+	Claim(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
+// NewNonceStore keeps nonces in Redis and in memory while Redis is unavailable. Without a connected Redis client
+// nonces are only remembered per instance.
+func NewNonceStore(client *redis.Client) NonceStore {
+	memory := NewMemoryNonceStore()
+	if client == nil || client.GetClient() == nil {
+		return memory
+	}
+	return &fallbackNonceStore{primary: &RedisNonceStore{client: client}, secondary: memory}
+}
+
+type RedisNonceStore struct {
+	client *redis.Client
+}
+
+func (s *RedisNonceStore) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return s.client.GetClient().SetNX(ctx, key, 1, ttl).Result()
+}
+
+type MemoryNonceStore struct {
+	mu     sync.Mutex
+	nonces map[string]time.Time
+}
+
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{
+		nonces: make(map[string]time.Time),
+	}
+}
+
+func (s *MemoryNonceStore) Claim(_ context.Context, key string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, expires := range s.nonces {
+		if now.After(expires) {
+			delete(s.nonces, k)
+		}
+	}
+
+	if _, ok := s.nonces[key]; ok {
+		return false, nil
+	}
+	s.nonces[key] = now.Add(ttl)
+	return true, nil
+}
+
+type fallbackNonceStore struct {
+	primary   NonceStore
+	secondary NonceStore
+}
+
+func (s *fallbackNonceStore) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	ok, err := s.primary.Claim(ctx, key, ttl)
+	if err == nil {
+		return ok, nil
+	}
+
+	logger.GetLogger().AddTraceInfoContextRequest(ctx).Warn().Err(err).Msg("nonce store unavailable, remembering nonce in memory")
+	return s.secondary.Claim(ctx, key, ttl)
+}
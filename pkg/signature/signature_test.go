@@ -0,0 +1,140 @@
+package signature
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCanonicalRequest(t *testing.T) {
+	got := string(CanonicalRequest("post", "/v1/point/transaction?a=1", []byte("{}"), "1700000000", "n-1"))
+	want := "POST\n/v1/point/transaction?a=1\n" + HashBody([]byte("{}")) + "\n1700000000\nn-1"
+	if got != want {
+		t.Errorf("CanonicalRequest() = %q, want %q", got, want)
+	}
+}
+
+func TestHMACKey(t *testing.T) {
+	message := []byte("message")
+	signer, err := NewSigner("hmac-sha256", "secret")
+	if err != nil {
+		t.Fatalf("NewSigner() = %v", err)
+	}
+	sig, err := signer.Sign(message)
+	if err != nil {
+		t.Fatalf("Sign() = %v", err)
+	}
+
+	if err = NewHMACKey([]byte("secret")).Verify(message, sig); err != nil {
+		t.Errorf("Verify() = %v", err)
+	}
+	if err = NewHMACKey([]byte("other")).Verify(message, sig); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Verify() with another secret = %v, want %v", err, ErrSignatureInvalid)
+	}
+	if err = NewHMACKey([]byte("secret")).Verify([]byte("tampered"), sig); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Verify() of another message = %v, want %v", err, ErrSignatureInvalid)
+	}
+	if err = NewHMACKey([]byte("secret")).Verify(message, "%not base64%"); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Verify() of a malformed signature = %v, want %v", err, ErrSignatureInvalid)
+	}
+}
+
+func TestEd25519Key(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	message := []byte("message")
+
+	for name, key := range map[string][]byte{"seed": private.Seed(), "private key": private} {
+		t.Run(name, func(t *testing.T) {
+			signer, err := NewSigner(AlgEd25519, base64.StdEncoding.EncodeToString(key))
+			if err != nil {
+				t.Fatalf("NewSigner() = %v", err)
+			}
+			sig, _ := signer.Sign(message)
+
+			verifier, err := NewEd25519Verifier(base64.StdEncoding.EncodeToString(public))
+			if err != nil {
+				t.Fatalf("NewEd25519Verifier() = %v", err)
+			}
+			if err = verifier.Verify(message, sig); err != nil {
+				t.Errorf("Verify() = %v", err)
+			}
+			if err = verifier.Verify([]byte("tampered"), sig); !errors.Is(err, ErrSignatureInvalid) {
+				t.Errorf("Verify() of another message = %v, want %v", err, ErrSignatureInvalid)
+			}
+		})
+	}
+}
+
+func TestNewSignerInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		alg  string
+		key  string
+	}{
+		{name: "empty key", alg: AlgHMACSHA256},
+		{name: "unknown algorithm", alg: "RSA", key: "secret"},
+		{name: "ed25519 key not base64", alg: AlgEd25519, key: "%not base64%"},
+		{name: "ed25519 key too short", alg: AlgEd25519, key: base64.StdEncoding.EncodeToString([]byte("short"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSigner(tt.alg, tt.key); err == nil {
+				t.Errorf("NewSigner(%q, %q) succeeded, want an error", tt.alg, tt.key)
+			}
+		})
+	}
+}
+
+func TestKeySet(t *testing.T) {
+	public, _, _ := ed25519.GenerateKey(rand.Reader)
+	ed25519Key := base64.StdEncoding.EncodeToString(public)
+
+	keys, err := NewKeySet(map[string]string{"partner-a": "secret"}, map[string]string{"partner-b": ed25519Key})
+	if err != nil {
+		t.Fatalf("NewKeySet() = %v", err)
+	}
+	message := []byte("message")
+	sig, _ := NewHMACKey([]byte("secret")).Sign(message)
+
+	if err = keys.Verify("partner-a", message, sig); err != nil {
+		t.Errorf("Verify() = %v", err)
+	}
+	if err = keys.Verify("partner-b", message, sig); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Verify() with the key of another partner = %v, want %v", err, ErrSignatureInvalid)
+	}
+	if err = keys.Verify("partner-c", message, sig); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Verify() with an unknown key = %v, want %v", err, ErrUnknownKey)
+	}
+
+	if _, err = NewKeySet(map[string]string{"partner-a": ""}, nil); err == nil {
+		t.Error("NewKeySet() accepted an empty hmac secret")
+	}
+	if _, err = NewKeySet(map[string]string{"partner-a": "secret"}, map[string]string{"partner-a": ed25519Key}); err == nil {
+		t.Error("NewKeySet() accepted a key id configured twice")
+	}
+}
+
+func TestMemoryNonceStore(t *testing.T) {
+	store := NewMemoryNonceStore()
+	ctx := context.Background()
+
+	if fresh, _ := store.Claim(ctx, "n-1", time.Minute); !fresh {
+		t.Fatal("first claim rejected")
+	}
+	if fresh, _ := store.Claim(ctx, "n-1", time.Minute); fresh {
+		t.Error("replayed nonce accepted")
+	}
+	if fresh, _ := store.Claim(ctx, "n-2", -time.Second); !fresh {
+		t.Fatal("claim of another nonce rejected")
+	}
+	if fresh, _ := store.Claim(ctx, "n-2", time.Minute); !fresh {
+		t.Error("expired nonce still remembered")
+	}
+}
@@ -0,0 +1,128 @@
+package signature
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	AlgHMACSHA256 = "HMAC-SHA256"
+	AlgEd25519    = "ED25519"
+)
+
+var (
+	ErrSignatureInvalid = errors.New("signature invalid")
+	ErrUnknownKey       = errors.New("signature key unknown")
+)
+
+// Signer signs a message, the signature is base64 encoded.
+type Signer interface {
+	Algorithm() string
+	Sign(message []byte) (string, error)
+}
+
+// Verifier checks a base64 signature of a message.
+type Verifier interface {
+	Algorithm() string
+	Verify(message []byte, signature string) error
+}
+
+type HMACKey struct {
+	secret []byte
+}
+
+func NewHMACKey(secret []byte) *HMACKey {
+	return &HMACKey{secret: secret}
+}
+
+func (k *HMACKey) Algorithm() string {
+	return AlgHMACSHA256
+}
+
+func (k *HMACKey) Sign(message []byte) (string, error) {
+	return base64.StdEncoding.EncodeToString(k.sum(message)), nil
+}
+
+func (k *HMACKey) Verify(message []byte, signature string) error {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(sig, k.sum(message)) {
+		return ErrSignatureInvalid
+	}
+	return nil
+}
+
+func (k *HMACKey) sum(message []byte) []byte {
+	mac := hmac.New(sha256.New, k.secret)
+	mac.Write(message)
+	return mac.Sum(nil)
+}
+
+type Ed25519Signer struct {
+	key ed25519.PrivateKey
+}
+
+func (s *Ed25519Signer) Algorithm() string {
+	return AlgEd25519
+}
+
+func (s *Ed25519Signer) Sign(message []byte) (string, error) {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, message)), nil
+}
+
+type Ed25519Verifier struct {
+	key ed25519.PublicKey
+}
+
+func (v *Ed25519Verifier) Algorithm() string {
+	return AlgEd25519
+}
+
+func (v *Ed25519Verifier) Verify(message []byte, signature string) error {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || !ed25519.Verify(v.key, message, sig) {
+		return ErrSignatureInvalid
+	}
+	return nil
+}
+
+// NewSigner builds a signer of alg. The key is the raw HMAC secret or the base64 Ed25519 seed or private key.
+func NewSigner(alg, key string) (Signer, error) {
+	if key == "" {
+		return nil, fmt.Errorf("signing key of %s is empty", alg)
+	}
+
+	switch strings.ToUpper(alg) {
+	case AlgHMACSHA256:
+		return NewHMACKey([]byte(key)), nil
+	case AlgEd25519:
+		raw, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			return nil, fmt.Errorf("decode ed25519 private key: %w", err)
+		}
+		switch len(raw) {
+		case ed25519.SeedSize:
+			return &Ed25519Signer{key: ed25519.NewKeyFromSeed(raw)}, nil
+		case ed25519.PrivateKeySize:
+			return &Ed25519Signer{key: raw}, nil
+		}
+		return nil, fmt.Errorf("ed25519 private key has %d bytes", len(raw))
+	}
+	return nil, fmt.Errorf("signing algorithm %q not supported", alg)
+}
+
+// NewEd25519Verifier builds a verifier from a base64 Ed25519 public key.
+func NewEd25519Verifier(key string) (*Ed25519Verifier, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("decode ed25519 public key: %w", err)
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("ed25519 public key has %d bytes", len(raw))
+	}
+	return &Ed25519Verifier{key: raw}, nil
+}
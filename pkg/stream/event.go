@@ -0,0 +1,49 @@
+package stream
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidEventID is returned for a Last-Event-ID which is not the ID of an event.
+var ErrInvalidEventID = errors.New("stream: invalid event id")
+
+// Event is an update of a profile. ID is the ID of its entry in the Redis stream of the profile, "<ms>-<seq>", so
+// the IDs of a profile only grow.
+type Event struct {
+	ID        string          `json:"id"`
+	ProfileID string          `json:"profileID"`
+	Name      string          `json:"name"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// eventID is a parsed Event ID, compared to skip the events a subscriber already got.
+type eventID struct {
+	ms, seq uint64
+}
+
+func parseEventID(id string) (eventID, error) {
+	msPart, seqPart, ok := strings.Cut(id, "-")
+	if !ok {
+		return eventID{}, ErrInvalidEventID
+	}
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return eventID{}, ErrInvalidEventID
+	}
+	seq, err := strconv.ParseUint(seqPart, 10, 64)
+	if err != nil {
+		return eventID{}, ErrInvalidEventID
+	}
+	return eventID{ms: ms, seq: seq}, nil
+}
+
+func (id eventID) after(other eventID) bool {
+	return id.ms > other.ms || id.ms == other.ms && id.seq > other.seq
+}
+
+func (id eventID) String() string {
+	return strconv.FormatUint(id.ms, 10) + "-" + strconv.FormatUint(id.seq, 10)
+}
@@ -0,0 +1,266 @@
+package stream
+
+import (
+	"build-service-gin/common/logger"
+	"build-service-gin/common/redis"
+	"build-service-gin/config"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// EventReset tells a resuming client that events may be missing, it reloads what it shows instead.
+const EventReset = "reset"
+
+var (
+	// ErrTooManyConnections is returned when the instance already serves MaxConnections streams.
+	ErrTooManyConnections = errors.New("stream: too many connections")
+	// ErrTooManyProfileConnections is returned when the instance already serves MaxProfileConnections streams of the
+	// profile.
+	ErrTooManyProfileConnections = errors.New("stream: too many connections of the profile")
+)
+
+// Hub fans the events of profiles out to their subscribers. Events are published on a Redis channel every instance
+// listens to, and kept in a Redis stream per profile for the subscribers resuming after a reconnect. Without a
+// connected Redis client events only reach the subscribers of this instance and streams cannot be resumed.
+type Hub struct {
+	client goredis.UniversalClient
+	conf   config.StreamConfig
+
+	mu     sync.Mutex
+	subs   map[string]map[*Subscription]struct{}
+	count  int
+	closed bool
+	lastID eventID
+}
+
+func NewHub(client *redis.Client, conf config.StreamConfig) *Hub {
+	h := &Hub{
+		conf: conf,
+		subs: map[string]map[*Subscription]struct{}{},
+	}
+	if client != nil && client.GetClient() != nil {
+		h.client = client.GetClient()
+	}
+	return h
+}
+
+// Start delivers the events published by every instance to the subscribers of this one until ctx is done, then
+// ends every subscription so that the streams are closed before the server shuts down.
+func (h *Hub) Start(ctx context.Context) {
+	defer h.close()
+	if h.client == nil {
+		<-ctx.Done()
+		return
+	}
+
+	log := logger.GetLogger().AddTraceInfoContextRequest(ctx)
+	pubsub := h.client.Subscribe(ctx, h.conf.Channel)
+	defer pubsub.Close()
+	messages := pubsub.Channel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			var event Event
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				log.Warn().Err(err).Msg("decode stream event failed")
+				continue
+			}
+			h.deliver(event)
+		}
+	}
+}
+
+// Publish sends the event name of profileID to its subscribers on every instance and keeps it in the buffer of the
+// profile.
+func (h *Hub) Publish(ctx context.Context, profileID, name string, data json.RawMessage) error {
+	event := Event{ProfileID: profileID, Name: name, Data: data}
+	if h.client == nil {
+		event.ID = h.nextLocalID().String()
+		h.deliver(event)
+		return nil
+	}
+
+	key := h.key(profileID)
+	id, err := h.client.XAdd(ctx, &goredis.XAddArgs{
+		Stream: key,
+		MaxLen: h.conf.BufferSize,
+		Approx: true,
+		Values: map[string]interface{}{"name": name, "data": []byte(data)},
+	}).Result()
+	if err != nil {
+		return err
+	}
+	event.ID = id
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = h.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Expire(ctx, key, h.conf.BufferTTL)
+		pipe.Publish(ctx, h.conf.Channel, payload)
+		return nil
+	})
+	return err
+}
+
+// Subscribe subscribes to the events of profileID. With lastEventID, the events buffered after it are received
+// first, preceded by a reset event when some of them may have left the buffer.
+func (h *Hub) Subscribe(ctx context.Context, profileID, lastEventID string) (*Subscription, error) {
+	var last eventID
+	if lastEventID != "" {
+		id, err := parseEventID(lastEventID)
+		if err != nil {
+			return nil, err
+		}
+		last = id
+	}
+
+	sub := newSubscription(h, profileID)
+	if err := h.add(sub); err != nil {
+		return nil, err
+	}
+	if lastEventID == "" {
+		return sub, nil
+	}
+
+	replayed, reset, err := h.replay(ctx, profileID, last)
+	if err != nil {
+		sub.Close()
+		return nil, err
+	}
+	sub.resume(last, replayed, reset)
+	return sub, nil
+}
+
+// replay reads the events of profileID buffered after last, and whether events after last may be missing: last
+// left the buffer, or the buffer expired since last.
+func (h *Hub) replay(ctx context.Context, profileID string, last eventID) ([]Event, bool, error) {
+	if h.client == nil {
+		return nil, true, nil
+	}
+
+	key := h.key(profileID)
+	var fromLast *goredis.XMessageSliceCmd
+	var length *goredis.IntCmd
+	_, err := h.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		fromLast = pipe.XRangeN(ctx, key, last.String(), "+", h.conf.BufferSize+1)
+		length = pipe.XLen(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	messages := fromLast.Val()
+	var reset bool
+	switch {
+	case len(messages) > 0 && messages[0].ID == last.String():
+		messages = messages[1:]
+	case length.Val() == 0:
+		reset = time.UnixMilli(int64(last.ms)).Before(time.Now().Add(-h.conf.BufferTTL))
+	default:
+		reset = true
+	}
+
+	events := make([]Event, 0, len(messages))
+	for _, message := range messages {
+		name, _ := message.Values["name"].(string)
+		data, _ := message.Values["data"].(string)
+		events = append(events, Event{ID: message.ID, ProfileID: profileID, Name: name, Data: json.RawMessage(data)})
+	}
+	return events, reset, nil
+}
+
+func (h *Hub) deliver(event Event) {
+	h.mu.Lock()
+	subs := make([]*Subscription, 0, len(h.subs[event.ProfileID]))
+	for sub := range h.subs[event.ProfileID] {
+		subs = append(subs, sub)
+	}
+	h.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.push(event)
+	}
+}
+
+func (h *Hub) add(sub *Subscription) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return ErrClosed
+	}
+	if h.count >= h.conf.MaxConnections {
+		return ErrTooManyConnections
+	}
+	if len(h.subs[sub.profileID]) >= h.conf.MaxProfileConnections {
+		return ErrTooManyProfileConnections
+	}
+
+	if h.subs[sub.profileID] == nil {
+		h.subs[sub.profileID] = map[*Subscription]struct{}{}
+	}
+	h.subs[sub.profileID][sub] = struct{}{}
+	h.count++
+	return nil
+}
+
+func (h *Hub) remove(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[sub.profileID][sub]; !ok {
+		return
+	}
+
+	delete(h.subs[sub.profileID], sub)
+	if len(h.subs[sub.profileID]) == 0 {
+		delete(h.subs, sub.profileID)
+	}
+	h.count--
+}
+
+// close ends every subscription and refuses new ones.
+func (h *Hub) close() {
+	h.mu.Lock()
+	h.closed = true
+	var subs []*Subscription
+	for _, byProfile := range h.subs {
+		for sub := range byProfile {
+			subs = append(subs, sub)
+		}
+	}
+	h.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.end(ErrClosed)
+	}
+}
+
+// nextLocalID returns the ID of an event published without Redis, growing like the IDs of Redis streams.
+func (h *Hub) nextLocalID() eventID {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	id := eventID{ms: uint64(time.Now().UnixMilli())}
+	if !id.after(h.lastID) {
+		id = eventID{ms: h.lastID.ms, seq: h.lastID.seq + 1}
+	}
+	h.lastID = id
+	return id
+}
+
+func (h *Hub) key(profileID string) string {
+	return h.conf.Channel + ":" + profileID
+}
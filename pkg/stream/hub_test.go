@@ -0,0 +1,363 @@
+package stream
+
+import (
+	"build-service-gin/common/logger"
+	"build-service-gin/config"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLog("test")
+	os.Exit(m.Run())
+}
+
+func testConfig() config.StreamConfig {
+	return config.StreamConfig{
+		Channel:               "profile-stream",
+		BufferSize:            100,
+		BufferTTL:             10 * time.Minute,
+		MaxConnections:        10,
+		MaxProfileConnections: 10,
+	}
+}
+
+// newRedisHub returns a hub publishing on mr, like an instance connected to a shared Redis.
+func newRedisHub(t *testing.T, mr *miniredis.Miniredis, conf config.StreamConfig) *Hub {
+	t.Helper()
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	h := NewHub(nil, conf)
+	h.client = client
+	return h
+}
+
+// startHub runs h until the end of the test, once it listens to the channel.
+func startHub(t *testing.T, mr *miniredis.Miniredis, h *Hub) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	listening := mr.PubSubNumSub(h.conf.Channel)[h.conf.Channel]
+	go func() {
+		defer close(done)
+		h.Start(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for mr.PubSubNumSub(h.conf.Channel)[h.conf.Channel] == listening {
+		if time.Now().After(deadline) {
+			t.Fatal("hub did not subscribe to the channel")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// receive returns the next n events of sub, failing the test when they do not come.
+func receive(t *testing.T, sub *Subscription, n int) []Event {
+	t.Helper()
+	var events []Event
+	timeout := time.After(2 * time.Second)
+	for len(events) < n {
+		select {
+		case <-sub.Ready():
+			next, err := sub.Next()
+			if err != nil {
+				t.Fatalf("Next() = %v after %d events, want %d", err, len(events), n)
+			}
+			events = append(events, next...)
+		case <-timeout:
+			t.Fatalf("received %d events, want %d", len(events), n)
+		}
+	}
+	return events
+}
+
+func publish(t *testing.T, h *Hub, profileID string, n int) []string {
+	t.Helper()
+	ids := make([]string, n)
+	for i := range ids {
+		if err := h.Publish(context.Background(), profileID, "balance", json.RawMessage(strconv.Itoa(i))); err != nil {
+			t.Fatalf("Publish() = %v", err)
+		}
+		ids[i] = lastID(t, h, profileID)
+	}
+	return ids
+}
+
+// lastID returns the ID of the last event buffered for profileID.
+func lastID(t *testing.T, h *Hub, profileID string) string {
+	t.Helper()
+	messages, err := h.client.XRevRangeN(context.Background(), h.key(profileID), "+", "-", 1).Result()
+	if err != nil || len(messages) != 1 {
+		t.Fatalf("XRevRangeN() = %v, %v", messages, err)
+	}
+	return messages[0].ID
+}
+
+func eventNames(events []Event) []string {
+	names := make([]string, len(events))
+	for i, event := range events {
+		names[i] = event.Name + ":" + string(event.Data)
+	}
+	return names
+}
+
+func TestHubFansOutAcrossInstances(t *testing.T) {
+	mr := miniredis.RunT(t)
+	publisher, subscriber := newRedisHub(t, mr, testConfig()), newRedisHub(t, mr, testConfig())
+	startHub(t, mr, subscriber)
+
+	sub, err := subscriber.Subscribe(context.Background(), "p1", "")
+	if err != nil {
+		t.Fatalf("Subscribe() = %v", err)
+	}
+	other, err := subscriber.Subscribe(context.Background(), "p2", "")
+	if err != nil {
+		t.Fatalf("Subscribe() = %v", err)
+	}
+
+	ids := publish(t, publisher, "p1", 2)
+	events := receive(t, sub, 2)
+	if events[0].ID != ids[0] || events[1].ID != ids[1] || events[0].ProfileID != "p1" || string(events[1].Data) != "1" {
+		t.Errorf("received %+v, want the events %v", events, ids)
+	}
+	if got, err := other.Next(); len(got) > 0 || err != nil {
+		t.Errorf("subscriber of another profile got %v, %v", got, err)
+	}
+}
+
+func TestHubResume(t *testing.T) {
+	tests := []struct {
+		name       string
+		bufferSize int64
+		published  int
+		// from is the index of the published event to resume after, -1 for an ID older than the buffer TTL
+		from      int
+		flush     bool
+		wantNames []string
+	}{
+		{name: "from the first event", bufferSize: 100, published: 3, from: 0, wantNames: []string{"balance:1", "balance:2"}},
+		{name: "from the last event", bufferSize: 100, published: 3, from: 2},
+		{
+			name:       "from an event which left the buffer",
+			bufferSize: 2,
+			published:  4,
+			from:       0,
+			wantNames:  []string{"reset:", "balance:2", "balance:3"},
+		},
+		{name: "from an event of an expired buffer", bufferSize: 100, published: 1, from: -1, flush: true, wantNames: []string{"reset:"}},
+		{name: "from a recent event of a buffer gone quiet", bufferSize: 100, published: 1, from: 0, flush: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			conf := testConfig()
+			conf.BufferSize = tt.bufferSize
+			h := newRedisHub(t, mr, conf)
+
+			ids := publish(t, h, "p1", tt.published)
+			from := eventID{ms: uint64(time.Now().Add(-time.Hour).UnixMilli())}.String()
+			if tt.from >= 0 {
+				from = ids[tt.from]
+			}
+			if tt.flush {
+				mr.FlushAll()
+			}
+
+			sub, err := h.Subscribe(context.Background(), "p1", from)
+			if err != nil {
+				t.Fatalf("Subscribe() = %v", err)
+			}
+			events, err := sub.Next()
+			if err != nil {
+				t.Fatalf("Next() = %v", err)
+			}
+			if got := eventNames(events); !equalNames(got, tt.wantNames) {
+				t.Errorf("resumed with %v, want %v", got, tt.wantNames)
+			}
+		})
+	}
+}
+
+func equalNames(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestHubResumeSkipsReplayedLiveEvents(t *testing.T) {
+	mr := miniredis.RunT(t)
+	h := newRedisHub(t, mr, testConfig())
+	ids := publish(t, h, "p1", 3)
+
+	sub := newSubscription(h, "p1")
+	if err := h.add(sub); err != nil {
+		t.Fatalf("add() = %v", err)
+	}
+	// the last event reached the subscriber live while the buffer was read
+	sub.push(Event{ID: ids[2], ProfileID: "p1", Name: "balance", Data: json.RawMessage("2")})
+	last, _ := parseEventID(ids[0])
+	replayed, reset, err := h.replay(context.Background(), "p1", last)
+	if err != nil {
+		t.Fatalf("replay() = %v", err)
+	}
+	sub.resume(last, replayed, reset)
+
+	events, _ := sub.Next()
+	if got, want := eventNames(events), []string{"balance:1", "balance:2"}; !equalNames(got, want) {
+		t.Errorf("resumed with %v, want %v", got, want)
+	}
+	// a late copy of an event already received is dropped
+	sub.push(Event{ID: ids[1], ProfileID: "p1", Name: "balance", Data: json.RawMessage("1")})
+	if events, err := sub.Next(); len(events) > 0 || err != nil {
+		t.Errorf("Next() = %v, %v, want nothing", events, err)
+	}
+}
+
+func TestHubSubscribeErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		lastEventID string
+		wantErr     error
+	}{
+		{name: "not an id", lastEventID: "last", wantErr: ErrInvalidEventID},
+		{name: "not a number", lastEventID: "1-x", wantErr: ErrInvalidEventID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHub(nil, testConfig())
+			if _, err := h.Subscribe(context.Background(), "p1", tt.lastEventID); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Subscribe() = %v, want %v", err, tt.wantErr)
+			}
+			if h.count != 0 {
+				t.Errorf("%d connections left open", h.count)
+			}
+		})
+	}
+}
+
+func TestHubResumeWithoutRedis(t *testing.T) {
+	h := NewHub(nil, testConfig())
+	sub, err := h.Subscribe(context.Background(), "p1", "1-0")
+	if err != nil {
+		t.Fatalf("Subscribe() = %v", err)
+	}
+	if events, _ := sub.Next(); len(events) != 1 || events[0].Name != EventReset {
+		t.Errorf("resumed with %v, want a reset", events)
+	}
+}
+
+func TestHubConnectionLimits(t *testing.T) {
+	conf := testConfig()
+	conf.MaxConnections, conf.MaxProfileConnections = 3, 2
+	h := NewHub(nil, conf)
+
+	subscribe := func(profileID string) (*Subscription, error) {
+		return h.Subscribe(context.Background(), profileID, "")
+	}
+	first, err := subscribe("p1")
+	if err != nil {
+		t.Fatalf("Subscribe(p1) = %v", err)
+	}
+	if _, err := subscribe("p1"); err != nil {
+		t.Fatalf("Subscribe(p1) = %v", err)
+	}
+	if _, err := subscribe("p1"); !errors.Is(err, ErrTooManyProfileConnections) {
+		t.Fatalf("third Subscribe(p1) = %v, want %v", err, ErrTooManyProfileConnections)
+	}
+	if _, err := subscribe("p2"); err != nil {
+		t.Fatalf("Subscribe(p2) = %v", err)
+	}
+	if _, err := subscribe("p3"); !errors.Is(err, ErrTooManyConnections) {
+		t.Fatalf("Subscribe(p3) = %v, want %v", err, ErrTooManyConnections)
+	}
+
+	first.Close()
+	first.Close()
+	if _, err := subscribe("p3"); err != nil {
+		t.Fatalf("Subscribe(p3) after a close = %v", err)
+	}
+	if _, err := subscribe("p1"); !errors.Is(err, ErrTooManyConnections) {
+		t.Fatalf("Subscribe(p1) = %v, want %v", err, ErrTooManyConnections)
+	}
+}
+
+func TestHubEvictsSlowSubscribers(t *testing.T) {
+	h := NewHub(nil, testConfig())
+	slow, err := h.Subscribe(context.Background(), "p1", "")
+	if err != nil {
+		t.Fatalf("Subscribe() = %v", err)
+	}
+	fast, err := h.Subscribe(context.Background(), "p1", "")
+	if err != nil {
+		t.Fatalf("Subscribe() = %v", err)
+	}
+
+	var received int
+	for i := 0; i <= maxPending; i++ {
+		if err := h.Publish(context.Background(), "p1", "balance", json.RawMessage(strconv.Itoa(i))); err != nil {
+			t.Fatalf("Publish() = %v", err)
+		}
+		events, err := fast.Next()
+		if err != nil {
+			t.Fatalf("Next() = %v", err)
+		}
+		received += len(events)
+	}
+	if received != maxPending+1 {
+		t.Errorf("the subscriber keeping up received %d events, want %d", received, maxPending+1)
+	}
+
+	select {
+	case <-slow.Ready():
+	default:
+		t.Fatal("the slow subscriber was not woken up")
+	}
+	if events, err := slow.Next(); len(events) > 0 || !errors.Is(err, ErrSlowConsumer) {
+		t.Fatalf("Next() = %d events, %v, want %v", len(events), err, ErrSlowConsumer)
+	}
+	if err := h.Publish(context.Background(), "p1", "balance", nil); err != nil {
+		t.Fatalf("Publish() = %v", err)
+	}
+	if events, err := slow.Next(); len(events) > 0 || !errors.Is(err, ErrSlowConsumer) {
+		t.Errorf("Next() after the eviction = %d events, %v, want %v", len(events), err, ErrSlowConsumer)
+	}
+}
+
+func TestHubStartClosesSubscriptions(t *testing.T) {
+	h := NewHub(nil, testConfig())
+	sub, err := h.Subscribe(context.Background(), "p1", "")
+	if err != nil {
+		t.Fatalf("Subscribe() = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	h.Start(ctx)
+
+	if _, err := sub.Next(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Next() = %v, want %v", err, ErrClosed)
+	}
+	if _, err := h.Subscribe(context.Background(), "p1", ""); !errors.Is(err, ErrClosed) {
+		t.Errorf("Subscribe() = %v, want %v", err, ErrClosed)
+	}
+}
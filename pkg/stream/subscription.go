@@ -0,0 +1,134 @@
+package stream
+
+import (
+	"errors"
+	"sync"
+)
+
+// maxPending is how many events a subscriber may fall behind by before it is ended with ErrSlowConsumer.
+const maxPending = 256
+
+var (
+	// ErrSlowConsumer ends a subscription which fell maxPending events behind, its client resumes from the buffer.
+	ErrSlowConsumer = errors.New("stream: subscriber fell behind")
+	// ErrClosed ends the subscriptions of a hub shutting down.
+	ErrClosed = errors.New("stream: hub closed")
+)
+
+// Subscription receives the events of a profile published after it was made, and after Last-Event-ID when it
+// resumes a stream.
+type Subscription struct {
+	hub       *Hub
+	profileID string
+	ready     chan struct{}
+
+	mu      sync.Mutex
+	pending []Event
+	last    eventID
+	err     error
+}
+
+func newSubscription(hub *Hub, profileID string) *Subscription {
+	return &Subscription{
+		hub:       hub,
+		profileID: profileID,
+		ready:     make(chan struct{}, 1),
+	}
+}
+
+// Ready receives when Next has events to return or the subscription ended.
+func (s *Subscription) Ready() <-chan struct{} {
+	return s.ready
+}
+
+// Next returns the events received since the previous call, oldest first, and the error the subscription ended
+// with once the events are drained.
+func (s *Subscription) Next() ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := s.pending
+	s.pending = nil
+	if len(events) > 0 {
+		return events, nil
+	}
+	return nil, s.err
+}
+
+// Close ends the subscription and releases its connection slot.
+func (s *Subscription) Close() {
+	s.hub.remove(s)
+	s.end(ErrClosed)
+}
+
+// push queues event unless the subscriber already got it.
+func (s *Subscription) push(event Event) {
+	id, err := parseEventID(event.ID)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil || !id.after(s.last) {
+		return
+	}
+	if len(s.pending) >= maxPending {
+		s.err, s.pending = ErrSlowConsumer, nil
+		s.signal()
+		return
+	}
+
+	s.pending = append(s.pending, event)
+	s.last = id
+	s.signal()
+}
+
+// resume puts the events replayed after last before the live events received meanwhile, dropping the live events
+// replayed too. A reset event comes first when events after last may be missing from the buffer.
+func (s *Subscription) resume(last eventID, replayed []Event, reset bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []Event
+	if reset {
+		events = append(events, Event{ProfileID: s.profileID, Name: EventReset})
+	}
+	for _, event := range replayed {
+		if id, err := parseEventID(event.ID); err == nil && id.after(last) {
+			events = append(events, event)
+			last = id
+		}
+	}
+	for _, event := range s.pending {
+		if id, err := parseEventID(event.ID); err == nil && id.after(last) {
+			events = append(events, event)
+			last = id
+		}
+	}
+
+	s.pending = events
+	if last.after(s.last) {
+		s.last = last
+	}
+	if len(s.pending) > 0 {
+		s.signal()
+	}
+}
+
+func (s *Subscription) end(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = err
+		s.signal()
+	}
+}
+
+// signal wakes the reader up, a wake up already pending covers the new events.
+func (s *Subscription) signal() {
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
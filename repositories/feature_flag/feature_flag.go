@@ -0,0 +1,76 @@
+package feature_flag
+
+import (
+	mongodb "build-service-gin/common/mongodb"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type FeatureFlagRepo struct {
+	*mongodb.Repository[FeatureFlag]
+}
+
+type IFeatureFlagRepo interface {
+	ListFlags(ctx context.Context) ([]*FeatureFlag, error)
+	UpsertFlag(ctx context.Context, data *FeatureFlag) (*FeatureFlag, error)
+	DeleteFlag(ctx context.Context, key string) error
+}
+
+func NewRepoFeatureFlag(dbStorage *mongodb.DatabaseStorage) IFeatureFlagRepo {
+	return &FeatureFlagRepo{
+		Repository: mongodb.NewRepository[FeatureFlag](dbStorage),
+	}
+}
+
+func (r *FeatureFlagRepo) R() *FeatureFlagRepo {
+	return &FeatureFlagRepo{
+		Repository: r.Repository.NewFilterPlayer(),
+	}
+}
+
+func (r *FeatureFlagRepo) byKey(key string) *FeatureFlagRepo {
+	filter := bson.M{
+		FFeatureFlagKey: key,
+	}
+	r.Append(filter)
+	return r
+}
+
+func (r *FeatureFlagRepo) ListFlags(ctx context.Context) ([]*FeatureFlag, error) {
+	return r.R().FindDocs(ctx)
+}
+
+// UpsertFlag creates the flag or replaces the settings of the flag with the same key.
+func (r *FeatureFlagRepo) UpsertFlag(ctx context.Context, data *FeatureFlag) (*FeatureFlag, error) {
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			FFeatureFlagDescription: data.Description,
+			FFeatureFlagEnabled:     data.Enabled,
+			FFeatureFlagPercentage:  data.Percentage,
+			FFeatureFlagRegions:     data.Regions,
+			FFeatureFlagProfiles:    data.Profiles,
+			FFeatureFlagUpdatedAt:   now,
+		},
+		"$setOnInsert": bson.M{
+			FFeatureFlagCreatedAt: now,
+		},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	return r.R().byKey(data.Key).FindOneAndUpdateDoc(ctx, update, opts)
+}
+
+func (r *FeatureFlagRepo) DeleteFlag(ctx context.Context, key string) error {
+	rs, err := r.R().byKey(key).DeleteOneDoc(ctx)
+	if err != nil {
+		return err
+	}
+	if rs.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package feature_flag
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type FeatureFlag struct {
+	Key         string     `bson:"key"`
+	Description string     `bson:"description"`
+	Enabled     bool       `bson:"enabled"`
+	Percentage  *int       `bson:"percentage"`
+	Regions     []string   `bson:"regions"`
+	Profiles    []string   `bson:"profiles"`
+	CreatedAt   *time.Time `bson:"created_at"`
+	UpdatedAt   *time.Time `bson:"updated_at"`
+}
+
+func (r FeatureFlag) CollectionName() string {
+	return "feature_flag"
+}
+
+func (r FeatureFlag) IndexModels() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: FFeatureFlagKey, Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+}
@@ -1,6 +1,7 @@
 package user_transaction_history
 
 import (
+	"build-service-gin/pkg/fieldcrypt"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -9,20 +10,55 @@ import (
 )
 
 type UserTransactionHistory struct {
-	TransactionID        string     `bson:"transaction_id"`
-	TransactionType      string     `bson:"transaction_type"`
-	ProfileID            string     `bson:"profile_id"`
-	Status               string     `bson:"status"`
-	PointAmount          int64      `bson:"point_amount"`
-	PointType            int64      `bson:"point_type"`
-	TotalAmount          float64    `bson:"total_amount"`
-	Currency             string     `bson:"currency"`
-	PaymentTransactionID string     `bson:"payment_transaction_id"`
-	Source               string     `bson:"source"`
-	SourceTime           *time.Time `bson:"source_time"`
-	SourceType           string     `bson:"source_type"`
-	CreatedAt            *time.Time `bson:"created_at"`
-	UpdatedAt            *time.Time `bson:"updated_at"`
+	TransactionID        string                   `bson:"transaction_id"`
+	TransactionType      string                   `bson:"transaction_type"`
+	ProfileID            string                   `bson:"profile_id"`
+	Status               string                   `bson:"status"`
+	PointAmount          int64                    `bson:"point_amount"`
+	PointType            int64                    `bson:"point_type"`
+	TotalAmount          float64                  `bson:"total_amount"`
+	Currency             string                   `bson:"currency"`
+	PaymentTransactionID fieldcrypt.Deterministic `bson:"payment_transaction_id"`
+	Source               string                   `bson:"source"`
+	SourceTime           *time.Time               `bson:"source_time"`
+	SourceType           string                   `bson:"source_type"`
+	StatusHistory        []StatusHistory          `bson:"status_history"`
+	OriginalTxID         string                   `bson:"original_transaction_id,omitempty"`
+	ReversedAmount       float64                  `bson:"reversed_amount"`
+	ReversedPointAmount  int64                    `bson:"reversed_point_amount"`
+	CreatedAt            *time.Time               `bson:"created_at"`
+	UpdatedAt            *time.Time               `bson:"updated_at"`
+}
+
+type StatusHistory struct {
+	Status    string     `bson:"status"`
+	ChangedAt *time.Time `bson:"changed_at"`
+}
+
+// StatusSummary aggregates the transactions of a profile in a status. Points sums all of them, the Recent fields the
+// ones created since the date of the aggregation, RecentCredited their positive points and RecentDebited the
+// negative ones.
+type StatusSummary struct {
+	ProfileID      string     `bson:"profile_id"`
+	Status         string     `bson:"status"`
+	Points         int64      `bson:"points"`
+	RecentCount    int64      `bson:"recent_count"`
+	RecentCredited int64      `bson:"recent_credited"`
+	RecentDebited  int64      `bson:"recent_debited"`
+	LastCreatedAt  *time.Time `bson:"last_created_at"`
+}
+
+// ProfileTransactions are the transactions of a profile read by GetUserTransactionHistoryByProfiles.
+type ProfileTransactions struct {
+	ProfileID string                    `bson:"_id"`
+	Items     []*UserTransactionHistory `bson:"items"`
+}
+
+// HistoryCursor matches the transactions older than CreatedAt, or created at the same time with a lower
+// TransactionID, the order of GetUserTransactionHistoryByProfiles.
+type HistoryCursor struct {
+	CreatedAt     time.Time
+	TransactionID string
 }
 
 func (r UserTransactionHistory) CollectionName() string {
@@ -37,5 +73,17 @@ func (r UserTransactionHistory) IndexModels() []mongo.IndexModel {
 			},
 			Options: options.Index().SetUnique(true),
 		},
+		{
+			Keys: bson.D{
+				{Key: FUserTransactionHistoryPaymentTransactionID, Value: 1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: FUserTransactionHistoryProfileID, Value: 1},
+				{Key: FUserTransactionHistoryCreatedAt, Value: -1},
+				{Key: FUserTransactionHistoryTransactionID, Value: -1},
+			},
+		},
 	}
 }
@@ -16,6 +16,10 @@ const (
 	FUserTransactionHistorySource               = "source"
 	FUserTransactionHistorySourceTime           = "source_time"
 	FUserTransactionHistorySourceType           = "source_type"
+	FUserTransactionHistoryStatusHistory        = "status_history"
+	FUserTransactionHistoryOriginalTxID         = "original_transaction_id"
+	FUserTransactionHistoryReversedAmount       = "reversed_amount"
+	FUserTransactionHistoryReversedPointAmount  = "reversed_point_amount"
 	FUserTransactionHistoryCreatedAt            = "created_at"
 	FUserTransactionHistoryUpdatedAt            = "updated_at"
 )
@@ -1,10 +1,14 @@
 package user_transaction_history
 
 import (
+	"build-service-gin/common/crypto"
 	mongodb "build-service-gin/common/mongodb"
+	"build-service-gin/pkg/fieldcrypt"
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
+	"slices"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -12,6 +16,14 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+var (
+	ErrStatusTransitionRejected = errors.New("user transaction history: status transition rejected")
+	// ErrStatusUnchanged is returned with the transaction when a completion finds it already in the status it is
+	// moved to, a redelivered event which changes nothing.
+	ErrStatusUnchanged  = errors.New("user transaction history: status unchanged")
+	ErrReversalConflict = errors.New("user transaction history: reversal conflict")
+)
+
 type UserTransactionHistoryRepo struct {
 	*mongodb.Repository[UserTransactionHistory]
 }
@@ -19,10 +31,16 @@ type UserTransactionHistoryRepo struct {
 type IUserTransactionHistoryRepo interface {
 	GetUserTransactionHistoryByProfile(ctx context.Context, profileID string, txTypes []string, recentMonth time.Time, skip, limit int64, status string) ([]*UserTransactionHistory, int64, error)
 	CreateUserTransactionHistory(ctx context.Context, data *UserTransactionHistory) (*UserTransactionHistory, error)
-	UpdateUserTransactionHistoryByProfile(ctx context.Context, data *UserTransactionHistory, profileID string) (*UserTransactionHistory, error)
-	DeleteUserTransactionHistoryByProfile(ctx context.Context, profileID string) error
-	UpsertCreateOrderTransaction(ctx context.Context, data *UserTransactionHistory) error
-	UpsertCompleteOrderTransaction(ctx context.Context, data *UserTransactionHistory) error
+	UpdateUserTransactionHistoryByProfile(ctx context.Context, data *UserTransactionHistory, profileID string, fromStatuses []string) (*UserTransactionHistory, bool, error)
+	DeleteUserTransactionHistoryByProfile(ctx context.Context, profileID string) (*UserTransactionHistory, error)
+	UpsertCreateOrderTransaction(ctx context.Context, data *UserTransactionHistory) (*UserTransactionHistory, error)
+	UpsertCompleteOrderTransaction(ctx context.Context, data *UserTransactionHistory, fromStatuses []string) (*UserTransactionHistory, error)
+	FindOriginalTransaction(ctx context.Context, transactionID, paymentTransactionID string) (*UserTransactionHistory, error)
+	FindReversal(ctx context.Context, reversalID string) (*UserTransactionHistory, error)
+	ApplyReversal(ctx context.Context, original *UserTransactionHistory, amount float64, pointAmount int64, fromStatuses []string, nextStatus string) error
+	RotateEncryptedFields(ctx context.Context, batchSize int64) (int64, error)
+	GetUserTransactionHistoryByProfiles(ctx context.Context, profileIDs []string, txTypes []string, recentMonth time.Time, status string, after *HistoryCursor, limit int64) ([]*ProfileTransactions, error)
+	SummarizeByProfiles(ctx context.Context, profileIDs []string, since time.Time) ([]*StatusSummary, error)
 }
 
 func NewRepoUserTransactionHistory(dbStorage *mongodb.DatabaseStorage) IUserTransactionHistoryRepo {
@@ -45,6 +63,29 @@ func (r *UserTransactionHistoryRepo) byProfileID(profileID string) *UserTransact
 	return r
 }
 
+func (r *UserTransactionHistoryRepo) byProfileIDs(profileIDs []string) *UserTransactionHistoryRepo {
+	filter := bson.M{
+		FUserTransactionHistoryProfileID: bson.M{"$in": profileIDs},
+	}
+	r.Append(filter)
+	return r
+}
+
+// byAfter matches the transactions following cursor when sorted by creation time then transaction ID, newest first.
+func (r *UserTransactionHistoryRepo) byAfter(cursor *HistoryCursor) *UserTransactionHistoryRepo {
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{FUserTransactionHistoryCreatedAt: bson.M{"$lt": cursor.CreatedAt}},
+			bson.M{
+				FUserTransactionHistoryCreatedAt:     cursor.CreatedAt,
+				FUserTransactionHistoryTransactionID: bson.M{"$lt": cursor.TransactionID},
+			},
+		},
+	}
+	r.Append(filter)
+	return r
+}
+
 func (r *UserTransactionHistoryRepo) byGTECreatedAt(date time.Time) *UserTransactionHistoryRepo {
 	filter := bson.M{
 		FUserTransactionHistoryCreatedAt: bson.M{
@@ -80,6 +121,76 @@ func (r *UserTransactionHistoryRepo) byStatus(status string) *UserTransactionHis
 	return r
 }
 
+// byStatusIn matches documents whose current status is one of fromStatuses.
+// It is the guard of the transaction state machine: an update only applies when the transition is allowed.
+func (r *UserTransactionHistoryRepo) byStatusIn(fromStatuses []string) *UserTransactionHistoryRepo {
+	filter := bson.M{
+		FUserTransactionHistoryStatus: bson.M{"$in": fromStatuses},
+	}
+	r.Append(filter)
+	return r
+}
+
+// byPaymentTransactionID matches the payment transaction ID whichever key version it was encrypted with.
+func (r *UserTransactionHistoryRepo) byPaymentTransactionID(paymentTransactionID string) *UserTransactionHistoryRepo {
+	filter := bson.M{
+		FUserTransactionHistoryPaymentTransactionID: bson.M{"$in": fieldcrypt.Candidates(paymentTransactionID)},
+	}
+	r.Append(filter)
+	return r
+}
+
+// byNotEncryptedWith matches documents whose payment transaction ID is set but not encrypted under keyPrefix.
+func (r *UserTransactionHistoryRepo) byNotEncryptedWith(keyPrefix string) *UserTransactionHistoryRepo {
+	filter := bson.M{
+		FUserTransactionHistoryPaymentTransactionID: bson.M{
+			"$nin": bson.A{nil, ""},
+			"$not": bson.M{"$regex": "^" + regexp.QuoteMeta(keyPrefix)},
+		},
+	}
+	r.Append(filter)
+	return r
+}
+
+// byTransactionIdAfter matches the transactions whose ID sorts after transactionId, to page through them.
+func (r *UserTransactionHistoryRepo) byTransactionIdAfter(transactionId string) *UserTransactionHistoryRepo {
+	filter := bson.M{
+		FUserTransactionHistoryTransactionID: bson.M{"$gt": transactionId},
+	}
+	r.Append(filter)
+	return r
+}
+
+// byOriginal excludes reversal records, which carry the transaction_id of the transaction they reverse.
+func (r *UserTransactionHistoryRepo) byOriginal() *UserTransactionHistoryRepo {
+	filter := bson.M{
+		FUserTransactionHistoryOriginalTxID: bson.M{"$in": bson.A{nil, ""}},
+	}
+	r.Append(filter)
+	return r
+}
+
+// byReversal only matches reversal records.
+func (r *UserTransactionHistoryRepo) byReversal() *UserTransactionHistoryRepo {
+	filter := bson.M{
+		FUserTransactionHistoryOriginalTxID: bson.M{"$nin": bson.A{nil, ""}},
+	}
+	r.Append(filter)
+	return r
+}
+
+// byReversedAmount matches the reversed amount read before the reversal, so concurrent reversals cannot both apply.
+func (r *UserTransactionHistoryRepo) byReversedAmount(reversedAmount float64) *UserTransactionHistoryRepo {
+	filter := bson.M{
+		FUserTransactionHistoryReversedAmount: reversedAmount,
+	}
+	if reversedAmount == 0 {
+		filter[FUserTransactionHistoryReversedAmount] = bson.M{"$in": bson.A{nil, 0}}
+	}
+	r.Append(filter)
+	return r
+}
+
 func (r *UserTransactionHistoryRepo) CheckExistTxType(ctx context.Context, txType string) (*UserTransactionHistory, error) {
 	rs, err := r.R().byTxType(txType).FindOneDoc(ctx)
 	if err != nil {
@@ -139,10 +250,87 @@ func (r *UserTransactionHistoryRepo) GetUserTransactionHistoryByProfile(ctx cont
 	return rs, total, nil
 }
 
+// GetUserTransactionHistoryByProfiles returns, in one query, the limit newest transactions of every profile of
+// profileIDs following after when it is set. Profiles without transactions are left out.
+func (r *UserTransactionHistoryRepo) GetUserTransactionHistoryByProfiles(ctx context.Context, profileIDs []string, txTypes []string, recentMonth time.Time, status string, after *HistoryCursor, limit int64) ([]*ProfileTransactions, error) {
+	queryBuilder := r.R().byProfileIDs(profileIDs).byTxTypes(txTypes).byGTECreatedAt(recentMonth)
+	if status != "" {
+		queryBuilder = queryBuilder.byStatus(status)
+	}
+	if after != nil {
+		queryBuilder = queryBuilder.byAfter(after)
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$sort", Value: bson.D{
+			{Key: FUserTransactionHistoryProfileID, Value: 1},
+			{Key: FUserTransactionHistoryCreatedAt, Value: -1},
+			{Key: FUserTransactionHistoryTransactionID, Value: -1},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$" + FUserTransactionHistoryProfileID,
+			"items": bson.M{"$push": "$$ROOT"},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"items": bson.M{"$slice": bson.A{"$items", limit}},
+		}}},
+	}
+
+	var rs []*ProfileTransactions
+	if err := queryBuilder.AggregateDocs(ctx, pipeline, &rs, options.Aggregate().SetAllowDiskUse(true)); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// SummarizeByProfiles sums, in one query, the points of the transactions of every profile of profileIDs by status,
+// and counts those created since since.
+func (r *UserTransactionHistoryRepo) SummarizeByProfiles(ctx context.Context, profileIDs []string, since time.Time) ([]*StatusSummary, error) {
+	recent := bson.M{"$gte": bson.A{"$" + FUserTransactionHistoryCreatedAt, since}}
+	recentPoints := func(cmp string) bson.M {
+		return bson.M{"$cond": bson.A{
+			bson.M{"$and": bson.A{recent, bson.M{cmp: bson.A{"$" + FUserTransactionHistoryPointAmount, 0}}}},
+			"$" + FUserTransactionHistoryPointAmount,
+			0,
+		}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"profile_id": "$" + FUserTransactionHistoryProfileID,
+				"status":     "$" + FUserTransactionHistoryStatus,
+			},
+			"points":          bson.M{"$sum": "$" + FUserTransactionHistoryPointAmount},
+			"recent_count":    bson.M{"$sum": bson.M{"$cond": bson.A{recent, 1, 0}}},
+			"recent_credited": bson.M{"$sum": recentPoints("$gt")},
+			"recent_debited":  bson.M{"$sum": recentPoints("$lt")},
+			"last_created_at": bson.M{"$max": "$" + FUserTransactionHistoryCreatedAt},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":             0,
+			"profile_id":      "$_id.profile_id",
+			"status":          "$_id.status",
+			"points":          1,
+			"recent_count":    1,
+			"recent_credited": 1,
+			"recent_debited":  1,
+			"last_created_at": 1,
+		}}},
+	}
+
+	var rs []*StatusSummary
+	if err := r.R().byProfileIDs(profileIDs).AggregateDocs(ctx, pipeline, &rs); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
 func (r *UserTransactionHistoryRepo) CreateUserTransactionHistory(ctx context.Context, data *UserTransactionHistory) (*UserTransactionHistory, error) {
 	t := time.Now()
 	data.CreatedAt = &t
 	data.UpdatedAt = &t
+	data.StatusHistory = []StatusHistory{{Status: data.Status, ChangedAt: &t}}
 
 	fmt.Printf("Inserting data: %+v\n", data)
 
@@ -157,7 +345,12 @@ func (r *UserTransactionHistoryRepo) CreateUserTransactionHistory(ctx context.Co
 	return data, nil
 }
 
-func (r *UserTransactionHistoryRepo) UpdateUserTransactionHistoryByProfile(ctx context.Context, data *UserTransactionHistory, profileID string) (*UserTransactionHistory, error) {
+// UpdateUserTransactionHistoryByProfile writes data to the transaction of the profile. A transaction in one of
+// fromStatuses moves to data.Status, recorded in its status history; a transaction already in data.Status only gets
+// its other fields written, statusChanged is then false. Any other status is rejected with
+// ErrStatusTransitionRejected and the current transaction.
+func (r *UserTransactionHistoryRepo) UpdateUserTransactionHistoryByProfile(ctx context.Context, data *UserTransactionHistory, profileID string, fromStatuses []string) (*UserTransactionHistory, bool, error) {
+	now := time.Now()
 	update := bson.M{
 		"$set": bson.M{
 			FUserTransactionHistorySource:               data.Source,
@@ -172,25 +365,58 @@ func (r *UserTransactionHistoryRepo) UpdateUserTransactionHistoryByProfile(ctx c
 			FUserTransactionHistoryPointType:            data.PointType,
 			FUserTransactionHistoryCurrency:             data.Currency,
 			FUserTransactionHistoryStatus:               data.Status,
-			FUserTransactionHistoryUpdatedAt:            time.Now(),
+			FUserTransactionHistoryUpdatedAt:            now,
+		},
+		"$push": bson.M{
+			FUserTransactionHistoryStatusHistory: StatusHistory{Status: data.Status, ChangedAt: &now},
 		},
 	}
-	_, err := r.R().UpdateOneDoc(ctx, update)
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	updated, err := r.R().byProfileID(profileID).byTransactionId(data.TransactionID).byStatusIn(fromStatuses).FindOneAndUpdateDoc(ctx, update, opts)
+	if err == nil {
+		return updated, true, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return data, false, err
+	}
+
+	delete(update, "$push")
+	updated, err = r.R().byProfileID(profileID).byTransactionId(data.TransactionID).byStatus(data.Status).FindOneAndUpdateDoc(ctx, update, opts)
+	if err == nil {
+		return updated, false, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return data, false, err
+	}
+
+	current, err := r.R().byProfileID(profileID).byTransactionId(data.TransactionID).FindOneDoc(ctx)
 	if err != nil {
-		return data, err
+		return data, false, err
 	}
-	return data, nil
+	return current, false, ErrStatusTransitionRejected
 }
 
-func (r *UserTransactionHistoryRepo) DeleteUserTransactionHistoryByProfile(ctx context.Context, profileID string) error {
-	_, err := r.R().byProfileID(profileID).DeleteOneDoc(ctx)
+// statusGuardError explains why the status guard did not match current, the transaction being moved to status.
+func statusGuardError(current *UserTransactionHistory, status string) error {
+	if current.Status == status {
+		return ErrStatusUnchanged
+	}
+	return ErrStatusTransitionRejected
+}
+
+// DeleteUserTransactionHistoryByProfile deletes one transaction of the profile and returns the deleted document.
+func (r *UserTransactionHistoryRepo) DeleteUserTransactionHistoryByProfile(ctx context.Context, profileID string) (*UserTransactionHistory, error) {
+	deleted, err := r.R().byProfileID(profileID).FindOneAndDeleteDoc(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return deleted, nil
 }
 
-func (r *UserTransactionHistoryRepo) UpsertCreateOrderTransaction(ctx context.Context, data *UserTransactionHistory) error {
+// UpsertCreateOrderTransaction creates the transaction of the order identified by its payment transaction ID,
+// or refreshes its source fields when it already exists.
+func (r *UserTransactionHistoryRepo) UpsertCreateOrderTransaction(ctx context.Context, data *UserTransactionHistory) (*UserTransactionHistory, error) {
 	now := time.Now()
 	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
 
@@ -212,30 +438,37 @@ func (r *UserTransactionHistoryRepo) UpsertCreateOrderTransaction(ctx context.Co
 			FUserTransactionHistoryProfileID:       data.ProfileID,
 			FUserTransactionHistoryCreatedAt:       now,
 			FUserTransactionHistoryStatus:          data.Status,
+			FUserTransactionHistoryStatusHistory:   []StatusHistory{{Status: data.Status, ChangedAt: &now}},
 		},
 	}
 
-	_, err := r.R().FindOneAndUpdateDoc(ctx, updater, opts)
+	upserted, err := r.R().byPaymentTransactionID(string(data.PaymentTransactionID)).byOriginal().FindOneAndUpdateDoc(ctx, updater, opts)
 
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return upserted, nil
 }
 
-func (r *UserTransactionHistoryRepo) UpsertCompleteOrderTransaction(ctx context.Context, data *UserTransactionHistory) error {
+// UpsertCompleteOrderTransaction moves the transaction to data.Status, creating it when it does not exist yet.
+// A transaction already in data.Status is returned with ErrStatusUnchanged. When the transaction exists in another
+// status outside fromStatuses ErrStatusTransitionRejected is returned, also when it moves between the read and the
+// upsert: the filter then does not match and the upsert collides with the unique transaction_id index.
+func (r *UserTransactionHistoryRepo) UpsertCompleteOrderTransaction(ctx context.Context, data *UserTransactionHistory, fromStatuses []string) (*UserTransactionHistory, error) {
 	now := time.Now()
 	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
 
 	updater := bson.M{
 		"$set": bson.M{
 			FUserTransactionHistoryPointAmount:     data.PointAmount,
-			FUserTransactionHistoryTransactionID:   data.TransactionID,
 			FUserTransactionHistoryTransactionType: data.TransactionType,
 			FUserTransactionHistoryUpdatedAt:       now,
 			FUserTransactionHistoryPointType:       data.PointType,
 			FUserTransactionHistoryStatus:          data.Status,
 		},
+		"$push": bson.M{
+			FUserTransactionHistoryStatusHistory: StatusHistory{Status: data.Status, ChangedAt: &now},
+		},
 		"$setOnInsert": bson.M{
 			FUserTransactionHistorySource:               data.Source,
 			FUserTransactionHistoryPaymentTransactionID: data.PaymentTransactionID,
@@ -248,10 +481,120 @@ func (r *UserTransactionHistoryRepo) UpsertCompleteOrderTransaction(ctx context.
 		},
 	}
 
-	_, err := r.R().FindOneAndUpdateDoc(ctx, updater, opts)
+	current, err := r.R().byTransactionId(data.TransactionID).FindOneDoc(ctx)
+	switch {
+	case err == nil && !slices.Contains(fromStatuses, current.Status):
+		return current, statusGuardError(current, data.Status)
+	case err != nil && !errors.Is(err, mongo.ErrNoDocuments):
+		return nil, err
+	}
+
+	completed, err := r.R().byTransactionId(data.TransactionID).byStatusIn(fromStatuses).FindOneAndUpdateDoc(ctx, updater, opts)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrStatusTransitionRejected
+		}
+		return nil, err
+	}
+
+	return completed, nil
+}
+
+func (r *UserTransactionHistoryRepo) FindOriginalTransaction(ctx context.Context, transactionID, paymentTransactionID string) (*UserTransactionHistory, error) {
+	queryBuilder := r.R().byOriginal()
+	if transactionID != "" {
+		queryBuilder = queryBuilder.byTransactionId(transactionID)
+	}
+	if paymentTransactionID != "" {
+		queryBuilder = queryBuilder.byPaymentTransactionID(paymentTransactionID)
+	}
+
+	return queryBuilder.FindOneDoc(ctx)
+}
+
+// FindReversal returns the reversal record created with reversalID, mongo.ErrNoDocuments when there is none.
+func (r *UserTransactionHistoryRepo) FindReversal(ctx context.Context, reversalID string) (*UserTransactionHistory, error) {
+	return r.R().byTransactionId(reversalID).byReversal().FindOneDoc(ctx)
+}
+
+// ApplyReversal adds amount/pointAmount to the reversed totals of the original transaction and moves it to
+// nextStatus when it is set. The update only applies while the original is in one of fromStatuses and has not
+// been reversed concurrently, otherwise ErrReversalConflict is returned.
+func (r *UserTransactionHistoryRepo) ApplyReversal(ctx context.Context, original *UserTransactionHistory, amount float64, pointAmount int64, fromStatuses []string, nextStatus string) error {
+	now := time.Now()
+	set := bson.M{
+		FUserTransactionHistoryUpdatedAt: now,
+	}
+	updater := bson.M{
+		"$inc": bson.M{
+			FUserTransactionHistoryReversedAmount:      amount,
+			FUserTransactionHistoryReversedPointAmount: pointAmount,
+		},
+		"$set": set,
+	}
+
+	if nextStatus != "" {
+		set[FUserTransactionHistoryStatus] = nextStatus
+		updater["$push"] = bson.M{
+			FUserTransactionHistoryStatusHistory: StatusHistory{Status: nextStatus, ChangedAt: &now},
+		}
+	}
+
+	rs, err := r.R().
+		byTransactionId(original.TransactionID).
+		byStatusIn(fromStatuses).
+		byReversedAmount(original.ReversedAmount).
+		UpdateOneDoc(ctx, updater)
 	if err != nil {
 		return err
 	}
 
+	if rs.MatchedCount == 0 {
+		return ErrReversalConflict
+	}
 	return nil
 }
+
+// RotateEncryptedFields re-encrypts with the active key the payment transaction IDs written in plaintext or with an
+// older key, batchSize documents at a time, and returns the number of documents rotated. Documents are paged by
+// transaction ID, a document failing to rotate is passed over rather than read again.
+func (r *UserTransactionHistoryRepo) RotateEncryptedFields(ctx context.Context, batchSize int64) (int64, error) {
+	keyring := crypto.Default()
+	if keyring == nil {
+		return 0, errors.New("field encryption is disabled")
+	}
+
+	var rotated int64
+	after := ""
+	for {
+		batch, err := r.R().
+			byNotEncryptedWith(keyring.EncryptPrefix()).
+			byTransactionIdAfter(after).
+			sort(bson.M{FUserTransactionHistoryTransactionID: 1}).
+			limit(batchSize).
+			FindDocs(ctx)
+		if err != nil {
+			return rotated, err
+		}
+		if len(batch) == 0 {
+			return rotated, nil
+		}
+		after = batch[len(batch)-1].TransactionID
+
+		for _, doc := range batch {
+			updater := bson.M{
+				"$set": bson.M{
+					FUserTransactionHistoryPaymentTransactionID: doc.PaymentTransactionID,
+				},
+			}
+			rs, err := r.R().
+				byTransactionId(doc.TransactionID).
+				byPaymentTransactionID(string(doc.PaymentTransactionID)).
+				UpdateManyDocs(ctx, updater)
+			if err != nil {
+				return rotated, err
+			}
+			rotated += rs.ModifiedCount
+		}
+	}
+}
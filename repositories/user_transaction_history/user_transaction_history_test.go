@@ -0,0 +1,96 @@
+package user_transaction_history
+
+import (
+	mongodb "build-service-gin/common/mongodb"
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func newTestRepo(mt *mtest.T) *UserTransactionHistoryRepo {
+	return &UserTransactionHistoryRepo{Repository: &mongodb.Repository[UserTransactionHistory]{Collection: mt.Coll}}
+}
+
+// findAndModifyResponse answers a findAndModify with doc, nil when no document matched.
+func findAndModifyResponse(doc bson.D) bson.D {
+	if doc == nil {
+		return mtest.CreateSuccessResponse(bson.E{Key: "value", Value: nil})
+	}
+	return mtest.CreateSuccessResponse(bson.E{Key: "value", Value: doc})
+}
+
+func TestUpdateUserTransactionHistoryByProfile(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	stored := func(status string, pointAmount int64) bson.D {
+		return bson.D{
+			{Key: FUserTransactionHistoryTransactionID, Value: "tx-1"},
+			{Key: FUserTransactionHistoryProfileID, Value: "p-1"},
+			{Key: FUserTransactionHistoryStatus, Value: status},
+			{Key: FUserTransactionHistoryPointAmount, Value: pointAmount},
+		}
+	}
+	fromStatuses := []string{"PENDING", "PROCESSING"}
+
+	mt.Run("status moves", func(mt *mtest.T) {
+		mt.AddMockResponses(findAndModifyResponse(stored("SUCCESS", 10)))
+
+		updated, statusChanged, err := newTestRepo(mt).UpdateUserTransactionHistoryByProfile(context.Background(),
+			&UserTransactionHistory{TransactionID: "tx-1", Status: "SUCCESS", PointAmount: 10}, "p-1", fromStatuses)
+		if err != nil || !statusChanged || updated.Status != "SUCCESS" {
+			t.Fatalf("UpdateUserTransactionHistoryByProfile() = %+v, %v, %v", updated, statusChanged, err)
+		}
+		if !hasStatusHistoryPush(mt.GetStartedEvent().Command) {
+			t.Error("the status change is not recorded in the status history")
+		}
+	})
+
+	mt.Run("same status writes the other fields", func(mt *mtest.T) {
+		mt.AddMockResponses(findAndModifyResponse(nil), findAndModifyResponse(stored("SUCCESS", 20)))
+
+		updated, statusChanged, err := newTestRepo(mt).UpdateUserTransactionHistoryByProfile(context.Background(),
+			&UserTransactionHistory{TransactionID: "tx-1", Status: "SUCCESS", PointAmount: 20}, "p-1", fromStatuses)
+		if err != nil || statusChanged || updated.PointAmount != 20 {
+			t.Fatalf("UpdateUserTransactionHistoryByProfile() = %+v, %v, %v, want the edited transaction", updated, statusChanged, err)
+		}
+
+		mt.GetStartedEvent() // the guarded status move
+		edit := mt.GetStartedEvent().Command
+		if hasStatusHistoryPush(edit) {
+			t.Error("an edit keeping the status is recorded in the status history")
+		}
+		filter := edit.Lookup("query").Document()
+		if status, _ := filter.Lookup(FUserTransactionHistoryStatus).StringValueOK(); status != "SUCCESS" {
+			t.Errorf("edit filter = %v, want the transaction in its current status", filter)
+		}
+		set := edit.Lookup("update", "$set").Document()
+		if points, _ := set.Lookup(FUserTransactionHistoryPointAmount).AsInt64OK(); points != 20 {
+			t.Errorf("edit $set = %v, want the new point amount", set)
+		}
+	})
+
+	mt.Run("other status is rejected", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			findAndModifyResponse(nil),
+			findAndModifyResponse(nil),
+			mtest.CreateCursorResponse(0, mt.Coll.Database().Name()+"."+mt.Coll.Name(), mtest.FirstBatch, stored("REVERSED", 10)),
+		)
+
+		current, statusChanged, err := newTestRepo(mt).UpdateUserTransactionHistoryByProfile(context.Background(),
+			&UserTransactionHistory{TransactionID: "tx-1", Status: "SUCCESS"}, "p-1", fromStatuses)
+		if !errors.Is(err, ErrStatusTransitionRejected) || statusChanged {
+			t.Fatalf("UpdateUserTransactionHistoryByProfile() = %v, %v, want %v", statusChanged, err, ErrStatusTransitionRejected)
+		}
+		if current.Status != "REVERSED" {
+			t.Errorf("current status = %q, want REVERSED", current.Status)
+		}
+	})
+}
+
+func hasStatusHistoryPush(command bson.Raw) bool {
+	_, err := command.LookupErr("update", "$push", FUserTransactionHistoryStatusHistory)
+	return err == nil
+}
@@ -1,24 +1,63 @@
 package user_transaction_history_postgresql
 
-import "time"
+import (
+	"build-service-gin/pkg/fieldcrypt"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
 
 type UserTransactionHistory struct {
-	TransactionID        string     `gorm:"column:transaction_id;primaryKey"`
-	TransactionType      string     `gorm:"column:transaction_type"`
-	ProfileID            string     `gorm:"column:profile_id"`
-	Status               string     `gorm:"column:status"`
-	PointAmount          int64      `gorm:"column:point_amount"`
-	PointType            int64      `gorm:"column:point_type"`
-	TotalAmount          float64    `gorm:"column:total_amount"`
-	Currency             string     `gorm:"column:currency"`
-	PaymentTransactionID string     `gorm:"column:payment_transaction_id"`
-	Source               string     `gorm:"column:source"`
-	SourceTime           *time.Time `gorm:"column:source_time"`
-	SourceType           string     `gorm:"column:source_type"`
-	CreatedAt            *time.Time `gorm:"column:created_at"`
-	UpdatedAt            *time.Time `gorm:"column:updated_at"`
+	TransactionID        string                   `gorm:"column:transaction_id;primaryKey"`
+	TransactionType      string                   `gorm:"column:transaction_type"`
+	ProfileID            string                   `gorm:"column:profile_id"`
+	Status               string                   `gorm:"column:status"`
+	PointAmount          int64                    `gorm:"column:point_amount"`
+	PointType            int64                    `gorm:"column:point_type"`
+	TotalAmount          float64                  `gorm:"column:total_amount"`
+	Currency             string                   `gorm:"column:currency"`
+	PaymentTransactionID fieldcrypt.Deterministic `gorm:"column:payment_transaction_id"`
+	Source               string                   `gorm:"column:source"`
+	SourceTime           *time.Time               `gorm:"column:source_time"`
+	SourceType           string                   `gorm:"column:source_type"`
+	StatusHistory        StatusHistories          `gorm:"column:status_history;type:jsonb"`
+	CreatedAt            *time.Time               `gorm:"column:created_at"`
+	UpdatedAt            *time.Time               `gorm:"column:updated_at"`
 }
 
 func (UserTransactionHistory) TableName() string {
 	return "user_transaction_history"
 }
+
+type StatusHistory struct {
+	Status    string     `json:"status"`
+	ChangedAt *time.Time `json:"changedAt"`
+}
+
+// StatusHistories is stored as a jsonb array in the status_history column.
+type StatusHistories []StatusHistory
+
+func (s StatusHistories) Value() (driver.Value, error) {
+	if s == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (s *StatusHistories) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	}
+	return fmt.Errorf("status_history: unsupported type %T", value)
+}
@@ -1,12 +1,19 @@
 package user_transaction_history_postgresql
 
 import (
+	"build-service-gin/common/crypto"
 	postgres "build-service-gin/common/postgresql"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
+
+	"gorm.io/gorm"
 )
 
+var ErrStatusTransitionRejected = errors.New("user transaction history postgresql: status transition rejected")
+
 type UserTransactionHistoryPostgresSQLRepo struct {
 	*postgres.Repository[UserTransactionHistory]
 }
@@ -14,8 +21,9 @@ type UserTransactionHistoryPostgresSQLRepo struct {
 type IUserTransactionHistoryPostgresSQLRepo interface {
 	GetUserTransactionHistoryByProfile(ctx context.Context, profileID string, txTypes []string, recentMonth time.Time, skip, limit int64, status string) ([]*UserTransactionHistory, int64, error)
 	CreateUserTransactionHistory(ctx context.Context, data *UserTransactionHistory) (*UserTransactionHistory, error)
-	UpdateUserTransactionHistoryByProfile(ctx context.Context, data *UserTransactionHistory, profileID string) (*UserTransactionHistory, error)
+	UpdateUserTransactionHistoryByProfile(ctx context.Context, data *UserTransactionHistory, profileID string, fromStatuses []string) (*UserTransactionHistory, bool, error)
 	DeleteUserTransactionHistoryByProfile(ctx context.Context, profileID string) error
+	RotateEncryptedFields(ctx context.Context, batchSize int) (int64, error)
 	//UpsertCreateOrderTransaction(ctx context.Context, data *UserTransactionHistory) error
 	//UpsertCompleteOrderTransaction(ctx context.Context, data *UserTransactionHistory) error
 }
@@ -62,6 +70,7 @@ func (r *UserTransactionHistoryPostgresSQLRepo) CreateUserTransactionHistory(ctx
 	t := time.Now()
 	data.CreatedAt = &t
 	data.UpdatedAt = &t
+	data.StatusHistory = StatusHistories{{Status: data.Status, ChangedAt: &t}}
 
 	if err := r.GetDB().Create(data).Error; err != nil {
 		fmt.Printf("Failed to insert data: %+v\nError: %v\n", data, err)
@@ -71,7 +80,17 @@ func (r *UserTransactionHistoryPostgresSQLRepo) CreateUserTransactionHistory(ctx
 	return data, nil
 }
 
-func (r *UserTransactionHistoryPostgresSQLRepo) UpdateUserTransactionHistoryByProfile(ctx context.Context, data *UserTransactionHistory, profileID string) (*UserTransactionHistory, error) {
+// UpdateUserTransactionHistoryByProfile writes data to the transaction of the profile. A transaction in one of
+// fromStatuses moves to data.Status, recorded in its status history; a transaction already in data.Status only gets
+// its other fields written, statusChanged is then false. Any other status is rejected with
+// ErrStatusTransitionRejected.
+func (r *UserTransactionHistoryPostgresSQLRepo) UpdateUserTransactionHistoryByProfile(ctx context.Context, data *UserTransactionHistory, profileID string, fromStatuses []string) (*UserTransactionHistory, bool, error) {
+	now := time.Now()
+	historyEntry, err := json.Marshal(StatusHistories{{Status: data.Status, ChangedAt: &now}})
+	if err != nil {
+		return nil, false, err
+	}
+
 	update := map[string]interface{}{
 		"source":                 data.Source,
 		"source_time":            data.SourceTime,
@@ -85,14 +104,41 @@ func (r *UserTransactionHistoryPostgresSQLRepo) UpdateUserTransactionHistoryByPr
 		"point_type":             data.PointType,
 		"currency":               data.Currency,
 		"status":                 data.Status,
-		"updated_at":             time.Now(),
+		"status_history":         gorm.Expr("COALESCE(status_history, '[]'::jsonb) || ?::jsonb", string(historyEntry)),
+		"updated_at":             now,
 	}
 
-	if err := r.GetDB().Model(&UserTransactionHistory{}).Where("profile_id = ?", profileID).Updates(update).Error; err != nil {
-		return nil, err
+	// an empty fromStatuses renders IN (NULL), which matches no row: the transition is rejected
+	rs := r.GetDB().Model(&UserTransactionHistory{}).
+		Where("profile_id = ?", profileID).
+		Where("transaction_id = ?", data.TransactionID).
+		Where("status IN ?", fromStatuses).
+		Updates(update)
+	if rs.Error != nil {
+		return nil, false, rs.Error
+	}
+	if rs.RowsAffected > 0 {
+		return data, true, nil
 	}
 
-	return data, nil
+	delete(update, "status_history")
+	rs = r.GetDB().Model(&UserTransactionHistory{}).
+		Where("profile_id = ?", profileID).
+		Where("transaction_id = ?", data.TransactionID).
+		Where("status = ?", data.Status).
+		Updates(update)
+	if rs.Error != nil {
+		return nil, false, rs.Error
+	}
+	if rs.RowsAffected > 0 {
+		return data, false, nil
+	}
+
+	var current UserTransactionHistory
+	if err := r.GetDB().Where("profile_id = ? AND transaction_id = ?", profileID, data.TransactionID).First(&current).Error; err != nil {
+		return nil, false, err
+	}
+	return nil, false, ErrStatusTransitionRejected
 }
 
 func (r *UserTransactionHistoryPostgresSQLRepo) DeleteUserTransactionHistoryByProfile(ctx context.Context, profileID string) error {
@@ -115,3 +161,42 @@ func (r *UserTransactionHistoryPostgresSQLRepo) DeleteUserTransactionHistoryByPr
 //		DoUpdates: gorm.AssignmentColumns([]string{"point_amount", "transaction_type", "updated_at", "point_type", "status"}),
 //	}).Create(data).Error
 //}
+
+// RotateEncryptedFields re-encrypts with the active key the payment transaction IDs written in plaintext or with an
+// older key, batchSize rows at a time, and returns the number of rows rotated. Rows are paged by transaction ID, a
+// row failing to rotate is passed over rather than read again.
+func (r *UserTransactionHistoryPostgresSQLRepo) RotateEncryptedFields(ctx context.Context, batchSize int) (int64, error) {
+	keyring := crypto.Default()
+	if keyring == nil {
+		return 0, errors.New("field encryption is disabled")
+	}
+
+	var rotated int64
+	after := ""
+	for {
+		var batch []*UserTransactionHistory
+		err := r.GetDB().WithContext(ctx).
+			Where("payment_transaction_id <> '' AND payment_transaction_id NOT LIKE ?", keyring.EncryptPrefix()+"%").
+			Where("transaction_id > ?", after).
+			Order("transaction_id").
+			Limit(batchSize).
+			Find(&batch).Error
+		if err != nil {
+			return rotated, err
+		}
+		if len(batch) == 0 {
+			return rotated, nil
+		}
+		after = batch[len(batch)-1].TransactionID
+
+		for _, row := range batch {
+			rs := r.GetDB().WithContext(ctx).Model(&UserTransactionHistory{}).
+				Where("transaction_id = ?", row.TransactionID).
+				Update("payment_transaction_id", row.PaymentTransactionID)
+			if rs.Error != nil {
+				return rotated, rs.Error
+			}
+			rotated += rs.RowsAffected
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package user_transaction_history_postgresql
+
+import (
+	postgres "build-service-gin/common/postgresql"
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	gormpostgres "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestRepo(t *testing.T) (*UserTransactionHistoryPostgresSQLRepo, sqlmock.Sqlmock) {
+	t.Helper()
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	db, err := gorm.Open(gormpostgres.New(gormpostgres.Config{Conn: conn}), &gorm.Config{SkipDefaultTransaction: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm.Open() = %v", err)
+	}
+	return &UserTransactionHistoryPostgresSQLRepo{Repository: postgres.NewRepositoryFromDB[UserTransactionHistory](db)}, mock
+}
+
+const (
+	setWithHistory    = `UPDATE "user_transaction_history" SET "currency"=$1,"payment_transaction_id"=$2,"point_amount"=$3,"point_type"=$4,"profile_id"=$5,"source"=$6,"source_time"=$7,"source_type"=$8,"status"=$9,"status_history"=COALESCE(status_history, '[]'::jsonb) || $10::jsonb,"total_amount"=$11,"transaction_id"=$12,"transaction_type"=$13,"updated_at"=$14 WHERE profile_id = $15 AND transaction_id = $16 AND status IN ($17,$18)`
+	setWithoutHistory = `UPDATE "user_transaction_history" SET "currency"=$1,"payment_transaction_id"=$2,"point_amount"=$3,"point_type"=$4,"profile_id"=$5,"source"=$6,"source_time"=$7,"source_type"=$8,"status"=$9,"total_amount"=$10,"transaction_id"=$11,"transaction_type"=$12,"updated_at"=$13 WHERE profile_id = $14 AND transaction_id = $15 AND status = $16`
+	selectCurrent     = `SELECT * FROM "user_transaction_history" WHERE profile_id = $1 AND transaction_id = $2`
+)
+
+func TestUpdateUserTransactionHistoryByProfile(t *testing.T) {
+	fromStatuses := []string{"PENDING", "PROCESSING"}
+
+	t.Run("status moves", func(t *testing.T) {
+		repo, mock := newTestRepo(t)
+		mock.ExpectExec(regexp.QuoteMeta(setWithHistory)).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		_, statusChanged, err := repo.UpdateUserTransactionHistoryByProfile(context.Background(),
+			&UserTransactionHistory{TransactionID: "tx-1", Status: "SUCCESS", PointAmount: 10}, "p-1", fromStatuses)
+		if err != nil || !statusChanged {
+			t.Fatalf("UpdateUserTransactionHistoryByProfile() = %v, %v", statusChanged, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("same status writes the other fields", func(t *testing.T) {
+		repo, mock := newTestRepo(t)
+		mock.ExpectExec(regexp.QuoteMeta(setWithHistory)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(setWithoutHistory)).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), int64(20), sqlmock.AnyArg(), "p-1", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+				"SUCCESS", sqlmock.AnyArg(), "tx-1", sqlmock.AnyArg(), sqlmock.AnyArg(), "p-1", "tx-1", "SUCCESS").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		updated, statusChanged, err := repo.UpdateUserTransactionHistoryByProfile(context.Background(),
+			&UserTransactionHistory{TransactionID: "tx-1", Status: "SUCCESS", PointAmount: 20}, "p-1", fromStatuses)
+		if err != nil || statusChanged || updated.PointAmount != 20 {
+			t.Fatalf("UpdateUserTransactionHistoryByProfile() = %+v, %v, %v, want the edited transaction", updated, statusChanged, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("other status is rejected", func(t *testing.T) {
+		repo, mock := newTestRepo(t)
+		mock.ExpectExec(regexp.QuoteMeta(setWithHistory)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(setWithoutHistory)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta(selectCurrent)).
+			WillReturnRows(sqlmock.NewRows([]string{"transaction_id", "profile_id", "status"}).AddRow("tx-1", "p-1", "REVERSED"))
+
+		_, statusChanged, err := repo.UpdateUserTransactionHistoryByProfile(context.Background(),
+			&UserTransactionHistory{TransactionID: "tx-1", Status: "SUCCESS"}, "p-1", fromStatuses)
+		if !errors.Is(err, ErrStatusTransitionRejected) || statusChanged {
+			t.Fatalf("UpdateUserTransactionHistoryByProfile() = %v, %v, want %v", statusChanged, err, ErrStatusTransitionRejected)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package webhook_delivery
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type WebhookDelivery struct {
+	DeliveryID     string            `bson:"delivery_id"`
+	SubscriptionID string            `bson:"subscription_id"`
+	EventID        string            `bson:"event_id"`
+	EventType      string            `bson:"event_type"`
+	Payload        string            `bson:"payload"`
+	Status         string            `bson:"status"`
+	Attempts       int               `bson:"attempts"`
+	ResponseStatus int               `bson:"response_status"`
+	ResponseBody   string            `bson:"response_body"`
+	Error          string            `bson:"error"`
+	AttemptLogs    []DeliveryAttempt `bson:"attempt_logs"`
+	RedeliveryOf   string            `bson:"redelivery_of,omitempty"`
+	NextAttemptAt  *time.Time        `bson:"next_attempt_at"`
+	DeliveredAt    *time.Time        `bson:"delivered_at"`
+	CreatedAt      *time.Time        `bson:"created_at"`
+	UpdatedAt      *time.Time        `bson:"updated_at"`
+}
+
+type DeliveryAttempt struct {
+	Attempt        int        `bson:"attempt"`
+	ResponseStatus int        `bson:"response_status"`
+	ResponseBody   string     `bson:"response_body"`
+	Error          string     `bson:"error"`
+	DurationMs     int64      `bson:"duration_ms"`
+	AttemptedAt    *time.Time `bson:"attempted_at"`
+}
+
+func (r WebhookDelivery) CollectionName() string {
+	return "webhook_delivery"
+}
+
+func (r WebhookDelivery) IndexModels() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: FWebhookDeliveryDeliveryID, Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: FWebhookDeliverySubscriptionID, Value: 1},
+				{Key: FWebhookDeliveryCreatedAt, Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: FWebhookDeliveryStatus, Value: 1},
+				{Key: FWebhookDeliveryNextAttemptAt, Value: 1},
+			},
+		},
+	}
+}
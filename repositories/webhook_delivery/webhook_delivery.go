@@ -0,0 +1,164 @@
+package webhook_delivery
+
+import (
+	mongodb "build-service-gin/common/mongodb"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type WebhookDeliveryRepo struct {
+	*mongodb.Repository[WebhookDelivery]
+}
+
+type IWebhookDeliveryRepo interface {
+	CreateDelivery(ctx context.Context, data *WebhookDelivery) (*WebhookDelivery, error)
+	GetDelivery(ctx context.Context, deliveryID string) (*WebhookDelivery, error)
+	ListDeliveriesBySubscription(ctx context.Context, subscriptionID, status string, skip, limit int64) ([]*WebhookDelivery, int64, error)
+	RecordAttempt(ctx context.Context, deliveryID, status string, attempt DeliveryAttempt, nextAttemptAt *time.Time) error
+	ClaimDueDelivery(ctx context.Context, statuses []string, now, leaseUntil time.Time) (*WebhookDelivery, error)
+}
+
+func NewRepoWebhookDelivery(dbStorage *mongodb.DatabaseStorage) IWebhookDeliveryRepo {
+	return &WebhookDeliveryRepo{
+		Repository: mongodb.NewRepository[WebhookDelivery](dbStorage),
+	}
+}
+
+func (r *WebhookDeliveryRepo) R() *WebhookDeliveryRepo {
+	return &WebhookDeliveryRepo{
+		Repository: r.Repository.NewFilterPlayer(),
+	}
+}
+
+func (r *WebhookDeliveryRepo) byDeliveryID(deliveryID string) *WebhookDeliveryRepo {
+	filter := bson.M{
+		FWebhookDeliveryDeliveryID: deliveryID,
+	}
+	r.Append(filter)
+	return r
+}
+
+func (r *WebhookDeliveryRepo) bySubscriptionID(subscriptionID string) *WebhookDeliveryRepo {
+	filter := bson.M{
+		FWebhookDeliverySubscriptionID: subscriptionID,
+	}
+	r.Append(filter)
+	return r
+}
+
+func (r *WebhookDeliveryRepo) byStatus(status string) *WebhookDeliveryRepo {
+	filter := bson.M{
+		FWebhookDeliveryStatus: status,
+	}
+	r.Append(filter)
+	return r
+}
+
+func (r *WebhookDeliveryRepo) byStatusIn(statuses []string) *WebhookDeliveryRepo {
+	filter := bson.M{
+		FWebhookDeliveryStatus: bson.M{"$in": statuses},
+	}
+	r.Append(filter)
+	return r
+}
+
+func (r *WebhookDeliveryRepo) byLTENextAttemptAt(date time.Time) *WebhookDeliveryRepo {
+	filter := bson.M{
+		FWebhookDeliveryNextAttemptAt: bson.M{"$lte": date},
+	}
+	r.Append(filter)
+	return r
+}
+
+func (r *WebhookDeliveryRepo) sort(sort bson.M) *WebhookDeliveryRepo {
+	r.AppendSort(sort)
+	return r
+}
+
+func (r *WebhookDeliveryRepo) limit(limit int64) *WebhookDeliveryRepo {
+	r.SetLimit(limit)
+	return r
+}
+
+func (r *WebhookDeliveryRepo) skip(skip int64) *WebhookDeliveryRepo {
+	r.SetSkip(skip)
+	return r
+}
+
+func (r *WebhookDeliveryRepo) CreateDelivery(ctx context.Context, data *WebhookDelivery) (*WebhookDelivery, error) {
+	t := time.Now()
+	data.CreatedAt = &t
+	data.UpdatedAt = &t
+
+	if _, err := r.R().CreateOneDocument(ctx, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (r *WebhookDeliveryRepo) GetDelivery(ctx context.Context, deliveryID string) (*WebhookDelivery, error) {
+	return r.R().byDeliveryID(deliveryID).FindOneDoc(ctx)
+}
+
+func (r *WebhookDeliveryRepo) ListDeliveriesBySubscription(ctx context.Context, subscriptionID, status string, skip, limit int64) ([]*WebhookDelivery, int64, error) {
+	sort := bson.M{FWebhookDeliveryCreatedAt: -1}
+
+	queryBuilder := r.R().bySubscriptionID(subscriptionID)
+	if status != "" {
+		queryBuilder = queryBuilder.byStatus(status)
+	}
+
+	rs, err := queryBuilder.sort(sort).limit(limit).skip(skip).FindDocs(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := queryBuilder.CountDocs(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return rs, total, nil
+}
+
+// RecordAttempt appends attempt to the delivery log and copies its outcome onto the delivery.
+func (r *WebhookDeliveryRepo) RecordAttempt(ctx context.Context, deliveryID, status string, attempt DeliveryAttempt, nextAttemptAt *time.Time) error {
+	now := time.Now()
+	set := bson.M{
+		FWebhookDeliveryStatus:         status,
+		FWebhookDeliveryAttempts:       attempt.Attempt,
+		FWebhookDeliveryResponseStatus: attempt.ResponseStatus,
+		FWebhookDeliveryResponseBody:   attempt.ResponseBody,
+		FWebhookDeliveryError:          attempt.Error,
+		FWebhookDeliveryNextAttemptAt:  nextAttemptAt,
+		FWebhookDeliveryUpdatedAt:      now,
+	}
+	if attempt.ResponseStatus >= 200 && attempt.ResponseStatus < 300 {
+		set[FWebhookDeliveryDeliveredAt] = now
+	}
+
+	update := bson.M{
+		"$set": set,
+		"$push": bson.M{
+			FWebhookDeliveryAttemptLogs: attempt,
+		},
+	}
+	_, err := r.R().byDeliveryID(deliveryID).UpdateOneDoc(ctx, update)
+	return err
+}
+
+// ClaimDueDelivery returns a delivery in one of statuses whose next attempt is due at now and moves its next attempt
+// to leaseUntil, so no other instance attempts it meanwhile. mongo.ErrNoDocuments is returned when none is due.
+func (r *WebhookDeliveryRepo) ClaimDueDelivery(ctx context.Context, statuses []string, now, leaseUntil time.Time) (*WebhookDelivery, error) {
+	update := bson.M{
+		"$set": bson.M{
+			FWebhookDeliveryNextAttemptAt: leaseUntil,
+			FWebhookDeliveryUpdatedAt:     now,
+		},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After).SetSort(bson.M{FWebhookDeliveryNextAttemptAt: 1})
+	return r.R().byStatusIn(statuses).byLTENextAttemptAt(now).FindOneAndUpdateDoc(ctx, update, opts)
+}
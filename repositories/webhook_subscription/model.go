@@ -0,0 +1,45 @@
+package webhook_subscription
+
+import (
+	"build-service-gin/pkg/fieldcrypt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type WebhookSubscription struct {
+	SubscriptionID      string                `bson:"subscription_id"`
+	Name                string                `bson:"name"`
+	URL                 string                `bson:"url"`
+	Secret              fieldcrypt.Randomized `bson:"secret"`
+	EventTypes          []string              `bson:"event_types"`
+	Enabled             bool                  `bson:"enabled"`
+	ConsecutiveFailures int                   `bson:"consecutive_failures"`
+	DisabledAt          *time.Time            `bson:"disabled_at"`
+	DisabledReason      string                `bson:"disabled_reason"`
+	CreatedAt           *time.Time            `bson:"created_at"`
+	UpdatedAt           *time.Time            `bson:"updated_at"`
+}
+
+func (r WebhookSubscription) CollectionName() string {
+	return "webhook_subscription"
+}
+
+func (r WebhookSubscription) IndexModels() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: FWebhookSubscriptionSubscriptionID, Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: FWebhookSubscriptionEnabled, Value: 1},
+				{Key: FWebhookSubscriptionEventTypes, Value: 1},
+			},
+		},
+	}
+}
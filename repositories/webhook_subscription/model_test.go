@@ -0,0 +1,35 @@
+package webhook_subscription
+
+import (
+	"build-service-gin/common/crypto"
+	"bytes"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestSecretEncryptedAtRest(t *testing.T) {
+	keyring, err := crypto.NewKeyring(map[int]string{1: strings.Repeat("ab", 32)}, 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	crypto.SetDefault(keyring)
+	defer crypto.SetDefault(nil)
+
+	raw, err := bson.Marshal(WebhookSubscription{SubscriptionID: "s-1", Secret: "signing-secret"})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if bytes.Contains(raw, []byte("signing-secret")) {
+		t.Fatal("secret stored in plaintext")
+	}
+
+	var got WebhookSubscription
+	if err = bson.Unmarshal(raw, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if got.Secret != "signing-secret" {
+		t.Errorf("Secret = %q, want the plaintext back", got.Secret)
+	}
+}
@@ -0,0 +1,205 @@
+package webhook_subscription
+
+import (
+	mongodb "build-service-gin/common/mongodb"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type WebhookSubscriptionRepo struct {
+	*mongodb.Repository[WebhookSubscription]
+}
+
+type IWebhookSubscriptionRepo interface {
+	CreateSubscription(ctx context.Context, data *WebhookSubscription) (*WebhookSubscription, error)
+	GetSubscription(ctx context.Context, subscriptionID string) (*WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context, skip, limit int64) ([]*WebhookSubscription, int64, error)
+	UpdateSubscription(ctx context.Context, data *WebhookSubscription) (*WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, subscriptionID string) error
+	FindActiveByEventType(ctx context.Context, eventType string) ([]*WebhookSubscription, error)
+	RecordDeliverySuccess(ctx context.Context, subscriptionID string) error
+	RecordDeliveryFailure(ctx context.Context, subscriptionID string, disableAfter int, reason string) (*WebhookSubscription, error)
+}
+
+func NewRepoWebhookSubscription(dbStorage *mongodb.DatabaseStorage) IWebhookSubscriptionRepo {
+	return &WebhookSubscriptionRepo{
+		Repository: mongodb.NewRepository[WebhookSubscription](dbStorage),
+	}
+}
+
+func (r *WebhookSubscriptionRepo) R() *WebhookSubscriptionRepo {
+	return &WebhookSubscriptionRepo{
+		Repository: r.Repository.NewFilterPlayer(),
+	}
+}
+
+func (r *WebhookSubscriptionRepo) bySubscriptionID(subscriptionID string) *WebhookSubscriptionRepo {
+	filter := bson.M{
+		FWebhookSubscriptionSubscriptionID: subscriptionID,
+	}
+	r.Append(filter)
+	return r
+}
+
+func (r *WebhookSubscriptionRepo) byEnabled(enabled bool) *WebhookSubscriptionRepo {
+	filter := bson.M{
+		FWebhookSubscriptionEnabled: enabled,
+	}
+	r.Append(filter)
+	return r
+}
+
+// byEventType matches subscriptions listening to eventType, an empty event filter listens to every event.
+func (r *WebhookSubscriptionRepo) byEventType(eventType string) *WebhookSubscriptionRepo {
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{FWebhookSubscriptionEventTypes: eventType},
+			bson.M{FWebhookSubscriptionEventTypes: bson.M{"$size": 0}},
+			bson.M{FWebhookSubscriptionEventTypes: nil},
+		},
+	}
+	r.Append(filter)
+	return r
+}
+
+func (r *WebhookSubscriptionRepo) byGTEConsecutiveFailures(failures int) *WebhookSubscriptionRepo {
+	filter := bson.M{
+		FWebhookSubscriptionConsecutiveFailures: bson.M{"$gte": failures},
+	}
+	r.Append(filter)
+	return r
+}
+
+func (r *WebhookSubscriptionRepo) sort(sort bson.M) *WebhookSubscriptionRepo {
+	r.AppendSort(sort)
+	return r
+}
+
+func (r *WebhookSubscriptionRepo) limit(limit int64) *WebhookSubscriptionRepo {
+	r.SetLimit(limit)
+	return r
+}
+
+func (r *WebhookSubscriptionRepo) skip(skip int64) *WebhookSubscriptionRepo {
+	r.SetSkip(skip)
+	return r
+}
+
+func (r *WebhookSubscriptionRepo) CreateSubscription(ctx context.Context, data *WebhookSubscription) (*WebhookSubscription, error) {
+	t := time.Now()
+	data.CreatedAt = &t
+	data.UpdatedAt = &t
+
+	if _, err := r.R().CreateOneDocument(ctx, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (r *WebhookSubscriptionRepo) GetSubscription(ctx context.Context, subscriptionID string) (*WebhookSubscription, error) {
+	return r.R().bySubscriptionID(subscriptionID).FindOneDoc(ctx)
+}
+
+func (r *WebhookSubscriptionRepo) ListSubscriptions(ctx context.Context, skip, limit int64) ([]*WebhookSubscription, int64, error) {
+	sort := bson.M{FWebhookSubscriptionCreatedAt: -1}
+
+	rs, err := r.R().sort(sort).limit(limit).skip(skip).FindDocs(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := r.R().CountDocs(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return rs, total, nil
+}
+
+// UpdateSubscription replaces the editable fields of the subscription. The secret is kept when data has none,
+// and enabling a subscription clears the failures that disabled it.
+func (r *WebhookSubscriptionRepo) UpdateSubscription(ctx context.Context, data *WebhookSubscription) (*WebhookSubscription, error) {
+	set := bson.M{
+		FWebhookSubscriptionName:       data.Name,
+		FWebhookSubscriptionURL:        data.URL,
+		FWebhookSubscriptionEventTypes: data.EventTypes,
+		FWebhookSubscriptionEnabled:    data.Enabled,
+		FWebhookSubscriptionUpdatedAt:  time.Now(),
+	}
+	if data.Secret != "" {
+		set[FWebhookSubscriptionSecret] = data.Secret
+	}
+	if data.Enabled {
+		set[FWebhookSubscriptionConsecutiveFailures] = 0
+		set[FWebhookSubscriptionDisabledAt] = nil
+		set[FWebhookSubscriptionDisabledReason] = ""
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	return r.R().bySubscriptionID(data.SubscriptionID).FindOneAndUpdateDoc(ctx, bson.M{"$set": set}, opts)
+}
+
+func (r *WebhookSubscriptionRepo) DeleteSubscription(ctx context.Context, subscriptionID string) error {
+	rs, err := r.R().bySubscriptionID(subscriptionID).DeleteOneDoc(ctx)
+	if err != nil {
+		return err
+	}
+	if rs.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *WebhookSubscriptionRepo) FindActiveByEventType(ctx context.Context, eventType string) ([]*WebhookSubscription, error) {
+	return r.R().byEnabled(true).byEventType(eventType).FindDocs(ctx)
+}
+
+func (r *WebhookSubscriptionRepo) RecordDeliverySuccess(ctx context.Context, subscriptionID string) error {
+	update := bson.M{
+		"$set": bson.M{
+			FWebhookSubscriptionConsecutiveFailures: 0,
+		},
+	}
+	_, err := r.R().bySubscriptionID(subscriptionID).UpdateOneDoc(ctx, update)
+	return err
+}
+
+// RecordDeliveryFailure counts a failed delivery and disables the subscription once disableAfter deliveries in
+// a row have failed. The returned subscription reflects both updates.
+func (r *WebhookSubscriptionRepo) RecordDeliveryFailure(ctx context.Context, subscriptionID string, disableAfter int, reason string) (*WebhookSubscription, error) {
+	now := time.Now()
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	update := bson.M{
+		"$inc": bson.M{
+			FWebhookSubscriptionConsecutiveFailures: 1,
+		},
+	}
+	sub, err := r.R().bySubscriptionID(subscriptionID).FindOneAndUpdateDoc(ctx, update, opts)
+	if err != nil || disableAfter <= 0 || sub.ConsecutiveFailures < disableAfter || !sub.Enabled {
+		return sub, err
+	}
+
+	disable := bson.M{
+		"$set": bson.M{
+			FWebhookSubscriptionEnabled:        false,
+			FWebhookSubscriptionDisabledAt:     now,
+			FWebhookSubscriptionDisabledReason: reason,
+			FWebhookSubscriptionUpdatedAt:      now,
+		},
+	}
+	disabled, err := r.R().bySubscriptionID(subscriptionID).byEnabled(true).byGTEConsecutiveFailures(disableAfter).FindOneAndUpdateDoc(ctx, disable, opts)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			// re-enabled or disabled concurrently
+			return sub, nil
+		}
+		return sub, err
+	}
+	return disabled, nil
+}